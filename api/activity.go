@@ -21,6 +21,11 @@ const (
 	ActivityMemberRoleUpdate ActivityType = "bb.member.role.update"
 	ActivityMemberActivate   ActivityType = "bb.member.activate"
 	ActivityMemberDeactivate ActivityType = "bb.member.deactivate"
+
+	// Database related
+	ActivityDatabaseSchemaDrift      ActivityType = "bb.database.schema.drift"
+	ActivityDatabaseDataSourceGrant  ActivityType = "bb.database.data-source.grant"
+	ActivityDatabaseDataSourceExpire ActivityType = "bb.database.data-source.expire"
 )
 
 func (e ActivityType) String() string {
@@ -43,6 +48,12 @@ func (e ActivityType) String() string {
 		return "bb.member.activate"
 	case ActivityMemberDeactivate:
 		return "bb.member.deactivate"
+	case ActivityDatabaseSchemaDrift:
+		return "bb.database.schema.drift"
+	case ActivityDatabaseDataSourceGrant:
+		return "bb.database.data-source.grant"
+	case ActivityDatabaseDataSourceExpire:
+		return "bb.database.data-source.expire"
 	}
 	return "bb.activity.unknown"
 }
@@ -128,6 +139,25 @@ type ActivityMemberActivateDeactivatePayload struct {
 	Role           Role   `json:"role"`
 }
 
+type ActivityDatabaseSchemaDriftPayload struct {
+	DriftId int    `json:"driftId"`
+	Version string `json:"version"`
+	// Used by inbox to display info without paying the join cost
+	DatabaseName string `json:"databaseName"`
+}
+
+// ActivityDatabaseDataSourcePayload is the payload for both granting and expiring the temporary data source.
+type ActivityDatabaseDataSourcePayload struct {
+	DataSourceId   int            `json:"dataSourceId"`
+	DataSourceName string         `json:"dataSourceName"`
+	DataSourceType DataSourceType `json:"dataSourceType"`
+	Username       string         `json:"username"`
+	RequesterId    int            `json:"requesterId"`
+	ExpireTs       int64          `json:"expireTs"`
+	// Used by inbox to display info without paying the join cost
+	DatabaseName string `json:"databaseName"`
+}
+
 type Activity struct {
 	ID int `jsonapi:"primary,activity"`
 
@@ -51,6 +51,8 @@ type DataSource struct {
 	Type     DataSourceType `jsonapi:"attr,type"`
 	Username string         `jsonapi:"attr,username"`
 	Password string         `jsonapi:"attr,password"`
+	// The temporary data source granted by the data source request expires at ExpireTs, 0 means never.
+	ExpireTs int64 `jsonapi:"attr,expireTs"`
 }
 
 type DataSourceCreate struct {
@@ -67,15 +69,21 @@ type DataSourceCreate struct {
 	Type     DataSourceType `jsonapi:"attr,type"`
 	Username string         `jsonapi:"attr,username"`
 	Password string         `jsonapi:"attr,password"`
+	ExpireTs int64
 }
 
 type DataSourceFind struct {
+	// Standard fields
+	CreatorId *int
+
 	// Related fields
 	InstanceId *int
 	DatabaseId *int
 
 	// Domain specific fields
 	Type *DataSourceType
+	// Finds the temporary data sources expired at or before the timestamp.
+	ExpireTsBefore *int64
 }
 
 func (find *DataSourceFind) String() string {
@@ -98,6 +106,10 @@ type DataSourcePatch struct {
 	Password *string `jsonapi:"attr,password"`
 }
 
+type DataSourceDelete struct {
+	ID int
+}
+
 type DataSourceService interface {
 	CreateDataSource(ctx context.Context, create *DataSourceCreate) (*DataSource, error)
 	// This is specifically used to create the admin data source when creating the instance.
@@ -105,4 +117,5 @@ type DataSourceService interface {
 	FindDataSourceList(ctx context.Context, find *DataSourceFind) ([]*DataSource, error)
 	FindDataSource(ctx context.Context, find *DataSourceFind) (*DataSource, error)
 	PatchDataSource(ctx context.Context, patch *DataSourcePatch) (*DataSource, error)
+	DeleteDataSource(ctx context.Context, delete *DataSourceDelete) error
 }
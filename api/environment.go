@@ -41,6 +41,10 @@ type Environment struct {
 	Name           string         `jsonapi:"attr,name"`
 	Order          int            `jsonapi:"attr,order"`
 	ApprovalPolicy ApprovalPolicy `jsonapi:"attr,approvalPolicy"`
+	// DataApprovalPolicy controls the data migrations, e.g. backfills, while ApprovalPolicy controls the schema migrations.
+	DataApprovalPolicy ApprovalPolicy `jsonapi:"attr,dataApprovalPolicy"`
+	// StatementTimeout is the timeout in seconds of a single statement executed by the tasks, 0 means no timeout.
+	StatementTimeout int `jsonapi:"attr,statementTimeout"`
 }
 
 type EnvironmentCreate struct {
@@ -51,6 +55,9 @@ type EnvironmentCreate struct {
 	// Domain specific fields
 	Name           string         `jsonapi:"attr,name"`
 	ApprovalPolicy ApprovalPolicy `jsonapi:"attr,approvalPolicy"`
+	// Default to ManualApprovalAlways if not set.
+	DataApprovalPolicy ApprovalPolicy `jsonapi:"attr,dataApprovalPolicy"`
+	StatementTimeout   int            `jsonapi:"attr,statementTimeout"`
 }
 
 type EnvironmentFind struct {
@@ -77,9 +84,11 @@ type EnvironmentPatch struct {
 	UpdaterId int
 
 	// Domain specific fields
-	Name           *string `jsonapi:"attr,name"`
-	Order          *int    `jsonapi:"attr,order"`
-	ApprovalPolicy *string `jsonapi:"attr,approvalPolicy"`
+	Name               *string `jsonapi:"attr,name"`
+	Order              *int    `jsonapi:"attr,order"`
+	ApprovalPolicy     *string `jsonapi:"attr,approvalPolicy"`
+	DataApprovalPolicy *string `jsonapi:"attr,dataApprovalPolicy"`
+	StatementTimeout   *int    `jsonapi:"attr,statementTimeout"`
 }
 
 type EnvironmentDelete struct {
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Event is the scheduled event in the database, e.g. MySQL event, not to confuse with the activity.
+type Event struct {
+	ID int `jsonapi:"primary,event"`
+
+	// Standard fields
+	CreatorId int
+	CreatedTs int64 `jsonapi:"attr,createdTs"`
+	UpdaterId int
+	UpdatedTs int64 `jsonapi:"attr,updatedTs"`
+
+	// Related fields
+	DatabaseId int `jsonapi:"attr,databaseId"`
+
+	// Domain specific fields
+	Name       string `jsonapi:"attr,name"`
+	Definition string `jsonapi:"attr,definition"`
+	// e.g. "EVERY 1 DAY" or "AT 2021-10-01 00:00:00"
+	Schedule string `jsonapi:"attr,schedule"`
+	Status   string `jsonapi:"attr,status"`
+	Comment  string `jsonapi:"attr,comment"`
+}
+
+type EventUpsert struct {
+	// Standard fields
+	CreatorId int
+
+	// Related fields
+	DatabaseId int
+
+	// Domain specific fields
+	Name       string
+	Definition string
+	Schedule   string
+	Status     string
+	Comment    string
+}
+
+type EventFind struct {
+	// Related fields
+	DatabaseId int
+}
+
+func (find *EventFind) String() string {
+	str, err := json.Marshal(*find)
+	if err != nil {
+		return err.Error()
+	}
+	return string(str)
+}
+
+type EventDelete struct {
+	ID int
+}
+
+type EventService interface {
+	// UpsertEvent would update the existing event if name matches.
+	UpsertEvent(ctx context.Context, upsert *EventUpsert) (*Event, error)
+	FindEventList(ctx context.Context, find *EventFind) ([]*Event, error)
+	DeleteEvent(ctx context.Context, delete *EventDelete) error
+}
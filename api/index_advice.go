@@ -0,0 +1,30 @@
+package api
+
+import (
+	"github.com/bytebase/bytebase/plugin/db"
+)
+
+// IndexAdvice is an unused or redundant index suggested to drop.
+type IndexAdvice struct {
+	Type      db.IndexAdviceType `json:"type"`
+	TableName string             `json:"tableName"`
+	IndexName string             `json:"indexName"`
+	// The index covering the redundant index, empty for the unused index.
+	CoveringIndexName string `json:"coveringIndexName"`
+	Reason            string `json:"reason"`
+	// The DROP INDEX statement.
+	Statement string `json:"statement"`
+}
+
+// IndexAdviceReport is the index advice of a database.
+type IndexAdviceReport struct {
+	DatabaseId int `jsonapi:"attr,databaseId"`
+	// Same as SqlResultSet, collecting the index usage may fail for connection issue or the engine not supporting it,
+	// so we return error in the response body. The redundant indexes are still reported.
+	UsageError string `jsonapi:"attr,usageError"`
+	// The unused indexes have no reads since UsageSinceTs.
+	UsageSinceTs int64          `jsonapi:"attr,usageSinceTs"`
+	AdviceList   []*IndexAdvice `jsonapi:"attr,adviceList"`
+	// All the DROP INDEX statements, for creating the schema update issue dropping the indexes.
+	Statement string `jsonapi:"attr,statement"`
+}
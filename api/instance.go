@@ -30,6 +30,9 @@ type Instance struct {
 	Host         string  `jsonapi:"attr,host"`
 	Port         string  `jsonapi:"attr,port"`
 	Username     string  `jsonapi:"attr,username"`
+	// Flavor and EngineVersion are detected from the instance by the schema syncer.
+	Flavor        db.Flavor `jsonapi:"attr,flavor"`
+	EngineVersion string    `jsonapi:"attr,engineVersion"`
 	// Password is not returned to the client
 	Password string
 }
@@ -82,6 +85,19 @@ type InstancePatch struct {
 	Port         *string `jsonapi:"attr,port"`
 	Username     *string `jsonapi:"attr,username"`
 	Password     *string `jsonapi:"attr,password"`
+	// Flavor and EngineVersion are only updated by the schema syncer.
+	Flavor        *db.Flavor
+	EngineVersion *string
+}
+
+// InstanceCapability describes the operations supported by the instance engine.
+type InstanceCapability struct {
+	TransactionalDDL bool `jsonapi:"attr,transactionalDDL"`
+	UserAndGrant     bool `jsonapi:"attr,userAndGrant"`
+	BackupRestore    bool `jsonapi:"attr,backupRestore"`
+	OnlineDDL        bool `jsonapi:"attr,onlineDDL"`
+	CreateDatabase   bool `jsonapi:"attr,createDatabase"`
+	IndexUsage       bool `jsonapi:"attr,indexUsage"`
 }
 
 // Instance migration schema status
@@ -128,10 +144,37 @@ type MigrationHistory struct {
 	Version           string             `jsonapi:"attr,version"`
 	Description       string             `jsonapi:"attr,description"`
 	Statement         string             `jsonapi:"attr,statement"`
+	RollbackStatement string             `jsonapi:"attr,rollbackStatement"`
 	ExecutionDuration int                `jsonapi:"attr,executionDuration"`
 	// This is a string instead of int as the issue id may come from other issue tracking system in the future
 	IssueId string `jsonapi:"attr,issueId"`
 	Payload string `jsonapi:"attr,payload"`
+	// The schema snapshots are stored by bytebase instead of the instance, empty if not taken.
+	SchemaPrev string `jsonapi:"attr,schemaPrev"`
+	Schema     string `jsonapi:"attr,schema"`
+	// SchemaDiff is the unified diff from SchemaPrev to Schema.
+	SchemaDiff string `jsonapi:"attr,schemaDiff"`
+}
+
+// MigrationRollback is the API message for rolling back a migration. It creates an issue
+// applying the rollback statement recorded with the migration.
+type MigrationRollback struct {
+	// Standard fields
+	// Value is assigned from the jwt subject field passed by the client.
+	CreatorId int
+
+	// Domain specific fields
+	AssigneeId int `jsonapi:"attr,assigneeId"`
+}
+
+// MigrationHistoryImport is the API message for importing the migration history recorded by another migration tool
+// in the database, so that the database can adopt bytebase without re-baselining.
+type MigrationHistoryImport struct {
+	// Domain specific fields
+	// Engine is either FLYWAY or LIQUIBASE.
+	Engine db.MigrationEngine `jsonapi:"attr,engine"`
+	// Table is the history table of the tool, the default table of the tool if empty.
+	Table string `jsonapi:"attr,table"`
 }
 
 type InstanceService interface {
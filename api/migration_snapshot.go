@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// MigrationSnapshot is the schema-only dump of a database right before and after a migration.
+// The migration history lives in the instance, so it's referenced by the migration history id there.
+type MigrationSnapshot struct {
+	ID int `jsonapi:"primary,migrationSnapshot"`
+
+	// Standard fields
+	CreatorId int
+	CreatedTs int64 `jsonapi:"attr,createdTs"`
+	UpdaterId int
+	UpdatedTs int64 `jsonapi:"attr,updatedTs"`
+
+	// Related fields
+	InstanceId         int    `jsonapi:"attr,instanceId"`
+	DatabaseName       string `jsonapi:"attr,databaseName"`
+	MigrationHistoryId int    `jsonapi:"attr,migrationHistoryId"`
+
+	// Domain specific fields
+	SchemaPrev string `jsonapi:"attr,schemaPrev"`
+	Schema     string `jsonapi:"attr,schema"`
+	// SyncedSchema is the schema synced right after the migration, which is expected until the next migration.
+	SyncedSchema string `jsonapi:"attr,syncedSchema"`
+}
+
+type MigrationSnapshotUpsert struct {
+	// Standard fields
+	CreatorId int
+
+	// Related fields
+	InstanceId         int
+	DatabaseName       string
+	MigrationHistoryId int
+
+	// Domain specific fields
+	SchemaPrev   string
+	Schema       string
+	SyncedSchema string
+}
+
+type MigrationSnapshotFind struct {
+	// Related fields
+	InstanceId         int
+	DatabaseName       *string
+	MigrationHistoryId *int
+}
+
+func (find *MigrationSnapshotFind) String() string {
+	str, err := json.Marshal(*find)
+	if err != nil {
+		return err.Error()
+	}
+	return string(str)
+}
+
+type MigrationSnapshotService interface {
+	// UpsertMigrationSnapshot would update the existing snapshot if the instance and migration history id match.
+	UpsertMigrationSnapshot(ctx context.Context, upsert *MigrationSnapshotUpsert) (*MigrationSnapshot, error)
+	FindMigrationSnapshotList(ctx context.Context, find *MigrationSnapshotFind) ([]*MigrationSnapshot, error)
+}
@@ -62,6 +62,9 @@ type Project struct {
 	Key          string              `jsonapi:"attr,key"`
 	WorkflowType ProjectWorkflowType `jsonapi:"attr,workflowType"`
 	Visibility   ProjectVisibility   `jsonapi:"attr,visibility"`
+	// TenantDatabasePattern is the glob pattern like "tenant_*" selecting the tenant databases of the project, to which
+	// the migration files for db.TenantDatabase apply. Empty means the project has no tenant databases.
+	TenantDatabasePattern string `jsonapi:"attr,tenantDatabasePattern"`
 }
 
 type ProjectCreate struct {
@@ -102,9 +105,10 @@ type ProjectPatch struct {
 	UpdaterId int
 
 	// Domain specific fields
-	Name         *string              `jsonapi:"attr,name"`
-	Key          *string              `jsonapi:"attr,key"`
-	WorkflowType *ProjectWorkflowType `jsonapi:"attr,workflowType"`
+	Name                  *string              `jsonapi:"attr,name"`
+	Key                   *string              `jsonapi:"attr,key"`
+	WorkflowType          *ProjectWorkflowType `jsonapi:"attr,workflowType"`
+	TenantDatabasePattern *string              `jsonapi:"attr,tenantDatabasePattern"`
 }
 
 type ProjectService interface {
@@ -3,6 +3,8 @@ package api
 import (
 	"context"
 	"encoding/json"
+
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 type Repository struct {
@@ -23,12 +25,13 @@ type Repository struct {
 	Project   *Project `jsonapi:"relation,project"`
 
 	// Domain specific fields
-	Name               string `jsonapi:"attr,name"`
-	FullPath           string `jsonapi:"attr,fullPath"`
-	WebURL             string `jsonapi:"attr,webURL"`
-	BaseDirectory      string `jsonapi:"attr,baseDirectory"`
-	BranchFilter       string `jsonapi:"attr,branchFilter"`
-	ExternalId         string `jsonapi:"attr,externalId"`
+	Name               string           `jsonapi:"attr,name"`
+	FullPath           string           `jsonapi:"attr,fullPath"`
+	WebURL             string           `jsonapi:"attr,webURL"`
+	BaseDirectory      string           `jsonapi:"attr,baseDirectory"`
+	BranchFilter       string           `jsonapi:"attr,branchFilter"`
+	VersionScheme      db.VersionScheme `jsonapi:"attr,versionScheme"`
+	ExternalId         string           `jsonapi:"attr,externalId"`
 	ExternalWebhookId  string
 	WebhookURLHost     string
 	WebhookEndpointId  string
@@ -49,12 +52,13 @@ type RepositoryCreate struct {
 	ProjectId int `jsonapi:"attr,projectId"`
 
 	// Domain specific fields
-	Name          string `jsonapi:"attr,name"`
-	FullPath      string `jsonapi:"attr,fullPath"`
-	WebURL        string `jsonapi:"attr,webURL"`
-	BaseDirectory string `jsonapi:"attr,baseDirectory"`
-	BranchFilter  string `jsonapi:"attr,branchFilter"`
-	ExternalId    string `jsonapi:"attr,externalId"`
+	Name          string           `jsonapi:"attr,name"`
+	FullPath      string           `jsonapi:"attr,fullPath"`
+	WebURL        string           `jsonapi:"attr,webURL"`
+	BaseDirectory string           `jsonapi:"attr,baseDirectory"`
+	BranchFilter  string           `jsonapi:"attr,branchFilter"`
+	VersionScheme db.VersionScheme `jsonapi:"attr,versionScheme"`
+	ExternalId    string           `jsonapi:"attr,externalId"`
 	// Token belonged by the user linking the project to the VCS repository. We store this token together
 	// with the refresh token in the new repository record so we can use it to call VCS API on
 	// behalf of that user to perform tasks like webhook CRUD later.
@@ -94,8 +98,9 @@ type RepositoryPatch struct {
 	UpdaterId int
 
 	// Domain specific fields
-	BaseDirectory *string `jsonapi:"attr,baseDirectory"`
-	BranchFilter  *string `jsonapi:"attr,branchFilter"`
+	BaseDirectory *string           `jsonapi:"attr,baseDirectory"`
+	BranchFilter  *string           `jsonapi:"attr,branchFilter"`
+	VersionScheme *db.VersionScheme `jsonapi:"attr,versionScheme"`
 }
 
 type RepositoryDelete struct {
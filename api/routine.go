@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type Routine struct {
+	ID int `jsonapi:"primary,routine"`
+
+	// Standard fields
+	CreatorId int
+	CreatedTs int64 `jsonapi:"attr,createdTs"`
+	UpdaterId int
+	UpdatedTs int64 `jsonapi:"attr,updatedTs"`
+
+	// Related fields
+	DatabaseId int `jsonapi:"attr,databaseId"`
+
+	// Domain specific fields
+	Name string `jsonapi:"attr,name"`
+	// PROCEDURE or FUNCTION
+	Type       string `jsonapi:"attr,type"`
+	Definition string `jsonapi:"attr,definition"`
+	Comment    string `jsonapi:"attr,comment"`
+}
+
+type RoutineUpsert struct {
+	// Standard fields
+	CreatorId int
+
+	// Related fields
+	DatabaseId int
+
+	// Domain specific fields
+	Name       string
+	Type       string
+	Definition string
+	Comment    string
+}
+
+type RoutineFind struct {
+	// Related fields
+	DatabaseId int
+}
+
+func (find *RoutineFind) String() string {
+	str, err := json.Marshal(*find)
+	if err != nil {
+		return err.Error()
+	}
+	return string(str)
+}
+
+type RoutineDelete struct {
+	ID int
+}
+
+type RoutineService interface {
+	// UpsertRoutine would update the existing routine if both name and type match.
+	UpsertRoutine(ctx context.Context, upsert *RoutineUpsert) (*Routine, error)
+	FindRoutineList(ctx context.Context, find *RoutineFind) ([]*Routine, error)
+	DeleteRoutine(ctx context.Context, delete *RoutineDelete) error
+}
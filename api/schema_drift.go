@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// SchemaDriftStatus is the status of a schema drift.
+type SchemaDriftStatus string
+
+const (
+	// SchemaDriftOpen is the status of a drift not resolved yet.
+	SchemaDriftOpen SchemaDriftStatus = "OPEN"
+	// SchemaDriftResolved is the status of a drift whose live schema no longer exists, either because the
+	// live schema matches the expected schema again, or a newer drift or migration supersedes it.
+	SchemaDriftResolved SchemaDriftStatus = "RESOLVED"
+)
+
+func (e SchemaDriftStatus) String() string {
+	switch e {
+	case SchemaDriftOpen:
+		return "OPEN"
+	case SchemaDriftResolved:
+		return "RESOLVED"
+	}
+	return "UNKNOWN"
+}
+
+// SchemaDrift is the live schema of a database diverging from the expected schema after its latest migration.
+type SchemaDrift struct {
+	ID int `jsonapi:"primary,schemaDrift"`
+
+	// Standard fields
+	CreatorId int
+	CreatedTs int64 `jsonapi:"attr,createdTs"`
+	UpdaterId int
+	UpdatedTs int64 `jsonapi:"attr,updatedTs"`
+
+	// Related fields
+	DatabaseId int `jsonapi:"attr,databaseId"`
+
+	// Domain specific fields
+	Status SchemaDriftStatus `jsonapi:"attr,status"`
+	// The latest migration version when the drift is detected.
+	Version string `jsonapi:"attr,version"`
+	// The live schema when the drift is detected.
+	Schema string `jsonapi:"attr,schema"`
+	// The unified diff from the expected schema to the live schema.
+	Diff string `jsonapi:"attr,diff"`
+}
+
+type SchemaDriftCreate struct {
+	// Standard fields
+	CreatorId int
+
+	// Related fields
+	DatabaseId int
+
+	// Domain specific fields
+	Version string
+	Schema  string
+	Diff    string
+}
+
+type SchemaDriftFind struct {
+	ID *int
+
+	// Related fields
+	DatabaseId *int
+
+	// Domain specific fields
+	Status *SchemaDriftStatus
+}
+
+func (find *SchemaDriftFind) String() string {
+	str, err := json.Marshal(*find)
+	if err != nil {
+		return err.Error()
+	}
+	return string(str)
+}
+
+type SchemaDriftPatch struct {
+	ID int
+
+	// Standard fields
+	UpdaterId int
+
+	// Domain specific fields
+	Status *SchemaDriftStatus
+}
+
+type SchemaDriftService interface {
+	CreateSchemaDrift(ctx context.Context, create *SchemaDriftCreate) (*SchemaDrift, error)
+	FindSchemaDriftList(ctx context.Context, find *SchemaDriftFind) ([]*SchemaDrift, error)
+	PatchSchemaDrift(ctx context.Context, patch *SchemaDriftPatch) (*SchemaDrift, error)
+}
@@ -18,6 +18,10 @@ func ProjectShortSlug(project *Project) string {
 	return fmt.Sprintf("%s", slug.Make(project.Name))
 }
 
+func DatabaseSlug(database *Database) string {
+	return fmt.Sprintf("%s-%d", slug.Make(database.Name), database.ID)
+}
+
 func EnvSlug(env *Environment) string {
 	return fmt.Sprintf("%s", slug.Make(env.Name))
 }
@@ -24,6 +24,30 @@ type SqlResultSet struct {
 	Error string `jsonapi:"attr,error"`
 }
 
+type SqlQuery struct {
+	DatabaseId int    `jsonapi:"attr,databaseId"`
+	Statement  string `jsonapi:"attr,statement"`
+	// Max number of rows returned, the server default is used if 0.
+	Limit int `jsonapi:"attr,limit"`
+}
+
+type SqlQueryResult struct {
+	// Same as SqlResultSet, the query may fail for connection issue or the statement itself, so we return error in the response body.
+	Error          string          `jsonapi:"attr,error"`
+	ColumnNameList []string        `jsonapi:"attr,columnNameList"`
+	ColumnTypeList []string        `jsonapi:"attr,columnTypeList"`
+	RowList        [][]interface{} `jsonapi:"attr,rowList"`
+	// Truncated is true if the rows beyond the limit are dropped.
+	Truncated bool `jsonapi:"attr,truncated"`
+}
+
+type SqlDryRunResult struct {
+	// Same as SqlResultSet, the dry run may fail for connection issue, so we return error in the response body.
+	Error string `jsonapi:"attr,error"`
+	// Problems found by the dry run, the migration is expected to succeed if empty.
+	ProblemList []string `jsonapi:"attr,problemList"`
+}
+
 type SqlService interface {
 	Ping(ctx context.Context, config *ConnectionInfo) (*SqlResultSet, error)
 }
@@ -21,20 +21,21 @@ type Table struct {
 	Database   *Database `jsonapi:"relation,database"`
 
 	// Domain specific fields
-	Name                 string     `jsonapi:"attr,name"`
-	Type                 string     `jsonapi:"attr,type"`
-	Engine               string     `jsonapi:"attr,engine"`
-	Collation            string     `jsonapi:"attr,collation"`
-	SyncStatus           SyncStatus `jsonapi:"attr,syncStatus"`
-	LastSuccessfulSyncTs int64      `jsonapi:"attr,lastSuccessfulSyncTs"`
-	RowCount             int64      `jsonapi:"attr,rowCount"`
-	DataSize             int64      `jsonapi:"attr,dataSize"`
-	IndexSize            int64      `jsonapi:"attr,indexSize"`
-	DataFree             int64      `jsonapi:"attr,dataFree"`
-	CreateOptions        string     `jsonapi:"attr,createOptions"`
-	Comment              string     `jsonapi:"attr,comment"`
-	ColumnList           []*Column  `jsonapi:"attr,columnList"`
-	IndexList            []*Index   `jsonapi:"attr,indexList"`
+	Name                 string        `jsonapi:"attr,name"`
+	Type                 string        `jsonapi:"attr,type"`
+	Engine               string        `jsonapi:"attr,engine"`
+	Collation            string        `jsonapi:"attr,collation"`
+	SyncStatus           SyncStatus    `jsonapi:"attr,syncStatus"`
+	LastSuccessfulSyncTs int64         `jsonapi:"attr,lastSuccessfulSyncTs"`
+	RowCount             int64         `jsonapi:"attr,rowCount"`
+	DataSize             int64         `jsonapi:"attr,dataSize"`
+	IndexSize            int64         `jsonapi:"attr,indexSize"`
+	DataFree             int64         `jsonapi:"attr,dataFree"`
+	CreateOptions        string        `jsonapi:"attr,createOptions"`
+	Comment              string        `jsonapi:"attr,comment"`
+	ColumnList           []*Column     `jsonapi:"attr,columnList"`
+	IndexList            []*Index      `jsonapi:"attr,indexList"`
+	ConstraintList       []*Constraint `jsonapi:"attr,constraintList"`
 }
 
 type TableCreate struct {
@@ -86,6 +87,10 @@ type TablePatch struct {
 	// Domain specific fields
 	SyncStatus           *SyncStatus
 	LastSuccessfulSyncTs *int64
+	RowCount             *int64
+	DataSize             *int64
+	IndexSize            *int64
+	DataFree             *int64
 }
 
 type TableService interface {
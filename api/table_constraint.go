@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type Constraint struct {
+	ID int `jsonapi:"primary,constraint"`
+
+	// Standard fields
+	CreatorId int
+	CreatedTs int64 `json:"createdTs"`
+	UpdaterId int
+	UpdatedTs int64 `json:"updatedTs"`
+
+	// Related fields
+	DatabaseId int
+	TableId    int
+
+	// Domain specific fields
+	Name string `json:"name"`
+	// PRIMARY KEY, FOREIGN KEY or CHECK
+	Type                 string   `json:"type"`
+	ColumnList           []string `json:"columnList"`
+	ReferencedTable      string   `json:"referencedTable"`
+	ReferencedColumnList []string `json:"referencedColumnList"`
+	UpdateRule           string   `json:"updateRule"`
+	DeleteRule           string   `json:"deleteRule"`
+	Expression           string   `json:"expression"`
+}
+
+type ConstraintUpsert struct {
+	// Standard fields
+	CreatorId int
+
+	// Related fields
+	DatabaseId int
+	TableId    int
+
+	// Domain specific fields
+	Name                 string
+	Type                 string
+	ColumnList           []string
+	ReferencedTable      string
+	ReferencedColumnList []string
+	UpdateRule           string
+	DeleteRule           string
+	Expression           string
+}
+
+type ConstraintFind struct {
+	// Related fields
+	DatabaseId *int
+	TableId    *int
+}
+
+func (find *ConstraintFind) String() string {
+	str, err := json.Marshal(*find)
+	if err != nil {
+		return err.Error()
+	}
+	return string(str)
+}
+
+type ConstraintDelete struct {
+	ID int
+}
+
+type ConstraintService interface {
+	// UpsertConstraint would update the existing constraint if both type and name match in the same table.
+	UpsertConstraint(ctx context.Context, upsert *ConstraintUpsert) (*Constraint, error)
+	FindConstraintList(ctx context.Context, find *ConstraintFind) ([]*Constraint, error)
+	DeleteConstraint(ctx context.Context, delete *ConstraintDelete) error
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// TableStatGranularity is the length of the bucket a table stat sample falls into.
+type TableStatGranularity string
+
+const (
+	TableStatHour TableStatGranularity = "HOUR"
+	TableStatDay  TableStatGranularity = "DAY"
+)
+
+func (e TableStatGranularity) String() string {
+	switch e {
+	case TableStatHour:
+		return "HOUR"
+	case TableStatDay:
+		return "DAY"
+	}
+	return "UNKNOWN"
+}
+
+// Seconds returns the length of the bucket in seconds.
+func (e TableStatGranularity) Seconds() int64 {
+	switch e {
+	case TableStatHour:
+		return 3600
+	case TableStatDay:
+		return 24 * 3600
+	}
+	return 0
+}
+
+// BucketTs returns the start of the bucket the ts falls into.
+func (e TableStatGranularity) BucketTs(ts int64) int64 {
+	return ts - ts%e.Seconds()
+}
+
+// TableStatUpsert is the sample of a table size taken by the schema sync. The latest sample in the bucket wins.
+type TableStatUpsert struct {
+	// Related fields
+	DatabaseId int
+	TableId    int
+
+	// Domain specific fields
+	Granularity TableStatGranularity
+	// The start of the bucket in UTC.
+	Ts        int64
+	RowCount  int64
+	DataSize  int64
+	IndexSize int64
+}
+
+// TableStatPoint is the size of a table, or the total size of the tables in a database or a project, in a bucket.
+type TableStatPoint struct {
+	Ts        int64 `json:"ts"`
+	RowCount  int64 `json:"rowCount"`
+	DataSize  int64 `json:"dataSize"`
+	IndexSize int64 `json:"indexSize"`
+}
+
+// TableGrowth is the size of a table, a database or a project over time.
+type TableGrowth struct {
+	Granularity TableStatGranularity `jsonapi:"attr,granularity"`
+	// Ordered by the bucket ts.
+	PointList []*TableStatPoint `jsonapi:"attr,pointList"`
+	// The change from the first point to the last point.
+	RowCountGrowth  int64 `jsonapi:"attr,rowCountGrowth"`
+	DataSizeGrowth  int64 `jsonapi:"attr,dataSizeGrowth"`
+	IndexSizeGrowth int64 `jsonapi:"attr,indexSizeGrowth"`
+}
+
+type TableStatFind struct {
+	// Related fields
+	// At least one of them is set, the stats of the tables matching all of them are summed up per bucket.
+	TableId    *int
+	DatabaseId *int
+	ProjectId  *int
+
+	// Domain specific fields
+	Granularity TableStatGranularity
+	// Finds the buckets starting at or after SinceTs.
+	SinceTs *int64
+}
+
+func (find *TableStatFind) String() string {
+	str, err := json.Marshal(*find)
+	if err != nil {
+		return err.Error()
+	}
+	return string(str)
+}
+
+// TableStatDelete prunes the stats of the granularity in the buckets starting before BeforeTs.
+type TableStatDelete struct {
+	Granularity TableStatGranularity
+	BeforeTs    int64
+}
+
+type TableStatService interface {
+	// UpsertTableStat would update the sample in the same bucket of the table.
+	UpsertTableStat(ctx context.Context, upsert *TableStatUpsert) error
+	// FindTableStatPointList returns the stats summed up per bucket, ordered by the bucket ts.
+	FindTableStatPointList(ctx context.Context, find *TableStatFind) ([]*TableStatPoint, error)
+	DeleteTableStat(ctx context.Context, delete *TableStatDelete) error
+}
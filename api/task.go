@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 const ONBOARDING_TASK_ID1 = 101
@@ -48,6 +49,9 @@ const (
 	TaskDatabaseSchemaUpdate TaskType = "bb.task.database.schema.update"
 	TaskDatabaseBackup       TaskType = "bb.task.database.backup"
 	TaskDatabaseRestore      TaskType = "bb.task.database.restore"
+	TaskDatabaseGrant        TaskType = "bb.task.database.grant"
+	TaskDatabaseRevoke       TaskType = "bb.task.database.revoke"
+	TaskDataSourceRequest    TaskType = "bb.task.data-source.request"
 )
 
 // These payload types are only used when marshalling to the json format for saving into the database.
@@ -67,6 +71,20 @@ type TaskDatabaseSchemaUpdatePayload struct {
 	Statement         string               `json:"statement,omitempty"`
 	RollbackStatement string               `json:"rollbackStatement,omitempty"`
 	VCSPushEvent      *common.VCSPushEvent `json:"pushEvent,omitempty"`
+	// If true, dry run the migration first and fail the task without applying if any problem is found.
+	DryRun bool `json:"dryRun,omitempty"`
+	// The migration type to record, empty means a normal SQL migration.
+	MigrationType db.MigrationType `json:"migrationType,omitempty"`
+	// The version scheme of the repository the VCSPushEvent comes from.
+	VersionScheme db.VersionScheme `json:"versionScheme,omitempty"`
+	// Statements before it are skipped, set when retrying the task failed or canceled halfway to resume from the failed
+	// statement.
+	ResumeFromStatement int `json:"resumeFromStatement,omitempty"`
+	// If true, apply the ALTER TABLE statements without blocking the writes, only for the engines supporting online DDL.
+	OnlineDDL bool `json:"onlineDDL,omitempty"`
+	// If true, copy the rows affected by the UPDATE and DELETE statements of a data migration into backup tables before
+	// applying it.
+	BackupAffectedRows bool `json:"backupAffectedRows,omitempty"`
 }
 
 // TaskDatabaseBackupPayload is the task payload for database backup.
@@ -82,6 +100,27 @@ type TaskDatabaseRestorePayload struct {
 	BackupId     int    `json:"backupId,omitempty"`
 }
 
+// TaskDatabaseGrantPayload is the task payload for granting or revoking the privileges on the database.
+type TaskDatabaseGrantPayload struct {
+	Username string `json:"username,omitempty"`
+	// The host of the MySQL user, "%" if empty.
+	Host string `json:"host,omitempty"`
+	// The password for granting is kept in the task secret instead, since the payload is returned by the API.
+	PrivilegeList []string `json:"privilegeList,omitempty"`
+	// Only for revoking, drops the user after revoking the privileges.
+	DropUser bool `json:"dropUser,omitempty"`
+}
+
+// TaskDataSourceRequestPayload is the task payload for requesting the temporary data source access on the database.
+type TaskDataSourceRequestPayload struct {
+	// Either RO or RW.
+	DataSourceType DataSourceType `json:"dataSourceType,omitempty"`
+	// The seconds the granted data source lasts before expiring.
+	TTL int64 `json:"ttl,omitempty"`
+	// The principal the data source is granted to.
+	RequesterId int `json:"requesterId,omitempty"`
+}
+
 type Task struct {
 	ID int `jsonapi:"primary,task"`
 
@@ -109,6 +148,9 @@ type Task struct {
 	Status  TaskStatus `jsonapi:"attr,status"`
 	Type    TaskType   `jsonapi:"attr,type"`
 	Payload string     `jsonapi:"attr,payload"`
+	// Secret is the sensitive input needed to run the task, e.g. the password of the database grant task.
+	// It's never serialized, and cleared once the task is done or canceled.
+	Secret string `json:"-"`
 }
 
 type TaskCreate struct {
@@ -127,15 +169,34 @@ type TaskCreate struct {
 	Name   string     `jsonapi:"attr,name"`
 	Status TaskStatus `jsonapi:"attr,status"`
 	Type   TaskType   `jsonapi:"attr,type"`
-	// Payload is dirived from fields below it
+	// Payload and Secret are dirived from fields below it
 	Payload           string
+	Secret            string
 	Statement         string `jsonapi:"attr,statement"`
 	RollbackStatement string `jsonapi:"attr,rollbackStatement"`
 	DatabaseName      string `jsonapi:"attr,databaseName"`
 	CharacterSet      string `jsonapi:"attr,characterSet"`
 	Collation         string `jsonapi:"attr,collation"`
 	BackupId          *int   `jsonapi:"attr,backupId"`
-	VCSPushEvent      *common.VCSPushEvent
+	DryRun            bool   `jsonapi:"attr,dryRun"`
+	OnlineDDL         bool   `jsonapi:"attr,onlineDDL"`
+	// If true, the statement changes the data instead of the schema, and follows the data approval policy.
+	DataMigration      bool `jsonapi:"attr,dataMigration"`
+	BackupAffectedRows bool `jsonapi:"attr,backupAffectedRows"`
+	// Fields of the database grant and revoke tasks, see TaskDatabaseGrantPayload.
+	Username string `jsonapi:"attr,username"`
+	Host     string `jsonapi:"attr,host"`
+	// Only for granting, creates the user if it doesn't exist and sets its password. Stored as the task secret.
+	Password      string   `jsonapi:"attr,password"`
+	PrivilegeList []string `jsonapi:"attr,privilegeList"`
+	DropUser      bool     `jsonapi:"attr,dropUser"`
+	// Fields of the data source request task, see TaskDataSourceRequestPayload.
+	DataSourceType DataSourceType `jsonapi:"attr,dataSourceType"`
+	TTL            int64          `jsonapi:"attr,ttl"`
+	VCSPushEvent   *common.VCSPushEvent
+	// Only set by the server, e.g. when rolling back a migration.
+	MigrationType db.MigrationType
+	VersionScheme db.VersionScheme
 }
 
 type TaskFind struct {
@@ -167,6 +228,10 @@ type TaskStatusPatch struct {
 	// Domain specific fields
 	Status  TaskStatus `jsonapi:"attr,status"`
 	Comment string     `jsonapi:"attr,comment"`
+	// Only for retrying the failed or canceled schema update task, the index of the statement to resume from.
+	ResumeFromStatement *int `jsonapi:"attr,resumeFromStatement"`
+	// Updates the task payload together with the status, only set by the server.
+	Payload *string
 }
 
 type TaskService interface {
@@ -174,4 +239,5 @@ type TaskService interface {
 	FindTaskList(ctx context.Context, find *TaskFind) ([]*Task, error)
 	FindTask(ctx context.Context, find *TaskFind) (*Task, error)
 	PatchTaskStatus(ctx context.Context, patch *TaskStatusPatch) (*Task, error)
+	PatchTaskRunResult(ctx context.Context, patch *TaskRunResultPatch) (*TaskRun, error)
 }
@@ -50,6 +50,8 @@ type TaskRun struct {
 	Type    TaskType      `jsonapi:"attr,type"`
 	Comment string        `jsonapi:"attr,comment"`
 	Payload string        `jsonapi:"attr,payload"`
+	// Result is updated while the task run is in progress, e.g. TaskRunSchemaUpdateResult for the schema update task.
+	Result string `jsonapi:"attr,result"`
 }
 
 type TaskRunCreate struct {
@@ -96,9 +98,70 @@ type TaskRunStatusPatch struct {
 	Comment string
 }
 
+type TaskRunResultPatch struct {
+	ID int
+
+	// Domain specific fields
+	Result string
+}
+
+// TaskRunStatementStatus is the status of a single statement of the schema update task run.
+type TaskRunStatementStatus string
+
+const (
+	TaskRunStatementPending TaskRunStatementStatus = "PENDING"
+	TaskRunStatementDone    TaskRunStatementStatus = "DONE"
+	TaskRunStatementFailed  TaskRunStatementStatus = "FAILED"
+	// The statement is skipped when resuming from a later statement, since it's applied by the previous task run.
+	TaskRunStatementSkipped TaskRunStatementStatus = "SKIPPED"
+	// The statement is applied but rolled back due to the failure of a later statement, only if the database
+	// supports transactional DDL.
+	TaskRunStatementRolledBack TaskRunStatementStatus = "ROLLED_BACK"
+)
+
+func (e TaskRunStatementStatus) String() string {
+	switch e {
+	case TaskRunStatementPending:
+		return "PENDING"
+	case TaskRunStatementDone:
+		return "DONE"
+	case TaskRunStatementFailed:
+		return "FAILED"
+	case TaskRunStatementSkipped:
+		return "SKIPPED"
+	case TaskRunStatementRolledBack:
+		return "ROLLED_BACK"
+	}
+	return "UNKNOWN"
+}
+
+// TaskRunStatementResult is the result of a single statement of the schema update task run.
+type TaskRunStatementResult struct {
+	Statement string                 `json:"statement"`
+	Status    TaskRunStatementStatus `json:"status"`
+	// Execution duration in milliseconds.
+	Duration int64  `json:"duration"`
+	Error    string `json:"error,omitempty"`
+	// Only set for the ALTER TABLE statement applied by the online DDL table copy.
+	OnlineDDL *TaskRunOnlineDDLProgress `json:"onlineDDL,omitempty"`
+}
+
+// TaskRunOnlineDDLProgress is the table copy progress of the ALTER TABLE statement applied by online DDL.
+type TaskRunOnlineDDLProgress struct {
+	CopiedRows int64 `json:"copiedRows"`
+	// Estimated from the table statistics, which may be lower than CopiedRows.
+	EstimatedRows int64 `json:"estimatedRows"`
+}
+
+// TaskRunSchemaUpdateResult is the task run result for database schema update.
+type TaskRunSchemaUpdateResult struct {
+	StatementList []*TaskRunStatementResult `json:"statementList"`
+}
+
 type TaskRunService interface {
 	CreateTaskRun(ctx context.Context, tx *sql.Tx, create *TaskRunCreate) (*TaskRun, error)
 	FindTaskRunList(ctx context.Context, tx *sql.Tx, find *TaskRunFind) ([]*TaskRun, error)
 	FindTaskRun(ctx context.Context, tx *sql.Tx, find *TaskRunFind) (*TaskRun, error)
 	PatchTaskRunStatus(ctx context.Context, tx *sql.Tx, patch *TaskRunStatusPatch) (*TaskRun, error)
+	PatchTaskRunResult(ctx context.Context, tx *sql.Tx, patch *TaskRunResultPatch) (*TaskRun, error)
 }
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type Trigger struct {
+	ID int `jsonapi:"primary,trigger"`
+
+	// Standard fields
+	CreatorId int
+	CreatedTs int64 `jsonapi:"attr,createdTs"`
+	UpdaterId int
+	UpdatedTs int64 `jsonapi:"attr,updatedTs"`
+
+	// Related fields
+	DatabaseId int `jsonapi:"attr,databaseId"`
+
+	// Domain specific fields
+	Name      string `jsonapi:"attr,name"`
+	TableName string `jsonapi:"attr,tableName"`
+	// BEFORE, AFTER or INSTEAD OF
+	Timing string `jsonapi:"attr,timing"`
+	// INSERT, UPDATE or DELETE
+	Event      string `jsonapi:"attr,event"`
+	Definition string `jsonapi:"attr,definition"`
+}
+
+type TriggerUpsert struct {
+	// Standard fields
+	CreatorId int
+
+	// Related fields
+	DatabaseId int
+
+	// Domain specific fields
+	Name       string
+	TableName  string
+	Timing     string
+	Event      string
+	Definition string
+}
+
+type TriggerFind struct {
+	// Related fields
+	DatabaseId int
+}
+
+func (find *TriggerFind) String() string {
+	str, err := json.Marshal(*find)
+	if err != nil {
+		return err.Error()
+	}
+	return string(str)
+}
+
+type TriggerDelete struct {
+	ID int
+}
+
+type TriggerService interface {
+	// UpsertTrigger would update the existing trigger if both table name and name match.
+	UpsertTrigger(ctx context.Context, upsert *TriggerUpsert) (*Trigger, error)
+	FindTriggerList(ctx context.Context, find *TriggerFind) ([]*Trigger, error)
+	DeleteTrigger(ctx context.Context, delete *TriggerDelete) error
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type View struct {
+	ID int `jsonapi:"primary,view"`
+
+	// Standard fields
+	CreatorId int
+	CreatedTs int64 `jsonapi:"attr,createdTs"`
+	UpdaterId int
+	UpdatedTs int64 `jsonapi:"attr,updatedTs"`
+
+	// Related fields
+	DatabaseId int `jsonapi:"attr,databaseId"`
+
+	// Domain specific fields
+	Name       string `jsonapi:"attr,name"`
+	Definition string `jsonapi:"attr,definition"`
+	Comment    string `jsonapi:"attr,comment"`
+}
+
+type ViewUpsert struct {
+	// Standard fields
+	CreatorId int
+
+	// Related fields
+	DatabaseId int
+
+	// Domain specific fields
+	Name       string
+	Definition string
+	Comment    string
+}
+
+type ViewFind struct {
+	// Related fields
+	DatabaseId int
+}
+
+func (find *ViewFind) String() string {
+	str, err := json.Marshal(*find)
+	if err != nil {
+		return err.Error()
+	}
+	return string(str)
+}
+
+type ViewDelete struct {
+	ID int
+}
+
+type ViewService interface {
+	// UpsertView would update the existing view if name matches.
+	UpsertView(ctx context.Context, upsert *ViewUpsert) (*View, error)
+	FindViewList(ctx context.Context, find *ViewFind) ([]*View, error)
+	DeleteView(ctx context.Context, delete *ViewDelete) error
+}
@@ -4,7 +4,7 @@ package mysqldump
 import (
 	"database/sql"
 	"fmt"
-	"os"
+	"io"
 	"strings"
 
 	"github.com/bytebase/bytebase/bin/bb/connect"
@@ -108,12 +108,12 @@ func (dp *Dumper) GetDumpableDatabases(database string) ([]string, error) {
 }
 
 // Dump dumps the schema of a MySQL instance.
-func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly, dumpAll bool) error {
+func (dp *Dumper) Dump(dbName string, out io.Writer, schemaOnly, dumpAll bool) error {
 	// mysqldump -u root --databases dbName --no-data --routines --events --triggers --compact
 
 	// Database header.
 	header := fmt.Sprintf(databaseHeaderFmt, dbName)
-	if _, err := out.WriteString(header); err != nil {
+	if _, err := io.WriteString(out, header); err != nil {
 		return err
 	}
 
@@ -123,7 +123,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly, dumpAll bool) er
 		return fmt.Errorf("failed to get tables of database %q: %s", dbName, err)
 	}
 	for _, tbl := range tables {
-		if _, err := out.WriteString(fmt.Sprintf("%s\n", tbl.statement)); err != nil {
+		if _, err := io.WriteString(out, fmt.Sprintf("%s\n", tbl.statement)); err != nil {
 			return err
 		}
 		if !schemaOnly && tbl.tableType == "BASE TABLE" {
@@ -132,12 +132,12 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly, dumpAll bool) er
 				return err
 			}
 			for _, stmt := range stmts {
-				if _, err := out.WriteString(stmt); err != nil {
+				if _, err := io.WriteString(out, stmt); err != nil {
 					return err
 				}
 			}
 			if len(stmts) > 0 {
-				if _, err := out.WriteString("\n"); err != nil {
+				if _, err := io.WriteString(out, "\n"); err != nil {
 					return err
 				}
 			}
@@ -150,7 +150,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly, dumpAll bool) er
 		return fmt.Errorf("failed to get routines of database %q: %s", dbName, err)
 	}
 	for _, rt := range routines {
-		if _, err := out.WriteString(fmt.Sprintf("%s\n", rt.statement)); err != nil {
+		if _, err := io.WriteString(out, fmt.Sprintf("%s\n", rt.statement)); err != nil {
 			return err
 		}
 	}
@@ -161,7 +161,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly, dumpAll bool) er
 		return fmt.Errorf("failed to get events of database %q: %s", dbName, err)
 	}
 	for _, et := range events {
-		if _, err := out.WriteString(fmt.Sprintf("%s\n", et.statement)); err != nil {
+		if _, err := io.WriteString(out, fmt.Sprintf("%s\n", et.statement)); err != nil {
 			return err
 		}
 	}
@@ -172,7 +172,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly, dumpAll bool) er
 		return fmt.Errorf("failed to get triggers of database %q: %s", dbName, err)
 	}
 	for _, tr := range triggers {
-		if _, err := out.WriteString(fmt.Sprintf("%s\n", tr.statement)); err != nil {
+		if _, err := io.WriteString(out, fmt.Sprintf("%s\n", tr.statement)); err != nil {
 			return err
 		}
 	}
@@ -4,7 +4,7 @@ package pgdump
 import (
 	"database/sql"
 	"fmt"
-	"os"
+	"io"
 	"regexp"
 	"strings"
 
@@ -211,7 +211,7 @@ func (dp *Dumper) GetDumpableDatabases(database string) ([]string, error) {
 }
 
 // Dump dumps the schema of a Postgres instance.
-func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
+func (dp *Dumper) Dump(dbName string, out io.Writer, schemaOnly bool) error {
 	// pg_dump -d dbName --schema-only
 	if err := dp.conn.SwitchDatabase(dbName); err != nil {
 		return err
@@ -219,7 +219,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
 
 	// Database statement.
 	dbStmt := getDatabaseStmt(dbName)
-	if _, err := out.WriteString(dbStmt); err != nil {
+	if _, err := io.WriteString(out, dbStmt); err != nil {
 		return err
 	}
 
@@ -229,7 +229,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
 		return err
 	}
 	for _, schema := range schemas {
-		if _, err := out.WriteString(schema.Statement()); err != nil {
+		if _, err := io.WriteString(out, schema.Statement()); err != nil {
 			return err
 		}
 	}
@@ -240,7 +240,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
 		return fmt.Errorf("failed to get sequences from database %q: %s", dbName, err)
 	}
 	for _, seq := range seqs {
-		if _, err := out.WriteString(seq.Statement()); err != nil {
+		if _, err := io.WriteString(out, seq.Statement()); err != nil {
 			return err
 		}
 	}
@@ -253,7 +253,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
 
 	constraints := make(map[string]bool)
 	for _, tbl := range tables {
-		if _, err := out.WriteString(tbl.Statement()); err != nil {
+		if _, err := io.WriteString(out, tbl.Statement()); err != nil {
 			return err
 		}
 		for _, constraint := range tbl.constraints {
@@ -266,12 +266,12 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
 				return err
 			}
 			for _, stmt := range stmts {
-				if _, err := out.WriteString(stmt); err != nil {
+				if _, err := io.WriteString(out, stmt); err != nil {
 					return err
 				}
 			}
 			if len(stmts) > 0 {
-				if _, err := out.WriteString("\n"); err != nil {
+				if _, err := io.WriteString(out, "\n"); err != nil {
 					return err
 				}
 			}
@@ -284,7 +284,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
 		return fmt.Errorf("failed to get views from database %q: %s", dbName, err)
 	}
 	for _, view := range views {
-		if _, err := out.WriteString(view.Statement()); err != nil {
+		if _, err := io.WriteString(out, view.Statement()); err != nil {
 			return err
 		}
 	}
@@ -299,7 +299,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
 		if constraints[key] {
 			continue
 		}
-		if _, err := out.WriteString(idx.Statement()); err != nil {
+		if _, err := io.WriteString(out, idx.Statement()); err != nil {
 			return err
 		}
 	}
@@ -310,7 +310,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
 		return fmt.Errorf("failed to get functions from database %q: %s", dbName, err)
 	}
 	for _, f := range fs {
-		if _, err := out.WriteString(f.Statement()); err != nil {
+		if _, err := io.WriteString(out, f.Statement()); err != nil {
 			return err
 		}
 	}
@@ -321,7 +321,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
 		return fmt.Errorf("failed to get triggers from database %q: %s", dbName, err)
 	}
 	for _, tr := range triggers {
-		if _, err := out.WriteString(tr.Statement()); err != nil {
+		if _, err := io.WriteString(out, tr.Statement()); err != nil {
 			return err
 		}
 	}
@@ -332,7 +332,7 @@ func (dp *Dumper) Dump(dbName string, out *os.File, schemaOnly bool) error {
 		return fmt.Errorf("failed to get event triggers from database %q: %s", dbName, err)
 	}
 	for _, evt := range events {
-		if _, err := out.WriteString(evt.Statement()); err != nil {
+		if _, err := io.WriteString(out, evt.Statement()); err != nil {
 			return err
 		}
 	}
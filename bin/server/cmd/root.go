@@ -12,6 +12,7 @@ import (
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/bytebase/bytebase/server"
 	"github.com/bytebase/bytebase/store"
 	"github.com/spf13/cobra"
@@ -65,6 +66,8 @@ var (
 	readonly bool
 	demo     bool
 	debug    bool
+	// How long a migration waits for the lock held by another migration of the same database.
+	migrationLockTimeout time.Duration
 
 	logger *zap.Logger
 
@@ -122,6 +125,7 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&readonly, "readonly", false, "whether to run in read-only mode")
 	rootCmd.PersistentFlags().BoolVar(&demo, "demo", false, "whether to run using demo data")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "whether to enable debug level logging")
+	rootCmd.PersistentFlags().DurationVar(&migrationLockTimeout, "migration-lock-timeout", db.DefaultMigrationLockTimeout, "how long a migration waits for another migration of the same database to finish, e.g. one run by another Bytebase server, before failing")
 }
 
 // -----------------------------------Command Line Config END--------------------------------------
@@ -223,6 +227,7 @@ func newMain() *main {
 	fmt.Printf("readonly=%t\n", readonly)
 	fmt.Printf("demo=%t\n", demo)
 	fmt.Printf("debug=%t\n", debug)
+	fmt.Printf("migrationLockTimeout=%v\n", migrationLockTimeout)
 	fmt.Println("-----Config END-------")
 
 	return &main{
@@ -277,7 +282,7 @@ func (m *main) Run() error {
 
 	m.db = db
 
-	s := server.NewServer(m.l, version, host, port, frontendHost, frontendPort, m.profile.mode, dataDir, m.profile.backupRunnerInterval, config.secret, readonly, demo, debug)
+	s := server.NewServer(m.l, version, host, port, frontendHost, frontendPort, m.profile.mode, dataDir, m.profile.backupRunnerInterval, migrationLockTimeout, config.secret, readonly, demo, debug)
 	s.SettingService = settingService
 	s.PrincipalService = store.NewPrincipalService(m.l, db, s.CacheService)
 	s.MemberService = store.NewMemberService(m.l, db, s.CacheService)
@@ -290,9 +295,17 @@ func (m *main) Run() error {
 	s.InstanceService = store.NewInstanceService(m.l, db, s.CacheService, s.DatabaseService, s.DataSourceService)
 	s.InstanceUserService = store.NewInstanceUserService(m.l, db)
 	s.TableService = store.NewTableService(m.l, db)
+	s.TableStatService = store.NewTableStatService(m.l, db)
 	s.ColumnService = store.NewColumnService(m.l, db)
 	s.IndexService = store.NewIndexService(m.l, db)
+	s.ConstraintService = store.NewConstraintService(m.l, db)
+	s.ViewService = store.NewViewService(m.l, db)
+	s.RoutineService = store.NewRoutineService(m.l, db)
+	s.TriggerService = store.NewTriggerService(m.l, db)
+	s.EventService = store.NewEventService(m.l, db)
 	s.BackupService = store.NewBackupService(m.l, db)
+	s.MigrationSnapshotService = store.NewMigrationSnapshotService(m.l, db)
+	s.SchemaDriftService = store.NewSchemaDriftService(m.l, db)
 	s.IssueService = store.NewIssueService(m.l, db, s.CacheService)
 	s.IssueSubscriberService = store.NewIssueSubscriberService(m.l, db)
 	s.PipelineService = store.NewPipelineService(m.l, db, s.CacheService)
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AffectedRowsQuery returns the SELECT query of the rows affected by the single table UPDATE or DELETE statement,
+// or empty if the statement is neither of them. It returns error if the statement is too complex to tell, e.g. it
+// has a table alias or joins other tables. ORDER BY and LIMIT are ignored, so the query may return more rows than
+// the statement affects.
+func AffectedRowsQuery(statement string) (string, error) {
+	tokenList := tokenize(statement)
+	if len(tokenList) == 0 {
+		return "", nil
+	}
+	errUnsupported := fmt.Errorf("only the single table UPDATE and DELETE without table alias are supported")
+
+	i := 1
+	isUpdate := tokenList[0].is("UPDATE")
+	switch {
+	case isUpdate:
+		for i < len(tokenList) && (tokenList[i].is("LOW_PRIORITY") || tokenList[i].is("IGNORE") || tokenList[i].is("ONLY")) {
+			i++
+		}
+	case tokenList[0].is("DELETE"):
+		for i < len(tokenList) && (tokenList[i].is("LOW_PRIORITY") || tokenList[i].is("QUICK") || tokenList[i].is("IGNORE")) {
+			i++
+		}
+		var hasFrom bool
+		if i, hasFrom = skipKeywords(tokenList, i, "FROM"); !hasFrom {
+			return "", errUnsupported
+		}
+		i, _ = skipKeywords(tokenList, i, "ONLY")
+	default:
+		return "", nil
+	}
+
+	// Keep the table name as written, so that the quoting matches the engine.
+	if i >= len(tokenList) || !tokenList[i].isName() {
+		return "", errUnsupported
+	}
+	nameStart := tokenList[i-1].end
+	i++
+	for i+1 < len(tokenList) && tokenList[i].is(".") && tokenList[i+1].isName() {
+		i += 2
+	}
+	table := strings.TrimSpace(statement[nameStart:tokenList[i-1].end])
+
+	if isUpdate {
+		if i >= len(tokenList) || !tokenList[i].is("SET") {
+			return "", errUnsupported
+		}
+	} else if i < len(tokenList) && !tokenList[i].is("WHERE") && !tokenList[i].is("ORDER") && !tokenList[i].is("LIMIT") && !tokenList[i].is("RETURNING") {
+		return "", errUnsupported
+	}
+
+	// Find the WHERE clause at the top level, the subqueries are in the parentheses.
+	where, end := -1, len(tokenList)
+	depth := 0
+	for ; i < len(tokenList); i++ {
+		t := tokenList[i]
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		case depth > 0:
+		case t.is("FROM") || t.is("USING"):
+			// UPDATE ... FROM joins other tables.
+			return "", errUnsupported
+		case t.is("WHERE") && where < 0:
+			where = i
+		case t.is("ORDER") || t.is("LIMIT") || t.is("RETURNING"):
+			end = i
+		}
+		if end < len(tokenList) {
+			break
+		}
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s", table)
+	if where < 0 {
+		return query, nil
+	}
+	if where+1 >= end {
+		return "", fmt.Errorf("empty WHERE clause")
+	}
+	if tokenList[where+1].is("CURRENT") {
+		return "", fmt.Errorf("WHERE CURRENT OF cursor is not supported")
+	}
+	condition := strings.TrimSpace(statement[tokenList[where].end:tokenList[end-1].end])
+	return fmt.Sprintf("%s WHERE %s", query, condition), nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import "testing"
+
+func TestAffectedRowsQuery(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      string
+		wantErr   bool
+	}{
+		{
+			statement: "UPDATE t1 SET c1 = 'a, b', c2 = (SELECT MAX(c2) FROM t2 WHERE t2.id = 1) WHERE id IN (SELECT id FROM t3 WHERE c = 'x')",
+			want:      "SELECT * FROM t1 WHERE id IN (SELECT id FROM t3 WHERE c = 'x')",
+		},
+		{
+			statement: "update low_priority `db1`.`my t` set c1 = 1 where c2 > 0 order by id limit 10",
+			want:      "SELECT * FROM `db1`.`my t` WHERE c2 > 0",
+		},
+		{
+			statement: "-- backfill\nDELETE FROM ONLY public.t1 WHERE created_ts < 100 RETURNING id",
+			want:      "SELECT * FROM public.t1 WHERE created_ts < 100",
+		},
+		{
+			statement: "DELETE FROM t1",
+			want:      "SELECT * FROM t1",
+		},
+		{
+			statement: "UPDATE t1 a SET c1 = 1",
+			wantErr:   true,
+		},
+		{
+			statement: "UPDATE t1 SET c1 = t2.c1 FROM t2 WHERE t1.id = t2.id",
+			wantErr:   true,
+		},
+		{
+			statement: "UPDATE t1, t2 SET t1.c1 = t2.c1 WHERE t1.id = t2.id",
+			wantErr:   true,
+		},
+		{
+			statement: "DELETE t1 FROM t1 JOIN t2 ON t1.id = t2.id",
+			wantErr:   true,
+		},
+		{
+			statement: "DELETE FROM t1 USING t2 WHERE t1.id = t2.id",
+			wantErr:   true,
+		},
+		{
+			statement: "INSERT INTO t1 VALUES (1)",
+		},
+	}
+
+	for _, test := range tests {
+		got, err := AffectedRowsQuery(test.statement)
+		if (err != nil) != test.wantErr {
+			t.Errorf("AffectedRowsQuery(%q) got error %v, want error %v", test.statement, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("AffectedRowsQuery(%q) got %q, want %q", test.statement, got, test.want)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"embed"
 	"fmt"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/bytebase/bytebase/common"
 	"go.uber.org/zap"
@@ -14,17 +17,126 @@ import (
 type Type string
 
 const (
-	Mysql Type = "MYSQL"
+	Mysql    Type = "MYSQL"
+	Postgres Type = "POSTGRES"
+	SQLite   Type = "SQLITE"
 )
 
 func (e Type) String() string {
 	switch e {
 	case Mysql:
 		return "MYSQL"
+	case Postgres:
+		return "POSTGRES"
+	case SQLite:
+		return "SQLITE"
 	}
 	return "UNKNOWN"
 }
 
+// Flavor distinguishes the database engines sharing the same driver type,
+// e.g. TiDB, MariaDB and OceanBase all speak the MySQL protocol.
+type Flavor string
+
+const (
+	FlavorMySQL     Flavor = "MYSQL"
+	FlavorMariaDB   Flavor = "MARIADB"
+	FlavorTiDB      Flavor = "TIDB"
+	FlavorOceanBase Flavor = "OCEANBASE"
+	FlavorPostgres  Flavor = "POSTGRES"
+	FlavorSQLite    Flavor = "SQLITE"
+)
+
+func (e Flavor) String() string {
+	switch e {
+	case FlavorMySQL:
+		return "MYSQL"
+	case FlavorMariaDB:
+		return "MARIADB"
+	case FlavorTiDB:
+		return "TIDB"
+	case FlavorOceanBase:
+		return "OCEANBASE"
+	case FlavorPostgres:
+		return "POSTGRES"
+	case FlavorSQLite:
+		return "SQLITE"
+	}
+	return "UNKNOWN"
+}
+
+// ServerInfo describes the database server the driver connects to.
+type ServerInfo struct {
+	Flavor Flavor
+	// Version is the version of the flavor, e.g. "5.0.1" for TiDB v5.0.1 even though it reports itself as MySQL 5.7.25.
+	Version string
+}
+
+// Operation is an operation which may not be supported by every driver.
+type Operation string
+
+const (
+	// Rolling back DDL statements together with the enclosing transaction.
+	OperationTransactionalDDL Operation = "TRANSACTIONAL_DDL"
+	// Managing users and their grants.
+	OperationUserAndGrant Operation = "USER_AND_GRANT"
+	// Backing up a database and restoring from the backup.
+	OperationBackupRestore Operation = "BACKUP_RESTORE"
+	// Changing the table schema without blocking the writes.
+	OperationOnlineDDL Operation = "ONLINE_DDL"
+	// Creating a new database.
+	OperationCreateDatabase Operation = "CREATE_DATABASE"
+	// Collecting the usage statistics of the indexes.
+	OperationIndexUsage Operation = "INDEX_USAGE"
+)
+
+func (e Operation) String() string {
+	switch e {
+	case OperationTransactionalDDL:
+		return "TRANSACTIONAL_DDL"
+	case OperationUserAndGrant:
+		return "USER_AND_GRANT"
+	case OperationBackupRestore:
+		return "BACKUP_RESTORE"
+	case OperationOnlineDDL:
+		return "ONLINE_DDL"
+	case OperationCreateDatabase:
+		return "CREATE_DATABASE"
+	case OperationIndexUsage:
+		return "INDEX_USAGE"
+	}
+	return "UNKNOWN"
+}
+
+// Capability describes the operations supported by a driver, it's registered together with the driver.
+type Capability struct {
+	TransactionalDDL bool
+	UserAndGrant     bool
+	BackupRestore    bool
+	OnlineDDL        bool
+	CreateDatabase   bool
+	IndexUsage       bool
+}
+
+// Supports returns whether the operation is supported.
+func (c Capability) Supports(op Operation) bool {
+	switch op {
+	case OperationTransactionalDDL:
+		return c.TransactionalDDL
+	case OperationUserAndGrant:
+		return c.UserAndGrant
+	case OperationBackupRestore:
+		return c.BackupRestore
+	case OperationOnlineDDL:
+		return c.OnlineDDL
+	case OperationCreateDatabase:
+		return c.CreateDatabase
+	case OperationIndexUsage:
+		return c.IndexUsage
+	}
+	return false
+}
+
 type DBUser struct {
 	Name  string
 	Grant string
@@ -52,21 +164,71 @@ type DBColumn struct {
 	Comment      string
 }
 
+type DBConstraint struct {
+	Name string
+	// PRIMARY KEY, FOREIGN KEY or CHECK
+	Type string
+	// The constrained columns in order, empty for CHECK
+	ColumnList []string
+	// The referenced table of FOREIGN KEY, qualified as {{database}}.{{table}} if it's in another database
+	ReferencedTable      string
+	ReferencedColumnList []string
+	// ON UPDATE and ON DELETE rules of FOREIGN KEY, e.g. CASCADE
+	UpdateRule string
+	DeleteRule string
+	// The check clause of CHECK
+	Expression string
+}
+
 type DBTable struct {
-	Name          string
-	CreatedTs     int64
-	UpdatedTs     int64
-	Type          string
-	Engine        string
-	Collation     string
-	RowCount      int64
-	DataSize      int64
-	IndexSize     int64
-	DataFree      int64
-	CreateOptions string
-	Comment       string
-	ColumnList    []DBColumn
-	IndexList     []DBIndex
+	Name           string
+	CreatedTs      int64
+	UpdatedTs      int64
+	Type           string
+	Engine         string
+	Collation      string
+	RowCount       int64
+	DataSize       int64
+	IndexSize      int64
+	DataFree       int64
+	CreateOptions  string
+	Comment        string
+	ColumnList     []DBColumn
+	IndexList      []DBIndex
+	ConstraintList []DBConstraint
+}
+
+type DBView struct {
+	Name       string
+	Definition string
+	Comment    string
+}
+
+type DBRoutine struct {
+	Name string
+	// PROCEDURE or FUNCTION
+	Type       string
+	Definition string
+	Comment    string
+}
+
+type DBTrigger struct {
+	Name      string
+	TableName string
+	// BEFORE, AFTER or INSTEAD OF
+	Timing string
+	// INSERT, UPDATE or DELETE, Postgres trigger may fire on multiple events, e.g. "INSERT OR UPDATE"
+	Event      string
+	Definition string
+}
+
+type DBEvent struct {
+	Name       string
+	Definition string
+	// e.g. "EVERY 1 DAY" or "AT 2021-10-01 00:00:00"
+	Schedule string
+	Status   string
+	Comment  string
 }
 
 type DBSchema struct {
@@ -75,11 +237,18 @@ type DBSchema struct {
 	Collation    string
 	UserList     []DBUser
 	TableList    []DBTable
+	// Views are also listed in the TableList as the "VIEW" type table, ViewList carries their definitions.
+	ViewList    []DBView
+	RoutineList []DBRoutine
+	TriggerList []DBTrigger
+	EventList   []DBEvent
 }
 
 var (
 	driversMu sync.RWMutex
 	drivers   = make(map[Type]DriverFunc)
+	// Guarded by driversMu as well.
+	capabilities = make(map[Type]Capability)
 )
 
 type DriverConfig struct {
@@ -93,6 +262,9 @@ type MigrationEngine string
 const (
 	UI  MigrationEngine = "UI"
 	VCS MigrationEngine = "VCS"
+	// Flyway and Liquibase are the migration history imported from the migration tools, see ImportMigrationHistory.
+	Flyway    MigrationEngine = "FLYWAY"
+	Liquibase MigrationEngine = "LIQUIBASE"
 )
 
 func (e MigrationEngine) String() string {
@@ -101,6 +273,10 @@ func (e MigrationEngine) String() string {
 		return "UI"
 	case VCS:
 		return "VCS"
+	case Flyway:
+		return "FLYWAY"
+	case Liquibase:
+		return "LIQUIBASE"
 	}
 	return "UNKNOWN"
 }
@@ -111,6 +287,11 @@ const (
 	Baseline MigrationType = "BASELINE"
 	Sql      MigrationType = "SQL"
 	Branch   MigrationType = "BRANCH"
+	// Rollback migration applies the recorded rollback statement of a previous migration.
+	Rollback MigrationType = "ROLLBACK"
+	// Data migration changes the data instead of the schema, e.g. backfilling a new column, which is tracked
+	// separately from the schema migrations and has its own approval policy.
+	Data MigrationType = "DATA"
 )
 
 func (e MigrationType) String() string {
@@ -121,10 +302,35 @@ func (e MigrationType) String() string {
 		return "SQL"
 	case Branch:
 		return "BRANCH"
+	case Rollback:
+		return "ROLLBACK"
+	case Data:
+		return "DATA"
 	}
 	return "UNKNOWN"
 }
 
+// latestMigrationSchemaVersion is the version of the bytebase migration schema this code expects,
+// recorded as "bb.schema.version" in the bytebase.setting table.
+// Bumping it requires a {{engine}}_migration_schema_upgrade_{{version}}.sql for each engine.
+const latestMigrationSchemaVersion = 4
+
+//go:embed *_migration_schema_upgrade_*.sql
+var migrationSchemaUpgradeFS embed.FS
+
+// getMigrationSchemaUpgradeList returns the statements upgrading the migration schema from version to latestMigrationSchemaVersion in order.
+func getMigrationSchemaUpgradeList(engine string, version int) ([]string, error) {
+	var list []string
+	for v := version + 1; v <= latestMigrationSchemaVersion; v++ {
+		buf, err := migrationSchemaUpgradeFS.ReadFile(fmt.Sprintf("%s_migration_schema_upgrade_%d.sql", engine, v))
+		if err != nil {
+			return nil, fmt.Errorf("failed to find %s migration schema upgrade to version %d: %w", engine, v, err)
+		}
+		list = append(list, string(buf))
+	}
+	return list, nil
+}
+
 type MigrationInfoPayload struct {
 	VCSPushEvent *common.VCSPushEvent `json:"pushEvent,omitempty"`
 }
@@ -140,6 +346,66 @@ type MigrationInfo struct {
 	Creator     string
 	IssueId     string
 	Payload     string
+	// RollbackStatement is recorded together with the migration so that it can be rolled back later.
+	RollbackStatement string
+	// VersionScheme decides the version order when checking the migration precondition, empty is LexicographicVersion.
+	VersionScheme VersionScheme
+	// LockTimeout is how long to wait for the migration lock of the namespace, DefaultMigrationLockTimeout if not set.
+	LockTimeout time.Duration
+	// Tenant is true if the migration applies to every tenant database of the project, see TenantDatabase.
+	// Database and Namespace are left empty by ParseMigrationInfo, which are set to each tenant database.
+	Tenant bool
+}
+
+// TenantDatabase is the database name in the migration filename for the migration applied to every tenant database,
+// e.g. "v1__@tenant__add_column". The tenant databases are selected by the tenant database pattern of the project.
+const TenantDatabase = "@tenant"
+
+// MigrationExecution executes the migration statement by statement, so that we know which statements have been
+// applied if the migration fails halfway without transactional DDL.
+type MigrationExecution struct {
+	// StatementList is the migration statement split by SplitStatement.
+	StatementList []string
+	// Statements before StartIndex are skipped, e.g. they have been applied by the previous attempt.
+	StartIndex int
+	// Progress is called after executing each statement, err is nil if the statement succeeds.
+	Progress func(index int, duration time.Duration, err error)
+	// OnlineDDL applies the ALTER TABLE statements without blocking the writes if set, only supported by the engines
+	// with OperationOnlineDDL capability.
+	OnlineDDL *OnlineDDLConfig
+}
+
+// MigrationStatementError is the error of the failed statement when executing the migration statement by statement.
+type MigrationStatementError struct {
+	// Index of the statement in MigrationExecution.StatementList.
+	Index     int
+	Statement string
+	Err       error
+}
+
+func (e *MigrationStatementError) Error() string {
+	return fmt.Sprintf("statement #%d failed: %v, statement: %q", e.Index+1, e.Err, e.Statement)
+}
+
+func (e *MigrationStatementError) Unwrap() error {
+	return e.Err
+}
+
+// MigrationHistoryError is returned if the migration statement is applied but its history fails to be recorded, e.g.
+// the Postgres migration history lives in another database and can't be committed atomically with the statement.
+// Retrying the migration would apply the statement again, the history needs to be reconciled manually instead.
+type MigrationHistoryError struct {
+	Namespace string
+	Version   string
+	Err       error
+}
+
+func (e *MigrationHistoryError) Error() string {
+	return fmt.Sprintf("migration %s of namespace %q has been applied but failed to record the migration history: %v, please record the migration history manually, e.g. by baselining the database, instead of retrying the migration", e.Version, e.Namespace, e.Err)
+}
+
+func (e *MigrationHistoryError) Unwrap() error {
+	return e.Err
 }
 
 // ParseMigrationInfo derives MigrationInfo from fullPath and baseDir
@@ -149,7 +415,10 @@ type MigrationInfo struct {
 // - {{version}}__db1__create_t1 (a normal migration with "create t1" as description)
 // - {{version}}__db1__baseline  (a baseline migration without description)
 // - {{version}}__db1__baseline__create_t1  (a baseline migration with "create t1" as description)
-func ParseMigrationInfo(fullPath string, baseDir string) (*MigrationInfo, error) {
+// - {{version}}__db1__data__backfill_c1  (a data migration with "backfill c1" as description)
+// - {{version}}__@tenant__create_t1 (a normal migration applied to every tenant database, see TenantDatabase)
+// The {{version}} must be valid under the versionScheme.
+func ParseMigrationInfo(fullPath string, baseDir string, versionScheme VersionScheme) (*MigrationInfo, error) {
 	filename := filepath.Base(fullPath)
 	parentDir := filepath.Base(filepath.Clean(filepath.Dir(strings.TrimPrefix(fullPath, baseDir))))
 	if parentDir == "." || parentDir == "/" {
@@ -160,19 +429,36 @@ func ParseMigrationInfo(fullPath string, baseDir string) (*MigrationInfo, error)
 		return nil, fmt.Errorf("invalid filename format, got %v, want {{version}}__{{dbname}}[__{{type}}][__{{description}}].sql", filename)
 	}
 
+	if err := ValidateVersion(versionScheme, parts[0]); err != nil {
+		return nil, fmt.Errorf("invalid filename %v: %w", filename, err)
+	}
+
 	mi := &MigrationInfo{
-		Engine:      VCS,
-		Version:     parts[0],
-		Namespace:   parts[1],
-		Database:    parts[1],
-		Environment: parentDir,
+		Engine:        VCS,
+		Version:       parts[0],
+		Namespace:     parts[1],
+		Database:      parts[1],
+		Environment:   parentDir,
+		VersionScheme: versionScheme,
+	}
+	databaseName := mi.Database
+	if parts[1] == TenantDatabase {
+		mi.Tenant = true
+		mi.Namespace = ""
+		mi.Database = ""
+		databaseName = "tenant"
 	}
 
 	migrationType := Sql
 	description := ""
 	if len(parts) > 2 {
-		if parts[2] == "baseline" {
+		switch parts[2] {
+		case "baseline":
 			migrationType = Baseline
+		case "data":
+			migrationType = Data
+		}
+		if migrationType != Sql {
 			if len(parts) > 3 {
 				description = strings.Join(parts[3:], " ")
 			}
@@ -182,9 +468,11 @@ func ParseMigrationInfo(fullPath string, baseDir string) (*MigrationInfo, error)
 	}
 	if description == "" {
 		if migrationType == Baseline {
-			description = fmt.Sprintf("Create %s baseline", mi.Database)
+			description = fmt.Sprintf("Create %s baseline", databaseName)
+		} else if migrationType == Data {
+			description = fmt.Sprintf("Create %s data migration", databaseName)
 		} else {
-			description = fmt.Sprintf("Create %s migration", mi.Database)
+			description = fmt.Sprintf("Create %s migration", databaseName)
 		}
 	}
 	mi.Type = migrationType
@@ -211,13 +499,16 @@ type MigrationHistory struct {
 	Version           string
 	Description       string
 	Statement         string
+	RollbackStatement string
 	ExecutionDuration int
 	IssueId           string
 	Payload           string
 }
 
 type MigrationHistoryFind struct {
+	ID       *int
 	Database *string
+	Version  *string
 	// If specified, then it will only fetch "Limit" most recent migration histories
 	Limit *int
 }
@@ -228,6 +519,19 @@ type ConnectionConfig struct {
 	Username string
 	Password string
 	Database string
+	// The max number of open and idle connections the driver keeps to the instance, 0 means the sql.DB defaults.
+	MaxOpenConns int
+	MaxIdleConns int
+}
+
+// setConnectionLimit applies the connection limit to db. A driver opening multiple sql.DB splits the limit among them.
+func setConnectionLimit(db *sql.DB, maxOpenConns int, maxIdleConns int) {
+	if maxOpenConns > 0 {
+		db.SetMaxOpenConns(maxOpenConns)
+	}
+	if maxIdleConns > 0 {
+		db.SetMaxIdleConns(maxIdleConns)
+	}
 }
 
 // Context not used for establishing the db connection, but is useful for logging.
@@ -241,8 +545,12 @@ type Driver interface {
 	// Remember to call Close to avoid connection leak
 	Close(ctx context.Context) error
 	Ping(ctx context.Context) error
+	GetServerInfo(ctx context.Context) (*ServerInfo, error)
 	SyncSchema(ctx context.Context) ([]*DBUser, []*DBSchema, error)
 	Execute(ctx context.Context, statement string) error
+	// Query runs the single read-only statement in a read-only transaction, and returns at most limit rows.
+	// The statement is limited by the statement timeout of ctx, see WithStatementTimeout.
+	Query(ctx context.Context, statement string, limit int) (*QueryResult, error)
 
 	// Migration related
 	// Check whether we need to setup migration (e.g. creating/upgrading the migration related tables)
@@ -250,15 +558,36 @@ type Driver interface {
 	// Create or upgrade migration related tables
 	SetupMigrationIfNeeded(ctx context.Context) error
 	// Execute migration will apply the statement and record the migration history on success.
-	ExecuteMigration(ctx context.Context, m *MigrationInfo, statement string) error
+	// If execution is not nil, the statements in execution are applied one by one instead, and MigrationStatementError
+	// is returned on failure. The migration history always records the whole statement.
+	ExecuteMigration(ctx context.Context, m *MigrationInfo, statement string, execution *MigrationExecution) error
+	// Dry run migration validates the migration against the database without applying it, and returns the problems found.
+	// The synced schema of the target database is used to check the referenced tables and columns, which can be nil if unknown.
+	DryRunMigration(ctx context.Context, m *MigrationInfo, statement string, schema *DBSchema) ([]*DryRunError, error)
 	// Find the migration history list and return most recent item first.
 	FindMigrationHistoryList(ctx context.Context, find *MigrationHistoryFind) ([]*MigrationHistory, error)
+	// Import the migration history recorded by another migration tool in the database, and return the number of
+	// migrations imported. The migrations imported before are skipped, so it's safe to import again.
+	ImportMigrationHistory(ctx context.Context, imp *MigrationHistoryImport) (int, error)
+
+	// User and grant related, only for the engines supporting OperationUserAndGrant.
+	// Grant the privileges on the database to the user, creating the user or changing its password if the password
+	// is set, and return the user with the grants afterwards.
+	GrantDatabase(ctx context.Context, grant *DatabaseGrant) (*DBUser, error)
+	// Revoke the privileges on the database from the user, terminating the sessions of the user and dropping the user
+	// if grant.DropUser is true, and return the user with the grants afterwards. Only the name is set if the user is
+	// dropped.
+	RevokeDatabase(ctx context.Context, grant *DatabaseGrant) (*DBUser, error)
+
+	// Index usage related, only for the engines supporting OperationIndexUsage.
+	// Find the number of reads through each index of the database since the statistics were reset.
+	FindIndexUsage(ctx context.Context, database string) (*IndexUsageStats, error)
 }
 
 // Register makes a database driver available by the provided type.
 // If Register is called twice with the same name or if driver is nil,
 // it panics.
-func register(dbType Type, f DriverFunc) {
+func register(dbType Type, f DriverFunc, capability Capability) {
 	driversMu.Lock()
 	defer driversMu.Unlock()
 	if f == nil {
@@ -268,6 +597,18 @@ func register(dbType Type, f DriverFunc) {
 		panic("db: Register called twice for driver " + dbType)
 	}
 	drivers[dbType] = f
+	capabilities[dbType] = capability
+}
+
+// GetCapability returns the capability of the database driver type.
+func GetCapability(dbType Type) (Capability, error) {
+	driversMu.RLock()
+	capability, ok := capabilities[dbType]
+	driversMu.RUnlock()
+	if !ok {
+		return Capability{}, fmt.Errorf("db: unknown driver %v", dbType)
+	}
+	return capability, nil
 }
 
 // Open opens a database specified by its database driver type and connection config
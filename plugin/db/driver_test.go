@@ -8,10 +8,11 @@ import (
 
 func TestParseMigrationInfo(t *testing.T) {
 	type test struct {
-		fullPath string
-		baseDir  string
-		want     MigrationInfo
-		wantErr  string
+		fullPath      string
+		baseDir       string
+		versionScheme VersionScheme
+		want          MigrationInfo
+		wantErr       string
 	}
 
 	tests := []test{
@@ -23,6 +24,7 @@ func TestParseMigrationInfo(t *testing.T) {
 				Namespace:   "db1",
 				Database:    "db1",
 				Environment: "",
+				Engine:      VCS,
 				Type:        "SQL",
 				Description: "Create db1 migration",
 				Creator:     "",
@@ -37,6 +39,7 @@ func TestParseMigrationInfo(t *testing.T) {
 				Namespace:   "db1",
 				Database:    "db1",
 				Environment: "",
+				Engine:      VCS,
 				Type:        "SQL",
 				Description: "Create db1 migration",
 				Creator:     "",
@@ -51,6 +54,7 @@ func TestParseMigrationInfo(t *testing.T) {
 				Namespace:   "db1",
 				Database:    "db1",
 				Environment: "dev",
+				Engine:      VCS,
 				Type:        "SQL",
 				Description: "Create db1 migration",
 				Creator:     "",
@@ -65,6 +69,7 @@ func TestParseMigrationInfo(t *testing.T) {
 				Namespace:   "db1",
 				Database:    "db1",
 				Environment: "dev",
+				Engine:      VCS,
 				Type:        "SQL",
 				Description: "Create db1 migration",
 				Creator:     "",
@@ -79,6 +84,7 @@ func TestParseMigrationInfo(t *testing.T) {
 				Namespace:   "db1",
 				Database:    "db1",
 				Environment: "",
+				Engine:      VCS,
 				Type:        "SQL",
 				Description: "Create t1",
 				Creator:     "",
@@ -93,6 +99,7 @@ func TestParseMigrationInfo(t *testing.T) {
 				Namespace:   "db1",
 				Database:    "db1",
 				Environment: "",
+				Engine:      VCS,
 				Type:        "BASELINE",
 				Description: "Create db1 baseline",
 				Creator:     "",
@@ -107,12 +114,28 @@ func TestParseMigrationInfo(t *testing.T) {
 				Namespace:   "db1",
 				Database:    "db1",
 				Environment: "",
+				Engine:      VCS,
 				Type:        "BASELINE",
 				Description: "Create t1",
 				Creator:     "",
 			},
 			wantErr: "",
 		},
+		{
+			fullPath: "001foo__db1__data__backfill_c1",
+			baseDir:  "",
+			want: MigrationInfo{
+				Version:     "001foo",
+				Namespace:   "db1",
+				Database:    "db1",
+				Environment: "",
+				Engine:      VCS,
+				Type:        "DATA",
+				Description: "Backfill c1",
+				Creator:     "",
+			},
+			wantErr: "",
+		},
 		{
 			fullPath: "001foo__db_shop1__baseline__create_t1",
 			baseDir:  "",
@@ -121,6 +144,7 @@ func TestParseMigrationInfo(t *testing.T) {
 				Namespace:   "db_shop1",
 				Database:    "db_shop1",
 				Environment: "",
+				Engine:      VCS,
 				Type:        "BASELINE",
 				Description: "Create t1",
 				Creator:     "",
@@ -141,15 +165,71 @@ func TestParseMigrationInfo(t *testing.T) {
 			},
 			wantErr: "invalid filename format",
 		},
+		{
+			fullPath:      "bytebase/v10__db1",
+			baseDir:       "bytebase",
+			versionScheme: NumericVersion,
+			want: MigrationInfo{
+				Version:       "v10",
+				Namespace:     "db1",
+				Database:      "db1",
+				Environment:   "",
+				Engine:        VCS,
+				Type:          "SQL",
+				Description:   "Create db1 migration",
+				Creator:       "",
+				VersionScheme: NumericVersion,
+			},
+			wantErr: "",
+		},
+		{
+			fullPath:      "bytebase/001foo__db1",
+			baseDir:       "bytebase",
+			versionScheme: NumericVersion,
+			want:          MigrationInfo{},
+			wantErr:       "invalid numeric version",
+		},
+		{
+			fullPath: "bytebase/dev/001foo__@tenant__add_c1",
+			baseDir:  "bytebase",
+			want: MigrationInfo{
+				Version:     "001foo",
+				Namespace:   "",
+				Database:    "",
+				Environment: "dev",
+				Engine:      VCS,
+				Type:        "SQL",
+				Description: "Add c1",
+				Creator:     "",
+				Tenant:      true,
+			},
+			wantErr: "",
+		},
+		{
+			fullPath: "bytebase/001foo__@tenant__baseline",
+			baseDir:  "bytebase",
+			want: MigrationInfo{
+				Version:     "001foo",
+				Namespace:   "",
+				Database:    "",
+				Environment: "",
+				Engine:      VCS,
+				Type:        "BASELINE",
+				Description: "Create tenant baseline",
+				Creator:     "",
+				Tenant:      true,
+			},
+			wantErr: "",
+		},
 	}
 
 	for _, tc := range tests {
-		mi, err := ParseMigrationInfo(tc.fullPath, tc.baseDir)
+		mi, err := ParseMigrationInfo(tc.fullPath, tc.baseDir, tc.versionScheme)
 		if err != nil {
 			if tc.wantErr == "" {
-				t.Errorf("fullPath=%s, baseDir=%s: expected no error, got %w", tc.fullPath, tc.baseDir, err)
+				t.Errorf("fullPath=%s, baseDir=%s: expected no error, got %v", tc.fullPath, tc.baseDir, err)
 			} else if !strings.Contains(err.Error(), tc.wantErr) {
-				t.Errorf("fullPath=%s, baseDir=%s: expected error %s, got %w", tc.fullPath, tc.baseDir, tc.wantErr, err)
+				t.Errorf("fullPath=%s, baseDir=%s: expected error %s, got %v", tc.fullPath, tc.baseDir, tc.wantErr, err)
 			}
 		} else {
 			if !reflect.DeepEqual(tc.want, *mi) {
@@ -159,3 +239,28 @@ func TestParseMigrationInfo(t *testing.T) {
 
 	}
 }
+
+func TestGetMigrationSchemaUpgradeList(t *testing.T) {
+	for _, engine := range []string{"mysql", "pg", "sqlite"} {
+		list, err := getMigrationSchemaUpgradeList(engine, 1)
+		if err != nil {
+			t.Fatalf("engine %s: %v", engine, err)
+		}
+		if len(list) != latestMigrationSchemaVersion-1 {
+			t.Errorf("engine %s: got %d upgrades, want %d", engine, len(list), latestMigrationSchemaVersion-1)
+		}
+		for i, statement := range list {
+			if !strings.Contains(statement, "bb.schema.version") {
+				t.Errorf("engine %s: upgrade to version %d doesn't bump bb.schema.version", engine, i+2)
+			}
+		}
+
+		list, err = getMigrationSchemaUpgradeList(engine, latestMigrationSchemaVersion)
+		if err != nil {
+			t.Fatalf("engine %s: %v", engine, err)
+		}
+		if len(list) != 0 {
+			t.Errorf("engine %s: got %d upgrades for the latest version, want 0", engine, len(list))
+		}
+	}
+}
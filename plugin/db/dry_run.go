@@ -0,0 +1,827 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// DryRunError is a problem found by dry running the migration, the migration is expected to fail
+// or to do something unintended if applied as is.
+type DryRunError struct {
+	// The offending statement, empty if the problem is not specific to a statement, e.g. the version is already applied.
+	Statement string
+	Message   string
+}
+
+func (e *DryRunError) Error() string {
+	if e.Statement == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s, statement: %q", e.Message, e.Statement)
+}
+
+// The explain function runs EXPLAIN for the DML statement and returns the error if any.
+type explainFunc func(ctx context.Context, statement string) error
+
+// dryRunStatement splits the statement and checks each of them against the synced schema.
+// schema can be nil if the database has not been synced, in which case only EXPLAIN is run.
+// defaultQualifierList contains the qualifiers referring to the database itself, e.g. the database name
+// for MySQL, "public" for Postgres, and "main" for SQLite, so "db1.t1" is treated the same as "t1".
+func dryRunStatement(ctx context.Context, statement string, schema *DBSchema, defaultQualifierList []string, explain explainFunc) []*DryRunError {
+	checker := newSchemaChecker(schema, defaultQualifierList)
+	var errorList []*DryRunError
+	for _, stmt := range splitStatement(statement, true) {
+		tokenList := tokenize(stmt)
+		if len(tokenList) == 0 {
+			continue
+		}
+		messageList, isDML := checker.check(tokenList)
+		for _, message := range messageList {
+			errorList = append(errorList, &DryRunError{Statement: stmt, Message: message})
+		}
+		// Skip EXPLAIN if the statement depends on tables changed by the earlier statements,
+		// since those changes have not been applied.
+		if isDML && len(messageList) == 0 && !checker.referencesChangedTable(tokenList) {
+			if err := explain(ctx, stmt); err != nil {
+				errorList = append(errorList, &DryRunError{Statement: stmt, Message: err.Error()})
+			}
+		}
+	}
+	return errorList
+}
+
+// dryRunPrecondition checks the migration precondition in a transaction rolled back afterwards.
+func dryRunPrecondition(ctx context.Context, db *sql.DB, m *MigrationInfo, queries migrationHistoryQueries) (*DryRunError, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := checkMigrationPrecondition(ctx, tx, m, queries); err != nil {
+		return &DryRunError{Message: err.Error()}, nil
+	}
+	return nil, nil
+}
+
+// explainQuery runs the EXPLAIN query and discards the plan.
+func explainQuery(ctx context.Context, db *sql.DB, query string) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return formatError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+	}
+	return rows.Err()
+}
+
+// SplitStatement splits the multi-statement string into the statements to execute one by one.
+// The parts containing only comments are dropped.
+func SplitStatement(statement string) []string {
+	var list []string
+	for _, stmt := range splitStatement(statement, true) {
+		if len(tokenize(stmt)) > 0 {
+			list = append(list, stmt)
+		}
+	}
+	return list
+}
+
+// splitStatement splits the multi-statement string by ";", while respecting the quotes, comments,
+// Postgres dollar quoting and BEGIN...END blocks used by routine and trigger bodies.
+// backslashEscape is true if the backslash escapes the quote in the string literals, see skipQuote.
+func splitStatement(statement string, backslashEscape bool) []string {
+	var list []string
+	start := 0
+	depth := 0
+	s := statement
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuote(s, i, backslashEscape)
+		case c == '-' && i+1 < len(s) && s[i+1] == '-', c == '#':
+			i = skipLine(s, i)
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			i = skipBlockComment(s, i)
+		case c == '$':
+			i = skipDollarQuote(s, i)
+		case isIdentifierStart(c):
+			j := i
+			for j < len(s) && isIdentifierPart(s[j]) {
+				j++
+			}
+			switch strings.ToUpper(s[i:j]) {
+			case "BEGIN":
+				// "BEGIN;", "BEGIN TRANSACTION" and "BEGIN WORK" start a transaction instead of a block.
+				next := strings.ToUpper(nextWord(s, j))
+				if next != ";" && next != "" && next != "TRANSACTION" && next != "WORK" {
+					depth++
+				}
+			case "CASE":
+				if depth > 0 {
+					depth++
+				}
+			case "END":
+				// "END IF", "END LOOP", etc. close the blocks not counted.
+				next := strings.ToUpper(nextWord(s, j))
+				if depth > 0 && next != "IF" && next != "LOOP" && next != "WHILE" && next != "REPEAT" {
+					depth--
+				}
+			}
+			i = j
+		case c == ';' && depth == 0:
+			if stmt := strings.TrimSpace(s[start:i]); stmt != "" {
+				list = append(list, stmt)
+			}
+			i++
+			start = i
+		default:
+			i++
+		}
+	}
+	if stmt := strings.TrimSpace(s[start:]); stmt != "" {
+		list = append(list, stmt)
+	}
+	return list
+}
+
+type tokenKind int
+
+const (
+	tokenIdentifier tokenKind = iota
+	tokenQuotedIdentifier
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	// Quotes are stripped from the quoted identifier and string.
+	text string
+	// end is the offset right after the token in the tokenized string.
+	end int
+}
+
+// is returns true if the token is the unquoted keyword or the symbol.
+func (t token) is(keyword string) bool {
+	return (t.kind == tokenIdentifier || t.kind == tokenSymbol) && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) isName() bool {
+	return t.kind == tokenIdentifier || t.kind == tokenQuotedIdentifier
+}
+
+// tokenize is a lexer good enough to find the object names referenced by the statement, it's not a SQL parser.
+func tokenize(s string) []token {
+	return tokenizeWith(s, true)
+}
+
+// tokenizeWith is tokenize with backslashEscape, see skipQuote.
+func tokenizeWith(s string, backslashEscape bool) []token {
+	var list []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && i+1 < len(s) && s[i+1] == '-', c == '#':
+			i = skipLine(s, i)
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			i = skipBlockComment(s, i)
+		case c == '\'':
+			j := skipQuote(s, i, backslashEscape)
+			list = append(list, token{kind: tokenString, text: unquote(s[i:j]), end: j})
+			i = j
+		case c == '"' || c == '`' || c == '[':
+			var j int
+			if c == '[' {
+				j = strings.IndexByte(s[i:], ']')
+				if j < 0 {
+					j = len(s)
+				} else {
+					j = i + j + 1
+				}
+			} else {
+				j = skipQuote(s, i, backslashEscape)
+			}
+			list = append(list, token{kind: tokenQuotedIdentifier, text: unquote(s[i:j]), end: j})
+			i = j
+		case c == '$':
+			j := skipDollarQuote(s, i)
+			list = append(list, token{kind: tokenString, text: s[i:j], end: j})
+			i = j
+		case isIdentifierStart(c) || (c >= '0' && c <= '9'):
+			j := i
+			for j < len(s) && isIdentifierPart(s[j]) {
+				j++
+			}
+			list = append(list, token{kind: tokenIdentifier, text: s[i:j], end: j})
+			i = j
+		default:
+			list = append(list, token{kind: tokenSymbol, text: s[i : i+1], end: i + 1})
+			i++
+		}
+	}
+	return list
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || (c >= '0' && c <= '9') || c == '$'
+}
+
+// skipQuote returns the index after the closing quote, quotes are escaped by doubling, or by backslash in the
+// string literals if backslashEscape is true. backslashEscape is true for MySQL by default, while Postgres with
+// standard_conforming_strings and SQLite treat the backslash as a plain character except in the Postgres E'...' strings.
+func skipQuote(s string, i int, backslashEscape bool) int {
+	quote := s[i]
+	backslashEscape = quote == '\'' && (backslashEscape || isEscapeString(s, i))
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			if backslashEscape {
+				j++
+			}
+		case quote:
+			if j+1 < len(s) && s[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// isEscapeString returns true if the quote at i starts the Postgres E'...' string.
+func isEscapeString(s string, i int) bool {
+	return i > 0 && (s[i-1] == 'E' || s[i-1] == 'e') && (i == 1 || !isIdentifierPart(s[i-2]))
+}
+
+func skipLine(s string, i int) int {
+	if j := strings.IndexByte(s[i:], '\n'); j >= 0 {
+		return i + j + 1
+	}
+	return len(s)
+}
+
+func skipBlockComment(s string, i int) int {
+	if j := strings.Index(s[i+2:], "*/"); j >= 0 {
+		return i + 2 + j + 2
+	}
+	return len(s)
+}
+
+// skipDollarQuote skips the Postgres $tag$...$tag$ string, or the single "$" otherwise.
+func skipDollarQuote(s string, i int) int {
+	j := i + 1
+	for j < len(s) && s[j] != '$' && isIdentifierPart(s[j]) {
+		j++
+	}
+	if j >= len(s) || s[j] != '$' {
+		return i + 1
+	}
+	tag := s[i : j+1]
+	if k := strings.Index(s[j+1:], tag); k >= 0 {
+		return j + 1 + k + len(tag)
+	}
+	return len(s)
+}
+
+// nextWord returns the next word or symbol after the index, skipping spaces.
+func nextWord(s string, i int) string {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+		i++
+	}
+	if i >= len(s) {
+		return ""
+	}
+	if !isIdentifierStart(s[i]) {
+		return s[i : i+1]
+	}
+	j := i
+	for j < len(s) && isIdentifierPart(s[j]) {
+		j++
+	}
+	return s[i:j]
+}
+
+func unquote(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	quote := s[0]
+	end := s[len(s)-1]
+	if quote == '[' && end == ']' {
+		return s[1 : len(s)-1]
+	}
+	if quote != end {
+		return s[1:]
+	}
+	inner := s[1 : len(s)-1]
+	return strings.ReplaceAll(inner, string([]byte{quote, quote}), string(quote))
+}
+
+// schemaChecker tracks the tables and columns while walking through the statements,
+// so the later statements are checked against the changes made by the earlier ones.
+type schemaChecker struct {
+	// Nil if the schema is unknown.
+	tableMap             map[string]*checkerTable
+	defaultQualifierList []string
+	// Tables created, dropped or altered by the checked statements.
+	changedTableMap map[string]bool
+}
+
+type checkerTable struct {
+	// Nil if the columns are unknown, e.g. created by the migration itself.
+	columnMap map[string]bool
+}
+
+func newSchemaChecker(schema *DBSchema, defaultQualifierList []string) *schemaChecker {
+	checker := &schemaChecker{
+		defaultQualifierList: defaultQualifierList,
+		changedTableMap:      make(map[string]bool),
+	}
+	if schema != nil {
+		checker.tableMap = make(map[string]*checkerTable)
+		for _, table := range schema.TableList {
+			columnMap := make(map[string]bool)
+			for _, column := range table.ColumnList {
+				columnMap[strings.ToLower(column.Name)] = true
+			}
+			checker.tableMap[strings.ToLower(table.Name)] = &checkerTable{columnMap: columnMap}
+		}
+	}
+	return checker
+}
+
+// parseName parses the possibly qualified name starting at position i, and returns the normalized name
+// and the position after it. Name is empty if it's not a name or qualified by a foreign database.
+func (c *schemaChecker) parseName(tokenList []token, i int) (string, int) {
+	if i >= len(tokenList) || !tokenList[i].isName() {
+		return "", i
+	}
+	partList := []string{tokenList[i].text}
+	i++
+	for i+1 < len(tokenList) && tokenList[i].is(".") && tokenList[i+1].isName() {
+		partList = append(partList, tokenList[i+1].text)
+		i += 2
+	}
+	if len(partList) > 1 {
+		for _, qualifier := range c.defaultQualifierList {
+			if strings.EqualFold(partList[0], qualifier) {
+				partList = partList[1:]
+				break
+			}
+		}
+	}
+	return strings.ToLower(strings.Join(partList, ".")), i
+}
+
+// lookupTable returns the table, or nil if it doesn't exist. known is false if we can't tell.
+func (c *schemaChecker) lookupTable(name string) (table *checkerTable, known bool) {
+	if c.tableMap == nil || name == "" {
+		return nil, false
+	}
+	if table, ok := c.tableMap[name]; ok {
+		return table, true
+	}
+	// It's probably qualified by another database/schema we don't know.
+	if strings.Contains(name, ".") {
+		return nil, false
+	}
+	return nil, true
+}
+
+func (c *schemaChecker) referencesChangedTable(tokenList []token) bool {
+	for i := 0; i < len(tokenList); i++ {
+		if name, next := c.parseName(tokenList, i); name != "" {
+			if c.changedTableMap[name] {
+				return true
+			}
+			i = next - 1
+		}
+	}
+	return false
+}
+
+// skipKeywords skips the optional keywords in order, e.g. "IF NOT EXISTS".
+func skipKeywords(tokenList []token, i int, keywordList ...string) (int, bool) {
+	j := i
+	for _, keyword := range keywordList {
+		if j >= len(tokenList) || !tokenList[j].is(keyword) {
+			return i, false
+		}
+		j++
+	}
+	return j, true
+}
+
+// check checks a single statement, and returns the problems found and whether the statement is DML.
+func (c *schemaChecker) check(tokenList []token) ([]string, bool) {
+	first := tokenList[0]
+	switch {
+	case first.is("CREATE"):
+		return c.checkCreate(tokenList), false
+	case first.is("DROP") && len(tokenList) > 1 && tokenList[1].is("TABLE"):
+		return c.checkDropTable(tokenList), false
+	case first.is("ALTER") && len(tokenList) > 1 && tokenList[1].is("TABLE"):
+		return c.checkAlterTable(tokenList), false
+	case first.is("RENAME") && len(tokenList) > 1 && tokenList[1].is("TABLE"):
+		return c.checkRenameTable(tokenList), false
+	case first.is("TRUNCATE"):
+		i, _ := skipKeywords(tokenList, 1, "TABLE")
+		name, _ := c.parseName(tokenList, i)
+		return c.checkTableExist(name), false
+	case first.is("INSERT") || first.is("REPLACE"):
+		return c.checkInsert(tokenList), true
+	case first.is("UPDATE"):
+		return c.checkUpdate(tokenList), true
+	case first.is("DELETE"):
+		return c.checkDelete(tokenList), true
+	}
+	return nil, false
+}
+
+func (c *schemaChecker) checkTableExist(name string) []string {
+	if table, known := c.lookupTable(name); known && table == nil {
+		return []string{fmt.Sprintf("table %q doesn't exist", name)}
+	}
+	return nil
+}
+
+func (c *schemaChecker) checkColumnExist(tableName string, columnName string) []string {
+	table, known := c.lookupTable(tableName)
+	if !known || table == nil || table.columnMap == nil {
+		return nil
+	}
+	if !table.columnMap[strings.ToLower(columnName)] {
+		return []string{fmt.Sprintf("column %q doesn't exist in table %q", columnName, tableName)}
+	}
+	return nil
+}
+
+func (c *schemaChecker) checkCreate(tokenList []token) []string {
+	i := 1
+	i, _ = skipKeywords(tokenList, i, "OR", "REPLACE")
+	i, _ = skipKeywords(tokenList, i, "TEMPORARY")
+	i, _ = skipKeywords(tokenList, i, "TEMP")
+	i, _ = skipKeywords(tokenList, i, "UNLOGGED")
+	if i < len(tokenList) && tokenList[i].is("TABLE") {
+		i, ifNotExists := skipKeywords(tokenList, i+1, "IF", "NOT", "EXISTS")
+		name, _ := c.parseName(tokenList, i)
+		if name == "" {
+			return nil
+		}
+		table, known := c.lookupTable(name)
+		if known && table != nil && !ifNotExists {
+			return []string{fmt.Sprintf("table %q already exists", name)}
+		}
+		if c.tableMap != nil && table == nil {
+			c.tableMap[name] = &checkerTable{}
+		}
+		c.changedTableMap[name] = true
+		return nil
+	}
+
+	// CREATE [UNIQUE|FULLTEXT|SPATIAL] INDEX [CONCURRENTLY] [IF NOT EXISTS] [name] ON table [USING method] (column, ...)
+	for i < len(tokenList) && (tokenList[i].is("UNIQUE") || tokenList[i].is("FULLTEXT") || tokenList[i].is("SPATIAL")) {
+		i++
+	}
+	if i >= len(tokenList) || !tokenList[i].is("INDEX") {
+		return nil
+	}
+	for i < len(tokenList) && !tokenList[i].is("ON") {
+		i++
+	}
+	tableName, i := c.parseName(tokenList, i+1)
+	if tableName == "" {
+		return nil
+	}
+	if messageList := c.checkTableExist(tableName); len(messageList) > 0 {
+		return messageList
+	}
+	if i, ok := skipKeywords(tokenList, i, "USING"); ok {
+		i++
+		return c.checkIndexColumnList(tableName, tokenList, i)
+	}
+	return c.checkIndexColumnList(tableName, tokenList, i)
+}
+
+// checkIndexColumnList checks the simple column references in "(column [ASC|DESC], ...)", the expressions are skipped.
+func (c *schemaChecker) checkIndexColumnList(tableName string, tokenList []token, i int) []string {
+	if i >= len(tokenList) || !tokenList[i].is("(") {
+		return nil
+	}
+	var messageList []string
+	for _, item := range splitByComma(tokenList[i+1:]) {
+		if len(item) > 0 && item[0].isName() && (len(item) == 1 || item[1].is("ASC") || item[1].is("DESC") || item[1].is("(")) {
+			messageList = append(messageList, c.checkColumnExist(tableName, item[0].text)...)
+		}
+	}
+	return messageList
+}
+
+func (c *schemaChecker) checkDropTable(tokenList []token) []string {
+	i, ifExists := skipKeywords(tokenList, 2, "IF", "EXISTS")
+	var messageList []string
+	for _, item := range splitByComma(tokenList[i:]) {
+		name, _ := c.parseName(item, 0)
+		if name == "" {
+			continue
+		}
+		if !ifExists {
+			messageList = append(messageList, c.checkTableExist(name)...)
+		}
+		if c.tableMap != nil {
+			delete(c.tableMap, name)
+		}
+		c.changedTableMap[name] = true
+	}
+	return messageList
+}
+
+func (c *schemaChecker) checkRenameTable(tokenList []token) []string {
+	var messageList []string
+	for _, item := range splitByComma(tokenList[2:]) {
+		from, i := c.parseName(item, 0)
+		i, _ = skipKeywords(item, i, "TO")
+		to, _ := c.parseName(item, i)
+		if from == "" || to == "" {
+			continue
+		}
+		messageList = append(messageList, c.renameTable(from, to)...)
+	}
+	return messageList
+}
+
+func (c *schemaChecker) renameTable(from string, to string) []string {
+	table, known := c.lookupTable(from)
+	if known && table == nil {
+		return []string{fmt.Sprintf("table %q doesn't exist", from)}
+	}
+	if c.tableMap != nil {
+		delete(c.tableMap, from)
+		if table == nil {
+			table = &checkerTable{}
+		}
+		c.tableMap[to] = table
+	}
+	c.changedTableMap[from] = true
+	c.changedTableMap[to] = true
+	return nil
+}
+
+func (c *schemaChecker) checkAlterTable(tokenList []token) []string {
+	i, ifExists := skipKeywords(tokenList, 2, "IF", "EXISTS")
+	i, _ = skipKeywords(tokenList, i, "ONLY")
+	name, i := c.parseName(tokenList, i)
+	if name == "" {
+		return nil
+	}
+	table, known := c.lookupTable(name)
+	if known && table == nil {
+		if ifExists {
+			return nil
+		}
+		return []string{fmt.Sprintf("table %q doesn't exist", name)}
+	}
+	c.changedTableMap[name] = true
+
+	var messageList []string
+	for _, spec := range splitByComma(tokenList[i:]) {
+		messageList = append(messageList, c.checkAlterSpec(name, spec)...)
+	}
+	return messageList
+}
+
+// checkAlterSpec checks a single ALTER TABLE specification, and applies the column changes.
+func (c *schemaChecker) checkAlterSpec(tableName string, spec []token) []string {
+	if len(spec) == 0 {
+		return nil
+	}
+	table, _ := c.lookupTable(tableName)
+	columnMap := map[string]bool(nil)
+	if table != nil {
+		columnMap = table.columnMap
+	}
+	action := spec[0]
+	i := 1
+
+	// Table constraint, index and partition specs are not checked.
+	isColumnSpec := func(i int) bool {
+		if i >= len(spec) || !spec[i].isName() {
+			return false
+		}
+		for _, keyword := range []string{"INDEX", "KEY", "PRIMARY", "UNIQUE", "FOREIGN", "CONSTRAINT", "CHECK", "FULLTEXT", "SPATIAL", "PARTITION", "DEFAULT"} {
+			if spec[i].is(keyword) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case action.is("ADD"):
+		i, _ = skipKeywords(spec, i, "COLUMN")
+		i, ifNotExists := skipKeywords(spec, i, "IF", "NOT", "EXISTS")
+		// MySQL "ADD (column definition, ...)" is not checked either.
+		if !isColumnSpec(i) {
+			return nil
+		}
+		column := strings.ToLower(spec[i].text)
+		if columnMap != nil {
+			if columnMap[column] && !ifNotExists {
+				return []string{fmt.Sprintf("column %q already exists in table %q", spec[i].text, tableName)}
+			}
+			columnMap[column] = true
+		}
+	case action.is("DROP"):
+		i, _ = skipKeywords(spec, i, "COLUMN")
+		i, ifExists := skipKeywords(spec, i, "IF", "EXISTS")
+		if !isColumnSpec(i) {
+			return nil
+		}
+		if !ifExists {
+			if messageList := c.checkColumnExist(tableName, spec[i].text); len(messageList) > 0 {
+				return messageList
+			}
+		}
+		if columnMap != nil {
+			delete(columnMap, strings.ToLower(spec[i].text))
+		}
+	case action.is("MODIFY") || action.is("ALTER"):
+		i, _ = skipKeywords(spec, i, "COLUMN")
+		if !isColumnSpec(i) {
+			return nil
+		}
+		return c.checkColumnExist(tableName, spec[i].text)
+	case action.is("CHANGE"):
+		i, _ = skipKeywords(spec, i, "COLUMN")
+		if !isColumnSpec(i) || i+1 >= len(spec) || !spec[i+1].isName() {
+			return nil
+		}
+		if messageList := c.checkColumnExist(tableName, spec[i].text); len(messageList) > 0 {
+			return messageList
+		}
+		if columnMap != nil {
+			delete(columnMap, strings.ToLower(spec[i].text))
+			columnMap[strings.ToLower(spec[i+1].text)] = true
+		}
+	case action.is("RENAME"):
+		// RENAME INDEX|KEY old TO new
+		if i < len(spec) && (spec[i].is("INDEX") || spec[i].is("KEY")) {
+			return nil
+		}
+		if j, ok := skipKeywords(spec, i, "COLUMN"); ok || (i+2 < len(spec) && spec[i].isName() && spec[i+1].is("TO") && !spec[i].is("TO") && !spec[i].is("AS")) {
+			// RENAME [COLUMN] old TO new
+			if !ok {
+				j = i
+			}
+			if j+2 >= len(spec) || !spec[j].isName() || !spec[j+1].is("TO") || !spec[j+2].isName() {
+				return nil
+			}
+			if messageList := c.checkColumnExist(tableName, spec[j].text); len(messageList) > 0 {
+				return messageList
+			}
+			if columnMap != nil {
+				delete(columnMap, strings.ToLower(spec[j].text))
+				columnMap[strings.ToLower(spec[j+2].text)] = true
+			}
+			return nil
+		}
+		// RENAME [TO|AS] new_table
+		i, _ = skipKeywords(spec, i, "TO")
+		i, _ = skipKeywords(spec, i, "AS")
+		to, _ := c.parseName(spec, i)
+		if to == "" {
+			return nil
+		}
+		return c.renameTable(tableName, to)
+	}
+	return nil
+}
+
+func (c *schemaChecker) checkInsert(tokenList []token) []string {
+	i := 1
+	for i < len(tokenList) && (tokenList[i].is("LOW_PRIORITY") || tokenList[i].is("DELAYED") || tokenList[i].is("HIGH_PRIORITY") || tokenList[i].is("IGNORE") || tokenList[i].is("OR")) {
+		// "INSERT OR REPLACE" in SQLite
+		if tokenList[i].is("OR") {
+			i++
+		}
+		i++
+	}
+	i, _ = skipKeywords(tokenList, i, "INTO")
+	name, i := c.parseName(tokenList, i)
+	if name == "" {
+		return nil
+	}
+	if messageList := c.checkTableExist(name); len(messageList) > 0 {
+		return messageList
+	}
+	// Skip the alias
+	if i, ok := skipKeywords(tokenList, i, "AS"); ok {
+		i++
+		return c.checkInsertColumnList(name, tokenList, i)
+	}
+	return c.checkInsertColumnList(name, tokenList, i)
+}
+
+func (c *schemaChecker) checkInsertColumnList(tableName string, tokenList []token, i int) []string {
+	if i >= len(tokenList) || !tokenList[i].is("(") {
+		return nil
+	}
+	// "INSERT INTO t (SELECT ...)" has no column list.
+	if i+1 < len(tokenList) && (tokenList[i+1].is("SELECT") || tokenList[i+1].is("WITH")) {
+		return nil
+	}
+	var messageList []string
+	for _, item := range splitByComma(tokenList[i+1:]) {
+		if len(item) == 1 && item[0].isName() {
+			messageList = append(messageList, c.checkColumnExist(tableName, item[0].text)...)
+		}
+	}
+	return messageList
+}
+
+func (c *schemaChecker) checkUpdate(tokenList []token) []string {
+	i := 1
+	for i < len(tokenList) && (tokenList[i].is("LOW_PRIORITY") || tokenList[i].is("IGNORE") || tokenList[i].is("ONLY")) {
+		i++
+	}
+	name, i := c.parseName(tokenList, i)
+	if name == "" {
+		return nil
+	}
+	if messageList := c.checkTableExist(name); len(messageList) > 0 {
+		return messageList
+	}
+	i, _ = skipKeywords(tokenList, i, "AS")
+	// Multi-table UPDATE is only checked for the table existence.
+	if i < len(tokenList) && !tokenList[i].is("SET") {
+		if i+1 < len(tokenList) && tokenList[i].isName() && tokenList[i+1].is("SET") {
+			// Alias
+			i++
+		} else {
+			return nil
+		}
+	}
+	var messageList []string
+	for _, item := range splitByComma(tokenList[i+1:]) {
+		if len(item) >= 2 && item[0].isName() && item[1].is("=") {
+			messageList = append(messageList, c.checkColumnExist(name, item[0].text)...)
+		}
+		// Stop at the end of the SET list.
+		for _, t := range item {
+			if t.is("WHERE") || t.is("FROM") || t.is("RETURNING") || t.is("ORDER") || t.is("LIMIT") {
+				return messageList
+			}
+		}
+	}
+	return messageList
+}
+
+func (c *schemaChecker) checkDelete(tokenList []token) []string {
+	i := 1
+	for i < len(tokenList) && (tokenList[i].is("LOW_PRIORITY") || tokenList[i].is("QUICK") || tokenList[i].is("IGNORE")) {
+		i++
+	}
+	i, ok := skipKeywords(tokenList, i, "FROM")
+	if !ok {
+		// Multi-table DELETE is not checked.
+		return nil
+	}
+	i, _ = skipKeywords(tokenList, i, "ONLY")
+	name, _ := c.parseName(tokenList, i)
+	return c.checkTableExist(name)
+}
+
+// splitByComma splits the tokens by the top level commas, it stops at the unmatched ")".
+func splitByComma(tokenList []token) [][]token {
+	var list [][]token
+	depth := 0
+	start := 0
+	for i, t := range tokenList {
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			if depth == 0 {
+				return append(list, tokenList[start:i])
+			}
+			depth--
+		case t.is(",") && depth == 0:
+			list = append(list, tokenList[start:i])
+			start = i + 1
+		}
+	}
+	return append(list, tokenList[start:])
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatement(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      []string
+	}{
+		{
+			statement: "CREATE TABLE t1 (id INT); INSERT INTO t1 VALUES (1);",
+			want:      []string{"CREATE TABLE t1 (id INT)", "INSERT INTO t1 VALUES (1)"},
+		},
+		{
+			statement: "INSERT INTO t1 VALUES ('a;b'); -- comment; here\nSELECT 1",
+			want:      []string{"INSERT INTO t1 VALUES ('a;b')", "-- comment; here\nSELECT 1"},
+		},
+		{
+			statement: "CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN RETURN NEW; END; $$ LANGUAGE plpgsql; SELECT 1",
+			want:      []string{"CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN RETURN NEW; END; $$ LANGUAGE plpgsql", "SELECT 1"},
+		},
+		{
+			statement: "CREATE TRIGGER t AFTER INSERT ON t1 BEGIN UPDATE t2 SET c = CASE WHEN 1 THEN 2 END; END; BEGIN; COMMIT;",
+			want:      []string{"CREATE TRIGGER t AFTER INSERT ON t1 BEGIN UPDATE t2 SET c = CASE WHEN 1 THEN 2 END; END", "BEGIN", "COMMIT"},
+		},
+	}
+
+	for _, test := range tests {
+		got := splitStatement(test.statement, true)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitStatement(%q) got %q, want %q", test.statement, got, test.want)
+		}
+	}
+}
+
+func TestSplitStatementDropsComment(t *testing.T) {
+	statement := "-- create t1\nCREATE TABLE t1 (id INT); /* done */; -- trailing comment"
+	want := []string{"-- create t1\nCREATE TABLE t1 (id INT)"}
+	if got := SplitStatement(statement); !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitStatement(%q) got %q, want %q", statement, got, want)
+	}
+}
+
+func TestDryRunStatement(t *testing.T) {
+	schema := &DBSchema{
+		Name: "db1",
+		TableList: []DBTable{
+			{
+				Name: "t1",
+				ColumnList: []DBColumn{
+					{Name: "id"},
+					{Name: "name"},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		statement string
+		// Problems found in order
+		want []string
+		// Statements passed to EXPLAIN in order
+		explained []string
+	}{
+		{
+			statement: "ALTER TABLE t1 ADD COLUMN age INT, DROP COLUMN name; ALTER TABLE `db1`.`t1` MODIFY age BIGINT",
+			want:      nil,
+		},
+		{
+			statement: "ALTER TABLE t2 ADD COLUMN age INT",
+			want:      []string{`table "t2" doesn't exist`},
+		},
+		{
+			statement: "ALTER TABLE t1 ADD COLUMN name TEXT, DROP COLUMN age, DROP INDEX idx_name",
+			want:      []string{`column "name" already exists in table "t1"`, `column "age" doesn't exist in table "t1"`},
+		},
+		{
+			statement: "CREATE TABLE t1 (id INT); CREATE TABLE IF NOT EXISTS t1 (id INT)",
+			want:      []string{`table "t1" already exists`},
+		},
+		{
+			statement: "CREATE UNIQUE INDEX idx ON t1 (name, email(10))",
+			want:      []string{`column "email" doesn't exist in table "t1"`},
+		},
+		{
+			statement: "INSERT INTO t1 (id, email) VALUES (1, 'a'); UPDATE t1 SET name = 'b' WHERE id = 1; DELETE FROM other.t2",
+			want:      []string{`column "email" doesn't exist in table "t1"`},
+			explained: []string{"UPDATE t1 SET name = 'b' WHERE id = 1", "DELETE FROM other.t2"},
+		},
+		{
+			statement: "CREATE TABLE t2 (id INT); INSERT INTO t2 VALUES (1); RENAME TABLE t1 TO t3; UPDATE t3 SET name = 'c'; DROP TABLE t1",
+			want:      []string{`table "t1" doesn't exist`},
+		},
+	}
+
+	for _, test := range tests {
+		var explained []string
+		explain := func(ctx context.Context, statement string) error {
+			explained = append(explained, statement)
+			return nil
+		}
+		errorList := dryRunStatement(context.Background(), test.statement, schema, []string{"db1"}, explain)
+		var got []string
+		for _, e := range errorList {
+			got = append(got, e.Message)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("dryRunStatement(%q) got %q, want %q", test.statement, got, test.want)
+		}
+		if !reflect.DeepEqual(explained, test.explained) {
+			t.Errorf("dryRunStatement(%q) explained %q, want %q", test.statement, explained, test.explained)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// userNamePattern matches the user names we can grant to. The names are quoted anyway, the pattern keeps them sane.
+var userNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-]*$`)
+
+// userHostPattern matches the MySQL user host, e.g. "%", "10.0.0.%" and "localhost".
+var userHostPattern = regexp.MustCompile(`^[A-Za-z0-9_.:%\-]+$`)
+
+var (
+	// mysqlDatabasePrivilegeList contains the privileges grantable on the database level.
+	mysqlDatabasePrivilegeList = []string{
+		"ALL", "ALL PRIVILEGES", "ALTER", "ALTER ROUTINE", "CREATE", "CREATE ROUTINE", "CREATE TEMPORARY TABLES",
+		"CREATE VIEW", "DELETE", "DROP", "EVENT", "EXECUTE", "INDEX", "INSERT", "LOCK TABLES", "REFERENCES", "SELECT",
+		"SHOW VIEW", "TRIGGER", "UPDATE",
+	}
+	// pgTablePrivilegeList contains the privileges grantable on the tables, which are granted on all the tables
+	// of the user schemas in the database.
+	pgTablePrivilegeList = []string{
+		"ALL", "ALL PRIVILEGES", "SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER",
+	}
+)
+
+// DatabaseGrant is the request to grant or revoke the privileges on a database for a user.
+type DatabaseGrant struct {
+	// Username is the user name without the host.
+	Username string
+	// Host is the host part of the MySQL user, "%" if empty. Not used by the other engines.
+	Host string
+	// Password creates the user if it doesn't exist and sets the password of the user. If empty, the user must exist.
+	// Only used when granting.
+	Password string
+	Database string
+	// PrivilegeList contains the privileges like "SELECT" and "INSERT".
+	PrivilegeList []string
+	// DropUser terminates the sessions of the user and drops the user after revoking the privileges. Only used when
+	// revoking.
+	DropUser bool
+}
+
+// ValidateDatabaseGrant returns an error if the grant is invalid for the database type.
+// The privileges are normalized to the upper case.
+func ValidateDatabaseGrant(dbType Type, grant *DatabaseGrant) error {
+	if !userNamePattern.MatchString(grant.Username) {
+		return fmt.Errorf("invalid user name %q", grant.Username)
+	}
+	if grant.Host != "" && !userHostPattern.MatchString(grant.Host) {
+		return fmt.Errorf("invalid user host %q", grant.Host)
+	}
+	// The password is quoted as a string literal, and the backslash escapes differ among the engines and the SQL modes.
+	if strings.ContainsAny(grant.Password, "\\\x00\n\r") {
+		return fmt.Errorf("password must not contain backslash or line breaks")
+	}
+	if grant.Database == "" {
+		return fmt.Errorf("database name missing")
+	}
+	if len(grant.PrivilegeList) == 0 && !grant.DropUser {
+		return fmt.Errorf("privilege list missing")
+	}
+
+	var allowedList []string
+	switch dbType {
+	case Mysql:
+		allowedList = mysqlDatabasePrivilegeList
+	case Postgres:
+		allowedList = pgTablePrivilegeList
+	default:
+		return fmt.Errorf("database type %s doesn't support granting privileges", dbType)
+	}
+	for i, privilege := range grant.PrivilegeList {
+		normalized := strings.Join(strings.Fields(strings.ToUpper(privilege)), " ")
+		allowed := false
+		for _, p := range allowedList {
+			if normalized == p {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("invalid privilege %q for database type %s, must be one of %s", privilege, dbType, strings.Join(allowedList, ", "))
+		}
+		grant.PrivilegeList[i] = normalized
+	}
+	return nil
+}
+
+// quoteStringLiteral quotes the string literal for MySQL and Postgres, the string must not contain backslash.
+func quoteStringLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// grantExecer is either *sql.DB or *sql.Tx.
+type grantExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// execGrantStatementList executes the user and grant statements one by one. The password is redacted from the error.
+func execGrantStatementList(ctx context.Context, execer grantExecer, stmtList []string, password string) error {
+	for _, stmt := range stmtList {
+		if _, err := execer.ExecContext(ctx, stmt); err != nil {
+			if password != "" {
+				stmt = strings.ReplaceAll(stmt, quoteStringLiteral(password), "'******'")
+			}
+			return formatErrorWithQuery(err, stmt)
+		}
+	}
+	return nil
+}
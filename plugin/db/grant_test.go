@@ -0,0 +1,113 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateDatabaseGrant(t *testing.T) {
+	tests := []struct {
+		dbType  Type
+		grant   DatabaseGrant
+		want    []string
+		wantErr bool
+	}{
+		{
+			dbType: Mysql,
+			grant:  DatabaseGrant{Username: "alice", Host: "10.0.0.%", Database: "db1", PrivilegeList: []string{"select", "lock  tables"}},
+			want:   []string{"SELECT", "LOCK TABLES"},
+		},
+		{
+			dbType: Postgres,
+			grant:  DatabaseGrant{Username: "alice", Database: "db1", DropUser: true},
+		},
+		{
+			dbType:  Postgres,
+			grant:   DatabaseGrant{Username: "alice", Database: "db1", PrivilegeList: []string{"LOCK TABLES"}},
+			wantErr: true,
+		},
+		{
+			dbType:  Mysql,
+			grant:   DatabaseGrant{Username: "alice", Database: "db1", PrivilegeList: []string{"SUPER"}},
+			wantErr: true,
+		},
+		{
+			dbType:  Mysql,
+			grant:   DatabaseGrant{Username: "alice'@'%", Database: "db1", PrivilegeList: []string{"SELECT"}},
+			wantErr: true,
+		},
+		{
+			dbType:  Mysql,
+			grant:   DatabaseGrant{Username: "alice", Password: `pass\`, Database: "db1", PrivilegeList: []string{"SELECT"}},
+			wantErr: true,
+		},
+		{
+			dbType:  Mysql,
+			grant:   DatabaseGrant{Username: "alice", Database: "db1"},
+			wantErr: true,
+		},
+		{
+			dbType:  SQLite,
+			grant:   DatabaseGrant{Username: "alice", Database: "db1", PrivilegeList: []string{"SELECT"}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateDatabaseGrant(test.dbType, &test.grant)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateDatabaseGrant(%s, %+v) got error %v, want error %v", test.dbType, test.grant, err, test.wantErr)
+			continue
+		}
+		if err == nil && len(test.want) > 0 && !reflect.DeepEqual(test.grant.PrivilegeList, test.want) {
+			t.Errorf("ValidateDatabaseGrant(%s, %+v) got privileges %v, want %v", test.dbType, test.grant, test.grant.PrivilegeList, test.want)
+		}
+	}
+}
+
+func TestGrantStatementList(t *testing.T) {
+	grant := &DatabaseGrant{Username: "alice", Password: "it's", Database: "db1", PrivilegeList: []string{"SELECT", "INSERT"}}
+	tests := []struct {
+		got  []string
+		want []string
+	}{
+		{
+			got: mysqlGrantStatementList(grant),
+			want: []string{
+				"CREATE USER IF NOT EXISTS 'alice'@'%' IDENTIFIED BY 'it''s'",
+				"ALTER USER 'alice'@'%' IDENTIFIED BY 'it''s'",
+				"GRANT SELECT, INSERT ON `db1`.* TO 'alice'@'%'",
+			},
+		},
+		{
+			got:  mysqlRevokeStatementList(&DatabaseGrant{Username: "alice", Database: "db1", DropUser: true}),
+			want: []string{"DROP USER IF EXISTS 'alice'@'%'"},
+		},
+		{
+			got: pgGrantStatementList(grant, true, []string{"public"}),
+			want: []string{
+				`ALTER ROLE "alice" WITH LOGIN PASSWORD 'it''s'`,
+				`GRANT CONNECT ON DATABASE "db1" TO "alice"`,
+				`GRANT USAGE ON SCHEMA "public" TO "alice"`,
+				`GRANT SELECT, INSERT ON ALL TABLES IN SCHEMA "public" TO "alice"`,
+				`ALTER DEFAULT PRIVILEGES IN SCHEMA "public" GRANT SELECT, INSERT ON TABLES TO "alice"`,
+			},
+		},
+		{
+			got: pgRevokeStatementList(&DatabaseGrant{Username: "alice", Database: "db1", DropUser: true}, []string{"public"}),
+			want: []string{
+				`REVOKE ALL ON ALL TABLES IN SCHEMA "public" FROM "alice"`,
+				`ALTER DEFAULT PRIVILEGES IN SCHEMA "public" REVOKE ALL ON TABLES FROM "alice"`,
+				`REVOKE ALL ON SCHEMA "public" FROM "alice"`,
+				`REVOKE ALL ON DATABASE "db1" FROM "alice"`,
+				`DROP ROLE "alice"`,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("#%d got statements %q, want %q", i, test.got, test.want)
+		}
+	}
+}
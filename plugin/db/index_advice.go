@@ -0,0 +1,222 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IndexUsage is the number of reads through an index since the statistics were reset.
+type IndexUsage struct {
+	// The table name as synced, e.g. {{schema}}.{{table}} for the Postgres tables outside the "public" schema.
+	TableName string
+	IndexName string
+	ReadCount int64
+}
+
+// IndexUsageStats is the usage of the indexes in a database.
+type IndexUsageStats struct {
+	// The usage is counted since SinceTs, when the server started or the statistics were reset.
+	SinceTs   int64
+	UsageList []*IndexUsage
+}
+
+// IndexAdviceType is the type of an index finding.
+type IndexAdviceType string
+
+const (
+	// IndexAdviceUnused is an index never read since the statistics were reset.
+	IndexAdviceUnused IndexAdviceType = "UNUSED"
+	// IndexAdviceRedundant is an index whose columns are a prefix of another index on the same table.
+	IndexAdviceRedundant IndexAdviceType = "REDUNDANT"
+)
+
+func (e IndexAdviceType) String() string {
+	switch e {
+	case IndexAdviceUnused:
+		return "UNUSED"
+	case IndexAdviceRedundant:
+		return "REDUNDANT"
+	}
+	return "UNKNOWN"
+}
+
+// IndexAdvice is an index suggested to drop.
+type IndexAdvice struct {
+	Type      IndexAdviceType
+	TableName string
+	IndexName string
+	// The index covering the redundant index, empty for the unused index.
+	CoveringIndexName string
+	Reason            string
+	// The DROP INDEX statement.
+	Statement string
+}
+
+// indexDef is an index with its expressions in order, the DBIndex list has a row per expression.
+type indexDef struct {
+	name           string
+	expressionList []string
+	indexType      string
+	unique         bool
+	visible        bool
+}
+
+// AdviseIndex finds the redundant indexes of the tables, and the unused indexes if usage is not nil.
+// The unique indexes are never suggested to drop since they enforce the constraints, neither are the indexes
+// which may back a foreign key.
+func AdviseIndex(dbType Type, tableList []DBTable, usage *IndexUsageStats) []*IndexAdvice {
+	// tableName -> indexName -> readCount map
+	usageMap := make(map[string]map[string]int64)
+	if usage != nil {
+		for _, u := range usage.UsageList {
+			if usageMap[u.TableName] == nil {
+				usageMap[u.TableName] = make(map[string]int64)
+			}
+			usageMap[u.TableName][u.IndexName] += u.ReadCount
+		}
+	}
+
+	var adviceList []*IndexAdvice
+	for _, table := range tableList {
+		defList := indexDefList(table.IndexList)
+		for _, def := range defList {
+			// Dropping the MySQL primary key changes the clustered index.
+			if def.name == "PRIMARY" {
+				continue
+			}
+			if covering := findCoveringIndex(def, defList); covering != nil {
+				adviceList = append(adviceList, &IndexAdvice{
+					Type:              IndexAdviceRedundant,
+					TableName:         table.Name,
+					IndexName:         def.name,
+					CoveringIndexName: covering.name,
+					Reason: fmt.Sprintf("Columns (%s) are a prefix of index %s (%s).",
+						strings.Join(def.expressionList, ", "), covering.name, strings.Join(covering.expressionList, ", ")),
+					Statement: dropIndexStatement(dbType, table.Name, def.name),
+				})
+				continue
+			}
+
+			if usage == nil || def.unique || backsForeignKey(def, table.ConstraintList) {
+				continue
+			}
+			// The index not found in the usage is unknown rather than unused, e.g. created after the statistics.
+			readCount, ok := usageMap[table.Name][def.name]
+			if !ok || readCount > 0 {
+				continue
+			}
+			adviceList = append(adviceList, &IndexAdvice{
+				Type:      IndexAdviceUnused,
+				TableName: table.Name,
+				IndexName: def.name,
+				Reason: fmt.Sprintf("No reads since %s.",
+					time.Unix(usage.SinceTs, 0).UTC().Format(time.RFC3339)),
+				Statement: dropIndexStatement(dbType, table.Name, def.name),
+			})
+		}
+	}
+
+	sort.Slice(adviceList, func(i, j int) bool {
+		if adviceList[i].TableName != adviceList[j].TableName {
+			return adviceList[i].TableName < adviceList[j].TableName
+		}
+		return adviceList[i].IndexName < adviceList[j].IndexName
+	})
+	return adviceList
+}
+
+// indexDefList groups the index rows by the index name, ordered by the name.
+func indexDefList(indexList []DBIndex) []*indexDef {
+	sorted := append([]DBIndex(nil), indexList...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Position < sorted[j].Position
+	})
+
+	var defList []*indexDef
+	for _, index := range sorted {
+		if len(defList) == 0 || defList[len(defList)-1].name != index.Name {
+			defList = append(defList, &indexDef{
+				name:      index.Name,
+				indexType: strings.ToUpper(index.Type),
+				unique:    index.Unique,
+				visible:   index.Visible,
+			})
+		}
+		def := defList[len(defList)-1]
+		def.expressionList = append(def.expressionList, index.Expression)
+	}
+	return defList
+}
+
+// findCoveringIndex returns the visible index of the same type covering def, or nil if not found.
+// For identical indexes, only the later one by name is covered, unless the earlier one is unique.
+func findCoveringIndex(def *indexDef, defList []*indexDef) *indexDef {
+	for _, other := range defList {
+		if other == def || !other.visible || other.indexType != def.indexType {
+			continue
+		}
+		if !isPrefix(def.expressionList, other.expressionList) {
+			continue
+		}
+		if len(def.expressionList) == len(other.expressionList) {
+			// The unique index enforces more than the other one, otherwise keep the first one.
+			if def.unique && !other.unique {
+				continue
+			}
+			if def.unique == other.unique && def.name < other.name {
+				continue
+			}
+			return other
+		}
+		// Only the B-tree indexes can serve the lookups on the leading columns.
+		if def.unique || def.indexType != "BTREE" {
+			continue
+		}
+		return other
+	}
+	return nil
+}
+
+// backsForeignKey returns true if the foreign key may rely on the index, e.g. MySQL requires an index
+// on the foreign key columns.
+func backsForeignKey(def *indexDef, constraintList []DBConstraint) bool {
+	for _, constraint := range constraintList {
+		if constraint.Type == "FOREIGN KEY" && isPrefix(constraint.ColumnList, def.expressionList) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrefix returns true if a is a prefix of b.
+func isPrefix(a []string, b []string) bool {
+	if len(a) == 0 || len(a) > len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// dropIndexStatement returns the statement dropping the index of the table.
+func dropIndexStatement(dbType Type, tableName string, indexName string) string {
+	switch dbType {
+	case Mysql:
+		return fmt.Sprintf("DROP INDEX `%s` ON `%s`;", strings.ReplaceAll(indexName, "`", "``"), strings.ReplaceAll(tableName, "`", "``"))
+	case Postgres:
+		// The index lives in the schema of the table, see pgQualifiedName.
+		if i := strings.Index(tableName, "."); i >= 0 {
+			return fmt.Sprintf("DROP INDEX %s.%s;", pgQuoteIdentifier(tableName[:i]), pgQuoteIdentifier(indexName))
+		}
+		return fmt.Sprintf("DROP INDEX %s;", pgQuoteIdentifier(indexName))
+	}
+	return fmt.Sprintf(`DROP INDEX "%s";`, strings.ReplaceAll(indexName, `"`, `""`))
+}
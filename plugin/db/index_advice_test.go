@@ -0,0 +1,91 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAdviseIndex(t *testing.T) {
+	tableList := []DBTable{
+		{
+			Name: "t1",
+			IndexList: []DBIndex{
+				{Name: "PRIMARY", Expression: "id", Position: 1, Type: "BTREE", Unique: true, Visible: true},
+				{Name: "idx_a", Expression: "a", Position: 1, Type: "BTREE", Visible: true},
+				{Name: "idx_a_b", Expression: "b", Position: 2, Type: "BTREE", Visible: true},
+				{Name: "idx_a_b", Expression: "a", Position: 1, Type: "BTREE", Visible: true},
+				{Name: "idx_a_b_copy", Expression: "a", Position: 1, Type: "BTREE", Visible: true},
+				{Name: "idx_a_b_copy", Expression: "b", Position: 2, Type: "BTREE", Visible: true},
+				{Name: "uk_c", Expression: "c", Position: 1, Type: "BTREE", Unique: true, Visible: true},
+				{Name: "idx_c", Expression: "c", Position: 1, Type: "BTREE", Visible: true},
+				{Name: "idx_d", Expression: "d", Position: 1, Type: "BTREE", Visible: true},
+				{Name: "idx_d_e", Expression: "d", Position: 1, Type: "BTREE", Visible: false},
+				{Name: "idx_d_e", Expression: "e", Position: 2, Type: "BTREE", Visible: false},
+				{Name: "idx_f", Expression: "f", Position: 1, Type: "BTREE", Visible: true},
+				{Name: "idx_g", Expression: "g", Position: 1, Type: "BTREE", Visible: true},
+			},
+			ConstraintList: []DBConstraint{
+				{Name: "fk_f", Type: "FOREIGN KEY", ColumnList: []string{"f"}, ReferencedTable: "t2", ReferencedColumnList: []string{"id"}},
+			},
+		},
+	}
+	usage := &IndexUsageStats{
+		SinceTs: 0,
+		UsageList: []*IndexUsage{
+			{TableName: "t1", IndexName: "PRIMARY", ReadCount: 0},
+			{TableName: "t1", IndexName: "idx_a_b", ReadCount: 10},
+			{TableName: "t1", IndexName: "uk_c", ReadCount: 0},
+			{TableName: "t1", IndexName: "idx_d", ReadCount: 5},
+			{TableName: "t1", IndexName: "idx_d_e", ReadCount: 0},
+			{TableName: "t1", IndexName: "idx_f", ReadCount: 0},
+			{TableName: "t1", IndexName: "idx_g", ReadCount: 0},
+		},
+	}
+
+	type finding struct {
+		adviceType IndexAdviceType
+		index      string
+		covering   string
+		statement  string
+	}
+	var got []finding
+	for _, advice := range AdviseIndex(Mysql, tableList, usage) {
+		got = append(got, finding{advice.Type, advice.IndexName, advice.CoveringIndexName, advice.Statement})
+	}
+	want := []finding{
+		{IndexAdviceRedundant, "idx_a", "idx_a_b", "DROP INDEX `idx_a` ON `t1`;"},
+		{IndexAdviceRedundant, "idx_a_b_copy", "idx_a_b", "DROP INDEX `idx_a_b_copy` ON `t1`;"},
+		{IndexAdviceRedundant, "idx_c", "uk_c", "DROP INDEX `idx_c` ON `t1`;"},
+		// idx_d is covered by the invisible idx_d_e only, idx_f backs the foreign key.
+		{IndexAdviceUnused, "idx_d_e", "", "DROP INDEX `idx_d_e` ON `t1`;"},
+		{IndexAdviceUnused, "idx_g", "", "DROP INDEX `idx_g` ON `t1`;"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got advice %+v, want %+v", got, want)
+	}
+
+	// Only the redundant indexes are found without the usage.
+	if adviceList := AdviseIndex(Mysql, tableList, nil); len(adviceList) != 3 {
+		t.Errorf("got %d advice without usage, want 3", len(adviceList))
+	}
+}
+
+func TestDropIndexStatement(t *testing.T) {
+	tests := []struct {
+		dbType    Type
+		tableName string
+		indexName string
+		want      string
+	}{
+		{dbType: Mysql, tableName: "t1", indexName: "idx`1", want: "DROP INDEX `idx``1` ON `t1`;"},
+		{dbType: Postgres, tableName: "t1", indexName: "idx_1", want: `DROP INDEX "idx_1";`},
+		{dbType: Postgres, tableName: "s1.t1", indexName: "idx_1", want: `DROP INDEX "s1"."idx_1";`},
+		{dbType: SQLite, tableName: "t1", indexName: "idx_1", want: `DROP INDEX "idx_1";`},
+	}
+
+	for _, test := range tests {
+		if got := dropIndexStatement(test.dbType, test.tableName, test.indexName); got != test.want {
+			t.Errorf("dropIndexStatement(%s, %q, %q) got %q, want %q", test.dbType, test.tableName, test.indexName, got, test.want)
+		}
+	}
+}
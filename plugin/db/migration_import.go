@@ -0,0 +1,344 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultFlywayHistoryTable is the default history table of Flyway.
+	DefaultFlywayHistoryTable = "flyway_schema_history"
+	// DefaultLiquibaseHistoryTable is the default history table of Liquibase.
+	DefaultLiquibaseHistoryTable = "DATABASECHANGELOG"
+)
+
+// historyTableNamePattern matches the optionally qualified table name, which is composed into the query as is.
+var historyTableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)?$`)
+
+// MigrationHistoryImport is the request to import the migration history recorded by another migration tool.
+type MigrationHistoryImport struct {
+	// Engine is the migration tool, either Flyway or Liquibase.
+	Engine MigrationEngine
+	// Namespace records the imported history, which is the database name.
+	Namespace string
+	// Table is the history table of the tool, DefaultFlywayHistoryTable or DefaultLiquibaseHistoryTable if not set.
+	// It can be qualified by the schema, e.g. "public.flyway_schema_history".
+	Table string
+	// LockTimeout is how long to wait for the migration lock of the namespace, DefaultMigrationLockTimeout if not set.
+	LockTimeout time.Duration
+}
+
+// ImportedMigrationPayload is the payload of the migration history imported from another migration tool.
+// The tools don't record the applied statement, the script and checksum identify it instead.
+type ImportedMigrationPayload struct {
+	// Script is the migration script of Flyway, or the changelog file of Liquibase.
+	Script   string `json:"script,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// migrationLockInfo returns the migration info to take the migration lock of the namespace.
+func (imp *MigrationHistoryImport) migrationLockInfo() *MigrationInfo {
+	return &MigrationInfo{Namespace: imp.Namespace, LockTimeout: imp.LockTimeout}
+}
+
+// readImportedMigrationHistory reads the history table of the migration tool from db, and converts the applied
+// migrations into the migration history in the order they are applied. The epochFormat converts a timestamp column
+// into the unix timestamp, e.g. "CAST(UNIX_TIMESTAMP(%s) AS SIGNED)" for MySQL.
+func readImportedMigrationHistory(ctx context.Context, db *sql.DB, imp *MigrationHistoryImport, epochFormat string) ([]*MigrationHistory, error) {
+	table := imp.Table
+	switch imp.Engine {
+	case Flyway:
+		if table == "" {
+			table = DefaultFlywayHistoryTable
+		}
+	case Liquibase:
+		if table == "" {
+			table = DefaultLiquibaseHistoryTable
+		}
+	default:
+		return nil, fmt.Errorf("cannot import migration history from engine %q, only %s and %s are supported", imp.Engine, Flyway, Liquibase)
+	}
+	if !historyTableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid history table name %q", table)
+	}
+
+	if imp.Engine == Flyway {
+		return readFlywayHistory(ctx, db, imp.Namespace, table, epochFormat)
+	}
+	return readLiquibaseHistory(ctx, db, imp.Namespace, table, epochFormat)
+}
+
+// flywayHistoryRow is a row of the flyway_schema_history table.
+type flywayHistoryRow struct {
+	installedRank int
+	// Empty for the repeatable migrations.
+	version       sql.NullString
+	description   string
+	migrationType string
+	script        string
+	checksum      sql.NullInt64
+	installedBy   string
+	installedTs   int64
+	// In milliseconds.
+	executionTime int64
+	success       bool
+}
+
+func readFlywayHistory(ctx context.Context, db *sql.DB, namespace string, table string, epochFormat string) ([]*MigrationHistory, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			installed_rank,
+			version,
+			description,
+			type,
+			script,
+			checksum,
+			installed_by,
+			%s,
+			execution_time,
+			success
+		FROM %s
+		ORDER BY installed_rank`, fmt.Sprintf(epochFormat, "installed_on"), table)
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var historyList []*MigrationHistory
+	for rows.Next() {
+		var row flywayHistoryRow
+		if err := rows.Scan(
+			&row.installedRank,
+			&row.version,
+			&row.description,
+			&row.migrationType,
+			&row.script,
+			&row.checksum,
+			&row.installedBy,
+			&row.installedTs,
+			&row.executionTime,
+			&row.success,
+		); err != nil {
+			return nil, err
+		}
+		history, err := row.toMigrationHistory(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if history != nil {
+			historyList = append(historyList, history)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return historyList, nil
+}
+
+// toMigrationHistory converts the row into the migration history, or returns nil if the row is not an applied migration.
+func (row *flywayHistoryRow) toMigrationHistory(namespace string) (*MigrationHistory, error) {
+	// The failed migrations are only recorded by the databases without transactional DDL, and they are not applied.
+	if !row.success {
+		return nil, nil
+	}
+
+	history := &MigrationHistory{
+		Namespace:         namespace,
+		Engine:            Flyway,
+		Type:              Sql,
+		Version:           row.version.String,
+		Description:       row.description,
+		ExecutionDuration: int(row.executionTime / 1000),
+	}
+	switch row.migrationType {
+	case "SCHEMA", "DELETE":
+		// SCHEMA marks the schemas created by Flyway, and DELETE marks the migrations removed by "flyway repair".
+		return nil, nil
+	case "BASELINE":
+		history.Type = Baseline
+	case "UNDO_SQL", "UNDO_JDBC", "UNDO_SCRIPT":
+		// The undo migration has the same version as the migration it undoes.
+		history.Type = Rollback
+		history.Version = fmt.Sprintf("%s.undo.%d", row.version.String, row.installedRank)
+	}
+	if !row.version.Valid {
+		// The repeatable migration is applied again whenever its checksum changes, so we identify it by the rank.
+		history.Version = fmt.Sprintf("repeatable.%d", row.installedRank)
+	}
+
+	payload := &ImportedMigrationPayload{Script: row.script}
+	if row.checksum.Valid {
+		payload.Checksum = strconv.FormatInt(row.checksum.Int64, 10)
+	}
+	return completeImportedMigrationHistory(history, row.installedBy, row.installedTs, payload)
+}
+
+// liquibaseHistoryRow is a row of the DATABASECHANGELOG table.
+type liquibaseHistoryRow struct {
+	id          string
+	author      string
+	filename    string
+	executedTs  int64
+	execType    string
+	md5sum      sql.NullString
+	description sql.NullString
+	comments    sql.NullString
+}
+
+func readLiquibaseHistory(ctx context.Context, db *sql.DB, namespace string, table string, epochFormat string) ([]*MigrationHistory, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			ID,
+			AUTHOR,
+			FILENAME,
+			%s,
+			EXECTYPE,
+			MD5SUM,
+			DESCRIPTION,
+			COMMENTS
+		FROM %s
+		ORDER BY ORDEREXECUTED, DATEEXECUTED`, fmt.Sprintf(epochFormat, "DATEEXECUTED"), table)
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var historyList []*MigrationHistory
+	for rows.Next() {
+		var row liquibaseHistoryRow
+		if err := rows.Scan(
+			&row.id,
+			&row.author,
+			&row.filename,
+			&row.executedTs,
+			&row.execType,
+			&row.md5sum,
+			&row.description,
+			&row.comments,
+		); err != nil {
+			return nil, err
+		}
+		history, err := row.toMigrationHistory(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if history != nil {
+			historyList = append(historyList, history)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return historyList, nil
+}
+
+// toMigrationHistory converts the row into the migration history, or returns nil if the changeset is not applied.
+func (row *liquibaseHistoryRow) toMigrationHistory(namespace string) (*MigrationHistory, error) {
+	history := &MigrationHistory{
+		Namespace: namespace,
+		Engine:    Liquibase,
+		// A changeset is identified by the changelog file, id and author together.
+		Version:     fmt.Sprintf("%s::%s::%s", row.filename, row.id, row.author),
+		Description: row.comments.String,
+	}
+	switch row.execType {
+	case "EXECUTED", "RERAN":
+		history.Type = Sql
+	case "MARK_RAN":
+		// The changeset is marked as applied without running, e.g. by "liquibase changelog-sync".
+		history.Type = Baseline
+	default:
+		// FAILED and SKIPPED
+		return nil, nil
+	}
+	if history.Description == "" {
+		history.Description = row.description.String
+	}
+
+	payload := &ImportedMigrationPayload{Script: row.filename, Checksum: row.md5sum.String}
+	return completeImportedMigrationHistory(history, row.author, row.executedTs, payload)
+}
+
+func completeImportedMigrationHistory(history *MigrationHistory, creator string, createdTs int64, payload *ImportedMigrationPayload) (*MigrationHistory, error) {
+	history.Creator = creator
+	history.CreatedTs = createdTs
+	history.Updater = creator
+	history.UpdatedTs = createdTs
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	history.Payload = string(bytes)
+	return history, nil
+}
+
+// importMigrationHistory records the imported history in tx, and returns the number of the migrations recorded.
+// The versions already recorded are skipped. insertQuery has the placeholders of the columns in the order of
+// importedMigrationHistoryInsert.
+func importMigrationHistory(ctx context.Context, tx *sql.Tx, historyList []*MigrationHistory, queries migrationHistoryQueries, insertQuery string) (int, error) {
+	count := 0
+	for _, history := range historyList {
+		duplicate, err := queries.checkDuplicateVersion(ctx, tx, history.Namespace, history.Engine, history.Version)
+		if err != nil {
+			return 0, err
+		}
+		if duplicate {
+			continue
+		}
+		sequence, err := queries.findNextSequence(ctx, tx, history.Namespace, false)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, insertQuery,
+			history.Creator,
+			history.CreatedTs,
+			history.Updater,
+			history.UpdatedTs,
+			history.Namespace,
+			sequence,
+			history.Engine,
+			history.Type,
+			history.Version,
+			history.Description,
+			// The tools don't record the applied statement.
+			"",
+			"",
+			history.ExecutionDuration,
+			"",
+			history.Payload,
+		); err != nil {
+			return 0, formatErrorWithQuery(err, insertQuery)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// importedMigrationHistoryInsert is the insert query of importMigrationHistory for MySQL and SQLite.
+const importedMigrationHistoryInsert = `
+	INSERT INTO bytebase.migration_history (
+		created_by,
+		created_ts,
+		updated_by,
+		updated_ts,
+		namespace,
+		sequence,
+		` + "`engine`," + `
+		` + "`type`," + `
+		version,
+		description,
+		statement,
+		rollback_statement,
+		execution_duration,
+		issue_id,
+		payload
+	)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestImportMigrationHistory(t *testing.T) {
+	ctx := context.Background()
+	driver, err := Open(SQLite, DriverConfig{Logger: zap.NewNop()}, ConnectionConfig{
+		Host:     t.TempDir(),
+		Database: "db1.db",
+	}, ConnectionContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Close(ctx)
+	if err := driver.SetupMigrationIfNeeded(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The history tables as created by Flyway and Liquibase.
+	if err := driver.Execute(ctx, `
+		CREATE TABLE flyway_schema_history (
+			installed_rank INT NOT NULL PRIMARY KEY,
+			version VARCHAR(50),
+			description VARCHAR(200) NOT NULL,
+			type VARCHAR(20) NOT NULL,
+			script VARCHAR(1000) NOT NULL,
+			checksum INT,
+			installed_by VARCHAR(100) NOT NULL,
+			installed_on TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f','now')),
+			execution_time INT NOT NULL,
+			success BOOLEAN NOT NULL
+		);
+		INSERT INTO flyway_schema_history VALUES
+			(1, '1', '<< Flyway Baseline >>', 'BASELINE', '<< Flyway Baseline >>', NULL, 'alice', '2021-01-01 00:00:00', 0, 1),
+			(2, '1.1', 'create t1', 'SQL', 'V1.1__create_t1.sql', 123, 'alice', '2021-01-02 00:00:00', 2500, 1),
+			(3, '1.2', 'broken', 'SQL', 'V1.2__broken.sql', 456, 'bob', '2021-01-03 00:00:00', 10, 0),
+			(4, NULL, 'views', 'SQL', 'R__views.sql', 789, 'bob', '2021-01-04 00:00:00', 10, 1);
+		CREATE TABLE DATABASECHANGELOG (
+			ID VARCHAR(255) NOT NULL,
+			AUTHOR VARCHAR(255) NOT NULL,
+			FILENAME VARCHAR(255) NOT NULL,
+			DATEEXECUTED TEXT NOT NULL,
+			ORDEREXECUTED INT NOT NULL,
+			EXECTYPE VARCHAR(10) NOT NULL,
+			MD5SUM VARCHAR(35),
+			DESCRIPTION VARCHAR(255),
+			COMMENTS VARCHAR(255)
+		);
+		INSERT INTO DATABASECHANGELOG VALUES
+			('1', 'alice', 'changelog.xml', '2021-02-01 00:00:00', 1, 'EXECUTED', '8:abc', 'createTable tableName=t2', ''),
+			('2', 'bob', 'changelog.xml', '2021-02-02 00:00:00', 2, 'FAILED', '8:def', 'addColumn tableName=t2', NULL);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		engine    MigrationEngine
+		wantCount int
+	}{
+		{Flyway, 3},
+		{Liquibase, 1},
+		// Importing again skips the imported ones.
+		{Flyway, 0},
+	} {
+		count, err := driver.ImportMigrationHistory(ctx, &MigrationHistoryImport{Engine: test.engine, Namespace: "db1"})
+		if err != nil {
+			t.Fatalf("failed to import %s history: %v", test.engine, err)
+		}
+		if count != test.wantCount {
+			t.Errorf("imported %d %s migrations, want %d", count, test.engine, test.wantCount)
+		}
+	}
+
+	namespace := "db1"
+	historyList, err := driver.FindMigrationHistoryList(ctx, &MigrationHistoryFind{Database: &namespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type history struct {
+		sequence          int
+		engine            MigrationEngine
+		migrationType     MigrationType
+		version           string
+		creator           string
+		createdTs         int64
+		executionDuration int
+	}
+	var got []history
+	for _, h := range historyList {
+		got = append(got, history{h.Sequence, h.Engine, h.Type, h.Version, h.Creator, h.CreatedTs, h.ExecutionDuration})
+	}
+	// Most recent first.
+	want := []history{
+		{4, Liquibase, Sql, "changelog.xml::1::alice", "alice", 1612137600, 0},
+		{3, Flyway, Sql, "repeatable.4", "bob", 1609718400, 0},
+		{2, Flyway, Sql, "1.1", "alice", 1609545600, 2},
+		{1, Flyway, Baseline, "1", "alice", 1609459200, 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got history %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got history #%d %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// The imported history establishes the baseline for the VCS migrations.
+	if err := driver.ExecuteMigration(ctx, &MigrationInfo{
+		Version:   "0001",
+		Namespace: "db1",
+		Database:  "db1",
+		Engine:    VCS,
+		Type:      Sql,
+		Creator:   "test",
+	}, "CREATE TABLE t3 (id INT)", nil); err != nil {
+		t.Errorf("failed to apply VCS migration after importing the history: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// DefaultMigrationLockTimeout is the default time to wait for the migration lock of the namespace.
+const DefaultMigrationLockTimeout = time.Minute
+
+// migrationLockPrefix prefixes the lock names, so that they don't collide with the locks taken by the applications.
+const migrationLockPrefix = "bytebase_migration:"
+
+// MigrationLockError is returned if the migration lock of the namespace can't be acquired in time, e.g. another
+// Bytebase server is migrating the same namespace.
+type MigrationLockError struct {
+	Namespace string
+	Timeout   time.Duration
+	// Holder describes the session holding the lock, empty if unknown, e.g. the lock is released meanwhile.
+	Holder string
+}
+
+func (e *MigrationLockError) Error() string {
+	holder := e.Holder
+	if holder == "" {
+		holder = "another session"
+	}
+	return fmt.Sprintf("timed out after %v waiting for the migration lock of namespace %q, which is held by %s", e.Timeout, e.Namespace, holder)
+}
+
+// migrationLockTimeout returns the lock timeout of the migration.
+func migrationLockTimeout(m *MigrationInfo) time.Duration {
+	if m.LockTimeout > 0 {
+		return m.LockTimeout
+	}
+	return DefaultMigrationLockTimeout
+}
+
+// migrationLockName returns the lock name of the namespace, which is hashed if it exceeds the 64 characters limit of
+// MySQL GET_LOCK.
+func migrationLockName(namespace string) string {
+	name := migrationLockPrefix + namespace
+	if len(name) <= 64 {
+		return name
+	}
+	sum := sha1.Sum([]byte(namespace))
+	return migrationLockPrefix + hex.EncodeToString(sum[:])
+}
+
+// migrationLockKey returns the 64-bit key of the namespace for the Postgres advisory lock.
+func migrationLockKey(namespace string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(migrationLockPrefix + namespace))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrationLockName(t *testing.T) {
+	if got, want := migrationLockName("db1"), "bytebase_migration:db1"; got != want {
+		t.Errorf("migrationLockName(%q) got %q, want %q", "db1", got, want)
+	}
+
+	long := strings.Repeat("a", 64)
+	got := migrationLockName(long)
+	if len(got) > 64 || !strings.HasPrefix(got, migrationLockPrefix) {
+		t.Errorf("migrationLockName(%q) got %q, want at most 64 characters with prefix %q", long, got, migrationLockPrefix)
+	}
+	if got == migrationLockName(strings.Repeat("b", 64)) {
+		t.Errorf("migrationLockName got the same name %q for different namespaces", got)
+	}
+}
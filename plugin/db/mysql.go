@@ -4,28 +4,47 @@ import (
 	"context"
 	"database/sql"
 	_ "embed"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"go.uber.org/zap"
 )
 
 //go:embed mysql_migration_schema.sql
 var migrationSchema string
 
+//go:embed oceanbase_migration_schema.sql
+var oceanBaseMigrationSchema string
+
 var (
 	_ Driver = (*MySQLDriver)(nil)
 )
 
 func init() {
-	register(Mysql, newDriver)
+	register(Mysql, newDriver, Capability{
+		// MySQL implicitly commits the transaction upon DDL.
+		TransactionalDDL: false,
+		UserAndGrant:     true,
+		// Backed by mysqldump and mysqlrestore.
+		BackupRestore: true,
+		// Backed by the shadow table copy, see OnlineDDLConfig.
+		OnlineDDL:      true,
+		CreateDatabase: true,
+		// Backed by the performance_schema.
+		IndexUsage: true,
+	})
 }
 
 type MySQLDriver struct {
 	l             *zap.Logger
 	connectionCtx ConnectionContext
+	// Detected upon opening the driver, the queries are adapted to the flavor and version.
+	serverInfo *ServerInfo
 
 	db *sql.DB
 }
@@ -59,12 +78,90 @@ func (driver *MySQLDriver) open(config ConnectionConfig, ctx ConnectionContext)
 	if err != nil {
 		panic(err)
 	}
+	setConnectionLimit(db, config.MaxOpenConns, config.MaxIdleConns)
+
+	serverInfo, err := detectMySQLServerInfo(context.Background(), db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	driver.l.Debug("Detected MySQL compatible server",
+		zap.String("flavor", serverInfo.Flavor.String()),
+		zap.String("version", serverInfo.Version),
+		zap.String("environment", ctx.EnvironmentName),
+		zap.String("database", ctx.InstanceName),
+	)
+
 	driver.db = db
 	driver.connectionCtx = ctx
+	driver.serverInfo = serverInfo
 
 	return driver, nil
 }
 
+// detectMySQLServerInfo detects the flavor and version of the MySQL compatible server.
+func detectMySQLServerInfo(ctx context.Context, db *sql.DB) (*ServerInfo, error) {
+	query := "SELECT VERSION(), @@version_comment"
+	var version, comment string
+	if err := db.QueryRowContext(ctx, query).Scan(&version, &comment); err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	return parseMySQLServerInfo(version, comment), nil
+}
+
+// parseMySQLServerInfo derives ServerInfo from the VERSION() and @@version_comment output.
+// Examples:
+// - MySQL: "8.0.25-0ubuntu0.20.04.1", "(Ubuntu)"
+// - MariaDB: "10.5.9-MariaDB-1:10.5.9+maria~focal", "mariadb.org binary distribution"
+// - TiDB: "5.7.25-TiDB-v5.0.1", "TiDB Server (Apache License 2.0) Community Edition, MySQL 5.7 compatible"
+// - OceanBase: "5.7.25", "OceanBase 3.1.0 (r1-0d3f5aa3ae6c1c4dc0b4d9b2bd2b8e4a0d6a3f7e) (Built Jun 30 2021 13:11:24)"
+func parseMySQLServerInfo(version string, comment string) *ServerInfo {
+	const tidbMarker = "-TiDB-"
+	if i := strings.Index(version, tidbMarker); i >= 0 {
+		return &ServerInfo{
+			Flavor:  FlavorTiDB,
+			Version: strings.TrimPrefix(version[i+len(tidbMarker):], "v"),
+		}
+	}
+
+	if strings.Contains(version, "MariaDB") {
+		return &ServerInfo{
+			Flavor:  FlavorMariaDB,
+			Version: strings.SplitN(version, "-", 2)[0],
+		}
+	}
+
+	if fields := strings.Fields(comment); len(fields) >= 2 && fields[0] == "OceanBase" {
+		return &ServerInfo{
+			Flavor:  FlavorOceanBase,
+			Version: fields[1],
+		}
+	}
+
+	return &ServerInfo{
+		Flavor:  FlavorMySQL,
+		Version: strings.SplitN(version, "-", 2)[0],
+	}
+}
+
+// versionAtLeast returns true if the dot separated version is equal to or higher than major.minor.
+func versionAtLeast(version string, major int, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	v1, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	// Tolerate suffix like "10.6rc"
+	v2, err := strconv.Atoi(strings.TrimRightFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' }))
+	if err != nil {
+		return false
+	}
+	return v1 > major || (v1 == major && v2 >= minor)
+}
+
 func (driver *MySQLDriver) Close(ctx context.Context) error {
 	return driver.db.Close()
 }
@@ -73,22 +170,11 @@ func (driver *MySQLDriver) Ping(ctx context.Context) error {
 	return driver.db.PingContext(ctx)
 }
 
-func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSchema, error) {
-	// Query MySQL version
-	query := "SELECT VERSION()"
-	versionRow, err := driver.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, nil, formatErrorWithQuery(err, query)
-	}
-	defer versionRow.Close()
-
-	var version string
-	versionRow.Next()
-	if err := versionRow.Scan(&version); err != nil {
-		return nil, nil, err
-	}
-	isMySQL8 := strings.HasPrefix(version, "8.0")
+func (driver *MySQLDriver) GetServerInfo(ctx context.Context) (*ServerInfo, error) {
+	return driver.serverInfo, nil
+}
 
+func (driver *MySQLDriver) excludedDatabaseList() []string {
 	excludedDatabaseList := []string{
 		"'mysql'",
 		"'information_schema'",
@@ -97,15 +183,505 @@ func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSche
 		// Skip our internal "bytebase" database
 		"'bytebase'",
 	}
+	switch driver.serverInfo.Flavor {
+	case FlavorTiDB:
+		// TiDB compares schema name in case-sensitive way unless the new collation framework is enabled.
+		excludedDatabaseList = append(excludedDatabaseList,
+			"'INFORMATION_SCHEMA'",
+			"'PERFORMANCE_SCHEMA'",
+			"'METRICS_SCHEMA'",
+			"'metrics_schema'",
+		)
+	case FlavorOceanBase:
+		excludedDatabaseList = append(excludedDatabaseList,
+			"'oceanbase'",
+			"'__recyclebin'",
+			"'__public'",
+			"'LBACSYS'",
+			"'ORAAUDITOR'",
+		)
+	}
+	return excludedDatabaseList
+}
 
-	// Query user info
-	query = `
+func (driver *MySQLDriver) userListQuery() string {
+	switch driver.serverInfo.Flavor {
+	case FlavorMariaDB:
+		// MariaDB stores roles in mysql.user as well, which can't be used with SHOW GRANTS FOR user@host.
+		return `
+			SELECT
+				user,
+				host
+			FROM mysql.user
+			WHERE user NOT LIKE 'mysql.%' AND user NOT LIKE 'mariadb.%' AND is_role = 'N'
+		`
+	}
+	return `
 	    SELECT
 			user,
 			host
 		FROM mysql.user
 		WHERE user NOT LIKE 'mysql.%'
 	`
+}
+
+func (driver *MySQLDriver) indexListQuery(indexWhere string) string {
+	info := driver.serverInfo
+	switch {
+	// MySQL 8.0 introduces functional index and invisible index, TiDB supports both since 5.0.
+	case info.Flavor == FlavorMySQL && versionAtLeast(info.Version, 8, 0),
+		info.Flavor == FlavorTiDB && versionAtLeast(info.Version, 5, 0):
+		return `
+			SELECT
+				TABLE_SCHEMA,
+				TABLE_NAME,
+				INDEX_NAME,
+				COLUMN_NAME,
+				EXPRESSION,
+				SEQ_IN_INDEX,
+				INDEX_TYPE,
+				CASE NON_UNIQUE WHEN 0 THEN 1 ELSE 0 END AS IS_UNIQUE,
+				CASE IS_VISIBLE WHEN 'YES' THEN 1 ELSE 0 END,
+				INDEX_COMMENT
+			FROM information_schema.STATISTICS
+			WHERE ` + indexWhere
+	// MariaDB 10.6 introduces ignored index, which is the counterpart of the MySQL invisible index.
+	case info.Flavor == FlavorMariaDB && versionAtLeast(info.Version, 10, 6):
+		return `
+			SELECT
+				TABLE_SCHEMA,
+				TABLE_NAME,
+				INDEX_NAME,
+				COLUMN_NAME,
+				'',
+				SEQ_IN_INDEX,
+				INDEX_TYPE,
+				CASE NON_UNIQUE WHEN 0 THEN 1 ELSE 0 END AS IS_UNIQUE,
+				CASE IGNORED WHEN 'NO' THEN 1 ELSE 0 END,
+				INDEX_COMMENT
+			FROM information_schema.STATISTICS
+			WHERE ` + indexWhere
+	}
+	return `
+			SELECT
+				TABLE_SCHEMA,
+				TABLE_NAME,
+				INDEX_NAME,
+				COLUMN_NAME,
+				'',
+				SEQ_IN_INDEX,
+				INDEX_TYPE,
+				CASE NON_UNIQUE WHEN 0 THEN 1 ELSE 0 END AS IS_UNIQUE,
+				1,
+				INDEX_COMMENT
+			FROM information_schema.STATISTICS
+			WHERE ` + indexWhere
+}
+
+// getTiDBSequenceOptionMap returns the dbName/sequenceName -> sequence options map.
+func (driver *MySQLDriver) getTiDBSequenceOptionMap(ctx context.Context, sequenceWhere string) (map[string]string, error) {
+	query := `
+			SELECT
+				SEQUENCE_SCHEMA,
+				SEQUENCE_NAME,
+				START,
+				INCREMENT,
+				MIN_VALUE,
+				MAX_VALUE,
+				CYCLE,
+				CACHE,
+				CACHE_VALUE
+			FROM information_schema.SEQUENCES
+			WHERE ` + sequenceWhere
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	sequenceMap := make(map[string]string)
+	for rows.Next() {
+		var dbName, sequenceName string
+		var start, increment, minValue, maxValue, cacheValue int64
+		var cycle, cache bool
+		if err := rows.Scan(
+			&dbName,
+			&sequenceName,
+			&start,
+			&increment,
+			&minValue,
+			&maxValue,
+			&cycle,
+			&cache,
+			&cacheValue,
+		); err != nil {
+			return nil, err
+		}
+
+		// Use the same notation as the CREATE SEQUENCE options.
+		optionList := []string{
+			fmt.Sprintf("start with %d", start),
+			fmt.Sprintf("increment by %d", increment),
+			fmt.Sprintf("minvalue %d", minValue),
+			fmt.Sprintf("maxvalue %d", maxValue),
+		}
+		if cache {
+			optionList = append(optionList, fmt.Sprintf("cache %d", cacheValue))
+		} else {
+			optionList = append(optionList, "nocache")
+		}
+		if cycle {
+			optionList = append(optionList, "cycle")
+		} else {
+			optionList = append(optionList, "nocycle")
+		}
+		sequenceMap[fmt.Sprintf("%s/%s", dbName, sequenceName)] = strings.Join(optionList, " ")
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sequenceMap, nil
+}
+
+// getConstraintMap returns the dbName/tableName -> constraintList map, including the primary keys,
+// foreign keys and check constraints.
+func (driver *MySQLDriver) getConstraintMap(ctx context.Context, excludedDatabaseList []string) (map[string][]DBConstraint, error) {
+	constraintMap := make(map[string][]DBConstraint)
+
+	// Query primary key and foreign key info, one row per column.
+	query := `
+			SELECT
+				kcu.TABLE_SCHEMA,
+				kcu.TABLE_NAME,
+				kcu.CONSTRAINT_NAME,
+				tc.CONSTRAINT_TYPE,
+				kcu.COLUMN_NAME,
+				IFNULL(kcu.REFERENCED_TABLE_SCHEMA, ''),
+				IFNULL(kcu.REFERENCED_TABLE_NAME, ''),
+				IFNULL(kcu.REFERENCED_COLUMN_NAME, ''),
+				IFNULL(rc.UPDATE_RULE, ''),
+				IFNULL(rc.DELETE_RULE, '')
+			FROM information_schema.KEY_COLUMN_USAGE kcu
+			JOIN information_schema.TABLE_CONSTRAINTS tc
+				ON tc.CONSTRAINT_SCHEMA = kcu.CONSTRAINT_SCHEMA AND tc.TABLE_NAME = kcu.TABLE_NAME AND tc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME
+			LEFT JOIN information_schema.REFERENTIAL_CONSTRAINTS rc
+				ON rc.CONSTRAINT_SCHEMA = kcu.CONSTRAINT_SCHEMA AND rc.TABLE_NAME = kcu.TABLE_NAME AND rc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME
+			WHERE tc.CONSTRAINT_TYPE IN ('PRIMARY KEY', 'FOREIGN KEY') AND ` + fmt.Sprintf("kcu.TABLE_SCHEMA NOT IN (%s)", strings.Join(excludedDatabaseList, ", ")) + `
+			ORDER BY kcu.TABLE_SCHEMA, kcu.TABLE_NAME, kcu.CONSTRAINT_NAME, kcu.ORDINAL_POSITION`
+	keyRows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer keyRows.Close()
+
+	for keyRows.Next() {
+		var dbName, tableName, columnName, referencedDBName, referencedTableName, referencedColumnName string
+		var constraint DBConstraint
+		if err := keyRows.Scan(
+			&dbName,
+			&tableName,
+			&constraint.Name,
+			&constraint.Type,
+			&columnName,
+			&referencedDBName,
+			&referencedTableName,
+			&referencedColumnName,
+			&constraint.UpdateRule,
+			&constraint.DeleteRule,
+		); err != nil {
+			return nil, err
+		}
+
+		key := fmt.Sprintf("%s/%s", dbName, tableName)
+		constraintList := constraintMap[key]
+		// Rows of the same constraint are adjacent, merge the columns into the last one.
+		if n := len(constraintList); n > 0 && constraintList[n-1].Name == constraint.Name && constraintList[n-1].Type == constraint.Type {
+			last := &constraintList[n-1]
+			last.ColumnList = append(last.ColumnList, columnName)
+			if referencedColumnName != "" {
+				last.ReferencedColumnList = append(last.ReferencedColumnList, referencedColumnName)
+			}
+			continue
+		}
+
+		constraint.ColumnList = []string{columnName}
+		if referencedTableName != "" {
+			constraint.ReferencedTable = referencedTableName
+			if referencedDBName != dbName {
+				constraint.ReferencedTable = fmt.Sprintf("%s.%s", referencedDBName, referencedTableName)
+			}
+			constraint.ReferencedColumnList = []string{referencedColumnName}
+		}
+		constraintMap[key] = append(constraintList, constraint)
+	}
+	if err := keyRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// CHECK_CONSTRAINTS is only available since MySQL 8.0.16 and MariaDB 10.2, and it's missing in TiDB.
+	query = `
+			SELECT
+				COUNT(*)
+			FROM information_schema.TABLES
+			WHERE TABLE_SCHEMA = 'information_schema' AND TABLE_NAME = 'CHECK_CONSTRAINTS'`
+	var count int
+	if err := driver.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	if count == 0 {
+		return constraintMap, nil
+	}
+
+	// Query check constraint info
+	query = `
+			SELECT
+				tc.TABLE_SCHEMA,
+				tc.TABLE_NAME,
+				tc.CONSTRAINT_NAME,
+				cc.CHECK_CLAUSE
+			FROM information_schema.TABLE_CONSTRAINTS tc
+			JOIN information_schema.CHECK_CONSTRAINTS cc
+				ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+			WHERE tc.CONSTRAINT_TYPE = 'CHECK' AND ` + fmt.Sprintf("tc.TABLE_SCHEMA NOT IN (%s)", strings.Join(excludedDatabaseList, ", ")) + `
+			ORDER BY tc.TABLE_SCHEMA, tc.TABLE_NAME, tc.CONSTRAINT_NAME`
+	checkRows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer checkRows.Close()
+
+	for checkRows.Next() {
+		var dbName, tableName string
+		constraint := DBConstraint{
+			Type: "CHECK",
+		}
+		if err := checkRows.Scan(
+			&dbName,
+			&tableName,
+			&constraint.Name,
+			&constraint.Expression,
+		); err != nil {
+			return nil, err
+		}
+
+		key := fmt.Sprintf("%s/%s", dbName, tableName)
+		constraintMap[key] = append(constraintMap[key], constraint)
+	}
+	if err := checkRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return constraintMap, nil
+}
+
+// supportsStoredProgram returns whether the server supports stored routines and triggers, TiDB doesn't support either.
+func (driver *MySQLDriver) supportsStoredProgram() bool {
+	return driver.serverInfo.Flavor != FlavorTiDB
+}
+
+// supportsEvent returns whether the server supports the event scheduler, neither TiDB nor OceanBase does.
+func (driver *MySQLDriver) supportsEvent() bool {
+	return driver.serverInfo.Flavor != FlavorTiDB && driver.serverInfo.Flavor != FlavorOceanBase
+}
+
+// getViewMap returns the dbName -> viewList map.
+func (driver *MySQLDriver) getViewMap(ctx context.Context, viewWhere string) (map[string][]DBView, error) {
+	query := `
+			SELECT
+				TABLE_SCHEMA,
+				TABLE_NAME,
+				IFNULL(VIEW_DEFINITION, '')
+			FROM information_schema.VIEWS
+			WHERE ` + viewWhere + `
+			ORDER BY TABLE_SCHEMA, TABLE_NAME`
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	viewMap := make(map[string][]DBView)
+	for rows.Next() {
+		var dbName string
+		var view DBView
+		if err := rows.Scan(
+			&dbName,
+			&view.Name,
+			&view.Definition,
+		); err != nil {
+			return nil, err
+		}
+		viewMap[dbName] = append(viewMap[dbName], view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return viewMap, nil
+}
+
+// getRoutineMap returns the dbName -> routineList map.
+func (driver *MySQLDriver) getRoutineMap(ctx context.Context, routineWhere string) (map[string][]DBRoutine, error) {
+	query := `
+			SELECT
+				ROUTINE_SCHEMA,
+				ROUTINE_NAME,
+				ROUTINE_TYPE,
+				IFNULL(ROUTINE_DEFINITION, ''),
+				ROUTINE_COMMENT
+			FROM information_schema.ROUTINES
+			WHERE ` + routineWhere + `
+			ORDER BY ROUTINE_SCHEMA, ROUTINE_TYPE, ROUTINE_NAME`
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	routineMap := make(map[string][]DBRoutine)
+	for rows.Next() {
+		var dbName string
+		var routine DBRoutine
+		if err := rows.Scan(
+			&dbName,
+			&routine.Name,
+			&routine.Type,
+			&routine.Definition,
+			&routine.Comment,
+		); err != nil {
+			return nil, err
+		}
+		routineMap[dbName] = append(routineMap[dbName], routine)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return routineMap, nil
+}
+
+// getTriggerMap returns the dbName -> triggerList map.
+func (driver *MySQLDriver) getTriggerMap(ctx context.Context, triggerWhere string) (map[string][]DBTrigger, error) {
+	query := `
+			SELECT
+				TRIGGER_SCHEMA,
+				TRIGGER_NAME,
+				EVENT_OBJECT_TABLE,
+				ACTION_TIMING,
+				EVENT_MANIPULATION,
+				ACTION_STATEMENT
+			FROM information_schema.TRIGGERS
+			WHERE ` + triggerWhere + `
+			ORDER BY TRIGGER_SCHEMA, EVENT_OBJECT_TABLE, TRIGGER_NAME`
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	triggerMap := make(map[string][]DBTrigger)
+	for rows.Next() {
+		var dbName string
+		var trigger DBTrigger
+		if err := rows.Scan(
+			&dbName,
+			&trigger.Name,
+			&trigger.TableName,
+			&trigger.Timing,
+			&trigger.Event,
+			&trigger.Definition,
+		); err != nil {
+			return nil, err
+		}
+		triggerMap[dbName] = append(triggerMap[dbName], trigger)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return triggerMap, nil
+}
+
+// getEventMap returns the dbName -> eventList map.
+func (driver *MySQLDriver) getEventMap(ctx context.Context, eventWhere string) (map[string][]DBEvent, error) {
+	query := `
+			SELECT
+				EVENT_SCHEMA,
+				EVENT_NAME,
+				EVENT_DEFINITION,
+				CASE EVENT_TYPE
+					WHEN 'RECURRING' THEN CONCAT('EVERY ', INTERVAL_VALUE, ' ', INTERVAL_FIELD)
+					ELSE CONCAT('AT ', EXECUTE_AT)
+				END,
+				STATUS,
+				EVENT_COMMENT
+			FROM information_schema.EVENTS
+			WHERE ` + eventWhere + `
+			ORDER BY EVENT_SCHEMA, EVENT_NAME`
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	eventMap := make(map[string][]DBEvent)
+	for rows.Next() {
+		var dbName string
+		var event DBEvent
+		if err := rows.Scan(
+			&dbName,
+			&event.Name,
+			&event.Definition,
+			&event.Schedule,
+			&event.Status,
+			&event.Comment,
+		); err != nil {
+			return nil, err
+		}
+		eventMap[dbName] = append(eventMap[dbName], event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return eventMap, nil
+}
+
+// applyTiDBShardingInfo records the TiDB row id sharding info, and marks the AUTO_RANDOM primary key column.
+// The sharding info is like "PK_AUTO_RANDOM_BITS=5", "SHARD_BITS=4", "NOT_SHARDED" or "NOT_SHARDED(PK_IS_HANDLE)".
+func applyTiDBShardingInfo(table *DBTable, shardingInfo string) {
+	if shardingInfo == "" || strings.HasPrefix(shardingInfo, "NOT_SHARDED") {
+		return
+	}
+
+	if table.CreateOptions == "" {
+		table.CreateOptions = shardingInfo
+	} else {
+		table.CreateOptions = table.CreateOptions + " " + shardingInfo
+	}
+
+	const autoRandomPrefix = "PK_AUTO_RANDOM_BITS="
+	if !strings.HasPrefix(shardingInfo, autoRandomPrefix) {
+		return
+	}
+	for _, index := range table.IndexList {
+		if index.Name == "PRIMARY" && index.Position == 1 {
+			for i := range table.ColumnList {
+				if table.ColumnList[i].Name == index.Expression {
+					table.ColumnList[i].Type = fmt.Sprintf("%s AUTO_RANDOM(%s)", table.ColumnList[i].Type, strings.TrimPrefix(shardingInfo, autoRandomPrefix))
+				}
+			}
+			break
+		}
+	}
+}
+
+func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSchema, error) {
+	excludedDatabaseList := driver.excludedDatabaseList()
+
+	// Query user info
+	query := driver.userListQuery()
 	userList := make([]*DBUser, 0)
 	userRows, err := driver.db.QueryContext(ctx, query)
 
@@ -128,62 +704,16 @@ func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSche
 		// instead of table (which should use backtick instead). MySQL actually works
 		// in both ways. On the other hand, some other MySQL compatible engines might not (OceanBase in this case).
 		name := fmt.Sprintf("'%s'@'%s'", user, host)
-		query = fmt.Sprintf("SHOW GRANTS FOR %s", name)
-		grantRows, err := driver.db.QueryContext(ctx,
-			query,
-		)
+		dbUser, err := driver.getUser(ctx, name)
 		if err != nil {
-			return nil, nil, formatErrorWithQuery(err, query)
-		}
-		defer grantRows.Close()
-
-		grantList := []string{}
-		for grantRows.Next() {
-			var grant string
-			if err := grantRows.Scan(&grant); err != nil {
-				return nil, nil, err
-			}
-			grantList = append(grantList, grant)
+			return nil, nil, err
 		}
-
-		userList = append(userList, &DBUser{
-			Name:  name,
-			Grant: strings.Join(grantList, "\n"),
-		})
+		userList = append(userList, dbUser)
 	}
 
 	// Query index info
 	indexWhere := fmt.Sprintf("TABLE_SCHEMA NOT IN (%s)", strings.Join(excludedDatabaseList, ", "))
-	query = `
-			SELECT
-				TABLE_SCHEMA,
-				TABLE_NAME,
-				INDEX_NAME,
-				COLUMN_NAME,
-				'',
-				SEQ_IN_INDEX,
-				INDEX_TYPE,
-				CASE NON_UNIQUE WHEN 0 THEN 1 ELSE 0 END AS IS_UNIQUE,
-				'YES',
-				INDEX_COMMENT
-			FROM information_schema.STATISTICS
-			WHERE ` + indexWhere
-	if isMySQL8 {
-		query = `
-			SELECT
-				TABLE_SCHEMA,
-				TABLE_NAME,
-				INDEX_NAME,
-				COLUMN_NAME,
-				EXPRESSION,
-				SEQ_IN_INDEX,
-				INDEX_TYPE,
-				CASE NON_UNIQUE WHEN 0 THEN 1 ELSE 0 END AS IS_UNIQUE,
-				CASE IS_VISIBLE WHEN 'YES' THEN 1 ELSE 0 END,
-				INDEX_COMMENT
-			FROM information_schema.STATISTICS
-			WHERE ` + indexWhere
-	}
+	query = driver.indexListQuery(indexWhere)
 	indexRows, err := driver.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, nil, formatErrorWithQuery(err, query)
@@ -288,8 +818,25 @@ func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSche
 		}
 	}
 
+	// Query constraint info
+	constraintMap, err := driver.getConstraintMap(ctx, excludedDatabaseList)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Query table info
 	tableWhere := fmt.Sprintf("TABLE_SCHEMA NOT IN (%s)", strings.Join(excludedDatabaseList, ", "))
+	// TiDB records the AUTO_RANDOM and SHARD_ROW_ID_BITS info here.
+	shardingInfoColumn := "''"
+	sequenceOptionMap := make(map[string]string)
+	if driver.serverInfo.Flavor == FlavorTiDB {
+		shardingInfoColumn = "IFNULL(TIDB_ROW_ID_SHARDING_INFO, '')"
+		sequenceWhere := fmt.Sprintf("SEQUENCE_SCHEMA NOT IN (%s)", strings.Join(excludedDatabaseList, ", "))
+		sequenceOptionMap, err = driver.getTiDBSequenceOptionMap(ctx, sequenceWhere)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 	query = `
 			SELECT
 				TABLE_SCHEMA, 
@@ -304,7 +851,8 @@ func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSche
 				IFNULL(INDEX_LENGTH, 0),
 				IFNULL(DATA_FREE, 0),
 				IFNULL(CREATE_OPTIONS, ''),
-				IFNULL(TABLE_COMMENT, '')
+				IFNULL(TABLE_COMMENT, ''),
+				` + shardingInfoColumn + `
 			FROM information_schema.TABLES
 			WHERE ` + tableWhere
 	tableRows, err := driver.db.QueryContext(ctx, query)
@@ -317,6 +865,7 @@ func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSche
 	tableMap := make(map[string][]DBTable)
 	for tableRows.Next() {
 		var dbName string
+		var shardingInfo string
 		var table DBTable
 		if err := tableRows.Scan(
 			&dbName,
@@ -332,13 +881,22 @@ func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSche
 			&table.DataFree,
 			&table.CreateOptions,
 			&table.Comment,
+			&shardingInfo,
 		); err != nil {
 			return nil, nil, err
 		}
 
 		key := fmt.Sprintf("%s/%s", dbName, table.Name)
-		table.ColumnList = columnMap[key]
-		table.IndexList = indexMap[key]
+		if table.Type == "SEQUENCE" {
+			// MariaDB and TiDB list sequences as tables, MariaDB even exposes its internal columns,
+			// we only keep the sequence options instead.
+			table.CreateOptions = sequenceOptionMap[key]
+		} else {
+			table.ColumnList = columnMap[key]
+			table.IndexList = indexMap[key]
+			table.ConstraintList = constraintMap[key]
+			applyTiDBShardingInfo(&table, shardingInfo)
+		}
 
 		tableList, ok := tableMap[dbName]
 		if ok {
@@ -349,6 +907,31 @@ func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSche
 		}
 	}
 
+	// Query view, routine, trigger and event info
+	viewMap, err := driver.getViewMap(ctx, fmt.Sprintf("TABLE_SCHEMA NOT IN (%s)", strings.Join(excludedDatabaseList, ", ")))
+	if err != nil {
+		return nil, nil, err
+	}
+	routineMap := make(map[string][]DBRoutine)
+	triggerMap := make(map[string][]DBTrigger)
+	eventMap := make(map[string][]DBEvent)
+	if driver.supportsStoredProgram() {
+		routineMap, err = driver.getRoutineMap(ctx, fmt.Sprintf("ROUTINE_SCHEMA NOT IN (%s)", strings.Join(excludedDatabaseList, ", ")))
+		if err != nil {
+			return nil, nil, err
+		}
+		triggerMap, err = driver.getTriggerMap(ctx, fmt.Sprintf("TRIGGER_SCHEMA NOT IN (%s)", strings.Join(excludedDatabaseList, ", ")))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if driver.supportsEvent() {
+		eventMap, err = driver.getEventMap(ctx, fmt.Sprintf("EVENT_SCHEMA NOT IN (%s)", strings.Join(excludedDatabaseList, ", ")))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Query db info
 	where := fmt.Sprintf("SCHEMA_NAME NOT IN (%s)", strings.Join(excludedDatabaseList, ", "))
 	query = `
@@ -376,6 +959,10 @@ func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSche
 		}
 
 		schema.TableList = tableMap[schema.Name]
+		schema.ViewList = viewMap[schema.Name]
+		schema.RoutineList = routineMap[schema.Name]
+		schema.TriggerList = triggerMap[schema.Name]
+		schema.EventList = eventMap[schema.Name]
 
 		schemaList = append(schemaList, &schema)
 	}
@@ -387,17 +974,93 @@ func (driver *MySQLDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSche
 }
 
 func (driver *MySQLDriver) Execute(ctx context.Context, statement string) error {
-	tx, err := driver.db.BeginTx(ctx, nil)
+	tx, cancel, release, err := driver.beginStatementTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer release()
 	defer tx.Rollback()
 
-	_, err = tx.ExecContext(ctx, statement)
-	return err
+	if err := execStatement(ctx, tx, statement, cancel); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (driver *MySQLDriver) NeedsSetupMigration(ctx context.Context) (bool, error) {
+func (driver *MySQLDriver) Query(ctx context.Context, statement string, limit int) (*QueryResult, error) {
+	tx, cancel, release, err := driver.beginStatementTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer tx.Rollback()
+
+	return queryRows(ctx, Mysql, tx, statement, limit, cancel)
+}
+
+// beginStatementTx begins the transaction on a dedicated connection applying the statement timeout of ctx as the
+// session timeout, and returns the function killing the statement running on the connection. The release must be
+// called after the transaction ends to reset the session timeout and return the connection.
+func (driver *MySQLDriver) beginStatementTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, cancelStatementFunc, func(), error) {
+	conn, err := driver.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var connectionID int64
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&connectionID); err != nil {
+		conn.Close()
+		return nil, nil, nil, err
+	}
+	set, reset := mysqlSessionTimeoutStatement(driver.serverInfo.Flavor, statementTimeout(ctx))
+	if set != "" {
+		if _, err := conn.ExecContext(ctx, set); err != nil {
+			conn.Close()
+			return nil, nil, nil, formatErrorWithQuery(err, set)
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		conn.Close()
+		return nil, nil, nil, err
+	}
+	cancel := func(ctx context.Context) error {
+		// The client closes the connection when the context is done, but the server keeps running the statement.
+		_, err := driver.db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", connectionID))
+		return err
+	}
+	release := func() {
+		if reset != "" {
+			// The connection is discarded anyway if it's broken by the cancellation.
+			conn.ExecContext(context.Background(), reset)
+		}
+		conn.Close()
+	}
+	return tx, cancel, release, nil
+}
+
+// mysqlSessionTimeoutStatement returns the statements setting and resetting the session timeout of the statements,
+// or empty if the timeout is not limited or the flavor has no such setting. MySQL and TiDB only limit the SELECT
+// statements by max_execution_time, the other statements are limited by the context and killed on the timeout.
+func mysqlSessionTimeoutStatement(flavor Flavor, timeout time.Duration) (string, string) {
+	if timeout <= 0 {
+		return "", ""
+	}
+	switch flavor {
+	case FlavorMySQL, FlavorTiDB:
+		return fmt.Sprintf("SET SESSION max_execution_time = %d", timeout.Milliseconds()), "SET SESSION max_execution_time = DEFAULT"
+	case FlavorMariaDB:
+		return fmt.Sprintf("SET SESSION max_statement_time = %g", timeout.Seconds()), "SET SESSION max_statement_time = DEFAULT"
+	case FlavorOceanBase:
+		return fmt.Sprintf("SET SESSION ob_query_timeout = %d", timeout.Microseconds()), "SET SESSION ob_query_timeout = DEFAULT"
+	}
+	return "", ""
+}
+
+// getMigrationSchemaVersion returns the version of the bytebase migration schema, 0 if the schema doesn't exist.
+func (driver *MySQLDriver) getMigrationSchemaVersion(ctx context.Context) (int, error) {
 	const query = `
 		SELECT 
 		    1
@@ -406,104 +1069,104 @@ func (driver *MySQLDriver) NeedsSetupMigration(ctx context.Context) (bool, error
 		`
 	rows, err := driver.db.QueryContext(ctx, query)
 	if err != nil {
-		return false, formatErrorWithQuery(err, query)
+		return 0, formatErrorWithQuery(err, query)
 	}
 	defer rows.Close()
 
-	if rows.Next() {
-		return false, nil
+	if !rows.Next() {
+		return 0, nil
 	}
 
-	return true, nil
+	return findMigrationSchemaVersion(ctx, driver.db)
+}
+
+func (driver *MySQLDriver) NeedsSetupMigration(ctx context.Context) (bool, error) {
+	version, err := driver.getMigrationSchemaVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return version < latestMigrationSchemaVersion, nil
 }
 
 func (driver *MySQLDriver) SetupMigrationIfNeeded(ctx context.Context) error {
-	setup, err := driver.NeedsSetupMigration(ctx)
+	version, err := driver.getMigrationSchemaVersion(ctx)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	if setup {
+	if version == 0 {
 		driver.l.Info("Bytebase migration schema not found, creating schema...",
 			zap.String("environment", driver.connectionCtx.EnvironmentName),
 			zap.String("database", driver.connectionCtx.InstanceName),
 		)
-		if err := driver.Execute(ctx, migrationSchema); err != nil {
+		schema := migrationSchema
+		if driver.serverInfo.Flavor == FlavorOceanBase {
+			schema = oceanBaseMigrationSchema
+		}
+		if err := driver.Execute(ctx, schema); err != nil {
 			driver.l.Error("Failed to initialize migration schema.",
 				zap.Error(err),
 				zap.String("environment", driver.connectionCtx.EnvironmentName),
 				zap.String("database", driver.connectionCtx.InstanceName),
 			)
-			return formatErrorWithQuery(err, migrationSchema)
+			return formatErrorWithQuery(err, schema)
 		}
 		driver.l.Info("Successfully created migration schema.",
 			zap.String("environment", driver.connectionCtx.EnvironmentName),
 			zap.String("database", driver.connectionCtx.InstanceName),
 		)
+	} else if version < latestMigrationSchemaVersion {
+		// OceanBase shares the MySQL upgrade since none of the upgrades index a TEXT column.
+		if err := upgradeMigrationSchema(ctx, driver.l, driver.connectionCtx, "mysql", version, driver.Execute); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (driver *MySQLDriver) ExecuteMigration(ctx context.Context, m *MigrationInfo, statement string, execution *MigrationExecution) error {
+	// The transaction doesn't serialize the DDL, the lock prevents the concurrent migrations of the same namespace.
+	release, err := driver.acquireMigrationLock(ctx, m)
+	if err != nil {
+		return err
 	}
+	defer release()
 
-	return nil
-}
+	if execution != nil && execution.OnlineDDL != nil {
+		return driver.executeOnlineMigration(ctx, m, statement, execution)
+	}
 
-func (driver *MySQLDriver) ExecuteMigration(ctx context.Context, m *MigrationInfo, statement string) error {
-	tx, err := driver.db.BeginTx(ctx, nil)
+	tx, cancel, releaseTx, err := driver.beginStatementTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer releaseTx()
 	defer tx.Rollback()
 
 	startedTs := time.Now().Unix()
 
 	// Phase 1 - Precheck before executing migration
-	// Check if the same migration version has alraedy been applied
-	duplicate, err := checkDuplicateVersion(ctx, tx, m.Namespace, m.Engine, m.Version)
+	sequence, err := checkMigrationPrecondition(ctx, tx, m, mysqlMigrationHistoryQueries)
 	if err != nil {
 		return err
 	}
-	if duplicate {
-		return fmt.Errorf("database %q has already applied version %s", m.Database, m.Version)
-	}
 
-	// Check if there is any higher version already been applied
-	version, err := checkOutofOrderVersion(ctx, tx, m.Namespace, m.Engine, m.Version)
-	if err != nil {
+	// Phase 2 - Executing migration
+	if err := executeMigrationStatement(ctx, tx, statement, execution, cancel); err != nil {
 		return err
 	}
-	if version != nil {
-		return fmt.Errorf("database %q has already applied version %s which is higher than %s", m.Database, *version, m.Version)
-	}
-
-	// If the migration engine is VCS and type is not baseline and is not branch, then we can only proceed if there is existing baseline
-	// This check is also wrapped in transaction to avoid edge case where two baselinings are running concurrently.
-	if m.Engine == VCS && m.Type != Baseline && m.Type != Branch {
-		hasBaseline, err := findBaseline(ctx, tx, m.Namespace)
-		if err != nil {
-			return err
-		}
-
-		if !hasBaseline {
-			return fmt.Errorf("%s has not created migration baseline yet", m.Database)
-		}
-	}
 
-	// VCS based SQL migration requires existing baselining
-	requireBaseline := m.Engine == VCS && m.Type == Sql
-	sequence, err := findNextSequence(ctx, tx, m.Namespace, requireBaseline)
-	if err != nil {
+	// Phase 3 - Record migration
+	if err := insertMySQLMigrationHistory(ctx, tx, m, sequence, statement, time.Now().Unix()-startedTs); err != nil {
 		return err
 	}
 
-	// Phase 2 - Executing migration
-	// Branch migration type always has empty sql.
-	// Baseline migration type could also has empty sql when the database is newly created.
-	if statement != "" {
-		_, err = tx.ExecContext(ctx, statement)
-		if err != nil {
-			return formatError(err)
-		}
-	}
+	return tx.Commit()
+}
 
-	// Phase 3 - Record migration
+func insertMySQLMigrationHistory(ctx context.Context, tx *sql.Tx, m *MigrationInfo, sequence int, statement string, executionDuration int64) error {
 	const query = `
 		INSERT INTO bytebase.migration_history (
 			created_by,
@@ -517,13 +1180,14 @@ func (driver *MySQLDriver) ExecuteMigration(ctx context.Context, m *MigrationInf
 			version,
 			description,
 			statement,
+			rollback_statement,
 			execution_duration,
 			issue_id,
 			payload
 		)
-		VALUES (?, unix_timestamp(), ?, unix_timestamp(), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, unix_timestamp(), ?, unix_timestamp(), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err = tx.ExecContext(ctx, query,
+	if _, err := tx.ExecContext(ctx, query,
 		m.Creator,
 		m.Creator,
 		m.Namespace,
@@ -533,31 +1197,107 @@ func (driver *MySQLDriver) ExecuteMigration(ctx context.Context, m *MigrationInf
 		m.Version,
 		m.Description,
 		statement,
-		time.Now().Unix()-startedTs,
+		m.RollbackStatement,
+		executionDuration,
 		m.IssueId,
 		m.Payload,
-	)
+	); err != nil {
+		return formatErrorWithQuery(err, query)
+	}
+	return nil
+}
 
+// acquireMigrationLock takes the GET_LOCK named lock of the migration namespace, which is held by the session until
+// the returned release is called.
+func (driver *MySQLDriver) acquireMigrationLock(ctx context.Context, m *MigrationInfo) (func(), error) {
+	conn, err := driver.db.Conn(ctx)
 	if err != nil {
-		return formatErrorWithQuery(err, query)
+		return nil, err
+	}
+
+	name := migrationLockName(m.Namespace)
+	timeout := migrationLockTimeout(m)
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, int(math.Ceil(timeout.Seconds()))).Scan(&acquired); err != nil {
+		conn.Close()
+		// Older TiDB and OceanBase don't support the named lock, we shouldn't block the migrations there.
+		if driver.serverInfo.Flavor != FlavorMySQL && driver.serverInfo.Flavor != FlavorMariaDB {
+			driver.l.Warn("Failed to acquire migration lock, running migration without the lock",
+				zap.String("namespace", m.Namespace),
+				zap.String("flavor", driver.serverInfo.Flavor.String()),
+				zap.Error(err),
+			)
+			return func() {}, nil
+		}
+		return nil, fmt.Errorf("failed to acquire migration lock %q: %w", name, formatError(err))
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		holder := driver.findMigrationLockHolder(ctx, conn, name)
+		conn.Close()
+		return nil, &MigrationLockError{Namespace: m.Namespace, Timeout: timeout, Holder: holder}
 	}
 
-	tx.Commit()
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name); err != nil {
+			driver.l.Warn("Failed to release migration lock", zap.String("namespace", m.Namespace), zap.Error(err))
+		}
+		// The lock is released together with the session anyway.
+		conn.Close()
+	}, nil
+}
 
-	return nil
+// findMigrationLockHolder describes the session holding the named lock, or returns empty if unknown.
+func (driver *MySQLDriver) findMigrationLockHolder(ctx context.Context, conn *sql.Conn, name string) string {
+	var connectionId sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?)", name).Scan(&connectionId); err != nil || !connectionId.Valid {
+		return ""
+	}
+	var user, host string
+	query := "SELECT USER, HOST FROM information_schema.PROCESSLIST WHERE ID = ?"
+	if err := conn.QueryRowContext(ctx, query, connectionId.Int64).Scan(&user, &host); err != nil {
+		return fmt.Sprintf("connection %d", connectionId.Int64)
+	}
+	return fmt.Sprintf("connection %d (%s@%s)", connectionId.Int64, user, host)
+}
+
+func (driver *MySQLDriver) DryRunMigration(ctx context.Context, m *MigrationInfo, statement string, schema *DBSchema) ([]*DryRunError, error) {
+	var errorList []*DryRunError
+	preconditionError, err := dryRunPrecondition(ctx, driver.db, m, mysqlMigrationHistoryQueries)
+	if err != nil {
+		return nil, err
+	}
+	if preconditionError != nil {
+		errorList = append(errorList, preconditionError)
+	}
+
+	explain := func(ctx context.Context, statement string) error {
+		return explainQuery(ctx, driver.db, "EXPLAIN "+statement)
+	}
+	return append(errorList, dryRunStatement(ctx, statement, schema, []string{m.Database}, explain)...), nil
 }
 
 func (driver *MySQLDriver) FindMigrationHistoryList(ctx context.Context, find *MigrationHistoryFind) ([]*MigrationHistory, error) {
-	tx, err := driver.db.BeginTx(ctx, nil)
+	return findMigrationHistoryList(ctx, driver.db, find)
+}
+
+// findMigrationHistoryList is shared by the engines accepting "?" placeholders and backtick quoting.
+func findMigrationHistoryList(ctx context.Context, db *sql.DB, find *MigrationHistoryFind) ([]*MigrationHistory, error) {
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
 	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := find.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
 	if v := find.Database; v != nil {
 		where, args = append(where, "namespace = ?"), append(args, *v)
 	}
+	if v := find.Version; v != nil {
+		where, args = append(where, "version = ?"), append(args, *v)
+	}
 
 	var query = `
 			SELECT 
@@ -573,6 +1313,7 @@ func (driver *MySQLDriver) FindMigrationHistoryList(ctx context.Context, find *M
 			version,
 			description,
 		    statement,
+		    rollback_statement,
 		    execution_duration,
 			issue_id,
 			payload
@@ -606,6 +1347,7 @@ func (driver *MySQLDriver) FindMigrationHistoryList(ctx context.Context, find *M
 			&history.Version,
 			&history.Description,
 			&history.Statement,
+			&history.RollbackStatement,
 			&history.ExecutionDuration,
 			&history.IssueId,
 			&history.Payload,
@@ -622,9 +1364,350 @@ func (driver *MySQLDriver) FindMigrationHistoryList(ctx context.Context, find *M
 	return list, nil
 }
 
+// findMigrationSchemaVersion returns the "bb.schema.version" setting of an existing bytebase migration schema.
+func findMigrationSchemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	const query = `SELECT value FROM bytebase.setting WHERE name = 'bb.schema.version'`
+	var value string
+	if err := db.QueryRowContext(ctx, query).Scan(&value); err != nil {
+		return 0, formatErrorWithQuery(err, query)
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid migration schema version %q: %w", value, err)
+	}
+	return version, nil
+}
+
+// upgradeMigrationSchema applies the engine's upgrades from version up to latestMigrationSchemaVersion.
+// Each upgrade bumps "bb.schema.version" itself, so a failed upgrade resumes from where it stopped.
+func upgradeMigrationSchema(ctx context.Context, l *zap.Logger, connectionCtx ConnectionContext, engine string, version int, execute func(ctx context.Context, statement string) error) error {
+	upgradeList, err := getMigrationSchemaUpgradeList(engine, version)
+	if err != nil {
+		return err
+	}
+
+	l.Info("Bytebase migration schema outdated, upgrading schema...",
+		zap.Int("version", version),
+		zap.String("environment", connectionCtx.EnvironmentName),
+		zap.String("database", connectionCtx.InstanceName),
+	)
+	for i, statement := range upgradeList {
+		if err := execute(ctx, statement); err != nil {
+			l.Error("Failed to upgrade migration schema.",
+				zap.Error(err),
+				zap.Int("version", version+i+1),
+				zap.String("environment", connectionCtx.EnvironmentName),
+				zap.String("database", connectionCtx.InstanceName),
+			)
+			return formatErrorWithQuery(err, statement)
+		}
+	}
+	l.Info("Successfully upgraded migration schema.",
+		zap.Int("version", latestMigrationSchemaVersion),
+		zap.String("environment", connectionCtx.EnvironmentName),
+		zap.String("database", connectionCtx.InstanceName),
+	)
+
+	return nil
+}
+
+func (driver *MySQLDriver) ImportMigrationHistory(ctx context.Context, imp *MigrationHistoryImport) (int, error) {
+	historyList, err := readImportedMigrationHistory(ctx, driver.db, imp, "CAST(UNIX_TIMESTAMP(%s) AS SIGNED)")
+	if err != nil {
+		return 0, err
+	}
+
+	release, err := driver.acquireMigrationLock(ctx, imp.migrationLockInfo())
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	count, err := importMigrationHistory(ctx, tx, historyList, mysqlMigrationHistoryQueries, importedMigrationHistoryInsert)
+	if err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+func (driver *MySQLDriver) GrantDatabase(ctx context.Context, grant *DatabaseGrant) (*DBUser, error) {
+	if err := ValidateDatabaseGrant(Mysql, grant); err != nil {
+		return nil, err
+	}
+	if err := execGrantStatementList(ctx, driver.db, mysqlGrantStatementList(grant), grant.Password); err != nil {
+		return nil, err
+	}
+	return driver.getUser(ctx, mysqlUserName(grant))
+}
+
+func (driver *MySQLDriver) RevokeDatabase(ctx context.Context, grant *DatabaseGrant) (*DBUser, error) {
+	if err := ValidateDatabaseGrant(Mysql, grant); err != nil {
+		return nil, err
+	}
+	if grant.DropUser {
+		if err := driver.killUserSessions(ctx, grant); err != nil {
+			return nil, err
+		}
+	}
+	if err := execGrantStatementList(ctx, driver.db, mysqlRevokeStatementList(grant), ""); err != nil {
+		return nil, err
+	}
+	if grant.DropUser {
+		return &DBUser{Name: mysqlUserName(grant)}, nil
+	}
+	return driver.getUser(ctx, mysqlUserName(grant))
+}
+
+func (driver *MySQLDriver) FindIndexUsage(ctx context.Context, database string) (*IndexUsageStats, error) {
+	// The usage would be all zero with the performance_schema disabled.
+	var enabled bool
+	query := "SELECT @@performance_schema"
+	if err := driver.db.QueryRowContext(ctx, query).Scan(&enabled); err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	if !enabled {
+		return nil, fmt.Errorf("performance_schema is disabled")
+	}
+
+	// The usage is counted since the server started, unless it's truncated by hand.
+	stats := &IndexUsageStats{}
+	query = "SELECT CAST(UNIX_TIMESTAMP() - VARIABLE_VALUE AS SIGNED) FROM performance_schema.global_status WHERE VARIABLE_NAME = 'Uptime'"
+	if err := driver.db.QueryRowContext(ctx, query).Scan(&stats.SinceTs); err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+
+	query = `
+		SELECT
+			OBJECT_NAME,
+			INDEX_NAME,
+			COUNT_READ
+		FROM performance_schema.table_io_waits_summary_by_index_usage
+		WHERE OBJECT_SCHEMA = ? AND INDEX_NAME IS NOT NULL`
+	rows, err := driver.db.QueryContext(ctx, query, database)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var usage IndexUsage
+		if err := rows.Scan(
+			&usage.TableName,
+			&usage.IndexName,
+			&usage.ReadCount,
+		); err != nil {
+			return nil, err
+		}
+		stats.UsageList = append(stats.UsageList, &usage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// mysqlUserName returns the user name in the 'user'@'host' form same as SyncSchema.
+func mysqlUserName(grant *DatabaseGrant) string {
+	host := grant.Host
+	if host == "" {
+		host = "%"
+	}
+	return fmt.Sprintf("%s@%s", quoteStringLiteral(grant.Username), quoteStringLiteral(host))
+}
+
+func mysqlGrantStatementList(grant *DatabaseGrant) []string {
+	user := mysqlUserName(grant)
+	var stmtList []string
+	if grant.Password != "" {
+		password := quoteStringLiteral(grant.Password)
+		stmtList = append(stmtList,
+			fmt.Sprintf("CREATE USER IF NOT EXISTS %s IDENTIFIED BY %s", user, password),
+			// Changes the password if the user exists already.
+			fmt.Sprintf("ALTER USER %s IDENTIFIED BY %s", user, password),
+		)
+	}
+	return append(stmtList, fmt.Sprintf("GRANT %s ON %s.* TO %s", strings.Join(grant.PrivilegeList, ", "), quoteMySQLIdentifier(grant.Database), user))
+}
+
+func mysqlRevokeStatementList(grant *DatabaseGrant) []string {
+	user := mysqlUserName(grant)
+	if grant.DropUser {
+		// Dropping the user revokes all the privileges.
+		return []string{fmt.Sprintf("DROP USER IF EXISTS %s", user)}
+	}
+	return []string{fmt.Sprintf("REVOKE %s ON %s.* FROM %s", strings.Join(grant.PrivilegeList, ", "), quoteMySQLIdentifier(grant.Database), user)}
+}
+
+// getUser returns the user with the grants by SHOW GRANTS, the name is in the 'user'@'host' form.
+// killUserSessions kills the sessions of the user, which survive dropping the user.
+func (driver *MySQLDriver) killUserSessions(ctx context.Context, grant *DatabaseGrant) error {
+	query := "SELECT ID FROM information_schema.PROCESSLIST WHERE USER = ? AND ID <> CONNECTION_ID()"
+	args := []interface{}{grant.Username}
+	if grant.Host != "" && grant.Host != "%" {
+		// The HOST column contains the client port as well.
+		query += " AND SUBSTRING_INDEX(HOST, ':', 1) = ?"
+		args = append(args, grant.Host)
+	}
+	rows, err := driver.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var idList []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		idList = append(idList, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, id := range idList {
+		stmt := fmt.Sprintf("KILL %d", id)
+		if _, err := driver.db.ExecContext(ctx, stmt); err != nil {
+			// ER_NO_SUCH_THREAD if the session has ended in the meantime.
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1094 {
+				continue
+			}
+			return formatErrorWithQuery(err, stmt)
+		}
+	}
+	return nil
+}
+
+func (driver *MySQLDriver) getUser(ctx context.Context, name string) (*DBUser, error) {
+	query := fmt.Sprintf("SHOW GRANTS FOR %s", name)
+	grantRows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer grantRows.Close()
+
+	grantList := []string{}
+	for grantRows.Next() {
+		var grant string
+		if err := grantRows.Scan(&grant); err != nil {
+			return nil, err
+		}
+		grantList = append(grantList, grant)
+	}
+	if err := grantRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &DBUser{
+		Name:  name,
+		Grant: strings.Join(grantList, "\n"),
+	}, nil
+}
+
+// migrationHistoryQueries are the engine specific queries on the migration history used by the migration precheck.
+type migrationHistoryQueries struct {
+	checkDuplicateVersion  func(ctx context.Context, tx *sql.Tx, namespace string, engine MigrationEngine, version string) (bool, error)
+	checkOutofOrderVersion func(ctx context.Context, tx *sql.Tx, namespace string, engine MigrationEngine, version string) (*string, error)
+	findVersionList        func(ctx context.Context, tx *sql.Tx, namespace string, engine MigrationEngine) ([]string, error)
+	findBaseline           func(ctx context.Context, tx *sql.Tx, namespace string) (bool, error)
+	findNextSequence       func(ctx context.Context, tx *sql.Tx, namespace string, requireBaseline bool) (int, error)
+}
+
+var mysqlMigrationHistoryQueries = migrationHistoryQueries{
+	checkDuplicateVersion:  checkDuplicateVersion,
+	checkOutofOrderVersion: checkOutofOrderVersion,
+	findVersionList:        findVersionList,
+	findBaseline:           findBaseline,
+	findNextSequence:       findNextSequence,
+}
+
+// checkMigrationPrecondition checks whether the migration can be applied, and returns the sequence for the migration history.
+func checkMigrationPrecondition(ctx context.Context, tx *sql.Tx, m *MigrationInfo, queries migrationHistoryQueries) (int, error) {
+	// Check if the same migration version has alraedy been applied
+	duplicate, err := queries.checkDuplicateVersion(ctx, tx, m.Namespace, m.Engine, m.Version)
+	if err != nil {
+		return -1, err
+	}
+	if duplicate {
+		return -1, fmt.Errorf("database %q has already applied version %s", m.Database, m.Version)
+	}
+
+	// Check if there is any higher version already been applied
+	var version *string
+	if m.VersionScheme == "" || m.VersionScheme == LexicographicVersion {
+		version, err = queries.checkOutofOrderVersion(ctx, tx, m.Namespace, m.Engine, m.Version)
+	} else {
+		version, err = checkOutofOrderVersionByScheme(ctx, tx, m, queries)
+	}
+	if err != nil {
+		return -1, err
+	}
+	if version != nil {
+		return -1, fmt.Errorf("database %q has already applied version %s which is higher than %s", m.Database, *version, m.Version)
+	}
+
+	// If the migration engine is VCS and type is not baseline and is not branch, then we can only proceed if there is existing baseline
+	// This check is also wrapped in transaction to avoid edge case where two baselinings are running concurrently.
+	if m.Engine == VCS && m.Type != Baseline && m.Type != Branch {
+		hasBaseline, err := queries.findBaseline(ctx, tx, m.Namespace)
+		if err != nil {
+			return -1, err
+		}
+
+		if !hasBaseline {
+			return -1, fmt.Errorf("%s has not created migration baseline yet", m.Database)
+		}
+	}
+
+	// VCS based SQL and data migration requires existing baselining
+	requireBaseline := m.Engine == VCS && (m.Type == Sql || m.Type == Data)
+	return queries.findNextSequence(ctx, tx, m.Namespace, requireBaseline)
+}
+
+// executeMigrationStatement applies the migration statement in tx, statement by statement if execution is not nil.
+// cancel cancels the statement running in tx on the server, see execStatement.
+func executeMigrationStatement(ctx context.Context, tx *sql.Tx, statement string, execution *MigrationExecution, cancel cancelStatementFunc) error {
+	if execution == nil {
+		// Branch migration type always has empty sql.
+		// Baseline migration type could also has empty sql when the database is newly created.
+		if statement != "" {
+			if err := execStatement(ctx, tx, statement, cancel); err != nil {
+				return formatError(err)
+			}
+		}
+		return nil
+	}
+
+	for i := execution.StartIndex; i < len(execution.StatementList); i++ {
+		stmt := execution.StatementList[i]
+		startedTs := time.Now()
+		err := execStatement(ctx, tx, stmt, cancel)
+		if err != nil {
+			err = formatError(err)
+		}
+		if execution.Progress != nil {
+			execution.Progress(i, time.Since(startedTs), err)
+		}
+		if err != nil {
+			return &MigrationStatementError{Index: i, Statement: stmt, Err: err}
+		}
+	}
+	return nil
+}
+
 func findBaseline(ctx context.Context, tx *sql.Tx, namespace string) (bool, error) {
 	query := `
-		SELECT 1 FROM bytebase.migration_history WHERE namespace = ? AND ` + "`type` = 'BASELINE'" + `
+		SELECT 1 FROM bytebase.migration_history WHERE namespace = ? AND (` + "`type` = 'BASELINE' OR `engine` IN ('FLYWAY', 'LIQUIBASE')" + `)
 	`
 	args := []interface{}{namespace}
 	row, err := tx.QueryContext(ctx, query,
@@ -690,6 +1773,67 @@ func checkOutofOrderVersion(ctx context.Context, tx *sql.Tx, namespace string, e
 	return nil, nil
 }
 
+// checkOutofOrderVersionByScheme is checkOutofOrderVersion ordering the versions by the scheme of the migration.
+// The versions invalid under the scheme are skipped, they are applied before switching to the scheme, and only the
+// versions applied afterwards are ordered.
+func checkOutofOrderVersionByScheme(ctx context.Context, tx *sql.Tx, m *MigrationInfo, queries migrationHistoryQueries) (*string, error) {
+	if err := ValidateVersion(m.VersionScheme, m.Version); err != nil {
+		return nil, err
+	}
+	versionList, err := queries.findVersionList(ctx, tx, m.Namespace, m.Engine)
+	if err != nil {
+		return nil, err
+	}
+
+	var minVersion *string
+	for i, version := range versionList {
+		c, err := CompareVersion(m.VersionScheme, version, m.Version)
+		if err != nil {
+			continue
+		}
+		// e.g. "v01" and "v1" are the same version under NumericVersion.
+		if c == 0 {
+			return nil, fmt.Errorf("database %q has already applied version %s which is the same as %s", m.Database, version, m.Version)
+		}
+		if c > 0 {
+			if minVersion == nil {
+				minVersion = &versionList[i]
+			} else if c, _ := CompareVersion(m.VersionScheme, version, *minVersion); c < 0 {
+				minVersion = &versionList[i]
+			}
+		}
+	}
+	return minVersion, nil
+}
+
+func findVersionList(ctx context.Context, tx *sql.Tx, namespace string, engine MigrationEngine) ([]string, error) {
+	query := `
+		SELECT version FROM bytebase.migration_history WHERE namespace = ? AND ` + "`engine` = ?" + `
+	`
+	args := []interface{}{namespace, engine.String()}
+	rows, err := tx.QueryContext(ctx, query,
+		args...,
+	)
+
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var versionList []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versionList = append(versionList, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return versionList, nil
+}
+
 func findNextSequence(ctx context.Context, tx *sql.Tx, namespace string, requireBaseline bool) (int, error) {
 	query := `
 		SELECT MAX(sequence) + 1 FROM bytebase.migration_history WHERE namespace = ?
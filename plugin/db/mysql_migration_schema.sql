@@ -15,7 +15,7 @@ CREATE TABLE bytebase.setting (
 
 CREATE UNIQUE INDEX bytebase_idx_unique_setting_name ON bytebase.setting (name(256));
 
--- Insert schema version 1
+-- Insert schema version 2
 INSERT INTO
     bytebase.setting (
         created_by,
@@ -33,7 +33,7 @@ VALUES
         'bytebase',
         UNIX_TIMESTAMP(),
         'bb.schema.version',
-        '1',
+        '4',
         'Schema version'
     );
 
@@ -51,13 +51,15 @@ CREATE TABLE bytebase.migration_history (
     namespace TEXT NOT NULL,
     -- Used to detect out of order migration together with 'namespace' and 'version' column.
     sequence INTEGER UNSIGNED NOT NULL,
-    -- We call it engine because maybe we could load history from other migration tool.
-    `engine` ENUM('UI', 'VCS') NOT NULL,
-    `type` ENUM('BASELINE', 'SQL', 'BRANCH') NOT NULL,
+    -- We call it engine because the history may be imported from other migration tools, e.g. Flyway and Liquibase.
+    `engine` ENUM('UI', 'VCS', 'FLYWAY', 'LIQUIBASE') NOT NULL,
+    `type` ENUM('BASELINE', 'SQL', 'BRANCH', 'ROLLBACK', 'DATA') NOT NULL,
     version TEXT NOT NULL,
     description TEXT NOT NULL,
     -- Recorded the migration statement
     statement TEXT NOT NULL,
+    -- Recorded the statement reverting the migration, empty if not provided
+    rollback_statement TEXT NOT NULL,
     execution_duration INTEGER NOT NULL,
     issue_id TEXT NOT NULL,
     payload TEXT NOT NULL
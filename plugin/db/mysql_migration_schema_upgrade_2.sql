@@ -0,0 +1,7 @@
+-- Upgrade the bytebase migration schema from version 1 to 2 for MySQL (also used by OceanBase)
+-- Record the rollback statement and allow the ROLLBACK migration type.
+ALTER TABLE bytebase.migration_history
+    MODIFY `type` ENUM('BASELINE', 'SQL', 'BRANCH', 'ROLLBACK') NOT NULL,
+    ADD COLUMN rollback_statement TEXT NOT NULL AFTER statement;
+
+UPDATE bytebase.setting SET value = '2', updated_by = 'bytebase', updated_ts = UNIX_TIMESTAMP() WHERE name = 'bb.schema.version';
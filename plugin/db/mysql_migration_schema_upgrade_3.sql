@@ -0,0 +1,6 @@
+-- Upgrade the bytebase migration schema from version 2 to 3 for MySQL (also used by OceanBase)
+-- Allow the DATA migration type.
+ALTER TABLE bytebase.migration_history
+    MODIFY `type` ENUM('BASELINE', 'SQL', 'BRANCH', 'ROLLBACK', 'DATA') NOT NULL;
+
+UPDATE bytebase.setting SET value = '3', updated_by = 'bytebase', updated_ts = UNIX_TIMESTAMP() WHERE name = 'bb.schema.version';
@@ -0,0 +1,6 @@
+-- Upgrade the bytebase migration schema from version 3 to 4 for MySQL (also used by OceanBase)
+-- Allow the history imported from Flyway and Liquibase.
+ALTER TABLE bytebase.migration_history
+    MODIFY `engine` ENUM('UI', 'VCS', 'FLYWAY', 'LIQUIBASE') NOT NULL;
+
+UPDATE bytebase.setting SET value = '4', updated_by = 'bytebase', updated_ts = UNIX_TIMESTAMP() WHERE name = 'bb.schema.version';
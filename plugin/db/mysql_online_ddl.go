@@ -0,0 +1,621 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultOnlineDDLChunkSize         = 1000
+	defaultOnlineDDLMaxThreadsRunning = 25
+	// The shadow table and trigger names are "_{{table}}_bb_{{suffix}}", which must fit in the 64 characters limit.
+	onlineDDLMaxTableNameLength = 64 - len("__bb_new")
+	// Lock wait timeout in seconds for the statements requiring the metadata lock of the table, e.g. the cut-over,
+	// so that they don't block the writes for long behind a long running transaction.
+	onlineDDLLockWaitTimeout   = 5
+	onlineDDLCutOverRetryCount = 3
+	onlineDDLThrottleInterval  = time.Second
+)
+
+// OnlineDDLConfig configures applying ALTER TABLE by copying into a shadow table, so that the writes to the table are
+// not blocked during the schema change. The rows changed during the copy are kept in sync by triggers, and the shadow
+// table is swapped in by an atomic RENAME TABLE at the end.
+type OnlineDDLConfig struct {
+	// ChunkSize is the number of rows copied by a single statement, 1000 if not set.
+	ChunkSize int
+	// The copy pauses while the Threads_running status of the server exceeds MaxThreadsRunning, 25 if not set.
+	// Negative value disables the throttling.
+	MaxThreadsRunning int
+	// Progress is called after copying each chunk of the statement at index, estimatedRows is from the table
+	// statistics and may be lower than copiedRows.
+	Progress func(index int, copiedRows int64, estimatedRows int64)
+}
+
+// onlineAlterTable is an ALTER TABLE statement applied by a shadow table copy.
+type onlineAlterTable struct {
+	// database is empty if the table is not qualified.
+	database string
+	table    string
+	// spec is the ALTER TABLE specification text after the table name, applied to the shadow table as is.
+	spec string
+	// columnRenameMap is the lowercase old column name -> new column name map.
+	columnRenameMap map[string]string
+}
+
+// parseOnlineAlterTable returns nil if the statement doesn't need the shadow table copy, e.g. it's not ALTER TABLE or
+// it only renames the table, and returns error if the ALTER TABLE can't be applied by the shadow table copy.
+func parseOnlineAlterTable(statement string) (*onlineAlterTable, error) {
+	tokenList := tokenize(statement)
+	if len(tokenList) < 4 || !tokenList[0].is("ALTER") || !tokenList[1].is("TABLE") || !tokenList[2].isName() {
+		return nil, nil
+	}
+	alter := &onlineAlterTable{
+		table:           tokenList[2].text,
+		columnRenameMap: make(map[string]string),
+	}
+	i := 3
+	if i+1 < len(tokenList) && tokenList[i].is(".") && tokenList[i+1].isName() {
+		alter.database, alter.table = alter.table, tokenList[i+1].text
+		i += 2
+	}
+	alter.spec = strings.TrimRight(strings.TrimSpace(statement[tokenList[i-1].end:]), ";")
+	if alter.spec == "" {
+		return nil, nil
+	}
+
+	specList := splitByComma(tokenList[i:])
+	for _, spec := range specList {
+		if len(spec) == 0 {
+			continue
+		}
+		action := spec[0]
+		switch {
+		case action.is("RENAME"):
+			j := 1
+			if j < len(spec) && (spec[j].is("INDEX") || spec[j].is("KEY")) {
+				continue
+			}
+			j, isColumn := skipKeywords(spec, j, "COLUMN")
+			if isColumn || (j+2 < len(spec) && spec[j].isName() && spec[j+1].is("TO") && !spec[j].is("TO") && !spec[j].is("AS")) {
+				// RENAME [COLUMN] old TO new
+				if j+2 < len(spec) && spec[j].isName() && spec[j+2].isName() {
+					alter.columnRenameMap[strings.ToLower(spec[j].text)] = spec[j+2].text
+				}
+				continue
+			}
+			// Renaming the table is a metadata change, which doesn't need the copy.
+			if len(specList) == 1 {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("renaming table %q together with other changes is not supported by online DDL, please rename the table in a separate statement", alter.table)
+		case action.is("CHANGE"):
+			j, _ := skipKeywords(spec, 1, "COLUMN")
+			if j+1 < len(spec) && spec[j].isName() && spec[j+1].isName() && !strings.EqualFold(spec[j].text, spec[j+1].text) {
+				alter.columnRenameMap[strings.ToLower(spec[j].text)] = spec[j+1].text
+			}
+		case action.is("DROP"):
+			if _, ok := skipKeywords(spec, 1, "PRIMARY", "KEY"); ok {
+				return nil, fmt.Errorf("dropping the primary key of table %q is not supported by online DDL, the copy relies on the primary key", alter.table)
+			}
+		case action.is("DISCARD") || action.is("IMPORT") || action.is("EXCHANGE") || action.is("TRUNCATE"):
+			return nil, fmt.Errorf("%s on table %q is not supported by online DDL", strings.ToUpper(action.text), alter.table)
+		}
+	}
+	return alter, nil
+}
+
+// executeOnlineMigration executes the migration with OnlineDDL. Unlike ExecuteMigration, the migration history is
+// recorded in a separate transaction afterwards, so that no transaction is held open during the long running copy.
+func (driver *MySQLDriver) executeOnlineMigration(ctx context.Context, m *MigrationInfo, statement string, execution *MigrationExecution) error {
+	startedTs := time.Now().Unix()
+
+	// Phase 1 - Precheck before executing migration
+	if err := driver.checkMigrationPrecondition(ctx, m); err != nil {
+		return err
+	}
+
+	// Phase 2 - Executing migration
+	if err := driver.executeOnlineStatementList(ctx, execution); err != nil {
+		return err
+	}
+
+	// Phase 3 - Record migration, the precondition is checked again in case another migration has been applied meanwhile.
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	sequence, err := checkMigrationPrecondition(ctx, tx, m, mysqlMigrationHistoryQueries)
+	if err != nil {
+		return err
+	}
+	if err := insertMySQLMigrationHistory(ctx, tx, m, sequence, statement, time.Now().Unix()-startedTs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (driver *MySQLDriver) checkMigrationPrecondition(ctx context.Context, m *MigrationInfo) error {
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = checkMigrationPrecondition(ctx, tx, m, mysqlMigrationHistoryQueries)
+	return err
+}
+
+// executeOnlineStatementList applies the statements in execution one by one, ALTER TABLE statements are applied by
+// the shadow table copy. Statements are not wrapped in a transaction, which makes no difference for DDL since MySQL
+// implicitly commits the transaction upon DDL.
+func (driver *MySQLDriver) executeOnlineStatementList(ctx context.Context, execution *MigrationExecution) error {
+	conn, err := driver.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// TiDB and OceanBase apply the schema changes online by themselves.
+	nativeOnline := driver.serverInfo.Flavor == FlavorTiDB || driver.serverInfo.Flavor == FlavorOceanBase
+	for i := execution.StartIndex; i < len(execution.StatementList); i++ {
+		stmt := execution.StatementList[i]
+		startedTs := time.Now()
+
+		var alter *onlineAlterTable
+		if !nativeOnline {
+			alter, err = parseOnlineAlterTable(stmt)
+		}
+		if err == nil {
+			if alter != nil {
+				index := i
+				copier := &onlineTableCopier{
+					l:      driver.l,
+					conn:   conn,
+					config: execution.OnlineDDL,
+					alter:  alter,
+					progress: func(copiedRows int64, estimatedRows int64) {
+						if execution.OnlineDDL.Progress != nil {
+							execution.OnlineDDL.Progress(index, copiedRows, estimatedRows)
+						}
+					},
+				}
+				err = copier.run(ctx)
+			} else if _, err = conn.ExecContext(ctx, stmt); err != nil {
+				err = formatError(err)
+			}
+		}
+
+		if execution.Progress != nil {
+			execution.Progress(i, time.Since(startedTs), err)
+		}
+		if err != nil {
+			return &MigrationStatementError{Index: i, Statement: stmt, Err: err}
+		}
+	}
+	return nil
+}
+
+// onlineTableCopier applies a single ALTER TABLE by the shadow table copy.
+type onlineTableCopier struct {
+	l        *zap.Logger
+	conn     *sql.Conn
+	config   *OnlineDDLConfig
+	alter    *onlineAlterTable
+	progress func(copiedRows int64, estimatedRows int64)
+
+	database string
+	// Quoted and qualified names.
+	table       string
+	shadowTable string
+	oldTable    string
+	triggerList []string
+
+	pkColumnList []string
+	// Shared columns of the table and the shadow table, shadowColumnList[i] is the new name of columnList[i].
+	columnList       []string
+	shadowColumnList []string
+	shadowPKList     []string
+}
+
+func (c *onlineTableCopier) run(ctx context.Context) error {
+	if err := c.prepare(ctx); err != nil {
+		return err
+	}
+
+	c.l.Info("Applying online DDL",
+		zap.String("database", c.database),
+		zap.String("table", c.alter.table),
+		zap.String("spec", c.alter.spec),
+	)
+	// Not cleaning up if the shadow table exists, which may be left by a previous attempt and needs to be checked.
+	if _, err := c.conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s LIKE %s", c.shadowTable, c.table)); err != nil {
+		return fmt.Errorf("failed to create shadow table %s, it may be left by a previous attempt and should be dropped first: %w", c.shadowTable, formatError(err))
+	}
+	if err := c.createShadowTable(ctx); err != nil {
+		c.cleanup()
+		return err
+	}
+	if err := c.copyRows(ctx); err != nil {
+		c.cleanup()
+		return err
+	}
+	if err := c.cutOver(ctx); err != nil {
+		c.cleanup()
+		return err
+	}
+	return nil
+}
+
+// prepare resolves the table and checks whether it can be copied online.
+func (c *onlineTableCopier) prepare(ctx context.Context) error {
+	c.database = c.alter.database
+	if c.database == "" {
+		var database sql.NullString
+		if err := c.conn.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&database); err != nil {
+			return formatError(err)
+		}
+		if !database.Valid {
+			return fmt.Errorf("no database selected for table %q", c.alter.table)
+		}
+		c.database = database.String
+	}
+	if len(c.alter.table) > onlineDDLMaxTableNameLength {
+		return fmt.Errorf("table name %q is too long for online DDL, it should be at most %d characters", c.alter.table, onlineDDLMaxTableNameLength)
+	}
+
+	quote := func(name string) string {
+		return quoteMySQLIdentifier(c.database) + "." + quoteMySQLIdentifier(name)
+	}
+	c.table = quote(c.alter.table)
+	c.shadowTable = quote(fmt.Sprintf("_%s_bb_new", c.alter.table))
+	c.oldTable = quote(fmt.Sprintf("_%s_bb_old", c.alter.table))
+	for _, suffix := range []string{"ins", "upd", "del"} {
+		c.triggerList = append(c.triggerList, quote(fmt.Sprintf("_%s_bb_%s", c.alter.table, suffix)))
+	}
+
+	if _, err := c.conn.ExecContext(ctx, fmt.Sprintf("SET SESSION lock_wait_timeout = %d", onlineDDLLockWaitTimeout)); err != nil {
+		return formatError(err)
+	}
+
+	pkColumnList, err := c.findPrimaryKey(ctx, c.alter.table)
+	if err != nil {
+		return err
+	}
+	if len(pkColumnList) == 0 {
+		return fmt.Errorf("table %q has no primary key, which is required by online DDL", c.alter.table)
+	}
+	c.pkColumnList = pkColumnList
+
+	// The triggers keeping the shadow table in sync would conflict with the existing ones.
+	var count int
+	query := `SELECT COUNT(*) FROM information_schema.TRIGGERS WHERE EVENT_OBJECT_SCHEMA = ? AND EVENT_OBJECT_TABLE = ?`
+	if err := c.conn.QueryRowContext(ctx, query, c.database, c.alter.table).Scan(&count); err != nil {
+		return formatErrorWithQuery(err, query)
+	}
+	if count > 0 {
+		return fmt.Errorf("table %q has triggers, which is not supported by online DDL", c.alter.table)
+	}
+
+	// The foreign keys would still reference the old table after the cut-over.
+	query = `
+		SELECT COUNT(*) FROM information_schema.KEY_COLUMN_USAGE
+		WHERE REFERENCED_TABLE_NAME IS NOT NULL
+			AND ((TABLE_SCHEMA = ? AND TABLE_NAME = ?) OR (REFERENCED_TABLE_SCHEMA = ? AND REFERENCED_TABLE_NAME = ?))`
+	if err := c.conn.QueryRowContext(ctx, query, c.database, c.alter.table, c.database, c.alter.table).Scan(&count); err != nil {
+		return formatErrorWithQuery(err, query)
+	}
+	if count > 0 {
+		return fmt.Errorf("table %q has or is referenced by foreign keys, which is not supported by online DDL", c.alter.table)
+	}
+	return nil
+}
+
+func (c *onlineTableCopier) findPrimaryKey(ctx context.Context, table string) ([]string, error) {
+	query := `
+		SELECT COLUMN_NAME FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = 'PRIMARY'
+		ORDER BY SEQ_IN_INDEX`
+	rows, err := c.conn.QueryContext(ctx, query, c.database, table)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var columnList []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columnList = append(columnList, column)
+	}
+	return columnList, rows.Err()
+}
+
+// findColumnList returns the columns in order, excluding the generated columns which can't be inserted.
+func (c *onlineTableCopier) findColumnList(ctx context.Context, table string) ([]string, error) {
+	query := `
+		SELECT COLUMN_NAME, EXTRA FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`
+	rows, err := c.conn.QueryContext(ctx, query, c.database, table)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var columnList []string
+	for rows.Next() {
+		var column, extra string
+		if err := rows.Scan(&column, &extra); err != nil {
+			return nil, err
+		}
+		// MySQL 8.0 reports DEFAULT_GENERATED for the columns with expression default, which can be inserted.
+		extra = strings.ToUpper(extra)
+		if strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED") || strings.Contains(extra, "PERSISTENT GENERATED") {
+			continue
+		}
+		columnList = append(columnList, column)
+	}
+	return columnList, rows.Err()
+}
+
+// createShadowTable alters the shadow table and creates the triggers syncing the changes into it.
+func (c *onlineTableCopier) createShadowTable(ctx context.Context) error {
+	if _, err := c.conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s %s", c.shadowTable, c.alter.spec)); err != nil {
+		return formatError(err)
+	}
+
+	columnList, err := c.findColumnList(ctx, c.alter.table)
+	if err != nil {
+		return err
+	}
+	shadowColumnList, err := c.findColumnList(ctx, fmt.Sprintf("_%s_bb_new", c.alter.table))
+	if err != nil {
+		return err
+	}
+	shadowColumnMap := make(map[string]string)
+	for _, column := range shadowColumnList {
+		shadowColumnMap[strings.ToLower(column)] = column
+	}
+	for _, column := range columnList {
+		name := column
+		if newName, ok := c.alter.columnRenameMap[strings.ToLower(column)]; ok {
+			name = newName
+		}
+		if shadowColumn, ok := shadowColumnMap[strings.ToLower(name)]; ok {
+			c.columnList = append(c.columnList, column)
+			c.shadowColumnList = append(c.shadowColumnList, shadowColumn)
+		}
+	}
+	for _, pk := range c.pkColumnList {
+		found := false
+		for i, column := range c.columnList {
+			if strings.EqualFold(column, pk) {
+				c.shadowPKList = append(c.shadowPKList, c.shadowColumnList[i])
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("primary key column %q of table %q is dropped, which is not supported by online DDL", pk, c.alter.table)
+		}
+	}
+
+	var insertValueList, oldPKMatchList, newPKMatchList []string
+	for _, column := range c.columnList {
+		insertValueList = append(insertValueList, "NEW."+quoteMySQLIdentifier(column))
+	}
+	for i, pk := range c.pkColumnList {
+		shadowPK := c.shadowTable + "." + quoteMySQLIdentifier(c.shadowPKList[i])
+		oldPKMatchList = append(oldPKMatchList, fmt.Sprintf("%s <=> OLD.%s", shadowPK, quoteMySQLIdentifier(pk)))
+		newPKMatchList = append(newPKMatchList, fmt.Sprintf("OLD.%s <=> NEW.%s", quoteMySQLIdentifier(pk), quoteMySQLIdentifier(pk)))
+	}
+	replace := fmt.Sprintf("REPLACE INTO %s (%s) VALUES (%s)", c.shadowTable, joinMySQLIdentifierList(c.shadowColumnList), strings.Join(insertValueList, ", "))
+	deleteOld := fmt.Sprintf("DELETE IGNORE FROM %s WHERE %s", c.shadowTable, strings.Join(oldPKMatchList, " AND "))
+	triggerBodyList := []string{
+		fmt.Sprintf("AFTER INSERT ON %s FOR EACH ROW %s", c.table, replace),
+		// Remove the row with the old primary key first if the primary key is changed.
+		fmt.Sprintf("AFTER UPDATE ON %s FOR EACH ROW BEGIN DELETE IGNORE FROM %s WHERE NOT (%s) AND %s; %s; END",
+			c.table, c.shadowTable, strings.Join(newPKMatchList, " AND "), strings.Join(oldPKMatchList, " AND "), replace),
+		fmt.Sprintf("AFTER DELETE ON %s FOR EACH ROW %s", c.table, deleteOld),
+	}
+	for i, trigger := range c.triggerList {
+		if _, err := c.conn.ExecContext(ctx, fmt.Sprintf("CREATE TRIGGER %s %s", trigger, triggerBodyList[i])); err != nil {
+			return formatError(err)
+		}
+	}
+	return nil
+}
+
+// copyRows copies the rows into the shadow table chunk by chunk in the primary key order.
+func (c *onlineTableCopier) copyRows(ctx context.Context) error {
+	chunkSize := c.config.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultOnlineDDLChunkSize
+	}
+
+	var estimatedRows sql.NullInt64
+	query := `SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`
+	if err := c.conn.QueryRowContext(ctx, query, c.database, c.alter.table).Scan(&estimatedRows); err != nil {
+		return formatErrorWithQuery(err, query)
+	}
+
+	pkList := joinMySQLIdentifierList(c.pkColumnList)
+	pkPlaceholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(c.pkColumnList)), ", ") + ")"
+	insert := fmt.Sprintf("INSERT IGNORE INTO %s (%s) SELECT %s FROM %s FORCE INDEX (PRIMARY)",
+		c.shadowTable, joinMySQLIdentifierList(c.shadowColumnList), joinMySQLIdentifierList(c.columnList), c.table)
+
+	// The primary key values are compared as strings, which are converted to the column type and collation by MySQL.
+	var lowerBound []interface{}
+	var copiedRows int64
+	for {
+		if err := c.throttle(ctx); err != nil {
+			return err
+		}
+
+		where := ""
+		var args []interface{}
+		if lowerBound != nil {
+			where = fmt.Sprintf("(%s) > %s", pkList, pkPlaceholder)
+			args = append(args, lowerBound...)
+		}
+		upperBound, err := c.findChunkUpperBound(ctx, where, args, chunkSize)
+		if err != nil {
+			return err
+		}
+		// The last chunk has no upper bound, the rows inserted afterwards are synced by the triggers.
+		if upperBound != nil {
+			if where != "" {
+				where += " AND "
+			}
+			where += fmt.Sprintf("(%s) <= %s", pkList, pkPlaceholder)
+			args = append(args, upperBound...)
+		}
+
+		query := insert
+		if where != "" {
+			query += " WHERE " + where
+		}
+		query += " LOCK IN SHARE MODE"
+		result, err := c.conn.ExecContext(ctx, query, args...)
+		if err != nil {
+			return formatErrorWithQuery(err, query)
+		}
+		if rowsAffected, err := result.RowsAffected(); err == nil {
+			copiedRows += rowsAffected
+		}
+		if c.progress != nil {
+			c.progress(copiedRows, estimatedRows.Int64)
+		}
+
+		if upperBound == nil {
+			break
+		}
+		lowerBound = upperBound
+	}
+	c.l.Info("Copied rows into shadow table",
+		zap.String("table", c.shadowTable),
+		zap.Int64("rows", copiedRows),
+	)
+	return nil
+}
+
+// findChunkUpperBound returns the primary key of the last row of the chunk, or nil if it's the last chunk.
+func (c *onlineTableCopier) findChunkUpperBound(ctx context.Context, where string, args []interface{}, chunkSize int) ([]interface{}, error) {
+	pkList := joinMySQLIdentifierList(c.pkColumnList)
+	query := fmt.Sprintf("SELECT %s FROM %s FORCE INDEX (PRIMARY)", pkList, c.table)
+	if where != "" {
+		query += " WHERE " + where
+	}
+	query += fmt.Sprintf(" ORDER BY %s LIMIT 1 OFFSET %d", pkList, chunkSize-1)
+
+	valueList := make([]sql.NullString, len(c.pkColumnList))
+	destList := make([]interface{}, len(valueList))
+	for i := range valueList {
+		destList[i] = &valueList[i]
+	}
+	if err := c.conn.QueryRowContext(ctx, query, args...).Scan(destList...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, formatErrorWithQuery(err, query)
+	}
+	var bound []interface{}
+	for _, value := range valueList {
+		bound = append(bound, value.String)
+	}
+	return bound, nil
+}
+
+// throttle waits until the Threads_running status of the server drops to the limit.
+func (c *onlineTableCopier) throttle(ctx context.Context) error {
+	maxThreadsRunning := c.config.MaxThreadsRunning
+	if maxThreadsRunning == 0 {
+		maxThreadsRunning = defaultOnlineDDLMaxThreadsRunning
+	}
+	if maxThreadsRunning < 0 {
+		return nil
+	}
+
+	for {
+		var name, value string
+		if err := c.conn.QueryRowContext(ctx, "SHOW GLOBAL STATUS LIKE 'Threads_running'").Scan(&name, &value); err != nil {
+			return formatError(err)
+		}
+		threadsRunning, err := strconv.Atoi(value)
+		if err != nil || threadsRunning <= maxThreadsRunning {
+			return nil
+		}
+		c.l.Debug("Throttling online DDL",
+			zap.String("table", c.alter.table),
+			zap.Int("threadsRunning", threadsRunning),
+			zap.Int("maxThreadsRunning", maxThreadsRunning),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(onlineDDLThrottleInterval):
+		}
+	}
+}
+
+// cutOver swaps in the shadow table atomically, and drops the old table together with the triggers.
+func (c *onlineTableCopier) cutOver(ctx context.Context) error {
+	rename := fmt.Sprintf("RENAME TABLE %s TO %s, %s TO %s", c.table, c.oldTable, c.shadowTable, c.table)
+	var err error
+	for i := 0; i < onlineDDLCutOverRetryCount; i++ {
+		if _, err = c.conn.ExecContext(ctx, rename); err == nil || ctx.Err() != nil {
+			break
+		}
+		c.l.Warn("Failed to cut over online DDL, retrying",
+			zap.String("table", c.alter.table),
+			zap.Error(err),
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to cut over after %d attempts: %w", onlineDDLCutOverRetryCount, formatError(err))
+	}
+
+	// The triggers are moved to the old table by the rename, and dropped together with it.
+	if _, err := c.conn.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", c.oldTable)); err != nil {
+		c.l.Warn("Failed to drop the old table after online DDL",
+			zap.String("table", c.oldTable),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
+
+// cleanup drops the triggers and the shadow table after failure. It ignores the cancellation of the migration.
+func (c *onlineTableCopier) cleanup() {
+	ctx := context.Background()
+	for _, trigger := range c.triggerList {
+		if _, err := c.conn.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER IF EXISTS %s", trigger)); err != nil {
+			c.l.Warn("Failed to drop online DDL trigger", zap.String("trigger", trigger), zap.Error(err))
+		}
+	}
+	if _, err := c.conn.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", c.shadowTable)); err != nil {
+		c.l.Warn("Failed to drop online DDL shadow table", zap.String("table", c.shadowTable), zap.Error(err))
+	}
+}
+
+func quoteMySQLIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func joinMySQLIdentifierList(nameList []string) string {
+	var list []string
+	for _, name := range nameList {
+		list = append(list, quoteMySQLIdentifier(name))
+	}
+	return strings.Join(list, ", ")
+}
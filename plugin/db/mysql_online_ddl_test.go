@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestParseOnlineAlterTable(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      *onlineAlterTable
+		wantErr   bool
+	}{
+		{
+			statement: "ALTER TABLE t1 ADD COLUMN c3 INT NOT NULL DEFAULT 0;",
+			want:      &onlineAlterTable{table: "t1", spec: "ADD COLUMN c3 INT NOT NULL DEFAULT 0", columnRenameMap: map[string]string{}},
+		},
+		{
+			statement: "alter table `db1`.`my t` change c1 c1_new varchar(10) comment 'a, b', rename column c2 to c2_new, add index idx_c3 (c3)",
+			want: &onlineAlterTable{
+				database:        "db1",
+				table:           "my t",
+				spec:            "change c1 c1_new varchar(10) comment 'a, b', rename column c2 to c2_new, add index idx_c3 (c3)",
+				columnRenameMap: map[string]string{"c1": "c1_new", "c2": "c2_new"},
+			},
+		},
+		{
+			statement: "ALTER TABLE t1 MODIFY c1 BIGINT, RENAME INDEX idx1 TO idx2, CHANGE c2 c2 TEXT",
+			want:      &onlineAlterTable{table: "t1", spec: "MODIFY c1 BIGINT, RENAME INDEX idx1 TO idx2, CHANGE c2 c2 TEXT", columnRenameMap: map[string]string{}},
+		},
+		{
+			statement: "ALTER TABLE t1 RENAME TO t2",
+		},
+		{
+			statement: "CREATE TABLE t1 (id INT PRIMARY KEY)",
+		},
+		{
+			statement: "ALTER TABLE t1 ADD COLUMN c3 INT, RENAME TO t2",
+			wantErr:   true,
+		},
+		{
+			statement: "ALTER TABLE t1 DROP PRIMARY KEY, ADD PRIMARY KEY (c1)",
+			wantErr:   true,
+		},
+		{
+			statement: "ALTER TABLE t1 DISCARD TABLESPACE",
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := parseOnlineAlterTable(test.statement)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseOnlineAlterTable(%q) got error %v, want error %v", test.statement, err, test.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseOnlineAlterTable(%q) got %+v, want %+v", test.statement, got, test.want)
+		}
+	}
+}
+
+// TestMySQLOnlineDDL runs against a local MySQL, e.g. started by
+// "docker run -d -p 3306:3306 -e MYSQL_ROOT_PASSWORD=root mysql:8.0", and is skipped unless
+// BB_TEST_MYSQL_HOST is set. BB_TEST_MYSQL_PORT, BB_TEST_MYSQL_USER and BB_TEST_MYSQL_PASSWORD are optional.
+func TestMySQLOnlineDDL(t *testing.T) {
+	host := os.Getenv("BB_TEST_MYSQL_HOST")
+	if host == "" {
+		t.Skip("BB_TEST_MYSQL_HOST is not set")
+	}
+	user := os.Getenv("BB_TEST_MYSQL_USER")
+	if user == "" {
+		user = "root"
+	}
+	const database = "bb_test_online_ddl"
+	ctx := context.Background()
+
+	driver, err := Open(Mysql, DriverConfig{Logger: zap.NewNop()}, ConnectionConfig{
+		Host:     host,
+		Port:     os.Getenv("BB_TEST_MYSQL_PORT"),
+		Username: user,
+		Password: os.Getenv("BB_TEST_MYSQL_PASSWORD"),
+	}, ConnectionContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Close(ctx)
+	if err := driver.SetupMigrationIfNeeded(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mysqlDB := driver.(*MySQLDriver).db
+	for _, statement := range []string{
+		fmt.Sprintf("DROP DATABASE IF EXISTS %s", database),
+		fmt.Sprintf("CREATE DATABASE %s", database),
+		fmt.Sprintf("CREATE TABLE %s.t1 (id INT PRIMARY KEY AUTO_INCREMENT, c1 VARCHAR(32) NOT NULL, c2 INT NOT NULL)", database),
+		fmt.Sprintf("DELETE FROM bytebase.migration_history WHERE namespace = '%s'", database),
+	} {
+		if _, err := mysqlDB.ExecContext(ctx, statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer mysqlDB.ExecContext(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS %s", database))
+
+	const rowCount = 5000
+	var valueList []string
+	for i := 1; i <= rowCount; i++ {
+		valueList = append(valueList, fmt.Sprintf("(%d, 'row%d', %d)", i, i, i))
+	}
+	if _, err := mysqlDB.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s.t1 (id, c1, c2) VALUES %s", database, strings.Join(valueList, ", "))); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keep writing during the copy, the changes should be synced into the shadow table by the triggers.
+	stop := make(chan struct{})
+	var insertedCount int
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			mysqlDB.ExecContext(ctx, fmt.Sprintf("UPDATE %s.t1 SET c2 = c2 + 1 WHERE id = %d", database, i%rowCount+1))
+			if _, err := mysqlDB.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s.t1 (c1, c2) VALUES ('new', %d)", database, i)); err == nil {
+				insertedCount++
+			}
+		}
+	}()
+
+	statement := fmt.Sprintf("ALTER TABLE %s.t1 CHANGE c1 name VARCHAR(64) NOT NULL, ADD COLUMN c3 INT NOT NULL DEFAULT 7;\nCREATE TABLE %s.t2 (id INT PRIMARY KEY)", database, database)
+	var progressCount int
+	var copiedRows int64
+	execution := &MigrationExecution{
+		StatementList: SplitStatement(statement),
+		OnlineDDL: &OnlineDDLConfig{
+			ChunkSize: 500,
+			Progress: func(index int, copied int64, estimated int64) {
+				progressCount++
+				copiedRows = copied
+			},
+		},
+	}
+	err = driver.ExecuteMigration(ctx, &MigrationInfo{
+		Version:   "0001",
+		Namespace: database,
+		Database:  database,
+		Engine:    UI,
+		Type:      Sql,
+		Creator:   "test",
+	}, statement, execution)
+	close(stop)
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progressCount < rowCount/500 || copiedRows < rowCount {
+		t.Errorf("got %d progress calls with %d rows copied, want at least %d calls with %d rows", progressCount, copiedRows, rowCount/500, rowCount)
+	}
+
+	// The synced rows should match the original ones after the cut-over.
+	var count int
+	if err := mysqlDB.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s.t1", database)).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != rowCount+insertedCount {
+		t.Errorf("got %d rows after online DDL, want %d", count, rowCount+insertedCount)
+	}
+	var mismatch int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s.t1 WHERE name IS NULL OR c3 <> 7 OR (id <= %d AND name <> CONCAT('row', id))", database, rowCount)
+	if err := mysqlDB.QueryRowContext(ctx, query).Scan(&mismatch); err != nil {
+		t.Fatal(err)
+	}
+	if mismatch != 0 {
+		t.Errorf("got %d mismatched rows after online DDL", mismatch)
+	}
+	var leftover int
+	query = fmt.Sprintf("SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME LIKE '\\_t1\\_bb\\_%%'", database)
+	if err := mysqlDB.QueryRowContext(ctx, query).Scan(&leftover); err != nil {
+		t.Fatal(err)
+	}
+	query = fmt.Sprintf("SELECT COUNT(*) FROM information_schema.TRIGGERS WHERE TRIGGER_SCHEMA = '%s'", database)
+	var triggerCount int
+	if err := mysqlDB.QueryRowContext(ctx, query).Scan(&triggerCount); err != nil {
+		t.Fatal(err)
+	}
+	if leftover != 0 || triggerCount != 0 {
+		t.Errorf("got %d leftover tables and %d leftover triggers after online DDL", leftover, triggerCount)
+	}
+
+	namespace := database
+	historyList, err := driver.FindMigrationHistoryList(ctx, &MigrationHistoryFind{Database: &namespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(historyList) != 1 || historyList[0].Version != "0001" || historyList[0].Statement != statement {
+		t.Errorf("got migration history %+v, want version 0001 with the whole statement", historyList)
+	}
+
+	if err := mysqlDB.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s.t2", database)).Scan(&count); err != nil {
+		t.Errorf("table t2 should be created by the statement other than ALTER TABLE: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestParseMySQLServerInfo(t *testing.T) {
+	tests := []struct {
+		version string
+		comment string
+		want    ServerInfo
+	}{
+		{
+			version: "8.0.25-0ubuntu0.20.04.1",
+			comment: "(Ubuntu)",
+			want:    ServerInfo{Flavor: FlavorMySQL, Version: "8.0.25"},
+		},
+		{
+			version: "5.7.34-log",
+			comment: "MySQL Community Server (GPL)",
+			want:    ServerInfo{Flavor: FlavorMySQL, Version: "5.7.34"},
+		},
+		{
+			version: "10.5.9-MariaDB-1:10.5.9+maria~focal",
+			comment: "mariadb.org binary distribution",
+			want:    ServerInfo{Flavor: FlavorMariaDB, Version: "10.5.9"},
+		},
+		{
+			version: "5.7.25-TiDB-v5.0.1",
+			comment: "TiDB Server (Apache License 2.0) Community Edition, MySQL 5.7 compatible",
+			want:    ServerInfo{Flavor: FlavorTiDB, Version: "5.0.1"},
+		},
+		{
+			version: "5.7.25",
+			comment: "OceanBase 3.1.0 (r1-0d3f5aa3ae6c1c4dc0b4d9b2bd2b8e4a0d6a3f7e) (Built Jun 30 2021 13:11:24)",
+			want:    ServerInfo{Flavor: FlavorOceanBase, Version: "3.1.0"},
+		},
+	}
+
+	for _, test := range tests {
+		got := parseMySQLServerInfo(test.version, test.comment)
+		if *got != test.want {
+			t.Errorf("parseMySQLServerInfo(%q, %q) got %+v, want %+v", test.version, test.comment, *got, test.want)
+		}
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version string
+		major   int
+		minor   int
+		want    bool
+	}{
+		{"8.0.25", 8, 0, true},
+		{"5.7.34", 8, 0, false},
+		{"10.6.4", 10, 6, true},
+		{"10.5.9", 10, 6, false},
+		{"11.0", 10, 6, true},
+		{"unknown", 5, 0, false},
+	}
+
+	for _, test := range tests {
+		if got := versionAtLeast(test.version, test.major, test.minor); got != test.want {
+			t.Errorf("versionAtLeast(%q, %d, %d) got %v, want %v", test.version, test.major, test.minor, got, test.want)
+		}
+	}
+}
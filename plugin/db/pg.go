@@ -0,0 +1,1498 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+//go:embed pg_migration_schema.sql
+var pgMigrationSchema string
+
+var (
+	_ Driver = (*PostgresDriver)(nil)
+)
+
+const (
+	// The database we connect to if the caller doesn't specify one. This is also the database
+	// hosting the "bytebase" schema to track the migration history of the whole instance.
+	pgDefaultDatabase = "postgres"
+	pgDefaultPort     = "5432"
+	// How often to retry the advisory lock of the migration namespace.
+	pgMigrationLockRetryInterval = 500 * time.Millisecond
+)
+
+var (
+	pgSystemDatabaseList = []string{
+		"'template0'",
+		"'template1'",
+	}
+	pgExcludedSchemaList = []string{
+		"'pg_catalog'",
+		"'information_schema'",
+		// Skip our internal "bytebase" schema
+		"'bytebase'",
+	}
+)
+
+func init() {
+	register(Postgres, newPostgresDriver, Capability{
+		TransactionalDDL: true,
+		UserAndGrant:     true,
+		BackupRestore:    false,
+		OnlineDDL:        false,
+		CreateDatabase:   true,
+		IndexUsage:       true,
+	})
+}
+
+type PostgresDriver struct {
+	l             *zap.Logger
+	connectionCtx ConnectionContext
+	config        ConnectionConfig
+
+	db *sql.DB
+	// migrationDB connects to the database hosting the "bytebase" schema. It's the same as db
+	// if the driver is opened against the default database.
+	// Guarded by migrationDBMu since the driver may be shared by concurrent callers.
+	migrationDBMu sync.Mutex
+	migrationDB   *sql.DB
+}
+
+func newPostgresDriver(config DriverConfig) Driver {
+	return &PostgresDriver{
+		l: config.Logger,
+	}
+}
+
+func (driver *PostgresDriver) open(config ConnectionConfig, ctx ConnectionContext) (Driver, error) {
+	if config.Port == "" {
+		config.Port = pgDefaultPort
+	}
+	if config.Database == "" {
+		config.Database = pgDefaultDatabase
+	}
+
+	driver.l.Debug("Opening Postgres driver",
+		zap.String("host", config.Host),
+		zap.String("port", config.Port),
+		zap.String("database", config.Database),
+		zap.String("environment", ctx.EnvironmentName),
+		zap.String("instance", ctx.InstanceName),
+	)
+	db, err := sql.Open("postgres", pgDSN(config, config.Database))
+	if err != nil {
+		return nil, err
+	}
+	setConnectionLimit(db, config.MaxOpenConns, config.MaxIdleConns)
+	driver.db = db
+	driver.config = config
+	driver.connectionCtx = ctx
+
+	return driver, nil
+}
+
+func (driver *PostgresDriver) Close(ctx context.Context) error {
+	driver.migrationDBMu.Lock()
+	defer driver.migrationDBMu.Unlock()
+	if driver.migrationDB != nil && driver.migrationDB != driver.db {
+		if err := driver.migrationDB.Close(); err != nil {
+			driver.db.Close()
+			return err
+		}
+	}
+	return driver.db.Close()
+}
+
+func (driver *PostgresDriver) Ping(ctx context.Context) error {
+	return driver.db.PingContext(ctx)
+}
+
+func (driver *PostgresDriver) GetServerInfo(ctx context.Context) (*ServerInfo, error) {
+	query := "SHOW server_version"
+	var version string
+	if err := driver.db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+
+	// The version may carry the distribution info, e.g. "13.3 (Debian 13.3-1.pgdg100+1)"
+	return &ServerInfo{
+		Flavor:  FlavorPostgres,
+		Version: strings.Fields(version)[0],
+	}, nil
+}
+
+func (driver *PostgresDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSchema, error) {
+	userList, err := driver.getUserList(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Query db info
+	where := fmt.Sprintf("datname NOT IN (%s) AND datistemplate = false", strings.Join(pgSystemDatabaseList, ", "))
+	query := `
+		SELECT
+			datname,
+			pg_encoding_to_char(encoding),
+			datcollate
+		FROM pg_database
+		WHERE ` + where
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	schemaList := make([]*DBSchema, 0)
+	for rows.Next() {
+		var schema DBSchema
+		if err := rows.Scan(
+			&schema.Name,
+			&schema.CharacterSet,
+			&schema.Collation,
+		); err != nil {
+			return nil, nil, err
+		}
+		schemaList = append(schemaList, &schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Unlike MySQL, Postgres can't query across databases, so we have to connect to each database
+	// to fetch its tables, views, routines and triggers.
+	for _, schema := range schemaList {
+		if err := driver.syncDatabaseSchema(ctx, schema); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return userList, schemaList, nil
+}
+
+func (driver *PostgresDriver) getUserList(ctx context.Context) ([]*DBUser, error) {
+	// Query user info
+	query := `
+		SELECT
+			rolname,
+			rolsuper,
+			rolinherit,
+			rolcreaterole,
+			rolcreatedb,
+			rolcanlogin,
+			rolreplication,
+			rolbypassrls
+		FROM pg_catalog.pg_roles
+		WHERE rolname NOT LIKE 'pg_%'
+	`
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	userList := make([]*DBUser, 0)
+	for rows.Next() {
+		var name string
+		var super, inherit, createRole, createDB, canLogin, replication, bypassRLS bool
+		if err := rows.Scan(
+			&name,
+			&super,
+			&inherit,
+			&createRole,
+			&createDB,
+			&canLogin,
+			&replication,
+			&bypassRLS,
+		); err != nil {
+			return nil, err
+		}
+
+		// Mimic the "Attributes" column of psql \du
+		attributeList := []string{}
+		if super {
+			attributeList = append(attributeList, "Superuser")
+		}
+		if !inherit {
+			attributeList = append(attributeList, "No inheritance")
+		}
+		if createRole {
+			attributeList = append(attributeList, "Create role")
+		}
+		if createDB {
+			attributeList = append(attributeList, "Create DB")
+		}
+		if !canLogin {
+			attributeList = append(attributeList, "Cannot login")
+		}
+		if replication {
+			attributeList = append(attributeList, "Replication")
+		}
+		if bypassRLS {
+			attributeList = append(attributeList, "Bypass RLS")
+		}
+
+		userList = append(userList, &DBUser{
+			Name:  name,
+			Grant: strings.Join(attributeList, " "),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return userList, nil
+}
+
+// syncDatabaseSchema fetches the objects across all the user schemas of the database.
+// Objects outside the "public" schema are named as {{schema}}.{{name}}.
+func (driver *PostgresDriver) syncDatabaseSchema(ctx context.Context, schema *DBSchema) error {
+	db := driver.db
+	if schema.Name != driver.config.Database {
+		var err error
+		db, err = driver.openTemporaryDB(schema.Name)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+	}
+
+	var err error
+	if schema.TableList, err = pgGetTableList(ctx, db); err != nil {
+		return err
+	}
+	if schema.ViewList, err = pgGetViewList(ctx, db); err != nil {
+		return err
+	}
+	if schema.RoutineList, err = pgGetRoutineList(ctx, db); err != nil {
+		return err
+	}
+	if schema.TriggerList, err = pgGetTriggerList(ctx, db); err != nil {
+		return err
+	}
+	// Postgres doesn't have scheduled events.
+	return nil
+}
+
+// pgQualifiedName returns {{schema}}.{{name}} for objects outside the "public" schema.
+func pgQualifiedName(schemaName string, name string) string {
+	if schemaName == "public" {
+		return name
+	}
+	return fmt.Sprintf("%s.%s", schemaName, name)
+}
+
+func pgGetTableList(ctx context.Context, db *sql.DB) ([]DBTable, error) {
+	// Query index info
+	query := `
+		SELECT
+			n.nspname,
+			t.relname,
+			i.relname,
+			pg_get_indexdef(ix.indexrelid, k.n, true),
+			k.n,
+			am.amname,
+			ix.indisunique,
+			COALESCE(obj_description(i.oid, 'pg_class'), '')
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_am am ON am.oid = i.relam
+		CROSS JOIN LATERAL generate_series(1, ix.indnatts) AS k(n)
+		WHERE ` + pgSchemaWhere("n.nspname")
+	indexRows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer indexRows.Close()
+
+	// schemaName.tableName -> indexList map
+	indexMap := make(map[string][]DBIndex)
+	for indexRows.Next() {
+		var schemaName string
+		var tableName string
+		var index DBIndex
+		if err := indexRows.Scan(
+			&schemaName,
+			&tableName,
+			&index.Name,
+			&index.Expression,
+			&index.Position,
+			&index.Type,
+			&index.Unique,
+			&index.Comment,
+		); err != nil {
+			return nil, err
+		}
+		// Postgres doesn't support invisible index.
+		index.Visible = true
+
+		key := fmt.Sprintf("%s.%s", schemaName, tableName)
+		indexMap[key] = append(indexMap[key], index)
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Query column info
+	query = `
+		SELECT
+			table_schema,
+			table_name,
+			column_name,
+			ordinal_position,
+			column_default,
+			is_nullable,
+			CASE WHEN character_maximum_length IS NULL THEN data_type ELSE data_type || '(' || character_maximum_length || ')' END,
+			COALESCE(character_set_name, ''),
+			COALESCE(collation_name, ''),
+			COALESCE(col_description(format('%I.%I', table_schema, table_name)::regclass, ordinal_position), '')
+		FROM information_schema.columns
+		WHERE ` + pgSchemaWhere("table_schema")
+	columnRows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer columnRows.Close()
+
+	// schemaName.tableName -> columnList map
+	columnMap := make(map[string][]DBColumn)
+	for columnRows.Next() {
+		var schemaName string
+		var tableName string
+		var nullable string
+		var defaultStr sql.NullString
+		var column DBColumn
+		if err := columnRows.Scan(
+			&schemaName,
+			&tableName,
+			&column.Name,
+			&column.Position,
+			&defaultStr,
+			&nullable,
+			&column.Type,
+			&column.CharacterSet,
+			&column.Collation,
+			&column.Comment,
+		); err != nil {
+			return nil, err
+		}
+
+		if defaultStr.Valid {
+			column.Default = &defaultStr.String
+		}
+		column.Nullable = nullable == "YES"
+
+		key := fmt.Sprintf("%s.%s", schemaName, tableName)
+		columnMap[key] = append(columnMap[key], column)
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Query table info
+	query = `
+		SELECT
+			n.nspname,
+			c.relname,
+			CASE c.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' ELSE 'BASE TABLE' END,
+			COALESCE(am.amname, ''),
+			GREATEST(c.reltuples, 0)::BIGINT,
+			pg_table_size(c.oid),
+			pg_indexes_size(c.oid),
+			COALESCE(array_to_string(c.reloptions, ','), ''),
+			COALESCE(obj_description(c.oid, 'pg_class'), '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_am am ON am.oid = c.relam
+		WHERE c.relkind IN ('r', 'p', 'v', 'm') AND ` + pgSchemaWhere("n.nspname")
+	tableRows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer tableRows.Close()
+
+	tableList := make([]DBTable, 0)
+	for tableRows.Next() {
+		var schemaName string
+		var table DBTable
+		if err := tableRows.Scan(
+			&schemaName,
+			&table.Name,
+			&table.Type,
+			&table.Engine,
+			&table.RowCount,
+			&table.DataSize,
+			&table.IndexSize,
+			&table.CreateOptions,
+			&table.Comment,
+		); err != nil {
+			return nil, err
+		}
+
+		key := fmt.Sprintf("%s.%s", schemaName, table.Name)
+		table.ColumnList = columnMap[key]
+		table.IndexList = indexMap[key]
+		table.Name = pgQualifiedName(schemaName, table.Name)
+
+		tableList = append(tableList, table)
+	}
+	if err := tableRows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tableList, func(i, j int) bool {
+		return tableList[i].Name < tableList[j].Name
+	})
+
+	return tableList, nil
+}
+
+func pgGetViewList(ctx context.Context, db *sql.DB) ([]DBView, error) {
+	query := `
+		SELECT
+			n.nspname,
+			c.relname,
+			pg_get_viewdef(c.oid, true),
+			COALESCE(obj_description(c.oid, 'pg_class'), '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('v', 'm') AND ` + pgSchemaWhere("n.nspname") + `
+		ORDER BY n.nspname, c.relname`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	viewList := make([]DBView, 0)
+	for rows.Next() {
+		var schemaName string
+		var view DBView
+		if err := rows.Scan(
+			&schemaName,
+			&view.Name,
+			&view.Definition,
+			&view.Comment,
+		); err != nil {
+			return nil, err
+		}
+		view.Name = pgQualifiedName(schemaName, view.Name)
+		viewList = append(viewList, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return viewList, nil
+}
+
+// pgGetRoutineList fetches the user defined functions and procedures, named with the argument types
+// to tell the overloaded ones apart, e.g. "add(integer, integer)".
+// Aggregates are skipped since pg_get_functiondef doesn't support them, so are the functions
+// created by extensions.
+func pgGetRoutineList(ctx context.Context, db *sql.DB) ([]DBRoutine, error) {
+	query := `
+		SELECT
+			n.nspname,
+			p.proname || '(' || pg_get_function_identity_arguments(p.oid) || ')',
+			CASE WHEN pg_get_function_result(p.oid) IS NULL THEN 'PROCEDURE' ELSE 'FUNCTION' END,
+			pg_get_functiondef(p.oid),
+			COALESCE(obj_description(p.oid, 'pg_proc'), '')
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE ` + pgSchemaWhere("n.nspname") + `
+			AND NOT EXISTS (SELECT 1 FROM pg_aggregate a WHERE a.aggfnoid = p.oid)
+			AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')
+		ORDER BY n.nspname, p.proname`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	routineList := make([]DBRoutine, 0)
+	for rows.Next() {
+		var schemaName string
+		var routine DBRoutine
+		if err := rows.Scan(
+			&schemaName,
+			&routine.Name,
+			&routine.Type,
+			&routine.Definition,
+			&routine.Comment,
+		); err != nil {
+			return nil, err
+		}
+		routine.Name = pgQualifiedName(schemaName, routine.Name)
+		routineList = append(routineList, routine)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return routineList, nil
+}
+
+// pgGetTriggerList fetches the user defined triggers, the timing and events are decoded from the tgtype bits.
+func pgGetTriggerList(ctx context.Context, db *sql.DB) ([]DBTrigger, error) {
+	query := `
+		SELECT
+			n.nspname,
+			t.tgname,
+			c.relname,
+			CASE WHEN t.tgtype::int & 2 > 0 THEN 'BEFORE' WHEN t.tgtype::int & 64 > 0 THEN 'INSTEAD OF' ELSE 'AFTER' END,
+			concat_ws(' OR ',
+				CASE WHEN t.tgtype::int & 4 > 0 THEN 'INSERT' END,
+				CASE WHEN t.tgtype::int & 16 > 0 THEN 'UPDATE' END,
+				CASE WHEN t.tgtype::int & 8 > 0 THEN 'DELETE' END,
+				CASE WHEN t.tgtype::int & 32 > 0 THEN 'TRUNCATE' END
+			),
+			pg_get_triggerdef(t.oid, true)
+		FROM pg_trigger t
+		JOIN pg_class c ON c.oid = t.tgrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE NOT t.tgisinternal AND ` + pgSchemaWhere("n.nspname") + `
+		ORDER BY n.nspname, c.relname, t.tgname`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	triggerList := make([]DBTrigger, 0)
+	for rows.Next() {
+		var schemaName string
+		var trigger DBTrigger
+		if err := rows.Scan(
+			&schemaName,
+			&trigger.Name,
+			&trigger.TableName,
+			&trigger.Timing,
+			&trigger.Event,
+			&trigger.Definition,
+		); err != nil {
+			return nil, err
+		}
+		trigger.TableName = pgQualifiedName(schemaName, trigger.TableName)
+		triggerList = append(triggerList, trigger)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return triggerList, nil
+}
+
+// pgSchemaWhere returns the condition to filter out the system and our internal schemas.
+func pgSchemaWhere(column string) string {
+	return fmt.Sprintf("%s NOT IN (%s) AND %s NOT LIKE 'pg_toast%%' AND %s NOT LIKE 'pg_temp%%'", column, strings.Join(pgExcludedSchemaList, ", "), column, column)
+}
+
+func (driver *PostgresDriver) Execute(ctx context.Context, statement string) error {
+	// CREATE DATABASE cannot run inside a transaction block.
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(statement)), "CREATE DATABASE") {
+		// The Postgres driver sends the cancel request itself when the context is done.
+		return execStatement(ctx, driver.db, statement, nil)
+	}
+
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	cancel, err := pgPrepareStatementTx(ctx, driver.db, tx)
+	if err != nil {
+		return err
+	}
+	if err := execStatement(ctx, tx, statement, cancel); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (driver *PostgresDriver) Query(ctx context.Context, statement string, limit int) (*QueryResult, error) {
+	tx, err := driver.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	cancel, err := pgPrepareStatementTx(ctx, driver.db, tx)
+	if err != nil {
+		return nil, err
+	}
+	return queryRows(ctx, Postgres, tx, statement, limit, cancel)
+}
+
+// pgPrepareStatementTx applies the statement timeout of ctx to tx begun on db, and returns the function canceling the
+// statement running in tx by pg_cancel_backend.
+func pgPrepareStatementTx(ctx context.Context, db *sql.DB, tx *sql.Tx) (cancelStatementFunc, error) {
+	var pid int
+	if err := tx.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+		return nil, err
+	}
+	if timeout := statementTimeout(ctx); timeout > 0 {
+		// SET LOCAL only lasts until the end of the transaction.
+		query := fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return nil, formatErrorWithQuery(err, query)
+		}
+	}
+	return func(ctx context.Context) error {
+		_, err := db.ExecContext(ctx, "SELECT pg_cancel_backend($1)", pid)
+		return err
+	}, nil
+}
+
+// getMigrationDB returns the connection to the database hosting the "bytebase" schema.
+func (driver *PostgresDriver) getMigrationDB() (*sql.DB, error) {
+	driver.migrationDBMu.Lock()
+	defer driver.migrationDBMu.Unlock()
+	if driver.migrationDB != nil {
+		return driver.migrationDB, nil
+	}
+
+	if driver.config.Database == pgDefaultDatabase {
+		driver.migrationDB = driver.db
+	} else {
+		db, err := sql.Open("postgres", pgDSN(driver.config, pgDefaultDatabase))
+		if err != nil {
+			return nil, err
+		}
+		// Split the connection limit of the driver, the migration holds the history transaction taking the lock on
+		// migrationDB, the statement transaction and its cancellation on db.
+		if maxOpenConns := driver.config.MaxOpenConns; maxOpenConns > 0 {
+			setConnectionLimit(db, maxOpenConns/2, 1)
+			setConnectionLimit(driver.db, maxOpenConns-maxOpenConns/2, 1)
+		}
+		driver.migrationDB = db
+	}
+	return driver.migrationDB, nil
+}
+
+// openTemporaryDB opens the database other than the one of the driver, the caller runs the queries one by one and
+// closes it after use. It takes a single connection so the driver stays close to its connection limit.
+func (driver *PostgresDriver) openTemporaryDB(database string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", pgDSN(driver.config, database))
+	if err != nil {
+		return nil, err
+	}
+	setConnectionLimit(db, 1, 1)
+	return db, nil
+}
+
+// getMigrationSchemaVersion returns the version of the bytebase migration schema, 0 if the schema doesn't exist.
+func (driver *PostgresDriver) getMigrationSchemaVersion(ctx context.Context) (int, error) {
+	migrationDB, err := driver.getMigrationDB()
+	if err != nil {
+		return 0, err
+	}
+
+	const query = `
+		SELECT
+			1
+		FROM information_schema.tables
+		WHERE table_schema = 'bytebase' AND table_name = 'migration_history'
+		`
+	rows, err := migrationDB.QueryContext(ctx, query)
+	if err != nil {
+		return 0, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return 0, nil
+	}
+
+	return findMigrationSchemaVersion(ctx, migrationDB)
+}
+
+func (driver *PostgresDriver) NeedsSetupMigration(ctx context.Context) (bool, error) {
+	version, err := driver.getMigrationSchemaVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return version < latestMigrationSchemaVersion, nil
+}
+
+func (driver *PostgresDriver) SetupMigrationIfNeeded(ctx context.Context) error {
+	version, err := driver.getMigrationSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	migrationDB, err := driver.getMigrationDB()
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		driver.l.Info("Bytebase migration schema not found, creating schema...",
+			zap.String("environment", driver.connectionCtx.EnvironmentName),
+			zap.String("database", driver.connectionCtx.InstanceName),
+		)
+
+		if err := pgExecuteTx(ctx, migrationDB, pgMigrationSchema); err != nil {
+			driver.l.Error("Failed to initialize migration schema.",
+				zap.Error(err),
+				zap.String("environment", driver.connectionCtx.EnvironmentName),
+				zap.String("database", driver.connectionCtx.InstanceName),
+			)
+			return formatErrorWithQuery(err, pgMigrationSchema)
+		}
+		driver.l.Info("Successfully created migration schema.",
+			zap.String("environment", driver.connectionCtx.EnvironmentName),
+			zap.String("database", driver.connectionCtx.InstanceName),
+		)
+	} else if version < latestMigrationSchemaVersion {
+		execute := func(ctx context.Context, statement string) error {
+			return pgExecuteTx(ctx, migrationDB, statement)
+		}
+		if err := upgradeMigrationSchema(ctx, driver.l, driver.connectionCtx, "pg", version, execute); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (driver *PostgresDriver) ExecuteMigration(ctx context.Context, m *MigrationInfo, statement string, execution *MigrationExecution) error {
+	migrationDB, err := driver.getMigrationDB()
+	if err != nil {
+		return err
+	}
+
+	// The migration history may live in a different database from the one we apply the statement.
+	// Postgres supports transactional DDL, so we hold both transactions open and only commit them
+	// after both the statement and the history record succeed.
+	migrationTx, err := migrationDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer migrationTx.Rollback()
+
+	// Concurrent DDL of the same namespace from another transaction isn't serialized by the history check, the lock
+	// prevents the concurrent migrations of the same namespace.
+	if err := acquireMigrationLock(ctx, migrationTx, m); err != nil {
+		return err
+	}
+
+	tx := migrationTx
+	if migrationDB != driver.db {
+		tx, err = driver.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+	}
+	cancel, err := pgPrepareStatementTx(ctx, driver.db, tx)
+	if err != nil {
+		return err
+	}
+
+	startedTs := time.Now().Unix()
+
+	// Phase 1 - Precheck before executing migration
+	sequence, err := checkMigrationPrecondition(ctx, migrationTx, m, pgMigrationHistoryQueries)
+	if err != nil {
+		return err
+	}
+
+	// Phase 2 - Executing migration
+	if err := executeMigrationStatement(ctx, tx, statement, execution, cancel); err != nil {
+		return err
+	}
+
+	// Phase 3 - Record migration
+	executionDuration := time.Now().Unix() - startedTs
+	if err := insertPgMigrationHistory(ctx, migrationTx, m, sequence, statement, executionDuration); err != nil {
+		return err
+	}
+
+	if tx == migrationTx {
+		return migrationTx.Commit()
+	}
+	// The statement and the history are committed in two transactions, the statement first since the history claims
+	// it's applied. The history is recorded again if the commit fails, regardless of ctx which may be done by the
+	// statement timeout.
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if err := migrationTx.Commit(); err != nil {
+		driver.l.Warn("Failed to commit migration history, retrying",
+			zap.String("namespace", m.Namespace),
+			zap.String("version", m.Version),
+			zap.Error(err),
+		)
+		if err := driver.retryInsertMigrationHistory(context.Background(), migrationDB, m, sequence, statement, executionDuration); err != nil {
+			return &MigrationHistoryError{Namespace: m.Namespace, Version: m.Version, Err: err}
+		}
+	}
+	return nil
+}
+
+// retryInsertMigrationHistory records the migration history in a new transaction, taking the migration lock again
+// since it's released together with the failed transaction.
+func (driver *PostgresDriver) retryInsertMigrationHistory(ctx context.Context, migrationDB *sql.DB, m *MigrationInfo, sequence int, statement string, executionDuration int64) error {
+	tx, err := migrationDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := acquireMigrationLock(ctx, tx, m); err != nil {
+		return err
+	}
+
+	if err := insertPgMigrationHistory(ctx, tx, m, sequence, statement, executionDuration); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertPgMigrationHistory(ctx context.Context, tx *sql.Tx, m *MigrationInfo, sequence int, statement string, executionDuration int64) error {
+	const query = `
+		INSERT INTO bytebase.migration_history (
+			created_by,
+			created_ts,
+			updated_by,
+			updated_ts,
+			namespace,
+			sequence,
+			engine,
+			type,
+			version,
+			description,
+			statement,
+			rollback_statement,
+			execution_duration,
+			issue_id,
+			payload
+		)
+		VALUES ($1, EXTRACT(epoch from NOW()), $2, EXTRACT(epoch from NOW()), $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	if _, err := tx.ExecContext(ctx, query,
+		m.Creator,
+		m.Creator,
+		m.Namespace,
+		sequence,
+		m.Engine,
+		m.Type,
+		m.Version,
+		m.Description,
+		statement,
+		m.RollbackStatement,
+		executionDuration,
+		m.IssueId,
+		m.Payload,
+	); err != nil {
+		return formatErrorWithQuery(err, query)
+	}
+	return nil
+}
+
+// acquireMigrationLock takes the transaction level advisory lock of the migration namespace in tx, which is released
+// when tx ends. The lock is taken in the database hosting the "bytebase" schema, which is the same one for all the
+// migrations of the instance. Unlike the session level lock, it doesn't hold another connection of the driver, so the
+// concurrent migrations of the same driver queue behind the lock rather than the connection limit.
+func acquireMigrationLock(ctx context.Context, tx *sql.Tx, m *MigrationInfo) error {
+	key := migrationLockKey(m.Namespace)
+	timeout := migrationLockTimeout(m)
+	deadline := time.Now().Add(timeout)
+	for {
+		var acquired bool
+		if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", key).Scan(&acquired); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", formatError(err))
+		}
+		if acquired {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			holder := findPgMigrationLockHolder(ctx, tx, key)
+			return &MigrationLockError{Namespace: m.Namespace, Timeout: timeout, Holder: holder}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pgMigrationLockRetryInterval):
+		}
+	}
+}
+
+// findPgMigrationLockHolder describes the session holding the advisory lock, or returns empty if unknown.
+// The 64-bit key is stored as classid (high 32 bits) and objid (low 32 bits) with objsubid 1 in pg_locks.
+func findPgMigrationLockHolder(ctx context.Context, tx *sql.Tx, key int64) string {
+	query := `
+		SELECT a.pid, COALESCE(a.usename, ''), COALESCE(host(a.client_addr), ''), COALESCE(a.application_name, '')
+		FROM pg_locks l JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.granted AND l.objsubid = 1
+			AND l.database = (SELECT oid FROM pg_database WHERE datname = current_database())
+			AND l.classid::bigint = $1 AND l.objid::bigint = $2`
+	var pid int
+	var user, clientAddr, application string
+	if err := tx.QueryRowContext(ctx, query, int64(uint64(key)>>32), int64(uint64(key)&0xffffffff)).Scan(&pid, &user, &clientAddr, &application); err != nil {
+		return ""
+	}
+	holder := fmt.Sprintf("pid %d (user %q", pid, user)
+	if clientAddr != "" {
+		holder += fmt.Sprintf(" from %s", clientAddr)
+	}
+	if application != "" {
+		holder += fmt.Sprintf(", application %q", application)
+	}
+	return holder + ")"
+}
+
+func (driver *PostgresDriver) DryRunMigration(ctx context.Context, m *MigrationInfo, statement string, schema *DBSchema) ([]*DryRunError, error) {
+	migrationDB, err := driver.getMigrationDB()
+	if err != nil {
+		return nil, err
+	}
+
+	var errorList []*DryRunError
+	preconditionError, err := dryRunPrecondition(ctx, migrationDB, m, pgMigrationHistoryQueries)
+	if err != nil {
+		return nil, err
+	}
+	if preconditionError != nil {
+		errorList = append(errorList, preconditionError)
+	}
+
+	// EXPLAIN without ANALYZE doesn't execute the statement.
+	explain := func(ctx context.Context, statement string) error {
+		return explainQuery(ctx, driver.db, "EXPLAIN "+statement)
+	}
+	return append(errorList, dryRunStatement(ctx, statement, schema, []string{"public"}, explain)...), nil
+}
+
+func (driver *PostgresDriver) FindMigrationHistoryList(ctx context.Context, find *MigrationHistoryFind) ([]*MigrationHistory, error) {
+	migrationDB, err := driver.getMigrationDB()
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := find.ID; v != nil {
+		where, args = append(where, fmt.Sprintf("id = $%d", len(args)+1)), append(args, *v)
+	}
+	if v := find.Database; v != nil {
+		where, args = append(where, fmt.Sprintf("namespace = $%d", len(args)+1)), append(args, *v)
+	}
+	if v := find.Version; v != nil {
+		where, args = append(where, fmt.Sprintf("version = $%d", len(args)+1)), append(args, *v)
+	}
+
+	var query = `
+		SELECT
+			id,
+			created_by,
+			created_ts,
+			updated_by,
+			updated_ts,
+			namespace,
+			sequence,
+			engine,
+			type,
+			version,
+			description,
+			statement,
+			rollback_statement,
+			execution_duration,
+			issue_id,
+			payload
+		FROM bytebase.migration_history
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY created_ts DESC`
+	if v := find.Limit; v != nil {
+		query += fmt.Sprintf(" LIMIT %d", *v)
+	}
+
+	rows, err := migrationDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	// Iterate over result set and deserialize rows into list.
+	list := make([]*MigrationHistory, 0)
+	for rows.Next() {
+		var history MigrationHistory
+		if err := rows.Scan(
+			&history.ID,
+			&history.Creator,
+			&history.CreatedTs,
+			&history.Updater,
+			&history.UpdatedTs,
+			&history.Namespace,
+			&history.Sequence,
+			&history.Engine,
+			&history.Type,
+			&history.Version,
+			&history.Description,
+			&history.Statement,
+			&history.RollbackStatement,
+			&history.ExecutionDuration,
+			&history.IssueId,
+			&history.Payload,
+		); err != nil {
+			return nil, err
+		}
+
+		list = append(list, &history)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func pgExecuteTx(ctx context.Context, db *sql.DB, statement string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, statement); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (driver *PostgresDriver) ImportMigrationHistory(ctx context.Context, imp *MigrationHistoryImport) (int, error) {
+	historyList, err := readImportedMigrationHistory(ctx, driver.db, imp, "CAST(EXTRACT(epoch FROM %s) AS BIGINT)")
+	if err != nil {
+		return 0, err
+	}
+
+	migrationDB, err := driver.getMigrationDB()
+	if err != nil {
+		return 0, err
+	}
+	tx, err := migrationDB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := acquireMigrationLock(ctx, tx, imp.migrationLockInfo()); err != nil {
+		return 0, err
+	}
+
+	count, err := importMigrationHistory(ctx, tx, historyList, pgMigrationHistoryQueries, pgImportedMigrationHistoryInsert)
+	if err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+// GrantDatabase grants the privileges on all the tables of the user schemas in the database, including the tables
+// created by the driver user afterwards. The driver must connect to the database.
+func (driver *PostgresDriver) GrantDatabase(ctx context.Context, grant *DatabaseGrant) (*DBUser, error) {
+	if err := ValidateDatabaseGrant(Postgres, grant); err != nil {
+		return nil, err
+	}
+	exists, schemaList, err := driver.prepareDatabaseGrant(ctx, grant)
+	if err != nil {
+		return nil, err
+	}
+	if !exists && grant.Password == "" {
+		return nil, fmt.Errorf("user %q doesn't exist, password is required to create the user", grant.Username)
+	}
+
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := execGrantStatementList(ctx, tx, pgGrantStatementList(grant, exists, schemaList), grant.Password); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return driver.getUser(ctx, grant.Username)
+}
+
+func (driver *PostgresDriver) RevokeDatabase(ctx context.Context, grant *DatabaseGrant) (*DBUser, error) {
+	if err := ValidateDatabaseGrant(Postgres, grant); err != nil {
+		return nil, err
+	}
+	exists, schemaList, err := driver.prepareDatabaseGrant(ctx, grant)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if grant.DropUser {
+			return &DBUser{Name: grant.Username}, nil
+		}
+		return nil, fmt.Errorf("user %q doesn't exist", grant.Username)
+	}
+
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if grant.DropUser {
+		// The sessions of the user survive dropping the user.
+		query := "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE usename = $1 AND pid <> pg_backend_pid()"
+		if _, err := tx.ExecContext(ctx, query, grant.Username); err != nil {
+			return nil, formatErrorWithQuery(err, query)
+		}
+	}
+	if err := execGrantStatementList(ctx, tx, pgRevokeStatementList(grant, schemaList), ""); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if grant.DropUser {
+		return &DBUser{Name: grant.Username}, nil
+	}
+	return driver.getUser(ctx, grant.Username)
+}
+
+func (driver *PostgresDriver) FindIndexUsage(ctx context.Context, database string) (*IndexUsageStats, error) {
+	// The statistics are per database.
+	db := driver.db
+	if database != driver.config.Database {
+		var err error
+		db, err = driver.openTemporaryDB(database)
+		if err != nil {
+			return nil, err
+		}
+		defer db.Close()
+	}
+
+	stats := &IndexUsageStats{}
+	query := `
+		SELECT EXTRACT(EPOCH FROM COALESCE(stats_reset, pg_postmaster_start_time()))::BIGINT
+		FROM pg_stat_database
+		WHERE datname = current_database()`
+	if err := db.QueryRowContext(ctx, query).Scan(&stats.SinceTs); err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+
+	query = `
+		SELECT
+			schemaname,
+			relname,
+			indexrelname,
+			idx_scan
+		FROM pg_stat_user_indexes
+		WHERE ` + pgSchemaWhere("schemaname")
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schemaName string
+		var usage IndexUsage
+		if err := rows.Scan(
+			&schemaName,
+			&usage.TableName,
+			&usage.IndexName,
+			&usage.ReadCount,
+		); err != nil {
+			return nil, err
+		}
+		usage.TableName = pgQualifiedName(schemaName, usage.TableName)
+		stats.UsageList = append(stats.UsageList, &usage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// prepareDatabaseGrant returns whether the user exists and the user schemas of the database to grant on.
+func (driver *PostgresDriver) prepareDatabaseGrant(ctx context.Context, grant *DatabaseGrant) (bool, []string, error) {
+	if grant.Database != driver.config.Database {
+		return false, nil, fmt.Errorf("cannot grant on database %q with the driver connecting database %q", grant.Database, driver.config.Database)
+	}
+
+	var exists bool
+	query := "SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = $1)"
+	if err := driver.db.QueryRowContext(ctx, query, grant.Username).Scan(&exists); err != nil {
+		return false, nil, formatErrorWithQuery(err, query)
+	}
+
+	query = fmt.Sprintf("SELECT nspname FROM pg_catalog.pg_namespace WHERE %s ORDER BY nspname", pgSchemaWhere("nspname"))
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return false, nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var schemaList []string
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			return false, nil, err
+		}
+		schemaList = append(schemaList, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return false, nil, err
+	}
+	return exists, schemaList, nil
+}
+
+func pgGrantStatementList(grant *DatabaseGrant, exists bool, schemaList []string) []string {
+	user := pgQuoteIdentifier(grant.Username)
+	privileges := strings.Join(grant.PrivilegeList, ", ")
+	var stmtList []string
+	if grant.Password != "" {
+		verb := "CREATE"
+		if exists {
+			verb = "ALTER"
+		}
+		stmtList = append(stmtList, fmt.Sprintf("%s ROLE %s WITH LOGIN PASSWORD %s", verb, user, quoteStringLiteral(grant.Password)))
+	}
+	stmtList = append(stmtList, fmt.Sprintf("GRANT CONNECT ON DATABASE %s TO %s", pgQuoteIdentifier(grant.Database), user))
+	for _, schema := range schemaList {
+		schema = pgQuoteIdentifier(schema)
+		stmtList = append(stmtList,
+			fmt.Sprintf("GRANT USAGE ON SCHEMA %s TO %s", schema, user),
+			fmt.Sprintf("GRANT %s ON ALL TABLES IN SCHEMA %s TO %s", privileges, schema, user),
+			// Only applies to the tables created by the driver user, e.g. by the migrations.
+			fmt.Sprintf("ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT %s ON TABLES TO %s", schema, privileges, user),
+		)
+	}
+	return stmtList
+}
+
+func pgRevokeStatementList(grant *DatabaseGrant, schemaList []string) []string {
+	user := pgQuoteIdentifier(grant.Username)
+	privileges := strings.Join(grant.PrivilegeList, ", ")
+	if grant.DropUser {
+		privileges = "ALL"
+	}
+	var stmtList []string
+	for _, schema := range schemaList {
+		schema = pgQuoteIdentifier(schema)
+		stmtList = append(stmtList,
+			fmt.Sprintf("REVOKE %s ON ALL TABLES IN SCHEMA %s FROM %s", privileges, schema, user),
+			fmt.Sprintf("ALTER DEFAULT PRIVILEGES IN SCHEMA %s REVOKE %s ON TABLES FROM %s", schema, privileges, user),
+		)
+		if grant.DropUser {
+			stmtList = append(stmtList, fmt.Sprintf("REVOKE ALL ON SCHEMA %s FROM %s", schema, user))
+		}
+	}
+	if grant.DropUser {
+		// DROP ROLE fails if the user still owns objects or has privileges in the other databases, which we leave
+		// to the DBA instead of dropping them.
+		stmtList = append(stmtList,
+			fmt.Sprintf("REVOKE ALL ON DATABASE %s FROM %s", pgQuoteIdentifier(grant.Database), user),
+			fmt.Sprintf("DROP ROLE %s", user),
+		)
+	}
+	return stmtList
+}
+
+// getUser returns the user with the attributes same as SyncSchema.
+func (driver *PostgresDriver) getUser(ctx context.Context, name string) (*DBUser, error) {
+	userList, err := driver.getUserList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range userList {
+		if user.Name == name {
+			return user, nil
+		}
+	}
+	return nil, fmt.Errorf("user %q not found", name)
+}
+
+func pgQuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// pgImportedMigrationHistoryInsert is importedMigrationHistoryInsert for Postgres.
+const pgImportedMigrationHistoryInsert = `
+	INSERT INTO bytebase.migration_history (
+		created_by,
+		created_ts,
+		updated_by,
+		updated_ts,
+		namespace,
+		sequence,
+		engine,
+		type,
+		version,
+		description,
+		statement,
+		rollback_statement,
+		execution_duration,
+		issue_id,
+		payload
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+`
+
+var pgMigrationHistoryQueries = migrationHistoryQueries{
+	checkDuplicateVersion:  pgCheckDuplicateVersion,
+	checkOutofOrderVersion: pgCheckOutofOrderVersion,
+	findVersionList:        pgFindVersionList,
+	findBaseline:           pgFindBaseline,
+	findNextSequence:       pgFindNextSequence,
+}
+
+func pgFindBaseline(ctx context.Context, tx *sql.Tx, namespace string) (bool, error) {
+	query := `
+		SELECT 1 FROM bytebase.migration_history WHERE namespace = $1 AND (type = 'BASELINE' OR engine IN ('FLYWAY', 'LIQUIBASE'))
+	`
+	row, err := tx.QueryContext(ctx, query, namespace)
+	if err != nil {
+		return false, formatErrorWithQuery(err, query)
+	}
+	defer row.Close()
+
+	if !row.Next() {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func pgCheckDuplicateVersion(ctx context.Context, tx *sql.Tx, namespace string, engine MigrationEngine, version string) (bool, error) {
+	query := `
+		SELECT 1 FROM bytebase.migration_history WHERE namespace = $1 AND engine = $2 AND version = $3
+	`
+	row, err := tx.QueryContext(ctx, query, namespace, engine.String(), version)
+	if err != nil {
+		return false, formatErrorWithQuery(err, query)
+	}
+	defer row.Close()
+
+	if row.Next() {
+		return true, nil
+	}
+	return false, nil
+}
+
+func pgCheckOutofOrderVersion(ctx context.Context, tx *sql.Tx, namespace string, engine MigrationEngine, version string) (*string, error) {
+	// Use the "C" collation so the comparison is byte-wise, consistent with STRCMP on the MySQL side.
+	query := `
+		SELECT MIN(version) FROM bytebase.migration_history WHERE namespace = $1 AND engine = $2 AND version COLLATE "C" > $3
+	`
+	row, err := tx.QueryContext(ctx, query, namespace, engine.String(), version)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer row.Close()
+
+	var minVersion sql.NullString
+	row.Next()
+	if err := row.Scan(&minVersion); err != nil {
+		return nil, err
+	}
+
+	if minVersion.Valid {
+		return &minVersion.String, nil
+	}
+
+	return nil, nil
+}
+
+func pgFindVersionList(ctx context.Context, tx *sql.Tx, namespace string, engine MigrationEngine) ([]string, error) {
+	query := `
+		SELECT version FROM bytebase.migration_history WHERE namespace = $1 AND engine = $2
+	`
+	rows, err := tx.QueryContext(ctx, query, namespace, engine.String())
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	var versionList []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versionList = append(versionList, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return versionList, nil
+}
+
+func pgFindNextSequence(ctx context.Context, tx *sql.Tx, namespace string, requireBaseline bool) (int, error) {
+	query := `
+		SELECT MAX(sequence) + 1 FROM bytebase.migration_history WHERE namespace = $1
+	`
+	row, err := tx.QueryContext(ctx, query, namespace)
+	if err != nil {
+		return -1, formatErrorWithQuery(err, query)
+	}
+	defer row.Close()
+
+	var sequence sql.NullInt32
+	row.Next()
+	if err := row.Scan(&sequence); err != nil {
+		return -1, err
+	}
+
+	if !sequence.Valid {
+		// Returns 1 if we haven't applied any migration for this namespace and doesn't require baselining
+		if !requireBaseline {
+			return 1, nil
+		}
+
+		// This should not happen normally since we already check the baselining exist beforehand. Just in case.
+		return -1, fmt.Errorf("unable to generate next migration_sequence, no migration hisotry found for %q, do you forget to baselining?", namespace)
+	}
+
+	return int(sequence.Int32), nil
+}
+
+// pgDSN builds the key/value connection string for lib/pq.
+func pgDSN(config ConnectionConfig, database string) string {
+	m := []struct {
+		key   string
+		value string
+	}{
+		{"host", config.Host},
+		{"port", config.Port},
+		{"user", config.Username},
+		{"password", config.Password},
+		{"dbname", database},
+	}
+
+	tokens := []string{"sslmode=disable"}
+	for _, kv := range m {
+		if kv.value != "" {
+			tokens = append(tokens, fmt.Sprintf("%s=%s", kv.key, pgQuoteDSNValue(kv.value)))
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// pgQuoteDSNValue quotes the value so that it can contain spaces and quotes.
+func pgQuoteDSNValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
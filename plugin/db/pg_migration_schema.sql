@@ -0,0 +1,72 @@
+-- This is the bytebase schema to track migration info for Postgres
+-- Create a schema called bytebase in the default database of the instance
+CREATE SCHEMA bytebase;
+
+CREATE TABLE bytebase.setting (
+    id SERIAL PRIMARY KEY,
+    created_by TEXT NOT NULL,
+    created_ts BIGINT NOT NULL,
+    updated_by TEXT NOT NULL,
+    updated_ts BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    value TEXT NOT NULL,
+    description TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX bytebase_idx_unique_setting_name ON bytebase.setting (name);
+
+-- Insert schema version 2
+INSERT INTO
+    bytebase.setting (
+        created_by,
+        created_ts,
+        updated_by,
+        updated_ts,
+        name,
+        value,
+        description
+    )
+VALUES
+    (
+        'bytebase',
+        EXTRACT(epoch from NOW()),
+        'bytebase',
+        EXTRACT(epoch from NOW()),
+        'bb.schema.version',
+        '4',
+        'Schema version'
+    );
+
+-- Create migration_history table
+CREATE TABLE bytebase.migration_history (
+    id SERIAL PRIMARY KEY,
+    created_by TEXT NOT NULL,
+    created_ts BIGINT NOT NULL,
+    updated_by TEXT NOT NULL,
+    updated_ts BIGINT NOT NULL,
+    -- Allows granular tracking of migration history (e.g If an application manages schemas for a multi-tenant service and each tenant has its own schema, that application can use namespace to record the tenant name to track the per-tenant schema migration)
+    -- Since bytebase also manages different application databases from an instance, it leverages this field to track each database migration history.
+    namespace TEXT NOT NULL,
+    -- Used to detect out of order migration together with 'namespace' and 'version' column.
+    sequence INTEGER NOT NULL CHECK (sequence >= 0),
+    -- We call it engine because the history may be imported from other migration tools, e.g. Flyway and Liquibase.
+    engine TEXT NOT NULL CHECK (engine IN ('UI', 'VCS', 'FLYWAY', 'LIQUIBASE')),
+    type TEXT NOT NULL CHECK (type IN ('BASELINE', 'SQL', 'BRANCH', 'ROLLBACK', 'DATA')),
+    version TEXT NOT NULL,
+    description TEXT NOT NULL,
+    -- Recorded the migration statement
+    statement TEXT NOT NULL,
+    -- Recorded the statement reverting the migration, empty if not provided
+    rollback_statement TEXT NOT NULL,
+    execution_duration INTEGER NOT NULL,
+    issue_id TEXT NOT NULL,
+    payload TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX bytebase_idx_unique_migration_history_namespace_sequence ON bytebase.migration_history (namespace, sequence);
+
+CREATE UNIQUE INDEX bytebase_idx_unique_migration_history_namespace_engine_version ON bytebase.migration_history (namespace, engine, version);
+
+CREATE INDEX bytebase_idx_migration_history_namespace_engine_type ON bytebase.migration_history (namespace, engine, type);
+
+CREATE INDEX bytebase_idx_migration_history_namespace_created ON bytebase.migration_history (namespace, created_ts);
@@ -0,0 +1,12 @@
+-- Upgrade the bytebase migration schema from version 1 to 2 for Postgres
+-- Record the rollback statement and allow the ROLLBACK migration type.
+ALTER TABLE bytebase.migration_history DROP CONSTRAINT migration_history_type_check;
+
+ALTER TABLE bytebase.migration_history ADD CONSTRAINT migration_history_type_check CHECK (type IN ('BASELINE', 'SQL', 'BRANCH', 'ROLLBACK'));
+
+-- Existing rows have no rollback statement.
+ALTER TABLE bytebase.migration_history ADD COLUMN rollback_statement TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE bytebase.migration_history ALTER COLUMN rollback_statement DROP DEFAULT;
+
+UPDATE bytebase.setting SET value = '2', updated_by = 'bytebase', updated_ts = EXTRACT(epoch from NOW()) WHERE name = 'bb.schema.version';
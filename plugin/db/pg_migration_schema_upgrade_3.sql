@@ -0,0 +1,7 @@
+-- Upgrade the bytebase migration schema from version 2 to 3 for Postgres
+-- Allow the DATA migration type.
+ALTER TABLE bytebase.migration_history DROP CONSTRAINT migration_history_type_check;
+
+ALTER TABLE bytebase.migration_history ADD CONSTRAINT migration_history_type_check CHECK (type IN ('BASELINE', 'SQL', 'BRANCH', 'ROLLBACK', 'DATA'));
+
+UPDATE bytebase.setting SET value = '3', updated_by = 'bytebase', updated_ts = EXTRACT(epoch from NOW()) WHERE name = 'bb.schema.version';
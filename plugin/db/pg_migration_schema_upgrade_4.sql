@@ -0,0 +1,7 @@
+-- Upgrade the bytebase migration schema from version 3 to 4 for Postgres
+-- Allow the history imported from Flyway and Liquibase.
+ALTER TABLE bytebase.migration_history DROP CONSTRAINT migration_history_engine_check;
+
+ALTER TABLE bytebase.migration_history ADD CONSTRAINT migration_history_engine_check CHECK (engine IN ('UI', 'VCS', 'FLYWAY', 'LIQUIBASE'));
+
+UPDATE bytebase.setting SET value = '4', updated_by = 'bytebase', updated_ts = EXTRACT(epoch from NOW()) WHERE name = 'bb.schema.version';
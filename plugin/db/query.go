@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// QueryResult is the result set of the read-only query.
+type QueryResult struct {
+	ColumnNameList []string
+	// The database type name of the columns, e.g. "VARCHAR", empty if unknown.
+	ColumnTypeList []string
+	// The values are nil, string, int64, float64, bool or time.Time depending on the driver.
+	// The binary values are returned as strings.
+	RowList [][]interface{}
+	// Truncated is true if the rows beyond the limit are dropped.
+	Truncated bool
+}
+
+// queryLeadingKeywordList contains the first keywords of the statements allowed by ValidateQueryStatement.
+var queryLeadingKeywordList = []string{"SELECT", "WITH", "VALUES", "TABLE", "SHOW", "EXPLAIN", "DESC", "DESCRIBE"}
+
+// queryForbiddenKeywordList contains the keywords of the statements writing data, e.g. the data-modifying WITH
+// queries of Postgres, "SELECT ... INTO" and "SELECT ... FOR UPDATE".
+var queryForbiddenKeywordList = []string{"INSERT", "UPDATE", "DELETE", "MERGE", "INTO"}
+
+// ValidateQueryStatement returns an error if the statement is not a single read-only statement of the database type.
+// It's a best effort check on the tokens, the drivers also run the query as a prepared statement in a read-only
+// transaction, so the statement can't be executed as multiple statements.
+func ValidateQueryStatement(dbType Type, statement string) error {
+	// The content of the MySQL executable comments "/*! ... */" and MariaDB "/*M! ... */" is executed rather than
+	// ignored. They are rejected even in the string literals for simplicity.
+	if strings.Contains(statement, "/*!") || strings.Contains(strings.ToUpper(statement), "/*M!") {
+		return fmt.Errorf("executable comments are not allowed")
+	}
+
+	// Only MySQL escapes the quote by backslash in the string literals by default.
+	backslashEscape := dbType == Mysql
+	var stmtList []string
+	for _, stmt := range splitStatement(statement, backslashEscape) {
+		if len(tokenizeWith(stmt, backslashEscape)) > 0 {
+			stmtList = append(stmtList, stmt)
+		}
+	}
+	if len(stmtList) == 0 {
+		return fmt.Errorf("empty statement")
+	}
+	if len(stmtList) > 1 {
+		return fmt.Errorf("only a single statement is allowed, got %d", len(stmtList))
+	}
+
+	tokenList := tokenizeWith(stmtList[0], backslashEscape)
+	allowed := false
+	for _, keyword := range queryLeadingKeywordList {
+		if tokenList[0].is(keyword) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("only read statements are allowed, got %q", tokenList[0].text)
+	}
+
+	for i, t := range tokenList {
+		// Skip the qualified names, e.g. "t1.update".
+		if i > 0 && tokenList[i-1].is(".") {
+			continue
+		}
+		for _, keyword := range queryForbiddenKeywordList {
+			if t.is(keyword) {
+				return fmt.Errorf("only read statements are allowed, got %q in the statement", t.text)
+			}
+		}
+		// Locking reads, e.g. "FOR SHARE", "FOR KEY SHARE" and "LOCK IN SHARE MODE".
+		if t.is("SHARE") && i > 0 && (tokenList[i-1].is("FOR") || tokenList[i-1].is("KEY") || tokenList[i-1].is("IN")) {
+			return fmt.Errorf("locking reads are not allowed")
+		}
+	}
+	return nil
+}
+
+// queryRows runs the query in the read-only transaction tx within the statement timeout of ctx, and reads at most
+// limit rows. The statement is canceled by cancel in the same way as execStatement.
+func queryRows(ctx context.Context, dbType Type, tx *sql.Tx, statement string, limit int, cancel cancelStatementFunc) (*QueryResult, error) {
+	if err := ValidateQueryStatement(dbType, statement); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid row limit %d, must be positive", limit)
+	}
+
+	result := &QueryResult{}
+	err := runStatement(ctx, cancel, func(stmtCtx context.Context) error {
+		// The prepared statement is sent by the MySQL binary protocol and the Postgres extended protocol, both of which
+		// reject multiple statements, unlike the plain query which may run them all, e.g. with MySQL multiStatements.
+		stmt, err := tx.PrepareContext(stmtCtx, statement)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		rows, err := stmt.QueryContext(stmtCtx)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		columnTypeList, err := rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+		result.ColumnNameList = []string{}
+		result.ColumnTypeList = []string{}
+		for _, columnType := range columnTypeList {
+			result.ColumnNameList = append(result.ColumnNameList, columnType.Name())
+			result.ColumnTypeList = append(result.ColumnTypeList, columnType.DatabaseTypeName())
+		}
+
+		result.RowList = [][]interface{}{}
+		for rows.Next() {
+			if len(result.RowList) >= limit {
+				result.Truncated = true
+				break
+			}
+			valueList := make([]interface{}, len(columnTypeList))
+			ptrList := make([]interface{}, len(columnTypeList))
+			for i := range valueList {
+				ptrList[i] = &valueList[i]
+			}
+			if err := rows.Scan(ptrList...); err != nil {
+				return err
+			}
+			for i, value := range valueList {
+				if bytes, ok := value.([]byte); ok {
+					valueList[i] = string(bytes)
+				}
+			}
+			result.RowList = append(result.RowList, valueList)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
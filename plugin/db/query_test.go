@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestValidateQueryStatement(t *testing.T) {
+	tests := []struct {
+		dbType    Type
+		statement string
+		wantErr   bool
+	}{
+		{statement: "SELECT * FROM t1 WHERE c1 = 'DELETE FROM t1'"},
+		{statement: "-- the latest\nselect t1.update, `delete` FROM t1 ORDER BY id DESC LIMIT 10;"},
+		{statement: "WITH t AS (SELECT 1) SELECT * FROM t"},
+		{statement: "SHOW TABLES"},
+		{statement: "EXPLAIN SELECT * FROM t1"},
+		{statement: "", wantErr: true},
+		{statement: "SELECT 1; SELECT 2", wantErr: true},
+		{statement: "SELECT 1; DROP TABLE t1", wantErr: true},
+		{statement: "UPDATE t1 SET c1 = 1", wantErr: true},
+		{statement: "WITH t AS (DELETE FROM t1 RETURNING *) SELECT * FROM t", wantErr: true},
+		{statement: "SELECT * INTO t2 FROM t1", wantErr: true},
+		{statement: "SELECT * FROM t1 FOR UPDATE", wantErr: true},
+		{statement: "SELECT * FROM t1 LOCK IN SHARE MODE", wantErr: true},
+		{statement: "EXPLAIN ANALYZE DELETE FROM t1", wantErr: true},
+		{dbType: Mysql, statement: "SELECT 1 /*!; COMMIT; DROP TABLE t */", wantErr: true},
+		{dbType: Mysql, statement: "SELECT 1 /*! , 1 INTO OUTFILE '/tmp/t' */", wantErr: true},
+		{dbType: Mysql, statement: "SELECT 1 /*M!; DROP TABLE t */", wantErr: true},
+		{dbType: Mysql, statement: `SELECT 'a\'; COMMIT; DROP TABLE t; --'`},
+		{dbType: Postgres, statement: `SELECT 'a\'; COMMIT; DROP TABLE t; --'`, wantErr: true},
+		{dbType: Postgres, statement: `SELECT E'a\'; COMMIT; DROP TABLE t; --'`},
+		{dbType: SQLite, statement: `SELECT 'a\'; DELETE FROM t1; --'`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		err := ValidateQueryStatement(test.dbType, test.statement)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateQueryStatement(%s, %q) got error %v, want error %v", test.dbType, test.statement, err, test.wantErr)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	driver, err := Open(SQLite, DriverConfig{Logger: zap.NewNop()}, ConnectionConfig{
+		Host:     t.TempDir(),
+		Database: "db1.db",
+	}, ConnectionContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Close(ctx)
+	if err := driver.Execute(ctx, `
+		CREATE TABLE t1 (id INTEGER PRIMARY KEY, name TEXT, data BLOB);
+		INSERT INTO t1 VALUES (1, 'a', x'6869'), (2, NULL, NULL), (3, 'c', NULL);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := driver.Query(ctx, "SELECT id, name, data FROM t1 ORDER BY id", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.ColumnNameList) != 3 || result.ColumnNameList[1] != "name" || result.ColumnTypeList[1] != "TEXT" {
+		t.Errorf("got columns %v with types %v, want id, name and data", result.ColumnNameList, result.ColumnTypeList)
+	}
+	if !result.Truncated || len(result.RowList) != 2 {
+		t.Fatalf("got %d rows truncated %v, want 2 rows truncated", len(result.RowList), result.Truncated)
+	}
+	if row := result.RowList[0]; row[0] != int64(1) || row[1] != "a" || row[2] != "hi" {
+		t.Errorf("got row %v, want [1 a hi]", row)
+	}
+	if row := result.RowList[1]; row[1] != nil {
+		t.Errorf("got row %v, want the NULL name", row)
+	}
+
+	if _, err := driver.Query(ctx, "SELECT * FROM t1; DELETE FROM t1", 10); err == nil {
+		t.Errorf("got no error for the write statement")
+	}
+	// The connection is writable again after the query.
+	if err := driver.Execute(ctx, "DELETE FROM t1 WHERE id = 3"); err != nil {
+		t.Errorf("failed to write after the query: %v", err)
+	}
+}
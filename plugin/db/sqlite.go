@@ -0,0 +1,720 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	_ "embed"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+)
+
+//go:embed sqlite_migration_schema.sql
+var sqliteMigrationSchema string
+
+var (
+	_ Driver = (*SQLiteDriver)(nil)
+)
+
+const (
+	// The file hosting the "bytebase" schema, it's attached to every connection as "bytebase".
+	sqliteBytebaseDatabase = "bytebase.db"
+)
+
+var (
+	// Matches the timing and event of CREATE TRIGGER, e.g. "CREATE TRIGGER IF NOT EXISTS t1 AFTER UPDATE OF c1 ON tbl".
+	sqliteTriggerRegexp = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:TEMP\s+|TEMPORARY\s+)?TRIGGER\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:"[^"]*"|` + "`[^`]*`" + `|\[[^\]]*\]|\S+)\s+(BEFORE\s+|AFTER\s+|INSTEAD\s+OF\s+)?(DELETE|INSERT|UPDATE)\b`)
+	// Files with these extensions under the instance directory are treated as databases.
+	sqliteDatabaseExtList = []string{".db", ".sqlite", ".sqlite3"}
+)
+
+func init() {
+	register(SQLite, newSQLiteDriver, Capability{
+		TransactionalDDL: true,
+		// SQLite has no user, the access is controlled by the file permission.
+		UserAndGrant:  false,
+		BackupRestore: false,
+		OnlineDDL:     false,
+		// SQLite has no CREATE DATABASE statement, a database file is created upon first open.
+		CreateDatabase: false,
+		// SQLite doesn't collect any statistics of the index usage.
+		IndexUsage: false,
+	})
+}
+
+// SQLiteDriver manages the SQLite database files under a directory. The instance host is the
+// directory path, and each database file under it is a database named after its file name.
+type SQLiteDriver struct {
+	l             *zap.Logger
+	connectionCtx ConnectionContext
+
+	dir string
+	db  *sql.DB
+}
+
+func newSQLiteDriver(config DriverConfig) Driver {
+	return &SQLiteDriver{
+		l: config.Logger,
+	}
+}
+
+func (driver *SQLiteDriver) open(config ConnectionConfig, ctx ConnectionContext) (Driver, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("sqlite instance requires the directory path as the host")
+	}
+	if config.Database != "" && filepath.Base(config.Database) != config.Database {
+		return nil, fmt.Errorf("invalid sqlite database name %q, must be a file name under %q", config.Database, config.Host)
+	}
+
+	// If no database is specified, we still need a main database to attach the "bytebase" database to.
+	dsn := ":memory:"
+	if config.Database != "" {
+		dsn = filepath.Join(config.Host, config.Database)
+	}
+
+	driver.l.Debug("Opening SQLite driver",
+		zap.String("dsn", dsn),
+		zap.String("environment", ctx.EnvironmentName),
+		zap.String("instance", ctx.InstanceName),
+	)
+	db := sql.OpenDB(newSQLiteConnector(dsn, filepath.Join(config.Host, sqliteBytebaseDatabase)))
+	// SQLite allows a single writer at a time, so we use a single connection instead of failing on the busy database.
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	driver.dir = config.Host
+	driver.db = db
+	driver.connectionCtx = ctx
+
+	return driver, nil
+}
+
+// sqliteConnector opens the connections attaching the "bytebase" database. ATTACH only applies to the connection
+// executing it, and database/sql may replace the connection at any time, e.g. after a bad connection.
+type sqliteConnector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+}
+
+func newSQLiteConnector(dsn string, bytebaseDatabasePath string) *sqliteConnector {
+	return &sqliteConnector{
+		dsn: dsn,
+		driver: &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				const query = "ATTACH DATABASE ? AS bytebase"
+				if _, err := conn.Exec(query, []sqldriver.Value{bytebaseDatabasePath}); err != nil {
+					return formatErrorWithQuery(err, query)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+func (c *sqliteConnector) Connect(ctx context.Context) (sqldriver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *sqliteConnector) Driver() sqldriver.Driver {
+	return c.driver
+}
+
+func (driver *SQLiteDriver) Close(ctx context.Context) error {
+	return driver.db.Close()
+}
+
+func (driver *SQLiteDriver) Ping(ctx context.Context) error {
+	return driver.db.PingContext(ctx)
+}
+
+func (driver *SQLiteDriver) GetServerInfo(ctx context.Context) (*ServerInfo, error) {
+	query := "SELECT sqlite_version()"
+	var version string
+	if err := driver.db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+
+	return &ServerInfo{
+		Flavor:  FlavorSQLite,
+		Version: version,
+	}, nil
+}
+
+func (driver *SQLiteDriver) SyncSchema(ctx context.Context) ([]*DBUser, []*DBSchema, error) {
+	fileList, err := ioutil.ReadDir(driver.dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list sqlite database files under %q: %w", driver.dir, err)
+	}
+
+	schemaList := make([]*DBSchema, 0)
+	for _, file := range fileList {
+		if file.IsDir() || file.Name() == sqliteBytebaseDatabase || !isSQLiteDatabaseFile(file.Name()) {
+			continue
+		}
+
+		schema, err := driver.syncDatabase(ctx, file.Name())
+		if err != nil {
+			return nil, nil, err
+		}
+		schemaList = append(schemaList, schema)
+	}
+
+	// SQLite doesn't have user concept.
+	return []*DBUser{}, schemaList, nil
+}
+
+func isSQLiteDatabaseFile(name string) bool {
+	ext := filepath.Ext(name)
+	for _, dbExt := range sqliteDatabaseExtList {
+		if ext == dbExt {
+			return true
+		}
+	}
+	return false
+}
+
+func (driver *SQLiteDriver) syncDatabase(ctx context.Context, name string) (*DBSchema, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", filepath.Join(driver.dir, name)))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	schema := &DBSchema{
+		Name:      name,
+		Collation: "BINARY",
+	}
+	query := "PRAGMA encoding"
+	if err := db.QueryRowContext(ctx, query).Scan(&schema.CharacterSet); err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+
+	// Query table info
+	query = `
+		SELECT
+			name,
+			CASE type WHEN 'view' THEN 'VIEW' ELSE 'BASE TABLE' END
+		FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'
+		ORDER BY name`
+	tableRows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer tableRows.Close()
+
+	for tableRows.Next() {
+		var table DBTable
+		if err := tableRows.Scan(
+			&table.Name,
+			&table.Type,
+		); err != nil {
+			return nil, err
+		}
+		schema.TableList = append(schema.TableList, table)
+	}
+	if err := tableRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range schema.TableList {
+		table := &schema.TableList[i]
+		if table.Type == "BASE TABLE" {
+			query = fmt.Sprintf("SELECT COUNT(*) FROM %s", sqliteQuoteIdentifier(table.Name))
+			if err := db.QueryRowContext(ctx, query).Scan(&table.RowCount); err != nil {
+				return nil, formatErrorWithQuery(err, query)
+			}
+		}
+
+		if table.ColumnList, err = sqliteGetColumnList(ctx, db, table.Name); err != nil {
+			return nil, err
+		}
+		if table.IndexList, err = sqliteGetIndexList(ctx, db, table.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if schema.ViewList, err = sqliteGetViewList(ctx, db); err != nil {
+		return nil, err
+	}
+	if schema.TriggerList, err = sqliteGetTriggerList(ctx, db); err != nil {
+		return nil, err
+	}
+	// SQLite doesn't have stored routines and scheduled events.
+
+	return schema, nil
+}
+
+func sqliteGetViewList(ctx context.Context, db *sql.DB) ([]DBView, error) {
+	query := `
+		SELECT
+			name,
+			sql
+		FROM sqlite_master
+		WHERE type = 'view'
+		ORDER BY name`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	viewList := make([]DBView, 0)
+	for rows.Next() {
+		var view DBView
+		if err := rows.Scan(
+			&view.Name,
+			&view.Definition,
+		); err != nil {
+			return nil, err
+		}
+		viewList = append(viewList, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return viewList, nil
+}
+
+func sqliteGetTriggerList(ctx context.Context, db *sql.DB) ([]DBTrigger, error) {
+	query := `
+		SELECT
+			name,
+			tbl_name,
+			sql
+		FROM sqlite_master
+		WHERE type = 'trigger'
+		ORDER BY tbl_name, name`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	triggerList := make([]DBTrigger, 0)
+	for rows.Next() {
+		var trigger DBTrigger
+		if err := rows.Scan(
+			&trigger.Name,
+			&trigger.TableName,
+			&trigger.Definition,
+		); err != nil {
+			return nil, err
+		}
+		trigger.Timing, trigger.Event = parseSQLiteTriggerTimingAndEvent(trigger.Definition)
+		triggerList = append(triggerList, trigger)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return triggerList, nil
+}
+
+// parseSQLiteTriggerTimingAndEvent parses the timing and event from the CREATE TRIGGER statement,
+// since sqlite_master only records the statement. The timing defaults to BEFORE if omitted.
+func parseSQLiteTriggerTimingAndEvent(definition string) (string, string) {
+	match := sqliteTriggerRegexp.FindStringSubmatch(definition)
+	if match == nil {
+		return "", ""
+	}
+	timing := strings.ToUpper(strings.Join(strings.Fields(match[1]), " "))
+	if timing == "" {
+		timing = "BEFORE"
+	}
+	return timing, strings.ToUpper(match[2])
+}
+
+func sqliteGetColumnList(ctx context.Context, db *sql.DB, table string) ([]DBColumn, error) {
+	query := fmt.Sprintf("PRAGMA table_info(%s)", sqliteQuoteIdentifier(table))
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	columnList := make([]DBColumn, 0)
+	for rows.Next() {
+		var notNull, pk int
+		var defaultStr sql.NullString
+		var column DBColumn
+		if err := rows.Scan(
+			&column.Position,
+			&column.Name,
+			&column.Type,
+			&notNull,
+			&defaultStr,
+			&pk,
+		); err != nil {
+			return nil, err
+		}
+
+		// cid starts from 0 while position starts from 1 in other engines.
+		column.Position++
+		column.Nullable = notNull == 0
+		if defaultStr.Valid {
+			column.Default = &defaultStr.String
+		}
+		columnList = append(columnList, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return columnList, nil
+}
+
+func sqliteGetIndexList(ctx context.Context, db *sql.DB, table string) ([]DBIndex, error) {
+	query := fmt.Sprintf("PRAGMA index_list(%s)", sqliteQuoteIdentifier(table))
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	type indexMeta struct {
+		name   string
+		unique bool
+	}
+	var metaList []indexMeta
+	for rows.Next() {
+		var seq, partial int
+		var origin string
+		var meta indexMeta
+		if err := rows.Scan(
+			&seq,
+			&meta.name,
+			&meta.unique,
+			&origin,
+			&partial,
+		); err != nil {
+			return nil, err
+		}
+		metaList = append(metaList, meta)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	indexList := make([]DBIndex, 0)
+	for _, meta := range metaList {
+		list, err := sqliteGetIndexColumnList(ctx, db, meta.name, meta.unique)
+		if err != nil {
+			return nil, err
+		}
+		indexList = append(indexList, list...)
+	}
+
+	return indexList, nil
+}
+
+func sqliteGetIndexColumnList(ctx context.Context, db *sql.DB, name string, unique bool) ([]DBIndex, error) {
+	query := fmt.Sprintf("PRAGMA index_info(%s)", sqliteQuoteIdentifier(name))
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	indexList := make([]DBIndex, 0)
+	for rows.Next() {
+		var seqno, cid int
+		var columnName sql.NullString
+		if err := rows.Scan(
+			&seqno,
+			&cid,
+			&columnName,
+		); err != nil {
+			return nil, err
+		}
+
+		index := DBIndex{
+			Name:     name,
+			Position: seqno + 1,
+			Type:     "BTREE",
+			Unique:   unique,
+			// SQLite doesn't support invisible index.
+			Visible: true,
+		}
+		// Column name is NULL if the index column is an expression.
+		if columnName.Valid {
+			index.Expression = columnName.String
+		}
+		indexList = append(indexList, index)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return indexList, nil
+}
+
+func (driver *SQLiteDriver) Execute(ctx context.Context, statement string) error {
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The SQLite driver interrupts the running statement itself when the context is done.
+	if err := execStatement(ctx, tx, statement, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (driver *SQLiteDriver) Query(ctx context.Context, statement string, limit int) (*QueryResult, error) {
+	conn, err := driver.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// The SQLite driver ignores the read-only transaction option, so we turn on query_only for the connection instead.
+	if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(context.Background(), "PRAGMA query_only = OFF")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return queryRows(ctx, SQLite, tx, statement, limit, nil)
+}
+
+// getMigrationSchemaVersion returns the version of the bytebase migration schema, 0 if the schema doesn't exist.
+func (driver *SQLiteDriver) getMigrationSchemaVersion(ctx context.Context) (int, error) {
+	const query = `
+		SELECT
+			1
+		FROM bytebase.sqlite_master
+		WHERE type = 'table' AND name = 'migration_history'
+		`
+	rows, err := driver.db.QueryContext(ctx, query)
+	if err != nil {
+		return 0, formatErrorWithQuery(err, query)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return 0, nil
+	}
+	// Release the single pinned connection before the next query.
+	rows.Close()
+
+	return findMigrationSchemaVersion(ctx, driver.db)
+}
+
+func (driver *SQLiteDriver) NeedsSetupMigration(ctx context.Context) (bool, error) {
+	version, err := driver.getMigrationSchemaVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return version < latestMigrationSchemaVersion, nil
+}
+
+func (driver *SQLiteDriver) SetupMigrationIfNeeded(ctx context.Context) error {
+	version, err := driver.getMigrationSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		driver.l.Info("Bytebase migration schema not found, creating schema...",
+			zap.String("environment", driver.connectionCtx.EnvironmentName),
+			zap.String("database", driver.connectionCtx.InstanceName),
+		)
+		if err := driver.Execute(ctx, sqliteMigrationSchema); err != nil {
+			driver.l.Error("Failed to initialize migration schema.",
+				zap.Error(err),
+				zap.String("environment", driver.connectionCtx.EnvironmentName),
+				zap.String("database", driver.connectionCtx.InstanceName),
+			)
+			return formatErrorWithQuery(err, sqliteMigrationSchema)
+		}
+		driver.l.Info("Successfully created migration schema.",
+			zap.String("environment", driver.connectionCtx.EnvironmentName),
+			zap.String("database", driver.connectionCtx.InstanceName),
+		)
+	} else if version < latestMigrationSchemaVersion {
+		if err := upgradeMigrationSchema(ctx, driver.l, driver.connectionCtx, "sqlite", version, driver.Execute); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (driver *SQLiteDriver) ExecuteMigration(ctx context.Context, m *MigrationInfo, statement string, execution *MigrationExecution) error {
+	// The "bytebase" database is attached to the same connection, so a single transaction
+	// covers both the statement and the history record. No migration lock is needed since
+	// SQLite allows a single writer per database file, the concurrent migration fails as busy instead of interleaving.
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	startedTs := time.Now().Unix()
+
+	// Phase 1 - Precheck before executing migration
+	sequence, err := checkMigrationPrecondition(ctx, tx, m, sqliteMigrationHistoryQueries)
+	if err != nil {
+		return err
+	}
+
+	// Phase 2 - Executing migration
+	if err := executeMigrationStatement(ctx, tx, statement, execution, nil); err != nil {
+		return err
+	}
+
+	// Phase 3 - Record migration
+	const query = `
+		INSERT INTO bytebase.migration_history (
+			created_by,
+			created_ts,
+			updated_by,
+			updated_ts,
+			namespace,
+			sequence,
+			` + "`engine`," + `
+			` + "`type`," + `
+			version,
+			description,
+			statement,
+			rollback_statement,
+			execution_duration,
+			issue_id,
+			payload
+		)
+		VALUES (?, strftime('%s', 'now'), ?, strftime('%s', 'now'), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		m.Creator,
+		m.Creator,
+		m.Namespace,
+		sequence,
+		m.Engine,
+		m.Type,
+		m.Version,
+		m.Description,
+		statement,
+		m.RollbackStatement,
+		time.Now().Unix()-startedTs,
+		m.IssueId,
+		m.Payload,
+	)
+
+	if err != nil {
+		return formatErrorWithQuery(err, query)
+	}
+
+	return tx.Commit()
+}
+
+func (driver *SQLiteDriver) DryRunMigration(ctx context.Context, m *MigrationInfo, statement string, schema *DBSchema) ([]*DryRunError, error) {
+	var errorList []*DryRunError
+	preconditionError, err := dryRunPrecondition(ctx, driver.db, m, sqliteMigrationHistoryQueries)
+	if err != nil {
+		return nil, err
+	}
+	if preconditionError != nil {
+		errorList = append(errorList, preconditionError)
+	}
+
+	explain := func(ctx context.Context, statement string) error {
+		return explainQuery(ctx, driver.db, "EXPLAIN QUERY PLAN "+statement)
+	}
+	return append(errorList, dryRunStatement(ctx, statement, schema, []string{"main"}, explain)...), nil
+}
+
+func (driver *SQLiteDriver) FindMigrationHistoryList(ctx context.Context, find *MigrationHistoryFind) ([]*MigrationHistory, error) {
+	return findMigrationHistoryList(ctx, driver.db, find)
+}
+
+func (driver *SQLiteDriver) ImportMigrationHistory(ctx context.Context, imp *MigrationHistoryImport) (int, error) {
+	historyList, err := readImportedMigrationHistory(ctx, driver.db, imp, "CAST(strftime('%%s', %s) AS INTEGER)")
+	if err != nil {
+		return 0, err
+	}
+
+	// No migration lock is needed, see ExecuteMigration.
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	count, err := importMigrationHistory(ctx, tx, historyList, sqliteMigrationHistoryQueries, importedMigrationHistoryInsert)
+	if err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+func (driver *SQLiteDriver) GrantDatabase(ctx context.Context, grant *DatabaseGrant) (*DBUser, error) {
+	return nil, fmt.Errorf("sqlite doesn't support users and grants")
+}
+
+func (driver *SQLiteDriver) RevokeDatabase(ctx context.Context, grant *DatabaseGrant) (*DBUser, error) {
+	return nil, fmt.Errorf("sqlite doesn't support users and grants")
+}
+
+var sqliteMigrationHistoryQueries = migrationHistoryQueries{
+	checkDuplicateVersion:  checkDuplicateVersion,
+	checkOutofOrderVersion: sqliteCheckOutofOrderVersion,
+	findVersionList:        findVersionList,
+	findBaseline:           findBaseline,
+	findNextSequence:       findNextSequence,
+}
+
+func sqliteCheckOutofOrderVersion(ctx context.Context, tx *sql.Tx, namespace string, engine MigrationEngine, version string) (*string, error) {
+	query := `
+		SELECT MIN(version) FROM bytebase.migration_history WHERE namespace = ? AND ` + "`engine` = ? AND version > ?" + `
+	`
+	args := []interface{}{namespace, engine.String(), version}
+	row, err := tx.QueryContext(ctx, query,
+		args...,
+	)
+
+	if err != nil {
+		return nil, formatErrorWithQuery(err, query)
+	}
+	defer row.Close()
+
+	var minVersion sql.NullString
+	row.Next()
+	if err := row.Scan(&minVersion); err != nil {
+		return nil, err
+	}
+
+	if minVersion.Valid {
+		return &minVersion.String, nil
+	}
+
+	return nil, nil
+}
+
+func sqliteQuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (driver *SQLiteDriver) FindIndexUsage(ctx context.Context, database string) (*IndexUsageStats, error) {
+	return nil, fmt.Errorf("sqlite doesn't support index usage statistics")
+}
@@ -0,0 +1,74 @@
+-- Upgrade the bytebase migration schema from version 1 to 2 for SQLite
+-- Record the rollback statement and allow the ROLLBACK migration type.
+-- SQLite can't alter a CHECK constraint, so we rebuild the table.
+CREATE TABLE bytebase.migration_history_v2 (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_by TEXT NOT NULL,
+    created_ts BIGINT NOT NULL,
+    updated_by TEXT NOT NULL,
+    updated_ts BIGINT NOT NULL,
+    namespace TEXT NOT NULL,
+    sequence INTEGER NOT NULL CHECK (sequence >= 0),
+    `engine` TEXT NOT NULL CHECK (`engine` IN ('UI', 'VCS')),
+    `type` TEXT NOT NULL CHECK (`type` IN ('BASELINE', 'SQL', 'BRANCH', 'ROLLBACK')),
+    version TEXT NOT NULL,
+    description TEXT NOT NULL,
+    statement TEXT NOT NULL,
+    rollback_statement TEXT NOT NULL,
+    execution_duration INTEGER NOT NULL,
+    issue_id TEXT NOT NULL,
+    payload TEXT NOT NULL
+);
+
+INSERT INTO
+    bytebase.migration_history_v2 (
+        id,
+        created_by,
+        created_ts,
+        updated_by,
+        updated_ts,
+        namespace,
+        sequence,
+        `engine`,
+        `type`,
+        version,
+        description,
+        statement,
+        rollback_statement,
+        execution_duration,
+        issue_id,
+        payload
+    )
+SELECT
+    id,
+    created_by,
+    created_ts,
+    updated_by,
+    updated_ts,
+    namespace,
+    sequence,
+    `engine`,
+    `type`,
+    version,
+    description,
+    statement,
+    '',
+    execution_duration,
+    issue_id,
+    payload
+FROM
+    bytebase.migration_history;
+
+DROP TABLE bytebase.migration_history;
+
+ALTER TABLE bytebase.migration_history_v2 RENAME TO migration_history;
+
+CREATE UNIQUE INDEX bytebase.bytebase_idx_unique_migration_history_namespace_sequence ON migration_history (namespace, sequence);
+
+CREATE UNIQUE INDEX bytebase.bytebase_idx_unique_migration_history_namespace_engine_version ON migration_history (namespace, `engine`, version);
+
+CREATE INDEX bytebase.bytebase_idx_migration_history_namespace_engine_type ON migration_history (namespace, `engine`, `type`);
+
+CREATE INDEX bytebase.bytebase_idx_migration_history_namespace_created ON migration_history (namespace, `created_ts`);
+
+UPDATE bytebase.setting SET value = '2', updated_by = 'bytebase', updated_ts = strftime('%s', 'now') WHERE name = 'bb.schema.version';
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestParseSQLiteTriggerTimingAndEvent(t *testing.T) {
+	tests := []struct {
+		definition string
+		timing     string
+		event      string
+	}{
+		{"CREATE TRIGGER t1 AFTER INSERT ON tbl BEGIN SELECT 1; END", "AFTER", "INSERT"},
+		{"create temp trigger if not exists \"my trigger\" instead  of update of c1 on v1 begin select 1; end", "INSTEAD OF", "UPDATE"},
+		{"CREATE TRIGGER main.t1 DELETE ON tbl BEGIN SELECT 1; END", "BEFORE", "DELETE"},
+		{"CREATE TRIGGER `t1`\n\tBEFORE UPDATE ON tbl BEGIN SELECT 1; END", "BEFORE", "UPDATE"},
+		{"CREATE VIEW v1 AS SELECT 1", "", ""},
+	}
+
+	for _, test := range tests {
+		timing, event := parseSQLiteTriggerTimingAndEvent(test.definition)
+		if timing != test.timing || event != test.event {
+			t.Errorf("parseSQLiteTriggerTimingAndEvent(%q) got (%q, %q), want (%q, %q)", test.definition, timing, event, test.timing, test.event)
+		}
+	}
+}
+
+func TestSQLiteDriverMigration(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	driver, err := Open(SQLite, DriverConfig{Logger: zap.NewNop()}, ConnectionConfig{Host: dir, Database: "app.db"}, ConnectionContext{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite driver: %v", err)
+	}
+	defer driver.Close(ctx)
+	// Close the connection after each use, so every operation below runs on a new connection which must have the
+	// "bytebase" database attached.
+	driver.(*SQLiteDriver).db.SetMaxIdleConns(0)
+
+	if err := driver.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := driver.SetupMigrationIfNeeded(ctx); err != nil {
+		t.Fatalf("failed to set up migration schema: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, sqliteBytebaseDatabase)); err != nil {
+		t.Errorf("got no migration schema file: %v", err)
+	}
+	needsSetup, err := driver.NeedsSetupMigration(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if needsSetup {
+		t.Errorf("got migration schema needing setup after setting up")
+	}
+
+	for i, statement := range []string{
+		"CREATE TABLE t1 (id INTEGER PRIMARY KEY);\nINSERT INTO t1 (id) VALUES (1);",
+		"ALTER TABLE t1 ADD COLUMN name TEXT;",
+	} {
+		m := &MigrationInfo{
+			Version:   fmt.Sprintf("000%d", i+1),
+			Namespace: "app",
+			Database:  "app",
+			Engine:    UI,
+			Type:      Sql,
+			Creator:   "test",
+		}
+		execution := &MigrationExecution{StatementList: SplitStatement(statement)}
+		if err := driver.ExecuteMigration(ctx, m, statement, execution); err != nil {
+			t.Fatalf("failed to execute migration %s: %v", m.Version, err)
+		}
+	}
+
+	// The same version is never applied twice.
+	statement := "DROP TABLE t1;"
+	err = driver.ExecuteMigration(ctx, &MigrationInfo{
+		Version:   "0002",
+		Namespace: "app",
+		Database:  "app",
+		Engine:    UI,
+		Type:      Sql,
+		Creator:   "test",
+	}, statement, &MigrationExecution{StatementList: SplitStatement(statement)})
+	if err == nil {
+		t.Errorf("got no error applying the duplicate version")
+	}
+
+	database := "app"
+	historyList, err := driver.FindMigrationHistoryList(ctx, &MigrationHistoryFind{Database: &database})
+	if err != nil {
+		t.Fatalf("failed to find migration history: %v", err)
+	}
+	var versionList []string
+	for _, history := range historyList {
+		versionList = append(versionList, fmt.Sprintf("%s#%d", history.Version, history.Sequence))
+	}
+	sort.Strings(versionList)
+	if want := []string{"0001#1", "0002#2"}; !reflect.DeepEqual(versionList, want) {
+		t.Errorf("got migration history %v, want %v", versionList, want)
+	}
+
+	var count int
+	if err := driver.(*SQLiteDriver).db.QueryRowContext(ctx, "SELECT COUNT(*) FROM t1 WHERE name IS NULL").Scan(&count); err != nil {
+		t.Fatalf("failed to query the migrated table: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("got %d rows in the migrated table, want 1", count)
+	}
+
+	// The instance driver without a database sees the same migration history.
+	instanceDriver, err := Open(SQLite, DriverConfig{Logger: zap.NewNop()}, ConnectionConfig{Host: dir}, ConnectionContext{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite driver without database: %v", err)
+	}
+	defer instanceDriver.Close(ctx)
+	instanceDriver.(*SQLiteDriver).db.SetMaxIdleConns(0)
+	for i := 0; i < 2; i++ {
+		historyList, err := instanceDriver.FindMigrationHistoryList(ctx, &MigrationHistoryFind{Database: &database})
+		if err != nil {
+			t.Fatalf("failed to find migration history without database: %v", err)
+		}
+		if len(historyList) != 2 {
+			t.Errorf("got %d migration histories without database, want 2", len(historyList))
+		}
+	}
+}
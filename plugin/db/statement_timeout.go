@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// statementCancelTimeout is how long to wait for canceling the running statement on the server.
+const statementCancelTimeout = 10 * time.Second
+
+type statementTimeoutKey struct{}
+
+// WithStatementTimeout returns the context limiting how long a single statement executed by Execute and
+// ExecuteMigration can run. The driver enforces it through the context and the session timeout of the engine,
+// and cancels the statement on the server once the timeout is reached or the context is done.
+// The statements are not limited if timeout is 0.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey{}, timeout)
+}
+
+// statementTimeout returns the statement timeout of the context, 0 if not limited.
+func statementTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(statementTimeoutKey{}).(time.Duration)
+	return timeout
+}
+
+// StatementTimeoutError is returned if the statement is canceled by the statement timeout.
+type StatementTimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *StatementTimeoutError) Error() string {
+	return fmt.Sprintf("statement exceeded the timeout %v: %v", e.Timeout, e.Err)
+}
+
+func (e *StatementTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// cancelStatementFunc cancels the statement running on the server connection, e.g. "KILL QUERY" for MySQL.
+type cancelStatementFunc func(ctx context.Context) error
+
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// execStatement executes the statement within the statement timeout of ctx. If ctx is done or the timeout is reached
+// before the statement finishes, cancel is called to cancel the statement on the server, since some engines keep
+// running the statement after the client gives up the connection. cancel can be nil if the driver does it already.
+func execStatement(ctx context.Context, execer sqlExecer, statement string, cancel cancelStatementFunc) error {
+	return runStatement(ctx, cancel, func(stmtCtx context.Context) error {
+		_, err := execer.ExecContext(stmtCtx, statement)
+		return err
+	})
+}
+
+// runStatement calls run with the context limited by the statement timeout of ctx, and cancels the statement in the
+// same way as execStatement.
+func runStatement(ctx context.Context, cancel cancelStatementFunc, run func(stmtCtx context.Context) error) error {
+	timeout := statementTimeout(ctx)
+	stmtCtx := ctx
+	if timeout > 0 {
+		var cancelCtx context.CancelFunc
+		stmtCtx, cancelCtx = context.WithTimeout(ctx, timeout)
+		defer cancelCtx()
+	}
+
+	var cancelErr error
+	var wg sync.WaitGroup
+	finished := make(chan struct{})
+	if cancel != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-finished:
+			case <-stmtCtx.Done():
+				cancelCtx, cancelCancelCtx := context.WithTimeout(context.Background(), statementCancelTimeout)
+				defer cancelCancelCtx()
+				cancelErr = cancel(cancelCtx)
+			}
+		}()
+	}
+
+	err := run(stmtCtx)
+	close(finished)
+	// Wait for the cancellation, so that it doesn't cancel the next statement on the same connection.
+	wg.Wait()
+	if err == nil {
+		return nil
+	}
+
+	if cancelErr != nil {
+		err = fmt.Errorf("%w, and failed to cancel the statement on the server: %v", err, cancelErr)
+	}
+	if ctx.Err() == nil && stmtCtx.Err() == context.DeadlineExceeded {
+		return &StatementTimeoutError{Timeout: timeout, Err: err}
+	}
+	return err
+}
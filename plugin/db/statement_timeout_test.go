@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingExecer blocks the statement until the context is done or the statement is canceled.
+type blockingExecer struct {
+	canceled chan struct{}
+}
+
+func (e *blockingExecer) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	select {
+	case <-ctx.Done():
+		// The server keeps running the statement after the client gives up, until it's canceled.
+		<-e.canceled
+		return nil, ctx.Err()
+	case <-e.canceled:
+		return nil, errors.New("query interrupted")
+	}
+}
+
+func TestExecStatement(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		timeout     time.Duration
+		cancelAfter time.Duration
+		wantTimeout bool
+	}{
+		{name: "timeout", timeout: 50 * time.Millisecond, wantTimeout: true},
+		{name: "canceled", cancelAfter: 50 * time.Millisecond},
+	} {
+		ctx, cancelCtx := context.WithCancel(context.Background())
+		if test.cancelAfter > 0 {
+			time.AfterFunc(test.cancelAfter, cancelCtx)
+		}
+		ctx = WithStatementTimeout(ctx, test.timeout)
+
+		execer := &blockingExecer{canceled: make(chan struct{})}
+		cancelCount := 0
+		cancel := func(ctx context.Context) error {
+			cancelCount++
+			close(execer.canceled)
+			return nil
+		}
+		err := execStatement(ctx, execer, "SELECT SLEEP(1000)", cancel)
+		cancelCtx()
+
+		var timeoutErr *StatementTimeoutError
+		if err == nil || errors.As(err, &timeoutErr) != test.wantTimeout {
+			t.Errorf("%s: got error %v, want timeout error %v", test.name, err, test.wantTimeout)
+		}
+		if cancelCount != 1 {
+			t.Errorf("%s: canceled the statement on the server %d times, want once", test.name, cancelCount)
+		}
+	}
+
+	// The statement finished in time is not canceled.
+	ctx := WithStatementTimeout(context.Background(), time.Second)
+	execer := &blockingExecer{canceled: make(chan struct{})}
+	close(execer.canceled)
+	cancel := func(ctx context.Context) error {
+		t.Errorf("canceled the finished statement")
+		return nil
+	}
+	if err := execStatement(ctx, execer, "SELECT 1", cancel); err == nil || err.Error() != "query interrupted" {
+		t.Errorf("got error %v, want the statement error", err)
+	}
+}
@@ -0,0 +1,256 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VersionScheme decides how the migration versions are ordered.
+type VersionScheme string
+
+const (
+	// LexicographicVersion compares versions as plain strings, e.g. "v10" < "v9". It's the default scheme.
+	LexicographicVersion VersionScheme = "LEXICOGRAPHIC"
+	// NumericVersion compares dot separated numbers with an optional "v" prefix, e.g. "v9" < "v10" and "1.2" < "1.10".
+	NumericVersion VersionScheme = "NUMERIC"
+	// SemanticVersion compares versions by the semantic versioning precedence, e.g. "1.0.0-rc.1" < "1.0.0".
+	SemanticVersion VersionScheme = "SEMVER"
+	// TimestampVersion compares timestamps like "20211018150405" or "202110181504", optionally followed by "." and
+	// a number to order the versions within the same timestamp, e.g. the version generated by bytebase.
+	TimestampVersion VersionScheme = "TIMESTAMP"
+)
+
+func (e VersionScheme) String() string {
+	switch e {
+	case LexicographicVersion:
+		return "LEXICOGRAPHIC"
+	case NumericVersion:
+		return "NUMERIC"
+	case SemanticVersion:
+		return "SEMVER"
+	case TimestampVersion:
+		return "TIMESTAMP"
+	}
+	return "UNKNOWN"
+}
+
+// timestampVersionLayoutList is the accepted layouts of TimestampVersion.
+var timestampVersionLayoutList = []string{"20060102150405", "200601021504", "20060102"}
+
+// ValidateVersion returns error if the version is invalid under the scheme. Empty scheme is LexicographicVersion.
+func ValidateVersion(scheme VersionScheme, version string) error {
+	_, err := parseVersion(scheme, version)
+	return err
+}
+
+// CompareVersion returns -1, 0 or 1 if a is lower than, equal to or higher than b under the scheme.
+// Empty scheme is LexicographicVersion.
+func CompareVersion(scheme VersionScheme, a string, b string) (int, error) {
+	keyA, err := parseVersion(scheme, a)
+	if err != nil {
+		return 0, err
+	}
+	keyB, err := parseVersion(scheme, b)
+	if err != nil {
+		return 0, err
+	}
+	return compareVersionKey(keyA, keyB), nil
+}
+
+// versionKey is the parsed version, compared part by part. A part is either a number or a string.
+type versionKey struct {
+	// Only set for LexicographicVersion, which is compared as is.
+	raw        *string
+	partList   []versionPart
+	prerelease []versionPart
+}
+
+type versionPart struct {
+	numeric bool
+	// Number without the leading zeros if numeric, so that we can compare arbitrary long numbers.
+	value string
+}
+
+func parseVersion(scheme VersionScheme, version string) (*versionKey, error) {
+	if version == "" {
+		return nil, fmt.Errorf("version is empty")
+	}
+	switch scheme {
+	case "", LexicographicVersion:
+		return &versionKey{raw: &version}, nil
+	case NumericVersion:
+		partList, err := parseNumberList(trimVersionPrefix(version), -1)
+		if err != nil {
+			return nil, fmt.Errorf("invalid numeric version %q, want dot separated numbers like v1 or 1.2: %w", version, err)
+		}
+		return &versionKey{partList: partList}, nil
+	case SemanticVersion:
+		key, err := parseSemanticVersion(trimVersionPrefix(version))
+		if err != nil {
+			return nil, fmt.Errorf("invalid semantic version %q, want MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]: %w", version, err)
+		}
+		return key, nil
+	case TimestampVersion:
+		key, err := parseTimestampVersion(version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp version %q, want YYYYMMDD[hhmm[ss]][.N]: %w", version, err)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown version scheme %q", scheme)
+}
+
+func trimVersionPrefix(version string) string {
+	if strings.HasPrefix(version, "v") || strings.HasPrefix(version, "V") {
+		return version[1:]
+	}
+	return version
+}
+
+// parseNumberList parses the dot separated numbers, count is the expected number count or -1 for any.
+func parseNumberList(s string, count int) ([]versionPart, error) {
+	fieldList := strings.Split(s, ".")
+	if count >= 0 && len(fieldList) != count {
+		return nil, fmt.Errorf("got %d numbers, want %d", len(fieldList), count)
+	}
+	var partList []versionPart
+	for _, field := range fieldList {
+		if !isNumber(field) {
+			return nil, fmt.Errorf("%q is not a number", field)
+		}
+		partList = append(partList, numberPart(field))
+	}
+	return partList, nil
+}
+
+func parseSemanticVersion(s string) (*versionKey, error) {
+	// Build metadata doesn't affect the precedence.
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	core, prerelease, hasPrerelease := s, "", false
+	if i := strings.Index(s, "-"); i >= 0 {
+		core, prerelease, hasPrerelease = s[:i], s[i+1:], true
+	}
+
+	partList, err := parseNumberList(core, 3)
+	if err != nil {
+		return nil, err
+	}
+	key := &versionKey{partList: partList}
+	if hasPrerelease {
+		for _, field := range strings.Split(prerelease, ".") {
+			if field == "" {
+				return nil, fmt.Errorf("empty prerelease identifier")
+			}
+			if isNumber(field) {
+				key.prerelease = append(key.prerelease, numberPart(field))
+			} else {
+				key.prerelease = append(key.prerelease, versionPart{value: field})
+			}
+		}
+	}
+	return key, nil
+}
+
+func parseTimestampVersion(s string) (*versionKey, error) {
+	timestamp, sequence := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		timestamp, sequence = s[:i], s[i+1:]
+		if !isNumber(sequence) {
+			return nil, fmt.Errorf("%q is not a number", sequence)
+		}
+	}
+
+	for _, layout := range timestampVersionLayoutList {
+		if len(timestamp) != len(layout) {
+			continue
+		}
+		t, err := time.Parse(layout, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		// Pad to the full timestamp so that different layouts are comparable.
+		key := &versionKey{partList: []versionPart{numberPart(t.Format(timestampVersionLayoutList[0]))}}
+		if sequence != "" {
+			key.partList = append(key.partList, numberPart(sequence))
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("%q is not a timestamp", timestamp)
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func numberPart(s string) versionPart {
+	value := strings.TrimLeft(s, "0")
+	if value == "" {
+		value = "0"
+	}
+	return versionPart{numeric: true, value: value}
+}
+
+func compareVersionKey(a *versionKey, b *versionKey) int {
+	if a.raw != nil && b.raw != nil {
+		return strings.Compare(*a.raw, *b.raw)
+	}
+	if c := comparePartList(a.partList, b.partList); c != 0 {
+		return c
+	}
+	// A version without prerelease has higher precedence than the one with prerelease.
+	switch {
+	case a.prerelease == nil && b.prerelease == nil:
+		return 0
+	case a.prerelease == nil:
+		return 1
+	case b.prerelease == nil:
+		return -1
+	}
+	return comparePartList(a.prerelease, b.prerelease)
+}
+
+// comparePartList compares the parts in order, the shorter list is lower if all its parts are equal, e.g. "1.2" < "1.2.0".
+func comparePartList(a []versionPart, b []versionPart) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := comparePart(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// comparePart compares numbers numerically and strings lexicographically, a number is lower than a string.
+func comparePart(a versionPart, b versionPart) int {
+	switch {
+	case a.numeric && b.numeric:
+		if len(a.value) != len(b.value) {
+			if len(a.value) < len(b.value) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.value, b.value)
+	case a.numeric:
+		return -1
+	case b.numeric:
+		return 1
+	}
+	return strings.Compare(a.value, b.value)
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareVersion(t *testing.T) {
+	type test struct {
+		scheme  VersionScheme
+		a       string
+		b       string
+		want    int
+		wantErr string
+	}
+
+	tests := []test{
+		{scheme: "", a: "v10", b: "v9", want: -1},
+		{scheme: LexicographicVersion, a: "001", b: "001", want: 0},
+		{scheme: NumericVersion, a: "v10", b: "v9", want: 1},
+		{scheme: NumericVersion, a: "1.2", b: "1.10", want: -1},
+		{scheme: NumericVersion, a: "V007", b: "7", want: 0},
+		{scheme: NumericVersion, a: "1.2", b: "1.2.0", want: -1},
+		{scheme: NumericVersion, a: "10a", b: "9", wantErr: "invalid numeric version"},
+		{scheme: SemanticVersion, a: "v1.10.0", b: "1.9.3", want: 1},
+		{scheme: SemanticVersion, a: "1.0.0-rc.1", b: "1.0.0", want: -1},
+		{scheme: SemanticVersion, a: "1.0.0-alpha.2", b: "1.0.0-alpha.10", want: -1},
+		{scheme: SemanticVersion, a: "1.0.0-alpha.beta", b: "1.0.0-alpha.1", want: 1},
+		{scheme: SemanticVersion, a: "1.0.0+build.2", b: "1.0.0+build.1", want: 0},
+		{scheme: SemanticVersion, a: "1.0", b: "1.0.0", wantErr: "invalid semantic version"},
+		{scheme: TimestampVersion, a: "20211018150405", b: "202110181504", want: 1},
+		{scheme: TimestampVersion, a: "20211018", b: "202110180000", want: 0},
+		{scheme: TimestampVersion, a: "20211018150405.9", b: "20211018150405.10", want: -1},
+		{scheme: TimestampVersion, a: "20211318", b: "20211018", wantErr: "invalid timestamp version"},
+		{scheme: "FOO", a: "1", b: "2", wantErr: "unknown version scheme"},
+	}
+
+	for _, tc := range tests {
+		got, err := CompareVersion(tc.scheme, tc.a, tc.b)
+		if err != nil {
+			if tc.wantErr == "" {
+				t.Errorf("scheme=%s, a=%s, b=%s: expected no error, got %v", tc.scheme, tc.a, tc.b, err)
+			} else if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("scheme=%s, a=%s, b=%s: expected error %s, got %v", tc.scheme, tc.a, tc.b, tc.wantErr, err)
+			}
+			continue
+		}
+		if tc.wantErr != "" {
+			t.Errorf("scheme=%s, a=%s, b=%s: expected error %s, got none", tc.scheme, tc.a, tc.b, tc.wantErr)
+		} else if got != tc.want {
+			t.Errorf("scheme=%s, a=%s, b=%s: expected %d, got %d", tc.scheme, tc.a, tc.b, tc.want, got)
+		}
+	}
+}
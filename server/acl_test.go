@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/labstack/echo/v4"
+	scas "github.com/qiangmzsx/string-adapter/v2"
+	"go.uber.org/zap"
+)
+
+// memberService returns the member of the role for any principal.
+type memberService struct {
+	api.MemberService
+	role api.Role
+}
+
+func (s *memberService) FindMember(ctx context.Context, find *api.MemberFind) (*api.Member, error) {
+	return &api.Member{PrincipalId: *find.PrincipalId, Role: s.role}, nil
+}
+
+func TestACLMiddleware(t *testing.T) {
+	m, err := model.NewModelFromString(casbinModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sa := scas.NewAdapter(strings.Join([]string{casbinOwnerPolicy, casbinDBAPolicy, casbinDeveloperPolicy}, "\n"))
+	ce, err := casbin.NewEnforcer(m, sa)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		route  string
+	}{
+		{method: http.MethodPost, path: "/api/pipeline/1/task/2/dryrun", route: "/pipeline/:pipelineId/task/:taskId/dryrun"},
+		{method: http.MethodGet, path: "/api/instance/1/capability", route: "/instance/:instanceId/capability"},
+	}
+
+	for _, role := range []api.Role{api.Owner, api.DBA, api.Developer} {
+		s := &Server{
+			MemberService: &memberService{role: role},
+			plan:          api.TEAM,
+		}
+		e := echo.New()
+		g := e.Group("/api")
+		g.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Set(GetPrincipalIdContextKey(), api.SYSTEM_BOT_ID)
+				return next(c)
+			}
+		})
+		g.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return ACLMiddleware(zap.NewNop(), s, ce, next, false)
+		})
+		for _, test := range tests {
+			g.Add(test.method, test.route, func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+		}
+
+		for _, test := range tests {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s %s as %s got status %d, want %d", test.method, test.path, role, rec.Code, http.StatusOK)
+			}
+		}
+	}
+}
@@ -18,7 +18,8 @@ type ActivityManager struct {
 }
 
 type ActivityMeta struct {
-	issue *api.Issue
+	issue    *api.Issue
+	database *api.Database
 }
 
 func NewActivityManager(server *Server, activityService api.ActivityService) *ActivityManager {
@@ -258,5 +259,94 @@ func (m *ActivityManager) CreateActivity(ctx context.Context, create *api.Activi
 		}
 	}
 
+	if meta.database != nil {
+		if err := m.postDatabaseWebhook(ctx, create, meta.database); err != nil {
+			return nil, err
+		}
+	}
+
 	return activity, nil
 }
+
+// postDatabaseWebhook posts the database activity to the webhooks of the project owning the database.
+func (m *ActivityManager) postDatabaseWebhook(ctx context.Context, create *api.ActivityCreate, database *api.Database) error {
+	hookFind := &api.ProjectWebhookFind{
+		ProjectId:    &database.ProjectId,
+		ActivityType: &create.Type,
+	}
+	hookList, err := m.s.ProjectWebhookService.FindProjectWebhookList(ctx, hookFind)
+	if err != nil {
+		return fmt.Errorf("failed to find project webhook for database activity: %v, error: %w", database.Name, err)
+	}
+	if len(hookList) == 0 {
+		return nil
+	}
+
+	if database.Project == nil {
+		projectFind := &api.ProjectFind{
+			ID: &database.ProjectId,
+		}
+		database.Project, err = m.s.ProjectService.FindProject(ctx, projectFind)
+		if err != nil {
+			return fmt.Errorf("failed to find project for posting webhook event of database activity: %v, error: %w", database.Name, err)
+		}
+	}
+
+	principalFind := &api.PrincipalFind{
+		ID: &create.CreatorId,
+	}
+	creator, err := m.s.PrincipalService.FindPrincipal(ctx, principalFind)
+	if err != nil {
+		return fmt.Errorf("failed to find creator for posting webhook event of database activity: %v, error: %w", database.Name, err)
+	}
+
+	// Call exteranl webhook endpoint in Go routine to avoid blocking the caller.
+	go func() {
+		for _, hook := range hookList {
+			level := webhook.WebhookInfo
+			title := ""
+			switch create.Type {
+			case api.ActivityDatabaseSchemaDrift:
+				level = webhook.WebhookWarn
+				title = fmt.Sprintf("Schema drift detected - %s", database.Name)
+			case api.ActivityDatabaseDataSourceGrant:
+				title = fmt.Sprintf("Temporary data source granted - %s", database.Name)
+			case api.ActivityDatabaseDataSourceExpire:
+				title = fmt.Sprintf("Temporary data source expired - %s", database.Name)
+			}
+
+			err := webhook.Post(
+				hook.Type,
+				webhook.WebhookContext{
+					URL:          hook.URL,
+					Level:        level,
+					Title:        title,
+					Description:  create.Comment,
+					Link:         fmt.Sprintf("%s:%d/db/%s", m.s.frontendHost, m.s.frontendPort, api.DatabaseSlug(database)),
+					CreatorName:  creator.Name,
+					CreatorEmail: creator.Email,
+					CreatedTs:    time.Now().Unix(),
+					MetaList: []webhook.WebhookMeta{
+						{
+							Name:  "Database",
+							Value: database.Name,
+						},
+						{
+							Name:  "Project",
+							Value: database.Project.Name,
+						},
+					},
+				},
+			)
+			if err != nil {
+				// The external webhook endpoint might be invalid which is out of our code control, so we just emit a warning
+				m.s.l.Warn("Failed to post webhook event of database activity",
+					zap.String("database_name", database.Name),
+					zap.String("type", string(create.Type)),
+					zap.Error(err))
+			}
+		}
+	}()
+
+	return nil
+}
@@ -67,6 +67,12 @@ func (s *BackupRunner) Run() error {
 						continue
 					}
 					backupSetting.Database = database
+					if err := checkTaskCapability(database.Instance, api.TaskDatabaseBackup); err != nil {
+						s.l.Debug("Skip automatic backup for database",
+							zap.Int("databaseID", database.ID),
+							zap.String("error", err.Error()))
+						continue
+					}
 
 					backupName := fmt.Sprintf("%s-%s-%s-autobackup", api.ProjectShortSlug(database.Project), api.EnvSlug(database.Instance.Environment), t.Format("20060102T030405"))
 					go func(database *api.Database, backupName string) {
@@ -93,7 +99,7 @@ func (s *BackupRunner) scheduleBackupTask(database *api.Database, backupName str
 	}
 
 	// Store the migration history version if exists.
-	migrationHistoryVersion, err := getMigrationVersion(database, s.l)
+	migrationHistoryVersion, err := getMigrationVersion(s.server, database)
 	if err != nil {
 		return fmt.Errorf("failed to get migration history for database %q: %w", database.Name, err)
 	}
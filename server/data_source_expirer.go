@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+	"go.uber.org/zap"
+)
+
+const (
+	DATA_SOURCE_EXPIRE_INTERVAL = time.Duration(1) * time.Minute
+)
+
+func NewDataSourceExpirer(logger *zap.Logger, server *Server) *DataSourceExpirer {
+	return &DataSourceExpirer{
+		l:      logger,
+		server: server,
+	}
+}
+
+// DataSourceExpirer drops the users of the expired temporary data sources granted by the data source requests,
+// and deletes the data sources.
+type DataSourceExpirer struct {
+	l      *zap.Logger
+	server *Server
+}
+
+func (s *DataSourceExpirer) Run() error {
+	go func() {
+		s.l.Debug(fmt.Sprintf("Data source expirer started and will run every %v", DATA_SOURCE_EXPIRE_INTERVAL))
+		for {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						err, ok := r.(error)
+						if !ok {
+							err = fmt.Errorf("%v", r)
+						}
+						s.l.Error("Data source expirer PANIC RECOVER", zap.Error(err))
+					}
+				}()
+
+				now := time.Now().Unix()
+				dataSourceFind := &api.DataSourceFind{
+					ExpireTsBefore: &now,
+				}
+				list, err := s.server.DataSourceService.FindDataSourceList(context.Background(), dataSourceFind)
+				if err != nil {
+					s.l.Error("Failed to retrieve expired data sources", zap.Error(err))
+					return
+				}
+
+				for _, dataSource := range list {
+					// Keep the data source on failure to retry in the next round.
+					if err := s.expireDataSource(context.Background(), dataSource); err != nil {
+						s.l.Error("Failed to expire data source",
+							zap.Int("id", dataSource.ID),
+							zap.String("name", dataSource.Name),
+							zap.String("error", err.Error()))
+					}
+				}
+			}()
+
+			time.Sleep(DATA_SOURCE_EXPIRE_INTERVAL)
+		}
+	}()
+
+	return nil
+}
+
+func (s *DataSourceExpirer) expireDataSource(ctx context.Context, dataSource *api.DataSource) error {
+	database, err := s.server.ComposeDatabaseByFind(ctx, &api.DatabaseFind{
+		ID: &dataSource.DatabaseId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find database %d: %w", dataSource.DatabaseId, err)
+	}
+
+	driver, err := s.server.GetDatabaseDriver(database.Instance, database.Name, api.Admin)
+	if err != nil {
+		return err
+	}
+	defer driver.Close(context.Background())
+
+	user, err := driver.RevokeDatabase(ctx, &db.DatabaseGrant{
+		Username: dataSource.Username,
+		Database: database.Name,
+		DropUser: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to drop user %s: %w", dataSource.Username, err)
+	}
+	if err := s.server.syncInstanceUser(ctx, database.Instance, user, true); err != nil {
+		return err
+	}
+
+	if err := s.server.DataSourceService.DeleteDataSource(ctx, &api.DataSourceDelete{ID: dataSource.ID}); err != nil {
+		return fmt.Errorf("failed to delete data source: %w", err)
+	}
+
+	if err := s.server.createDataSourceActivity(ctx, api.ActivityDatabaseDataSourceExpire, database, dataSource,
+		fmt.Sprintf("Temporary %s data source %q on database %q expired, user %s dropped.",
+			dataSource.Type, dataSource.Name, database.Name, dataSource.Username)); err != nil {
+		s.l.Error("Failed to create data source expire activity", zap.Error(err))
+	}
+
+	s.l.Info("Expired temporary data source",
+		zap.String("instance", database.Instance.Name),
+		zap.String("database", database.Name),
+		zap.String("name", dataSource.Name),
+		zap.String("user", dataSource.Username))
+	return nil
+}
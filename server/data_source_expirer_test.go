@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bytebase/bytebase/api"
+	"go.uber.org/zap"
+)
+
+func TestExpireDataSource(t *testing.T) {
+	s, opener := newTestServer(t)
+	ctx := context.Background()
+	task := createDataSourceRequestTask(t, s, api.RO, 600)
+	if _, _, err := NewDataSourceRequestTaskExecutor(zap.NewNop()).RunOnce(ctx, s, task); err != nil {
+		t.Fatalf("failed to run the data source request task: %v", err)
+	}
+
+	databaseId := 7002
+	dataSourceList, err := s.DataSourceService.FindDataSourceList(ctx, &api.DataSourceFind{DatabaseId: &databaseId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataSourceList) != 1 {
+		t.Fatalf("got %d data sources on the database, want 1", len(dataSourceList))
+	}
+	dataSource := dataSourceList[0]
+
+	// The expirer only picks up the data source after its TTL.
+	for _, test := range []struct {
+		expireTsBefore int64
+		want           int
+	}{
+		{expireTsBefore: dataSource.ExpireTs - 1, want: 0},
+		{expireTsBefore: dataSource.ExpireTs + 1, want: 1},
+	} {
+		expiredList, err := s.DataSourceService.FindDataSourceList(ctx, &api.DataSourceFind{ExpireTsBefore: &test.expireTsBefore})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(expiredList) != test.want {
+			t.Errorf("got %d data sources expiring before %d, want %d", len(expiredList), test.expireTsBefore, test.want)
+		}
+	}
+
+	expirer := NewDataSourceExpirer(zap.NewNop(), s)
+	if err := expirer.expireDataSource(ctx, dataSource); err != nil {
+		t.Fatalf("failed to expire data source: %v", err)
+	}
+
+	// The user is dropped with the ADMIN data source, which terminates the sessions of the user as well.
+	driverList := opener.list()
+	if len(driverList) != 1 || driverList[0].dataSourceId != 8001 {
+		t.Fatalf("got %d drivers opened, want 1 with the ADMIN data source 8001", len(driverList))
+	}
+	revokeList := driverList[0].revokeList
+	if len(revokeList) != 1 {
+		t.Fatalf("got %d revokes, want 1", len(revokeList))
+	}
+	if revoke := revokeList[0]; revoke.Username != dataSource.Username || revoke.Database != "testdb_dev" || !revoke.DropUser {
+		t.Errorf("got revoke %+v, want dropping user %s on database testdb_dev", revoke, dataSource.Username)
+	}
+
+	dataSourceList, err = s.DataSourceService.FindDataSourceList(ctx, &api.DataSourceFind{DatabaseId: &databaseId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataSourceList) != 0 {
+		t.Errorf("got %d data sources on the database after expiring, want 0", len(dataSourceList))
+	}
+	instanceUserList, err := s.InstanceUserService.FindInstanceUserList(ctx, &api.InstanceUserFind{InstanceId: 6001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, instanceUser := range instanceUserList {
+		if instanceUser.Name == dataSource.Username {
+			t.Errorf("got the dropped user %s still synced to the instance", dataSource.Username)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bytebase/bytebase/api"
@@ -199,6 +200,15 @@ func (s *Server) registerDatabaseRoutes(g *echo.Group) {
 			if err != nil {
 				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch index list for database id: %d, table name: %s", id, table.Name)).SetInternal(err)
 			}
+
+			constraintFind := &api.ConstraintFind{
+				DatabaseId: &id,
+				TableId:    &table.ID,
+			}
+			table.ConstraintList, err = s.ConstraintService.FindConstraintList(context.Background(), constraintFind)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch constraint list for database id: %d, table name: %s", id, table.Name)).SetInternal(err)
+			}
 		}
 
 		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
@@ -255,6 +265,15 @@ func (s *Server) registerDatabaseRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch index list for database id: %d, table name: %s", id, table.Name)).SetInternal(err)
 		}
 
+		constraintFind := &api.ConstraintFind{
+			DatabaseId: &id,
+			TableId:    &table.ID,
+		}
+		table.ConstraintList, err = s.ConstraintService.FindConstraintList(context.Background(), constraintFind)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch constraint list for database id: %d, table name: %s", id, table.Name)).SetInternal(err)
+		}
+
 		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
 		if err := jsonapi.MarshalPayload(c.Response().Writer, table); err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal fetch table response: %v", id)).SetInternal(err)
@@ -262,6 +281,260 @@ func (s *Server) registerDatabaseRoutes(g *echo.Group) {
 		return nil
 	})
 
+	g.GET("/database/:id/view", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		databaseFind := &api.DatabaseFind{
+			ID: &id,
+		}
+		if _, err := s.DatabaseService.FindDatabase(context.Background(), databaseFind); err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+
+		viewFind := &api.ViewFind{
+			DatabaseId: id,
+		}
+		viewList, err := s.ViewService.FindViewList(context.Background(), viewFind)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch view list for database id: %d", id)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, viewList); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal fetch view list response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
+
+	g.GET("/database/:id/routine", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		databaseFind := &api.DatabaseFind{
+			ID: &id,
+		}
+		if _, err := s.DatabaseService.FindDatabase(context.Background(), databaseFind); err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+
+		routineFind := &api.RoutineFind{
+			DatabaseId: id,
+		}
+		routineList, err := s.RoutineService.FindRoutineList(context.Background(), routineFind)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch routine list for database id: %d", id)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, routineList); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal fetch routine list response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
+
+	g.GET("/database/:id/trigger", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		databaseFind := &api.DatabaseFind{
+			ID: &id,
+		}
+		if _, err := s.DatabaseService.FindDatabase(context.Background(), databaseFind); err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+
+		triggerFind := &api.TriggerFind{
+			DatabaseId: id,
+		}
+		triggerList, err := s.TriggerService.FindTriggerList(context.Background(), triggerFind)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch trigger list for database id: %d", id)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, triggerList); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal fetch trigger list response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
+
+	g.GET("/database/:id/event", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		databaseFind := &api.DatabaseFind{
+			ID: &id,
+		}
+		if _, err := s.DatabaseService.FindDatabase(context.Background(), databaseFind); err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+
+		eventFind := &api.EventFind{
+			DatabaseId: id,
+		}
+		eventList, err := s.EventService.FindEventList(context.Background(), eventFind)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch event list for database id: %d", id)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, eventList); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal fetch event list response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
+
+	g.GET("/database/:id/drift", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		databaseFind := &api.DatabaseFind{
+			ID: &id,
+		}
+		if _, err := s.DatabaseService.FindDatabase(context.Background(), databaseFind); err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+
+		driftFind := &api.SchemaDriftFind{
+			DatabaseId: &id,
+		}
+		if statusStr := c.QueryParam("status"); statusStr != "" {
+			status := api.SchemaDriftStatus(statusStr)
+			driftFind.Status = &status
+		}
+		driftList, err := s.SchemaDriftService.FindSchemaDriftList(context.Background(), driftFind)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch schema drift list for database id: %d", id)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, driftList); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal fetch schema drift list response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
+
+	// Returns the unexpired temporary data sources granted to the caller on the database by the data source requests.
+	g.GET("/database/:id/datasource", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		databaseFind := &api.DatabaseFind{
+			ID: &id,
+		}
+		if _, err := s.DatabaseService.FindDatabase(context.Background(), databaseFind); err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+
+		principalId := c.Get(GetPrincipalIdContextKey()).(int)
+		dataSourceFind := &api.DataSourceFind{
+			CreatorId:  &principalId,
+			DatabaseId: &id,
+		}
+		list, err := s.DataSourceService.FindDataSourceList(context.Background(), dataSourceFind)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch data source list for database id: %d", id)).SetInternal(err)
+		}
+		now := time.Now().Unix()
+		dataSourceList := []*api.DataSource{}
+		for _, dataSource := range list {
+			if dataSource.ExpireTs > now {
+				dataSourceList = append(dataSourceList, dataSource)
+			}
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, dataSourceList); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal fetch data source list response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
+
+	g.GET("/database/:id/indexadvice", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		databaseFind := &api.DatabaseFind{
+			ID: &id,
+		}
+		database, err := s.ComposeDatabaseByFind(context.Background(), databaseFind)
+		if err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+
+		tableList, err := s.findSyncedTableList(context.Background(), database)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch table list for database id: %d", id)).SetInternal(err)
+		}
+
+		report := &api.IndexAdviceReport{
+			DatabaseId: id,
+		}
+		usage, err := s.findIndexUsage(context.Background(), database)
+		if err != nil {
+			report.UsageError = err.Error()
+		} else {
+			report.UsageSinceTs = usage.SinceTs
+		}
+
+		report.AdviceList = []*api.IndexAdvice{}
+		var statementList []string
+		for _, advice := range db.AdviseIndex(database.Instance.Engine, tableList, usage) {
+			report.AdviceList = append(report.AdviceList, &api.IndexAdvice{
+				Type:              advice.Type,
+				TableName:         advice.TableName,
+				IndexName:         advice.IndexName,
+				CoveringIndexName: advice.CoveringIndexName,
+				Reason:            advice.Reason,
+				Statement:         advice.Statement,
+			})
+			statementList = append(statementList, advice.Statement)
+		}
+		report.Statement = strings.Join(statementList, "\n")
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, report); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal index advice response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
+
 	g.POST("/database/:id/backup", func(c echo.Context) error {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
@@ -285,12 +558,16 @@ func (s *Server) registerDatabaseRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
 		}
 
+		if err := checkTaskCapability(database.Instance, api.TaskDatabaseBackup); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, common.ErrorMessage(err)).SetInternal(err)
+		}
+
 		backupCreate.Path, err = getAndCreateBackupPath(s.dataDir, database, backupCreate.Name)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to create backup directory for database ID: %v", id)).SetInternal(err)
 		}
 
-		version, err := getMigrationVersion(database, s.l)
+		version, err := getMigrationVersion(s, database)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to get migration history for database %q", database.Name)).SetInternal(err)
 		}
@@ -393,6 +670,190 @@ func (s *Server) registerDatabaseRoutes(g *echo.Group) {
 		return nil
 	})
 
+	// Rolling back goes through a regular schema update issue, so it follows the same approval and audit as any other change.
+	g.POST("/database/:id/migration/:migrationId/rollback", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+		migrationId, err := strconv.Atoi(c.Param("migrationId"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Migration ID is not a number: %s", c.Param("migrationId"))).SetInternal(err)
+		}
+
+		migrationRollback := &api.MigrationRollback{}
+		if err := jsonapi.UnmarshalPayload(c.Request().Body, migrationRollback); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformatted rollback migration request").SetInternal(err)
+		}
+		migrationRollback.CreatorId = c.Get(GetPrincipalIdContextKey()).(int)
+
+		databaseFind := &api.DatabaseFind{
+			ID: &id,
+		}
+		database, err := s.ComposeDatabaseByFind(context.Background(), databaseFind)
+		if err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+
+		driver, err := s.GetDatabaseDriver(database.Instance, database.Name, api.RO)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to connect to database %q", database.Name)).SetInternal(err)
+		}
+		defer driver.Close(context.Background())
+
+		historyList, err := driver.FindMigrationHistoryList(context.Background(), &db.MigrationHistoryFind{
+			ID:       &migrationId,
+			Database: &database.Name,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch migration history for database %q", database.Name)).SetInternal(err)
+		}
+		if len(historyList) == 0 {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Migration ID not found for database %q: %d", database.Name, migrationId))
+		}
+		history := historyList[0]
+		if history.RollbackStatement == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Migration version %s of database %q has no rollback statement recorded", history.Version, database.Name))
+		}
+
+		taskStatus := migrationTaskStatus(database.Instance.Environment, db.Rollback)
+		issueCreate := &api.IssueCreate{
+			ProjectId: database.ProjectId,
+			Pipeline: api.PipelineCreate{
+				StageList: []api.StageCreate{
+					{
+						EnvironmentId: database.Instance.EnvironmentId,
+						TaskList: []api.TaskCreate{
+							{
+								InstanceId: database.InstanceId,
+								DatabaseId: &database.ID,
+								Name:       fmt.Sprintf("Rollback version %s", history.Version),
+								Status:     taskStatus,
+								Type:       api.TaskDatabaseSchemaUpdate,
+								Statement:  history.RollbackStatement,
+								// Keep the rolled back statement so that the rollback itself can be rolled back.
+								RollbackStatement: history.Statement,
+								MigrationType:     db.Rollback,
+							},
+						},
+						Name: database.Instance.Environment.Name,
+					},
+				},
+				Name: fmt.Sprintf("Pipeline - Rollback %s version %s", database.Name, history.Version),
+			},
+			Name:        fmt.Sprintf("Rollback %s version %s", database.Name, history.Version),
+			Type:        api.IssueDatabaseSchemaUpdate,
+			Description: fmt.Sprintf("Roll back migration version %s (%s) of database %q.", history.Version, history.Description, database.Name),
+			AssigneeId:  migrationRollback.AssigneeId,
+		}
+		issue, err := s.CreateIssue(context.Background(), issueCreate, migrationRollback.CreatorId)
+		if err != nil {
+			if common.ErrorCode(err) == common.ENOTIMPLEMENTED {
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorMessage(err)).SetInternal(err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to create issue to rollback migration version %s", history.Version)).SetInternal(err)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, issue); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal rollback migration response").SetInternal(err)
+		}
+		return nil
+	})
+
+	// Returns the migration history of the database after importing, most recent first.
+	g.POST("/database/:id/migration/import", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		migrationImport := &api.MigrationHistoryImport{}
+		if err := jsonapi.UnmarshalPayload(c.Request().Body, migrationImport); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformatted import migration history request").SetInternal(err)
+		}
+		if migrationImport.Engine != db.Flyway && migrationImport.Engine != db.Liquibase {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid migration engine %q, only %s and %s are supported", migrationImport.Engine, db.Flyway, db.Liquibase))
+		}
+
+		databaseFind := &api.DatabaseFind{
+			ID: &id,
+		}
+		database, err := s.ComposeDatabaseByFind(context.Background(), databaseFind)
+		if err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+
+		driver, err := s.GetDatabaseDriver(database.Instance, database.Name, api.RW)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to connect to database %q", database.Name)).SetInternal(err)
+		}
+		defer driver.Close(context.Background())
+
+		setup, err := driver.NeedsSetupMigration(context.Background())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to check migration setup for instance %q", database.Instance.Name)).SetInternal(err)
+		}
+		if setup {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Missing or outdated migration schema for instance %q", database.Instance.Name))
+		}
+
+		count, err := driver.ImportMigrationHistory(context.Background(), &db.MigrationHistoryImport{
+			Engine:      migrationImport.Engine,
+			Namespace:   database.Name,
+			Table:       migrationImport.Table,
+			LockTimeout: s.migrationLockTimeout,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to import %s migration history for database %q", migrationImport.Engine, database.Name)).SetInternal(err)
+		}
+		s.l.Info("Imported migration history",
+			zap.String("instance", database.Instance.Name),
+			zap.String("database", database.Name),
+			zap.String("engine", migrationImport.Engine.String()),
+			zap.Int("count", count),
+		)
+
+		list, err := driver.FindMigrationHistoryList(context.Background(), &db.MigrationHistoryFind{
+			Database: &database.Name,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch migration history for database %q", database.Name)).SetInternal(err)
+		}
+		historyList := []*api.MigrationHistory{}
+		for _, entry := range list {
+			historyList = append(historyList, &api.MigrationHistory{
+				ID:                entry.ID,
+				Creator:           entry.Creator,
+				CreatedTs:         entry.CreatedTs,
+				Updater:           entry.Updater,
+				UpdatedTs:         entry.UpdatedTs,
+				Database:          entry.Namespace,
+				Engine:            entry.Engine,
+				Type:              entry.Type,
+				Version:           entry.Version,
+				Description:       entry.Description,
+				Statement:         entry.Statement,
+				RollbackStatement: entry.RollbackStatement,
+				ExecutionDuration: entry.ExecutionDuration,
+				IssueId:           entry.IssueId,
+				Payload:           entry.Payload,
+			})
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, historyList); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal migration history response for database %q", database.Name)).SetInternal(err)
+		}
+		return nil
+	})
+
 	g.PATCH("/database/:id/backupsetting", func(c echo.Context) error {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
@@ -408,13 +869,19 @@ func (s *Server) registerDatabaseRoutes(g *echo.Group) {
 		databaseFind := &api.DatabaseFind{
 			ID: &id,
 		}
-		_, err = s.ComposeDatabaseByFind(context.Background(), databaseFind)
+		database, err := s.ComposeDatabaseByFind(context.Background(), databaseFind)
 		if err != nil {
 			if common.ErrorCode(err) == common.ENOTFOUND {
 				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
 			}
 			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
 		}
+		// Disabling is always allowed, e.g. for the settings enabled before the engine capability was checked.
+		if backupSettingUpsert.Enabled {
+			if err := checkTaskCapability(database.Instance, api.TaskDatabaseBackup); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+		}
 
 		backupSetting, err := s.BackupService.UpsertBackupSetting(context.Background(), backupSettingUpsert)
 		if err != nil {
@@ -562,18 +1029,175 @@ func (s *Server) ComposeBackupRelationship(ctx context.Context, backup *api.Back
 	return nil
 }
 
-// Retrieve db.Driver connection.
-// Upon successful return, caller MUST call driver.Close, otherwise, it will leak the database connection.
-func GetDatabaseDriver(instance *api.Instance, databaseName string, logger *zap.Logger) (db.Driver, error) {
+// Retrieve db.Driver connection from the driver pool, connecting with the data source picked for the operation
+// requiring the data source type, see findDataSource:
+// - ADMIN for creating databases, backup, restore and the migration schema setup.
+// - RW for the migrations.
+// - RO for the schema sync and reading the migration history.
+// The user queries use GetStrictDatabaseDriver instead.
+// Upon successful return, caller MUST call driver.Close, otherwise, the driver will never be released back to the pool.
+func (s *Server) GetDatabaseDriver(instance *api.Instance, databaseName string, dataSourceType api.DataSourceType) (db.Driver, error) {
+	dataSource, err := s.findDataSource(context.Background(), instance, databaseName, dataSourceType, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.DriverPool.Get(instance, databaseName, dataSource)
+}
+
+// GetStrictDatabaseDriver is GetDatabaseDriver without falling back to the more privileged data source type, used for
+// the operations on behalf of the users, e.g. the user queries never connect with the RW or ADMIN data source.
+// Upon successful return, caller MUST call driver.Close, otherwise, the driver will never be released back to the pool.
+func (s *Server) GetStrictDatabaseDriver(instance *api.Instance, databaseName string, dataSourceType api.DataSourceType) (db.Driver, error) {
+	dataSource, err := s.findDataSource(context.Background(), instance, databaseName, dataSourceType, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.DriverPool.Get(instance, databaseName, dataSource)
+}
+
+// findDataSource returns the data source of the instance to connect the database with for the data source type.
+// The data source of the database itself is preferred over the instance wide one, which belongs to the
+// ALL_DATABASE_NAME database same as the admin data source. If neither exists and fallback is true, it falls back
+// to the more privileged type, i.e. RO falls back to RW, and RW falls back to ADMIN.
+func (s *Server) findDataSource(ctx context.Context, instance *api.Instance, databaseName string, dataSourceType api.DataSourceType, fallback bool) (*api.DataSource, error) {
+	dataSourceFind := &api.DataSourceFind{
+		InstanceId: &instance.ID,
+	}
+	dataSourceList, err := s.DataSourceService.FindDataSourceList(ctx, dataSourceFind)
+	if err != nil {
+		return nil, err
+	}
+	databaseFind := &api.DatabaseFind{
+		InstanceId:         &instance.ID,
+		IncludeAllDatabase: true,
+	}
+	databaseList, err := s.DatabaseService.FindDatabaseList(ctx, databaseFind)
+	if err != nil {
+		return nil, err
+	}
+
+	// The databases to look for the data source in order.
+	var databaseIdList []int
+	for _, name := range []string{databaseName, api.ALL_DATABASE_NAME} {
+		for _, database := range databaseList {
+			if name != "" && database.Name == name {
+				databaseIdList = append(databaseIdList, database.ID)
+			}
+		}
+	}
+
+	var typeList []api.DataSourceType
+	switch {
+	case !fallback:
+		typeList = []api.DataSourceType{dataSourceType}
+	case dataSourceType == api.RO:
+		typeList = []api.DataSourceType{api.RO, api.RW, api.Admin}
+	case dataSourceType == api.RW:
+		typeList = []api.DataSourceType{api.RW, api.Admin}
+	default:
+		typeList = []api.DataSourceType{api.Admin}
+	}
+	for _, t := range typeList {
+		for _, databaseId := range databaseIdList {
+			for _, dataSource := range dataSourceList {
+				// The temporary data sources granted by the data source requests belong to the requesters.
+				if dataSource.ExpireTs != 0 {
+					continue
+				}
+				if dataSource.Type == t && dataSource.DatabaseId == databaseId {
+					return dataSource, nil
+				}
+			}
+		}
+	}
+	return nil, &common.Error{Code: common.ENOTFOUND, Message: fmt.Sprintf("missing %s data source for database %s/%s", dataSourceType, instance.Name, databaseName)}
+}
+
+// findSyncedTableList returns the tables of the database with the indexes and constraints as of the last schema sync.
+// The tables and indexes no longer found by the sync are left out.
+func (s *Server) findSyncedTableList(ctx context.Context, database *api.Database) ([]db.DBTable, error) {
+	tableList, err := s.TableService.FindTableList(ctx, &api.TableFind{DatabaseId: &database.ID})
+	if err != nil {
+		return nil, err
+	}
+	indexList, err := s.IndexService.FindIndexList(ctx, &api.IndexFind{DatabaseId: &database.ID})
+	if err != nil {
+		return nil, err
+	}
+	constraintList, err := s.ConstraintService.FindConstraintList(ctx, &api.ConstraintFind{DatabaseId: &database.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	var dbTableList []db.DBTable
+	for _, table := range tableList {
+		if table.SyncStatus != api.OK {
+			continue
+		}
+		dbTable := db.DBTable{
+			Name: table.Name,
+			Type: table.Type,
+		}
+		for _, index := range indexList {
+			if index.TableId == table.ID && index.SyncStatus == api.OK {
+				dbTable.IndexList = append(dbTable.IndexList, db.DBIndex{
+					Name:       index.Name,
+					Expression: index.Expression,
+					Position:   index.Position,
+					Type:       index.Type,
+					Unique:     index.Unique,
+					Visible:    index.Visible,
+				})
+			}
+		}
+		for _, constraint := range constraintList {
+			if constraint.TableId == table.ID {
+				dbTable.ConstraintList = append(dbTable.ConstraintList, db.DBConstraint{
+					Name:       constraint.Name,
+					Type:       constraint.Type,
+					ColumnList: constraint.ColumnList,
+				})
+			}
+		}
+		dbTableList = append(dbTableList, dbTable)
+	}
+	return dbTableList, nil
+}
+
+// findIndexUsage returns the index usage of the database from the instance.
+func (s *Server) findIndexUsage(ctx context.Context, database *api.Database) (*db.IndexUsageStats, error) {
+	capability, err := db.GetCapability(database.Instance.Engine)
+	if err != nil {
+		return nil, err
+	}
+	if !capability.Supports(db.OperationIndexUsage) {
+		return nil, fmt.Errorf("database type %s doesn't support index usage statistics", database.Instance.Engine)
+	}
+
+	driver, err := s.GetDatabaseDriver(database.Instance, database.Name, api.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer driver.Close(ctx)
+
+	return driver.FindIndexUsage(ctx, database.Name)
+}
+
+// openDataSourceDriver opens a new db.Driver connection with the credentials of the data source bypassing the driver pool.
+// The driver opens at most maxOpenConns connections, 0 means unlimited.
+func openDataSourceDriver(instance *api.Instance, databaseName string, dataSource *api.DataSource, maxOpenConns int, logger *zap.Logger) (db.Driver, error) {
 	driver, err := db.Open(
 		instance.Engine,
 		db.DriverConfig{Logger: logger},
 		db.ConnectionConfig{
-			Username: instance.Username,
-			Password: instance.Password,
-			Host:     instance.Host,
-			Port:     instance.Port,
-			Database: databaseName,
+			Username:     dataSource.Username,
+			Password:     dataSource.Password,
+			Host:         instance.Host,
+			Port:         instance.Port,
+			Database:     databaseName,
+			MaxOpenConns: maxOpenConns,
+			// The idle drivers are evicted from the pool anyway, no need to keep many idle connections.
+			MaxIdleConns: 1,
 		},
 		db.ConnectionContext{
 			EnvironmentName: instance.Environment.Name,
@@ -581,7 +1205,7 @@ func GetDatabaseDriver(instance *api.Instance, databaseName string, logger *zap.
 		},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect database %s/%s at %q:%q with user %q: %w", instance.Name, databaseName, instance.Host, instance.Port, instance.Username, err)
+		return nil, fmt.Errorf("failed to connect database %s/%s at %q:%q with user %q: %w", instance.Name, databaseName, instance.Host, instance.Port, dataSource.Username, err)
 	}
 	return driver, nil
 }
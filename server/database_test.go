@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/labstack/echo/v4"
+)
+
+func TestFindDataSource(t *testing.T) {
+	// The data sources created on the MySQL instance 6001 besides the seeded ADMIN data source 8001 on the
+	// ALL_DATABASE_NAME database 7001.
+	type dataSource struct {
+		name       string
+		typ        api.DataSourceType
+		databaseId int
+		temporary  bool
+	}
+	const (
+		allDatabaseId = 7001
+		databaseId    = 7002
+	)
+	tests := []struct {
+		name           string
+		dataSourceList []dataSource
+		dataSourceType api.DataSourceType
+		strict         bool
+		// The name of the data source to connect with, "admin" for 8001. Empty means not found.
+		want string
+	}{
+		{
+			name: "RO prefers RO",
+			dataSourceList: []dataSource{
+				{name: "rw", typ: api.RW, databaseId: databaseId},
+				{name: "ro", typ: api.RO, databaseId: databaseId},
+			},
+			dataSourceType: api.RO,
+			want:           "ro",
+		},
+		{
+			name: "RO prefers the database over the instance",
+			dataSourceList: []dataSource{
+				{name: "instance ro", typ: api.RO, databaseId: allDatabaseId},
+				{name: "ro", typ: api.RO, databaseId: databaseId},
+			},
+			dataSourceType: api.RO,
+			want:           "ro",
+		},
+		{
+			name: "RO falls back to the instance RO",
+			dataSourceList: []dataSource{
+				{name: "instance ro", typ: api.RO, databaseId: allDatabaseId},
+				{name: "rw", typ: api.RW, databaseId: databaseId},
+			},
+			dataSourceType: api.RO,
+			want:           "instance ro",
+		},
+		{
+			name: "RO falls back to RW",
+			dataSourceList: []dataSource{
+				{name: "instance rw", typ: api.RW, databaseId: allDatabaseId},
+			},
+			dataSourceType: api.RO,
+			want:           "instance rw",
+		},
+		{
+			name:           "RO falls back to ADMIN",
+			dataSourceType: api.RO,
+			want:           "admin",
+		},
+		{
+			name: "RO skips the temporary data source",
+			dataSourceList: []dataSource{
+				{name: "temporary ro", typ: api.RO, databaseId: databaseId, temporary: true},
+			},
+			dataSourceType: api.RO,
+			want:           "admin",
+		},
+		{
+			name: "RW never falls back to RO",
+			dataSourceList: []dataSource{
+				{name: "ro", typ: api.RO, databaseId: databaseId},
+			},
+			dataSourceType: api.RW,
+			want:           "admin",
+		},
+		{
+			name:           "ADMIN",
+			dataSourceType: api.Admin,
+			want:           "admin",
+		},
+		{
+			name: "strict RO",
+			dataSourceList: []dataSource{
+				{name: "instance ro", typ: api.RO, databaseId: allDatabaseId},
+				{name: "rw", typ: api.RW, databaseId: databaseId},
+			},
+			dataSourceType: api.RO,
+			strict:         true,
+			want:           "instance ro",
+		},
+		{
+			name: "strict RO without RO",
+			dataSourceList: []dataSource{
+				{name: "rw", typ: api.RW, databaseId: databaseId},
+			},
+			dataSourceType: api.RO,
+			strict:         true,
+		},
+		{
+			name: "strict RO without the permanent RO",
+			dataSourceList: []dataSource{
+				{name: "temporary ro", typ: api.RO, databaseId: databaseId, temporary: true},
+			},
+			dataSourceType: api.RO,
+			strict:         true,
+		},
+		{
+			name: "strict RW without RW",
+			dataSourceList: []dataSource{
+				{name: "ro", typ: api.RO, databaseId: databaseId},
+			},
+			dataSourceType: api.RW,
+			strict:         true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, opener := newTestServer(t)
+			ctx := context.Background()
+			idMap := map[int]string{8001: "admin"}
+			for _, ds := range test.dataSourceList {
+				dataSourceCreate := &api.DataSourceCreate{
+					CreatorId:  api.SYSTEM_BOT_ID,
+					InstanceId: 6001,
+					DatabaseId: ds.databaseId,
+					Name:       ds.name,
+					Type:       ds.typ,
+					Username:   "user",
+				}
+				if ds.temporary {
+					dataSourceCreate.ExpireTs = time.Now().Add(time.Hour).Unix()
+				}
+				created, err := s.DataSourceService.CreateDataSource(ctx, dataSourceCreate)
+				if err != nil {
+					t.Fatalf("failed to create data source %q: %v", ds.name, err)
+				}
+				idMap[created.ID] = ds.name
+			}
+			instanceId := 6001
+			instance, err := s.InstanceService.FindInstance(ctx, &api.InstanceFind{ID: &instanceId})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			getDriver := s.GetDatabaseDriver
+			if test.strict {
+				getDriver = s.GetStrictDatabaseDriver
+			}
+			driver, err := getDriver(instance, "testdb_dev", test.dataSourceType)
+			if test.want == "" {
+				if err == nil {
+					driver.Close(ctx)
+					t.Fatalf("got %s data source %q, want not found", test.dataSourceType, idMap[opener.list()[0].dataSourceId])
+				}
+				if common.ErrorCode(err) != common.ENOTFOUND {
+					t.Errorf("got error %v, want not found", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get %s driver: %v", test.dataSourceType, err)
+			}
+			defer driver.Close(ctx)
+			if got := idMap[opener.list()[0].dataSourceId]; got != test.want {
+				t.Errorf("got %s data source %q, want %q", test.dataSourceType, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTableConstraint(t *testing.T) {
+	s, _ := newTestServer(t)
+	ctx := context.Background()
+	instanceId, databaseId, tableName := 6001, 7002, "tbl1"
+	instance, err := s.InstanceService.FindInstance(ctx, &api.InstanceFind{ID: &instanceId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err := s.DatabaseService.FindDatabase(ctx, &api.DatabaseFind{ID: &databaseId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := s.TableService.FindTable(ctx, &api.TableFind{DatabaseId: &databaseId, Name: &tableName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	primaryKey := db.DBConstraint{
+		Name:       "PRIMARY",
+		Type:       "PRIMARY KEY",
+		ColumnList: []string{"id"},
+	}
+	foreignKey := db.DBConstraint{
+		Name:                 "fk_tbl1_tbl2",
+		Type:                 "FOREIGN KEY",
+		ColumnList:           []string{"tbl2_id", "tbl2_version"},
+		ReferencedTable:      "other_db.tbl2",
+		ReferencedColumnList: []string{"id", "version"},
+		UpdateRule:           "CASCADE",
+		DeleteRule:           "RESTRICT",
+	}
+	check := db.DBConstraint{
+		Name:       "chk_id",
+		Type:       "CHECK",
+		Expression: "(`id` > 0)",
+	}
+	if err := s.syncTableConstraintList(instance, database, table, []db.DBConstraint{primaryKey, foreignKey, check}); err != nil {
+		t.Fatalf("failed to sync constraints: %v", err)
+	}
+	// The next sync deletes the dropped constraints and updates the changed ones.
+	foreignKey.DeleteRule = "SET NULL"
+	if err := s.syncTableConstraintList(instance, database, table, []db.DBConstraint{primaryKey, foreignKey}); err != nil {
+		t.Fatalf("failed to sync constraints: %v", err)
+	}
+
+	e := echo.New()
+	s.registerDatabaseRoutes(e.Group("/api"))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/database/7002/table/tbl1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d fetching the table, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var response struct {
+		Data struct {
+			Attributes struct {
+				ConstraintList []*api.Constraint `json:"constraintList"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal the table response: %v", err)
+	}
+
+	var got []db.DBConstraint
+	for _, constraint := range response.Data.Attributes.ConstraintList {
+		got = append(got, db.DBConstraint{
+			Name:                 constraint.Name,
+			Type:                 constraint.Type,
+			ColumnList:           constraint.ColumnList,
+			ReferencedTable:      constraint.ReferencedTable,
+			ReferencedColumnList: constraint.ReferencedColumnList,
+			UpdateRule:           constraint.UpdateRule,
+			DeleteRule:           constraint.DeleteRule,
+			Expression:           constraint.Expression,
+		})
+	}
+	// Normalize the empty lists to compare.
+	for i := range got {
+		if len(got[i].ReferencedColumnList) == 0 {
+			got[i].ReferencedColumnList = nil
+		}
+	}
+	// The primary key comes first.
+	if want := []db.DBConstraint{primaryKey, foreignKey}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got constraints %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,289 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+	"go.uber.org/zap"
+)
+
+const (
+	// Drivers not used for this long are closed by the eviction loop.
+	DRIVER_POOL_IDLE_TIMEOUT = time.Duration(5) * time.Minute
+	// How often the eviction loop runs.
+	DRIVER_POOL_EVICT_INTERVAL = time.Duration(1) * time.Minute
+	// Max number of drivers opened at the same time for a single instance.
+	DRIVER_POOL_MAX_OPEN_PER_INSTANCE = 10
+	// Max number of connections opened at the same time to a single instance, split evenly among its drivers.
+	DRIVER_POOL_MAX_CONN_PER_INSTANCE = 50
+	// How long to wait for a driver slot once an instance reaches the max open limit.
+	DRIVER_POOL_WAIT_TIMEOUT = time.Duration(30) * time.Second
+)
+
+// driverKey identifies a pooled driver. Database name is empty for the instance level driver.
+type driverKey struct {
+	instanceId   int
+	databaseName string
+	dataSourceId int
+}
+
+type driverEntry struct {
+	key    driverKey
+	driver db.Driver
+	// Number of callers currently holding the driver.
+	refCount   int
+	lastUsedTs time.Time
+	// A stale entry has been removed from the pool and is closed once released by all the holders.
+	stale bool
+}
+
+func NewDriverPool(logger *zap.Logger) *DriverPool {
+	return &DriverPool{
+		l:                  logger,
+		idleTimeout:        DRIVER_POOL_IDLE_TIMEOUT,
+		maxOpenPerInstance: DRIVER_POOL_MAX_OPEN_PER_INSTANCE,
+		entries:            make(map[driverKey]*driverEntry),
+		openCount:          make(map[int]int),
+		released:           make(chan struct{}),
+		openDriver:         openDataSourceDriver,
+	}
+}
+
+// DriverPool shares the opened database drivers among the callers, so we don't need to
+// open and ping a new connection for every schema sync, task run and API call.
+type DriverPool struct {
+	l                  *zap.Logger
+	idleTimeout        time.Duration
+	maxOpenPerInstance int
+
+	mu      sync.Mutex
+	entries map[driverKey]*driverEntry
+	// instanceId -> number of opened drivers including the stale ones not closed yet.
+	openCount map[int]int
+	// Closed and replaced whenever a driver slot may become available, to wake up the waiting callers.
+	released chan struct{}
+
+	// openDriver opens the driver on a cache miss, replaced by the tests to avoid connecting a real instance.
+	openDriver func(instance *api.Instance, databaseName string, dataSource *api.DataSource, maxOpenConns int, logger *zap.Logger) (db.Driver, error)
+}
+
+// Run starts the loop evicting the idle drivers.
+func (p *DriverPool) Run() error {
+	go func() {
+		p.l.Debug(fmt.Sprintf("Driver pool eviction started and will run every %v", DRIVER_POOL_EVICT_INTERVAL))
+		for {
+			p.evictIdle(time.Now().Add(-p.idleTimeout))
+			time.Sleep(DRIVER_POOL_EVICT_INTERVAL)
+		}
+	}()
+
+	return nil
+}
+
+// Get returns the pooled driver connecting the instance and database with the data source, opening a new one if needed.
+// Upon successful return, caller MUST call driver.Close to return the driver to the pool.
+func (p *DriverPool) Get(instance *api.Instance, databaseName string, dataSource *api.DataSource) (db.Driver, error) {
+	key := driverKey{instanceId: instance.ID, databaseName: databaseName, dataSourceId: dataSource.ID}
+	deadline := time.Now().Add(DRIVER_POOL_WAIT_TIMEOUT)
+	for {
+		p.mu.Lock()
+		if entry, ok := p.entries[key]; ok {
+			entry.refCount++
+			p.mu.Unlock()
+			return &pooledDriver{Driver: entry.driver, pool: p, entry: entry}, nil
+		}
+
+		var evicted *driverEntry
+		if p.openCount[instance.ID] >= p.maxOpenPerInstance {
+			evicted = p.removeLeastRecentlyUsedIdleLocked(instance.ID)
+		}
+		if evicted == nil && p.openCount[instance.ID] >= p.maxOpenPerInstance {
+			released := p.released
+			p.mu.Unlock()
+
+			wait := time.Until(deadline)
+			if wait <= 0 {
+				return nil, fmt.Errorf("failed to connect database %s/%s, reached the max %d open connections to the instance", instance.Name, databaseName, p.maxOpenPerInstance)
+			}
+			select {
+			case <-released:
+			case <-time.After(wait):
+			}
+			continue
+		}
+		// Reserve the slot before opening the driver outside the lock.
+		p.openCount[instance.ID]++
+		p.mu.Unlock()
+
+		if evicted != nil {
+			p.closeDriver(evicted)
+		}
+
+		driver, err := p.openDriver(instance, databaseName, dataSource, DRIVER_POOL_MAX_CONN_PER_INSTANCE/p.maxOpenPerInstance, p.l)
+		if err != nil {
+			p.mu.Lock()
+			p.releaseSlotLocked(instance.ID)
+			p.mu.Unlock()
+			return nil, err
+		}
+
+		p.mu.Lock()
+		if entry, ok := p.entries[key]; ok {
+			// Another caller has opened the same driver concurrently, use that one instead.
+			entry.refCount++
+			p.releaseSlotLocked(instance.ID)
+			p.mu.Unlock()
+			driver.Close(context.Background())
+			return &pooledDriver{Driver: entry.driver, pool: p, entry: entry}, nil
+		}
+		entry := &driverEntry{
+			key:        key,
+			driver:     driver,
+			refCount:   1,
+			lastUsedTs: time.Now(),
+		}
+		p.entries[key] = entry
+		p.mu.Unlock()
+		return &pooledDriver{Driver: driver, pool: p, entry: entry}, nil
+	}
+}
+
+// Invalidate closes all the drivers of the instance, e.g. after its connection info changes.
+// Drivers being used are closed once released.
+func (p *DriverPool) Invalidate(instanceId int) {
+	var closeList []*driverEntry
+	p.mu.Lock()
+	for key, entry := range p.entries {
+		if key.instanceId != instanceId {
+			continue
+		}
+		delete(p.entries, key)
+		entry.stale = true
+		if entry.refCount == 0 {
+			p.releaseSlotLocked(instanceId)
+			closeList = append(closeList, entry)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, entry := range closeList {
+		p.closeDriver(entry)
+	}
+}
+
+// Close closes all the drivers in the pool.
+func (p *DriverPool) Close() {
+	var closeList []*driverEntry
+	p.mu.Lock()
+	for key, entry := range p.entries {
+		delete(p.entries, key)
+		entry.stale = true
+		if entry.refCount == 0 {
+			p.releaseSlotLocked(key.instanceId)
+			closeList = append(closeList, entry)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, entry := range closeList {
+		p.closeDriver(entry)
+	}
+}
+
+func (p *DriverPool) release(entry *driverEntry) {
+	p.mu.Lock()
+	entry.refCount--
+	entry.lastUsedTs = time.Now()
+	closeDriver := entry.stale && entry.refCount == 0
+	if closeDriver {
+		p.releaseSlotLocked(entry.key.instanceId)
+	} else if entry.refCount == 0 {
+		// The idle driver can be evicted to make room for the waiting callers.
+		p.notifyLocked()
+	}
+	p.mu.Unlock()
+
+	if closeDriver {
+		p.closeDriver(entry)
+	}
+}
+
+func (p *DriverPool) evictIdle(before time.Time) {
+	var closeList []*driverEntry
+	p.mu.Lock()
+	for key, entry := range p.entries {
+		if entry.refCount == 0 && entry.lastUsedTs.Before(before) {
+			delete(p.entries, key)
+			p.releaseSlotLocked(key.instanceId)
+			closeList = append(closeList, entry)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, entry := range closeList {
+		p.closeDriver(entry)
+	}
+}
+
+// removeLeastRecentlyUsedIdleLocked removes the least recently used idle driver of the instance from the pool.
+// The caller takes over the slot of the removed driver and is responsible for closing it.
+func (p *DriverPool) removeLeastRecentlyUsedIdleLocked(instanceId int) *driverEntry {
+	var lru *driverEntry
+	for key, entry := range p.entries {
+		if key.instanceId != instanceId || entry.refCount > 0 {
+			continue
+		}
+		if lru == nil || entry.lastUsedTs.Before(lru.lastUsedTs) {
+			lru = entry
+		}
+	}
+	if lru != nil {
+		delete(p.entries, lru.key)
+		p.openCount[instanceId]--
+	}
+	return lru
+}
+
+func (p *DriverPool) releaseSlotLocked(instanceId int) {
+	p.openCount[instanceId]--
+	if p.openCount[instanceId] <= 0 {
+		delete(p.openCount, instanceId)
+	}
+	p.notifyLocked()
+}
+
+func (p *DriverPool) notifyLocked() {
+	close(p.released)
+	p.released = make(chan struct{})
+}
+
+func (p *DriverPool) closeDriver(entry *driverEntry) {
+	if err := entry.driver.Close(context.Background()); err != nil {
+		p.l.Warn("Failed to close pooled driver",
+			zap.Int("instance_id", entry.key.instanceId),
+			zap.String("database", entry.key.databaseName),
+			zap.Int("data_source_id", entry.key.dataSourceId),
+			zap.Error(err),
+		)
+	}
+}
+
+// pooledDriver returns the driver to the pool upon Close instead of closing the connection.
+type pooledDriver struct {
+	db.Driver
+	pool  *DriverPool
+	entry *driverEntry
+
+	closeOnce sync.Once
+}
+
+func (driver *pooledDriver) Close(ctx context.Context) error {
+	driver.closeOnce.Do(func() {
+		driver.pool.release(driver.entry)
+	})
+	return nil
+}
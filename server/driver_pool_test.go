@@ -0,0 +1,255 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+	"go.uber.org/zap"
+)
+
+// fakeDriver is opened by the tests instead of connecting a real instance. Calling the methods not overridden panics.
+type fakeDriver struct {
+	db.Driver
+	dataSourceId int
+
+	mu         sync.Mutex
+	closed     bool
+	grantList  []*db.DatabaseGrant
+	revokeList []*db.DatabaseGrant
+}
+
+func (d *fakeDriver) Close(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	return nil
+}
+
+func (d *fakeDriver) isClosed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+func (d *fakeDriver) GrantDatabase(ctx context.Context, grant *db.DatabaseGrant) (*db.DBUser, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.grantList = append(d.grantList, grant)
+	return &db.DBUser{Name: grant.Username, Grant: "GRANT " + grant.Username}, nil
+}
+
+func (d *fakeDriver) RevokeDatabase(ctx context.Context, grant *db.DatabaseGrant) (*db.DBUser, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.revokeList = append(d.revokeList, grant)
+	return &db.DBUser{Name: grant.Username}, nil
+}
+
+// fakeDriverOpener opens a fakeDriver for each cache miss of the driver pool, and records them in order.
+type fakeDriverOpener struct {
+	mu         sync.Mutex
+	driverList []*fakeDriver
+}
+
+func (o *fakeDriverOpener) open(instance *api.Instance, databaseName string, dataSource *api.DataSource, maxOpenConns int, logger *zap.Logger) (db.Driver, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	driver := &fakeDriver{dataSourceId: dataSource.ID}
+	o.driverList = append(o.driverList, driver)
+	return driver, nil
+}
+
+func (o *fakeDriverOpener) list() []*fakeDriver {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]*fakeDriver(nil), o.driverList...)
+}
+
+func newTestDriverPool(maxOpenPerInstance int) (*DriverPool, *fakeDriverOpener) {
+	opener := &fakeDriverOpener{}
+	pool := NewDriverPool(zap.NewNop())
+	pool.maxOpenPerInstance = maxOpenPerInstance
+	pool.openDriver = opener.open
+	return pool, opener
+}
+
+func TestDriverPoolGetRelease(t *testing.T) {
+	pool, opener := newTestDriverPool(2)
+	instance := &api.Instance{ID: 1, Name: "i1"}
+	dataSource := &api.DataSource{ID: 1}
+
+	d1, err := pool.Get(instance, "db1", dataSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := pool.Get(instance, "db1", dataSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(opener.list()); got != 1 {
+		t.Fatalf("got %d drivers opened for the same key, want 1", got)
+	}
+	if d1.(*pooledDriver).Driver != d2.(*pooledDriver).Driver {
+		t.Errorf("got different drivers for the same key")
+	}
+
+	d1.Close(context.Background())
+	// Closing twice doesn't release the driver twice.
+	d1.Close(context.Background())
+	if got := pool.entries[driverKey{instanceId: 1, databaseName: "db1", dataSourceId: 1}].refCount; got != 1 {
+		t.Errorf("got refCount %d after releasing one holder, want 1", got)
+	}
+	d2.Close(context.Background())
+	if opener.list()[0].isClosed() {
+		t.Errorf("got the released driver closed, want it kept in the pool")
+	}
+
+	// A different database opens another driver.
+	d3, err := pool.Get(instance, "db2", dataSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d3.Close(context.Background())
+	if got := len(opener.list()); got != 2 {
+		t.Errorf("got %d drivers opened, want 2", got)
+	}
+	if got := pool.openCount[instance.ID]; got != 2 {
+		t.Errorf("got openCount %d, want 2", got)
+	}
+}
+
+func TestDriverPoolWaitForRelease(t *testing.T) {
+	pool, opener := newTestDriverPool(1)
+	instance := &api.Instance{ID: 1, Name: "i1"}
+
+	d1, err := pool.Get(instance, "db1", &api.DataSource{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		driver db.Driver
+		err    error
+	}
+	resultChan := make(chan result, 1)
+	go func() {
+		driver, err := pool.Get(instance, "db2", &api.DataSource{ID: 1})
+		resultChan <- result{driver, err}
+	}()
+
+	select {
+	case <-resultChan:
+		t.Fatalf("got the driver beyond the max open per instance before releasing")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	d1.Close(context.Background())
+	select {
+	case r := <-resultChan:
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		defer r.driver.Close(context.Background())
+	case <-time.After(5 * time.Second):
+		t.Fatalf("got no driver after releasing")
+	}
+
+	driverList := opener.list()
+	if len(driverList) != 2 {
+		t.Fatalf("got %d drivers opened, want 2", len(driverList))
+	}
+	// The idle driver is evicted to make room for the waiting caller.
+	if !driverList[0].isClosed() {
+		t.Errorf("got the idle driver open, want it evicted")
+	}
+	if got := pool.openCount[instance.ID]; got != 1 {
+		t.Errorf("got openCount %d, want 1", got)
+	}
+}
+
+func TestDriverPoolInvalidate(t *testing.T) {
+	pool, opener := newTestDriverPool(2)
+	instance := &api.Instance{ID: 1, Name: "i1"}
+	dataSource := &api.DataSource{ID: 1}
+
+	d1, err := pool.Get(instance, "db1", dataSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := pool.Get(instance, "db1", dataSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Invalidate(instance.ID)
+	stale := opener.list()[0]
+	if stale.isClosed() {
+		t.Fatalf("got the driver in use closed by invalidate")
+	}
+
+	// The next caller gets a new driver, while the stale one still counts until closed.
+	d3, err := pool.Get(instance, "db1", dataSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d3.Close(context.Background())
+	if got := len(opener.list()); got != 2 {
+		t.Fatalf("got %d drivers opened after invalidate, want 2", got)
+	}
+	if got := pool.openCount[instance.ID]; got != 2 {
+		t.Errorf("got openCount %d, want 2", got)
+	}
+
+	d1.Close(context.Background())
+	if stale.isClosed() {
+		t.Errorf("got the stale driver closed before the last release")
+	}
+	d2.Close(context.Background())
+	if !stale.isClosed() {
+		t.Errorf("got the stale driver open after the last release")
+	}
+	if got := pool.openCount[instance.ID]; got != 1 {
+		t.Errorf("got openCount %d, want 1", got)
+	}
+}
+
+func TestDriverPoolEvictIdle(t *testing.T) {
+	pool, opener := newTestDriverPool(2)
+	instance := &api.Instance{ID: 1, Name: "i1"}
+
+	idle, err := pool.Get(instance, "db1", &api.DataSource{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	idle.Close(context.Background())
+	inUse, err := pool.Get(instance, "db2", &api.DataSource{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inUse.Close(context.Background())
+
+	// Nothing is idle for long enough.
+	pool.evictIdle(time.Now().Add(-time.Hour))
+	if opener.list()[0].isClosed() {
+		t.Fatalf("got the recently used driver evicted")
+	}
+
+	pool.evictIdle(time.Now().Add(time.Second))
+	driverList := opener.list()
+	if !driverList[0].isClosed() {
+		t.Errorf("got the idle driver open, want it evicted")
+	}
+	if driverList[1].isClosed() {
+		t.Errorf("got the driver in use evicted")
+	}
+	if got := len(pool.entries); got != 1 {
+		t.Errorf("got %d drivers in the pool, want 1", got)
+	}
+	if got := pool.openCount[instance.ID]; got != 1 {
+		t.Errorf("got openCount %d, want 1", got)
+	}
+}
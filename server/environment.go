@@ -9,6 +9,7 @@ import (
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
 )
@@ -19,6 +20,9 @@ func (s *Server) registerEnvironmentRoutes(g *echo.Group) {
 		if err := jsonapi.UnmarshalPayload(c.Request().Body, environmentCreate); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformatted create environment request").SetInternal(err)
 		}
+		if environmentCreate.StatementTimeout < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid statement timeout %d, must not be negative", environmentCreate.StatementTimeout))
+		}
 
 		environmentCreate.CreatorId = c.Get(GetPrincipalIdContextKey()).(int)
 
@@ -78,6 +82,9 @@ func (s *Server) registerEnvironmentRoutes(g *echo.Group) {
 		if err := jsonapi.UnmarshalPayload(c.Request().Body, environmentPatch); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformatted patch environment request").SetInternal(err)
 		}
+		if v := environmentPatch.StatementTimeout; v != nil && *v < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid statement timeout %d, must not be negative", *v))
+		}
 
 		environment, err := s.EnvironmentService.PatchEnvironment(context.Background(), environmentPatch)
 		if err != nil {
@@ -167,3 +174,16 @@ func (s *Server) ComposeEnvironmentRelationship(ctx context.Context, environment
 
 	return nil
 }
+
+// migrationTaskStatus returns the initial status of the migration task in the environment according to its approval
+// policy. The data migrations follow the data approval policy of the environment.
+func migrationTaskStatus(environment *api.Environment, migrationType db.MigrationType) api.TaskStatus {
+	policy := environment.ApprovalPolicy
+	if migrationType == db.Data {
+		policy = environment.DataApprovalPolicy
+	}
+	if policy == api.ManualApprovalNever {
+		return api.TaskPending
+	}
+	return api.TaskPendingApproval
+}
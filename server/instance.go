@@ -38,7 +38,7 @@ func (s *Server) registerInstanceRoutes(g *echo.Group) {
 		// Try creating the "bytebase" db in the added instance if needed.
 		// Since we allow user to add new instance upfront even providing the incorrect username/password,
 		// thus it's OK if it fails. Frontend will surface relavant info suggesting the "bytebase" db hasn't created yet.
-		db, err := GetDatabaseDriver(instance, "", s.l)
+		db, err := s.GetDatabaseDriver(instance, "", api.Admin)
 		if err == nil {
 			defer db.Close(context.Background())
 			db.SetupMigrationIfNeeded(context.Background())
@@ -158,6 +158,11 @@ func (s *Server) registerInstanceRoutes(g *echo.Group) {
 			}
 		}
 
+		// Drop the pooled connections using the stale connection info.
+		if instancePatch.RowStatus != nil || instancePatch.Host != nil || instancePatch.Port != nil || instancePatch.Username != nil || instancePatch.Password != nil {
+			s.DriverPool.Invalidate(id)
+		}
+
 		if err := s.ComposeInstanceRelationship(context.Background(), instance); err != nil {
 			return err
 		}
@@ -190,6 +195,40 @@ func (s *Server) registerInstanceRoutes(g *echo.Group) {
 		return nil
 	})
 
+	g.GET("/instance/:instanceId/capability", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("instanceId"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("instanceId"))).SetInternal(err)
+		}
+
+		instance, err := s.ComposeInstanceById(context.Background(), id)
+		if err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Instance ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch instance ID: %v", id)).SetInternal(err)
+		}
+
+		capability, err := db.GetCapability(instance.Engine)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch capability for instance ID: %v", id)).SetInternal(err)
+		}
+		instanceCapability := &api.InstanceCapability{
+			TransactionalDDL: capability.TransactionalDDL,
+			UserAndGrant:     capability.UserAndGrant,
+			BackupRestore:    capability.BackupRestore,
+			OnlineDDL:        capability.OnlineDDL,
+			CreateDatabase:   capability.CreateDatabase,
+			IndexUsage:       capability.IndexUsage,
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, instanceCapability); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal instance capability response: %v", id)).SetInternal(err)
+		}
+		return nil
+	})
+
 	g.POST("/instance/:instanceId/migration", func(c echo.Context) error {
 		id, err := strconv.Atoi(c.Param("instanceId"))
 		if err != nil {
@@ -205,20 +244,7 @@ func (s *Server) registerInstanceRoutes(g *echo.Group) {
 		}
 
 		resultSet := &api.SqlResultSet{}
-		db, err := db.Open(
-			instance.Engine,
-			db.DriverConfig{Logger: s.l},
-			db.ConnectionConfig{
-				Username: instance.Username,
-				Password: instance.Password,
-				Host:     instance.Host,
-				Port:     instance.Port,
-			},
-			db.ConnectionContext{
-				EnvironmentName: instance.Environment.Name,
-				InstanceName:    instance.Name,
-			},
-		)
+		db, err := s.GetDatabaseDriver(instance, "", api.Admin)
 		if err != nil {
 			resultSet.Error = err.Error()
 		} else {
@@ -250,7 +276,7 @@ func (s *Server) registerInstanceRoutes(g *echo.Group) {
 		}
 
 		instanceMigration := &api.InstanceMigration{}
-		db, err := GetDatabaseDriver(instance, "", s.l)
+		db, err := s.GetDatabaseDriver(instance, "", api.Admin)
 		if err != nil {
 			instanceMigration.Status = api.InstanceMigrationSchemaUnknown
 			instanceMigration.Error = err.Error()
@@ -302,7 +328,7 @@ func (s *Server) registerInstanceRoutes(g *echo.Group) {
 		}
 
 		historyList := []*api.MigrationHistory{}
-		driver, err := GetDatabaseDriver(instance, "", s.l)
+		driver, err := s.GetDatabaseDriver(instance, "", api.RO)
 		if err == nil {
 			defer driver.Close(context.Background())
 			list, err := driver.FindMigrationHistoryList(context.Background(), find)
@@ -310,8 +336,20 @@ func (s *Server) registerInstanceRoutes(g *echo.Group) {
 				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch migration history list").SetInternal(err)
 			}
 
+			snapshotList, err := s.MigrationSnapshotService.FindMigrationSnapshotList(context.Background(), &api.MigrationSnapshotFind{
+				InstanceId:   instance.ID,
+				DatabaseName: find.Database,
+			})
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch migration snapshot list").SetInternal(err)
+			}
+			snapshotMap := make(map[int]*api.MigrationSnapshot)
+			for _, snapshot := range snapshotList {
+				snapshotMap[snapshot.MigrationHistoryId] = snapshot
+			}
+
 			for _, entry := range list {
-				historyList = append(historyList, &api.MigrationHistory{
+				history := &api.MigrationHistory{
 					ID:                entry.ID,
 					Creator:           entry.Creator,
 					CreatedTs:         entry.CreatedTs,
@@ -323,10 +361,17 @@ func (s *Server) registerInstanceRoutes(g *echo.Group) {
 					Version:           entry.Version,
 					Description:       entry.Description,
 					Statement:         entry.Statement,
+					RollbackStatement: entry.RollbackStatement,
 					ExecutionDuration: entry.ExecutionDuration,
 					IssueId:           entry.IssueId,
 					Payload:           entry.Payload,
-				})
+				}
+				if snapshot, ok := snapshotMap[entry.ID]; ok {
+					history.SchemaPrev = snapshot.SchemaPrev
+					history.Schema = snapshot.Schema
+					history.SchemaDiff = computeSchemaDiff(snapshot.SchemaPrev, snapshot.Schema)
+				}
+				historyList = append(historyList, history)
 			}
 		}
 
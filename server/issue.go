@@ -7,9 +7,11 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
 )
@@ -23,6 +25,9 @@ func (s *Server) registerIssueRoutes(g *echo.Group) {
 
 		issue, err := s.CreateIssue(context.Background(), issueCreate, c.Get(GetPrincipalIdContextKey()).(int))
 		if err != nil {
+			if common.ErrorCode(err) == common.ENOTIMPLEMENTED {
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorMessage(err)).SetInternal(err)
+			}
 			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create issue").SetInternal(err)
 		}
 
@@ -314,6 +319,14 @@ func (s *Server) ComposeIssueRelationship(ctx context.Context, issue *api.Issue)
 	return nil
 }
 
+// approvalRequiredTaskTypes are the task types changing the database privileges, which always wait for the approval
+// regardless of the status sent by the client and the approval policy of the environment.
+var approvalRequiredTaskTypes = map[api.TaskType]bool{
+	api.TaskDatabaseGrant:     true,
+	api.TaskDatabaseRevoke:    true,
+	api.TaskDataSourceRequest: true,
+}
+
 func (s *Server) CreateIssue(ctx context.Context, issueCreate *api.IssueCreate, creatorId int) (*api.Issue, error) {
 	// Run pre-condition check first to make sure all tasks are valid, otherwise we will create partial pipelines
 	// since we are not creating pipeline/stage list/task list in a single transaction.
@@ -321,6 +334,14 @@ func (s *Server) CreateIssue(ctx context.Context, issueCreate *api.IssueCreate,
 	// quite unlikely so we will live with it for now.
 	for _, stageCreate := range issueCreate.Pipeline.StageList {
 		for _, taskCreate := range stageCreate.TaskList {
+			instance, err := s.InstanceService.FindInstance(ctx, &api.InstanceFind{ID: &taskCreate.InstanceId})
+			if err != nil {
+				return nil, fmt.Errorf("failed to find instance %d for task %q. Error %w", taskCreate.InstanceId, taskCreate.Name, err)
+			}
+			if err := checkTaskCapability(instance, taskCreate.Type); err != nil {
+				return nil, err
+			}
+
 			if taskCreate.Type == api.TaskDatabaseCreate {
 				if taskCreate.Statement == "" {
 					return nil, fmt.Errorf("failed to create database creation task, sql statement missing")
@@ -338,6 +359,9 @@ func (s *Server) CreateIssue(ctx context.Context, issueCreate *api.IssueCreate,
 				if taskCreate.Statement == "" {
 					return nil, fmt.Errorf("failed to create schema update task, sql statement missing")
 				}
+				if taskCreate.BackupAffectedRows && !taskCreate.DataMigration && taskCreate.MigrationType != db.Data {
+					return nil, fmt.Errorf("failed to create schema update task, only data migration can back up the affected rows")
+				}
 			} else if taskCreate.Type == api.TaskDatabaseRestore {
 				if taskCreate.DatabaseName == "" {
 					return nil, fmt.Errorf("failed to create restore database task, database name missing")
@@ -345,6 +369,38 @@ func (s *Server) CreateIssue(ctx context.Context, issueCreate *api.IssueCreate,
 				if taskCreate.BackupId == nil {
 					return nil, fmt.Errorf("failed to create restore database task, backup missing")
 				}
+			} else if taskCreate.Type == api.TaskDatabaseGrant || taskCreate.Type == api.TaskDatabaseRevoke {
+				if taskCreate.DatabaseId == nil {
+					return nil, fmt.Errorf("failed to create database grant task, database missing")
+				}
+				database, err := s.DatabaseService.FindDatabase(ctx, &api.DatabaseFind{ID: taskCreate.DatabaseId})
+				if err != nil {
+					return nil, fmt.Errorf("failed to find database %d for task %q. Error %w", *taskCreate.DatabaseId, taskCreate.Name, err)
+				}
+				grant := &db.DatabaseGrant{
+					Username:      taskCreate.Username,
+					Host:          taskCreate.Host,
+					Password:      taskCreate.Password,
+					Database:      database.Name,
+					PrivilegeList: taskCreate.PrivilegeList,
+				}
+				if taskCreate.Type == api.TaskDatabaseRevoke {
+					grant.Password = ""
+					grant.DropUser = taskCreate.DropUser
+				}
+				if err := db.ValidateDatabaseGrant(instance.Engine, grant); err != nil {
+					return nil, fmt.Errorf("failed to create database grant task, %w", err)
+				}
+			} else if taskCreate.Type == api.TaskDataSourceRequest {
+				if taskCreate.DatabaseId == nil {
+					return nil, fmt.Errorf("failed to create data source request task, database missing")
+				}
+				if taskCreate.DataSourceType != api.RO && taskCreate.DataSourceType != api.RW {
+					return nil, fmt.Errorf("failed to create data source request task, data source type must be either %s or %s", api.RO, api.RW)
+				}
+				if taskCreate.TTL < 0 || time.Duration(taskCreate.TTL)*time.Second > DATA_SOURCE_REQUEST_MAX_TTL {
+					return nil, fmt.Errorf("failed to create data source request task, ttl must be between 0 and %d seconds", int64(DATA_SOURCE_REQUEST_MAX_TTL/time.Second))
+				}
 			}
 		}
 	}
@@ -364,6 +420,20 @@ func (s *Server) CreateIssue(ctx context.Context, issueCreate *api.IssueCreate,
 		}
 
 		for _, taskCreate := range stageCreate.TaskList {
+			if taskCreate.DataMigration && taskCreate.MigrationType == "" {
+				taskCreate.MigrationType = db.Data
+			}
+			// The data migrations follow the data approval policy of the environment, which the client doesn't know about.
+			if taskCreate.MigrationType == db.Data && (taskCreate.Status == api.TaskPending || taskCreate.Status == api.TaskPendingApproval) {
+				environment, err := s.EnvironmentService.FindEnvironment(ctx, &api.EnvironmentFind{ID: &stageCreate.EnvironmentId})
+				if err != nil {
+					return nil, fmt.Errorf("failed to find environment %d for task %q. Error %w", stageCreate.EnvironmentId, taskCreate.Name, err)
+				}
+				taskCreate.Status = migrationTaskStatus(environment, db.Data)
+			}
+			if approvalRequiredTaskTypes[taskCreate.Type] {
+				taskCreate.Status = api.TaskPendingApproval
+			}
 			taskCreate.CreatorId = creatorId
 			taskCreate.PipelineId = createdPipeline.ID
 			taskCreate.StageId = createdStage.ID
@@ -388,6 +458,11 @@ func (s *Server) CreateIssue(ctx context.Context, issueCreate *api.IssueCreate,
 				if taskCreate.VCSPushEvent != nil {
 					payload.VCSPushEvent = taskCreate.VCSPushEvent
 				}
+				payload.DryRun = taskCreate.DryRun
+				payload.OnlineDDL = taskCreate.OnlineDDL
+				payload.BackupAffectedRows = taskCreate.BackupAffectedRows
+				payload.MigrationType = taskCreate.MigrationType
+				payload.VersionScheme = taskCreate.VersionScheme
 				bytes, err := json.Marshal(payload)
 				if err != nil {
 					return nil, fmt.Errorf("failed to create schema update task, unable to marshal payload %w", err)
@@ -402,6 +477,35 @@ func (s *Server) CreateIssue(ctx context.Context, issueCreate *api.IssueCreate,
 					return nil, fmt.Errorf("failed to create restore database task, unable to marshal payload %w", err)
 				}
 				taskCreate.Payload = string(bytes)
+			} else if taskCreate.Type == api.TaskDatabaseGrant || taskCreate.Type == api.TaskDatabaseRevoke {
+				payload := api.TaskDatabaseGrantPayload{}
+				payload.Username = taskCreate.Username
+				payload.Host = taskCreate.Host
+				payload.PrivilegeList = taskCreate.PrivilegeList
+				if taskCreate.Type == api.TaskDatabaseGrant {
+					taskCreate.Secret = taskCreate.Password
+				} else {
+					payload.DropUser = taskCreate.DropUser
+				}
+				bytes, err := json.Marshal(payload)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create database grant task, unable to marshal payload %w", err)
+				}
+				taskCreate.Payload = string(bytes)
+			} else if taskCreate.Type == api.TaskDataSourceRequest {
+				payload := api.TaskDataSourceRequestPayload{}
+				payload.DataSourceType = taskCreate.DataSourceType
+				payload.TTL = taskCreate.TTL
+				if payload.TTL == 0 {
+					payload.TTL = int64(DATA_SOURCE_REQUEST_DEFAULT_TTL / time.Second)
+				}
+				// The data source is granted to the issue creator.
+				payload.RequesterId = creatorId
+				bytes, err := json.Marshal(payload)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create data source request task, unable to marshal payload %w", err)
+				}
+				taskCreate.Payload = string(bytes)
 			}
 			_, err := s.TaskService.CreateTask(context.Background(), &taskCreate)
 			if err != nil {
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bytebase/bytebase/api"
+)
+
+func TestCreateIssueRequiresApproval(t *testing.T) {
+	s, _ := newTestServer(t)
+	ctx := context.Background()
+
+	// The Dev environment 5001 never requires the approval, and the database 7002 is on the MySQL instance 6001.
+	databaseId := 7002
+	tests := []struct {
+		taskCreate api.TaskCreate
+		want       api.TaskStatus
+	}{
+		{
+			taskCreate: api.TaskCreate{
+				Type:          api.TaskDatabaseGrant,
+				Username:      "alice",
+				Password:      "pass",
+				PrivilegeList: []string{"ALL"},
+			},
+			want: api.TaskPendingApproval,
+		},
+		{
+			taskCreate: api.TaskCreate{
+				Type:     api.TaskDatabaseRevoke,
+				Username: "alice",
+				DropUser: true,
+			},
+			want: api.TaskPendingApproval,
+		},
+		{
+			taskCreate: api.TaskCreate{
+				Type:           api.TaskDataSourceRequest,
+				DataSourceType: api.RO,
+			},
+			want: api.TaskPendingApproval,
+		},
+	}
+
+	for _, test := range tests {
+		taskCreate := test.taskCreate
+		taskCreate.Name = string(taskCreate.Type)
+		taskCreate.Status = api.TaskPending
+		taskCreate.InstanceId = 6001
+		taskCreate.DatabaseId = &databaseId
+		issue, err := s.CreateIssue(ctx, &api.IssueCreate{
+			ProjectId:  3001,
+			Name:       string(taskCreate.Type),
+			Type:       api.IssueGeneral,
+			AssigneeId: api.SYSTEM_BOT_ID,
+			Pipeline: api.PipelineCreate{
+				Name: string(taskCreate.Type),
+				StageList: []api.StageCreate{
+					{
+						Name:          "Dev",
+						EnvironmentId: 5001,
+						TaskList:      []api.TaskCreate{taskCreate},
+					},
+				},
+			},
+		}, api.SYSTEM_BOT_ID)
+		if err != nil {
+			t.Fatalf("failed to create issue with task type %s: %v", taskCreate.Type, err)
+		}
+
+		// Find the task from the store since the PENDING task would have been scheduled after creating the issue.
+		taskList, err := s.TaskService.FindTaskList(ctx, &api.TaskFind{PipelineId: &issue.PipelineId})
+		if err != nil {
+			t.Fatalf("failed to find task with task type %s: %v", taskCreate.Type, err)
+		}
+		if len(taskList) != 1 {
+			t.Fatalf("got %d tasks with task type %s, want 1", len(taskList), taskCreate.Type)
+		}
+		if taskList[0].Status != test.want {
+			t.Errorf("got task type %s stored with status %s from the client status %s, want %s", taskCreate.Type, taskList[0].Status, api.TaskPending, test.want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/bin/bb/connect"
+	"github.com/bytebase/bytebase/bin/bb/dump/mysqldump"
+	"github.com/bytebase/bytebase/bin/bb/dump/pgdump"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
+	"go.uber.org/zap"
+)
+
+const (
+	// Number of unchanged lines shown around each change of the schema diff.
+	schemaDiffContextLines = 3
+	// Above this many line pairs, we skip matching the changed lines and show them as replaced entirely,
+	// so that a huge dump doesn't blow up the memory.
+	schemaDiffMaxCellCount = 4000000
+)
+
+// dumpDatabaseSchema returns the schema-only dump of the database.
+// Returns ENOTIMPLEMENTED if we can't dump the database engine.
+func dumpDatabaseSchema(instance *api.Instance, databaseName string) (string, error) {
+	var buf bytes.Buffer
+	switch instance.Engine {
+	case db.Mysql:
+		conn, err := connect.NewMysql(instance.Username, instance.Password, instance.Host, instance.Port, databaseName, nil /* tlsConfig */)
+		if err != nil {
+			return "", fmt.Errorf("failed to connect instance %q at %q:%q with user %q: %w", instance.Name, instance.Host, instance.Port, instance.Username, err)
+		}
+		defer conn.Close()
+
+		if err := mysqldump.New(conn).Dump(databaseName, &buf, true /* schemaOnly */, false /* dumpAll */); err != nil {
+			return "", err
+		}
+	case db.Postgres:
+		conn, err := connect.NewPostgres(instance.Username, instance.Password, instance.Host, instance.Port, databaseName, "" /* sslCA */, "" /* sslCert */, "" /* sslKey */)
+		if err != nil {
+			return "", fmt.Errorf("failed to connect instance %q at %q:%q with user %q: %w", instance.Name, instance.Host, instance.Port, instance.Username, err)
+		}
+		defer conn.Close()
+
+		if err := pgdump.New(conn).Dump(databaseName, &buf, true /* schemaOnly */); err != nil {
+			return "", err
+		}
+	default:
+		return "", &common.Error{Code: common.ENOTIMPLEMENTED, Message: fmt.Sprintf("dumping schema is not supported for %s", instance.Engine)}
+	}
+	return buf.String(), nil
+}
+
+// recordMigrationSnapshot stores the schema before and after the migration we just applied, together with
+// the synced schema as the expected schema for detecting drift. The dumped schema is only recorded if we
+// dumped the schema before the migration.
+func (s *Server) recordMigrationSnapshot(ctx context.Context, driver db.Driver, task *api.Task, mi *db.MigrationInfo, schemaPrev string, dumped bool) error {
+	schema := ""
+	if dumped {
+		var err error
+		schema, err = dumpDatabaseSchema(task.Instance, mi.Database)
+		if err != nil {
+			// Still record the synced schema, otherwise we can't detect drift until the next migration.
+			s.l.Warn("Failed to dump schema after migration",
+				zap.String("instance", task.Instance.Name),
+				zap.String("database", mi.Database),
+				zap.Error(err),
+			)
+			schemaPrev = ""
+		}
+	}
+
+	syncedSchema, err := syncDatabaseSchema(ctx, driver, mi.Database)
+	if err != nil {
+		return fmt.Errorf("failed to sync schema after migration: %w", err)
+	}
+
+	list, err := driver.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{
+		Database: &mi.Namespace,
+		Version:  &mi.Version,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find the migration history: %w", err)
+	}
+	if len(list) == 0 {
+		return fmt.Errorf("migration history not found for database %q version %s", mi.Namespace, mi.Version)
+	}
+
+	if _, err := s.MigrationSnapshotService.UpsertMigrationSnapshot(ctx, &api.MigrationSnapshotUpsert{
+		CreatorId:          task.CreatorId,
+		InstanceId:         task.Instance.ID,
+		DatabaseName:       mi.Namespace,
+		MigrationHistoryId: list[0].ID,
+		SchemaPrev:         schemaPrev,
+		Schema:             schema,
+		SyncedSchema:       syncedSchema,
+	}); err != nil {
+		return fmt.Errorf("failed to store the migration snapshot: %w", err)
+	}
+	return nil
+}
+
+type diffLine struct {
+	// ' ' for unchanged, '-' for removed and '+' for added.
+	kind byte
+	text string
+}
+
+// computeSchemaDiff returns the unified diff from schemaPrev to schema, empty if they are the same.
+func computeSchemaDiff(schemaPrev string, schema string) string {
+	lineList := diffLineList(splitLines(schemaPrev), splitLines(schema))
+
+	// Show the changed lines together with the unchanged lines nearby.
+	visible := make([]bool, len(lineList))
+	for i, line := range lineList {
+		if line.kind == ' ' {
+			continue
+		}
+		for j := i - schemaDiffContextLines; j <= i+schemaDiffContextLines; j++ {
+			if j >= 0 && j < len(lineList) {
+				visible[j] = true
+			}
+		}
+	}
+
+	var buf strings.Builder
+	prevLine, line := 0, 0
+	for i := 0; i < len(lineList); {
+		if !visible[i] {
+			prevLine, line = prevLine+1, line+1
+			i++
+			continue
+		}
+
+		prevStart, start := prevLine, line
+		var hunk strings.Builder
+		for ; i < len(lineList) && visible[i]; i++ {
+			hunk.WriteByte(lineList[i].kind)
+			hunk.WriteString(lineList[i].text)
+			hunk.WriteByte('\n')
+			if lineList[i].kind != '+' {
+				prevLine++
+			}
+			if lineList[i].kind != '-' {
+				line++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", formatHunkRange(prevStart, prevLine-prevStart), formatHunkRange(start, line-start))
+		buf.WriteString(hunk.String())
+	}
+	return buf.String()
+}
+
+// formatHunkRange formats the hunk range in the unified diff format, start is the count of the lines before the hunk.
+func formatHunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLineList matches the longest common subsequence of the lines. The common prefix and suffix
+// are skipped first since a migration usually only touches a small part of the schema.
+func diffLineList(prevList []string, list []string) []diffLine {
+	prefix := 0
+	for prefix < len(prevList) && prefix < len(list) && prevList[prefix] == list[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(prevList)-prefix && suffix < len(list)-prefix && prevList[len(prevList)-1-suffix] == list[len(list)-1-suffix] {
+		suffix++
+	}
+
+	var lineList []diffLine
+	for _, text := range prevList[:prefix] {
+		lineList = append(lineList, diffLine{kind: ' ', text: text})
+	}
+
+	a, b := prevList[prefix:len(prevList)-suffix], list[prefix:len(list)-suffix]
+	i, j := 0, 0
+	if len(a)*len(b) <= schemaDiffMaxCellCount {
+		// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+		lcs := make([][]int, len(a)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+		for i < len(a) && j < len(b) {
+			if a[i] == b[j] {
+				lineList = append(lineList, diffLine{kind: ' ', text: a[i]})
+				i, j = i+1, j+1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lineList = append(lineList, diffLine{kind: '-', text: a[i]})
+				i++
+			} else {
+				lineList = append(lineList, diffLine{kind: '+', text: b[j]})
+				j++
+			}
+		}
+	}
+	for _, text := range a[i:] {
+		lineList = append(lineList, diffLine{kind: '-', text: text})
+	}
+	for _, text := range b[j:] {
+		lineList = append(lineList, diffLine{kind: '+', text: text})
+	}
+
+	for _, text := range prevList[len(prevList)-suffix:] {
+		lineList = append(lineList, diffLine{kind: ' ', text: text})
+	}
+	return lineList
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestComputeSchemaDiff(t *testing.T) {
+	tests := []struct {
+		schemaPrev string
+		schema     string
+		want       string
+	}{
+		{
+			schemaPrev: "a\nb\n",
+			schema:     "a\nb\n",
+			want:       "",
+		},
+		{
+			schemaPrev: "",
+			schema:     "a\nb\n",
+			want:       "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			schemaPrev: "a\nb\nc\n",
+			schema:     "a\nx\nc\n",
+			want:       "@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			// The unchanged lines beyond the context are left out, and the far apart changes go to separate hunks.
+			schemaPrev: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			schema:     "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			want:       "@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := computeSchemaDiff(test.schemaPrev, test.schema); got != test.want {
+			t.Errorf("computeSchemaDiff(%q, %q) got %q, want %q", test.schemaPrev, test.schema, got, test.want)
+		}
+	}
+}
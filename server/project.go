@@ -6,12 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"path"
 	"strconv"
 	"strings"
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/external/gitlab"
+	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/google/jsonapi"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -121,6 +123,11 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 		if err := jsonapi.UnmarshalPayload(c.Request().Body, projectPatch); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformatted patch project request").SetInternal(err)
 		}
+		if v := projectPatch.TenantDatabasePattern; v != nil {
+			if _, err := path.Match(*v, ""); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid tenant database pattern: %s", *v)).SetInternal(err)
+			}
+		}
 
 		project, err := s.ProjectService.PatchProject(context.Background(), projectPatch)
 		if err != nil {
@@ -147,6 +154,12 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 		if err := jsonapi.UnmarshalPayload(c.Request().Body, repositoryCreate); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Malformatted create linked repository request").SetInternal(err)
 		}
+		if repositoryCreate.VersionScheme == "" {
+			repositoryCreate.VersionScheme = db.LexicographicVersion
+		}
+		if repositoryCreate.VersionScheme.String() == "UNKNOWN" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid version scheme: %s", repositoryCreate.VersionScheme))
+		}
 
 		vcsFind := &api.VCSFind{
 			ID: &repositoryCreate.VCSId,
@@ -273,6 +286,10 @@ func (s *Server) registerProjectRoutes(g *echo.Group) {
 			baseDir := strings.Trim(*repositoryPatch.BaseDirectory, "/")
 			repositoryPatch.BaseDirectory = &baseDir
 		}
+		// The versions applied under the previous scheme are not reordered, see checkOutofOrderVersionByScheme.
+		if v := repositoryPatch.VersionScheme; v != nil && v.String() == "UNKNOWN" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid version scheme: %s", *v))
+		}
 
 		repositoryFind := &api.RepositoryFind{
 			ProjectId: &projectId,
@@ -0,0 +1,339 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+	"go.uber.org/zap"
+)
+
+const (
+	SCHEMA_DRIFT_CHECK_INTERVAL = time.Duration(30) * time.Minute
+)
+
+func NewSchemaDriftChecker(logger *zap.Logger, server *Server) *SchemaDriftChecker {
+	return &SchemaDriftChecker{
+		l:      logger,
+		server: server,
+	}
+}
+
+// SchemaDriftChecker compares the live schema of each database with the schema right after its latest migration,
+// and raises a drift if they diverge.
+type SchemaDriftChecker struct {
+	l      *zap.Logger
+	server *Server
+}
+
+func (s *SchemaDriftChecker) Run() error {
+	go func() {
+		s.l.Debug(fmt.Sprintf("Schema drift checker started and will run every %v", SCHEMA_DRIFT_CHECK_INTERVAL))
+		for {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						err, ok := r.(error)
+						if !ok {
+							err = fmt.Errorf("%v", r)
+						}
+						s.l.Error("Schema drift checker PANIC RECOVER", zap.Error(err))
+					}
+				}()
+
+				rowStatus := api.Normal
+				instanceFind := &api.InstanceFind{
+					RowStatus: &rowStatus,
+				}
+				list, err := s.server.InstanceService.FindInstanceList(context.Background(), instanceFind)
+				if err != nil {
+					s.l.Error("Failed to retrieve instances", zap.Error(err))
+				}
+
+				for _, instance := range list {
+					if err := s.server.ComposeInstanceRelationship(context.Background(), instance); err != nil {
+						s.l.Error("Failed to check schema drift for instance",
+							zap.Int("id", instance.ID),
+							zap.String("name", instance.Name),
+							zap.String("error", err.Error()))
+						continue
+					}
+					if err := s.checkInstance(context.Background(), instance); err != nil {
+						s.l.Debug("Failed to check schema drift for instance",
+							zap.Int("id", instance.ID),
+							zap.String("name", instance.Name),
+							zap.String("error", err.Error()))
+					}
+				}
+			}()
+
+			time.Sleep(SCHEMA_DRIFT_CHECK_INTERVAL)
+		}
+	}()
+
+	return nil
+}
+
+func (s *SchemaDriftChecker) checkInstance(ctx context.Context, instance *api.Instance) error {
+	driver, err := s.server.GetDatabaseDriver(instance, "", api.RO)
+	if err != nil {
+		return err
+	}
+	defer driver.Close(context.Background())
+
+	// Without the migration history, there is no expected schema to compare with.
+	setup, err := driver.NeedsSetupMigration(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check migration setup: %w", err)
+	}
+	if setup {
+		return nil
+	}
+
+	_, schemaList, err := driver.SyncSchema(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sync schema: %w", err)
+	}
+	schemaMap := make(map[string]*db.DBSchema)
+	for _, schema := range schemaList {
+		schemaMap[schema.Name] = schema
+	}
+
+	databaseFind := &api.DatabaseFind{
+		InstanceId: &instance.ID,
+	}
+	databaseList, err := s.server.DatabaseService.FindDatabaseList(ctx, databaseFind)
+	if err != nil {
+		return fmt.Errorf("failed to find databases: %w", err)
+	}
+
+	for _, database := range databaseList {
+		schema, ok := schemaMap[database.Name]
+		if !ok {
+			continue
+		}
+		if err := s.checkDatabase(ctx, driver, instance, database, schema); err != nil {
+			s.l.Error("Failed to check schema drift for database",
+				zap.String("instance", instance.Name),
+				zap.String("database", database.Name),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *SchemaDriftChecker) checkDatabase(ctx context.Context, driver db.Driver, instance *api.Instance, database *api.Database, schema *db.DBSchema) error {
+	limit := 1
+	historyList, err := driver.FindMigrationHistoryList(ctx, &db.MigrationHistoryFind{
+		Database: &database.Name,
+		Limit:    &limit,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find the latest migration history: %w", err)
+	}
+	if len(historyList) == 0 {
+		return nil
+	}
+	history := historyList[0]
+
+	snapshotList, err := s.server.MigrationSnapshotService.FindMigrationSnapshotList(ctx, &api.MigrationSnapshotFind{
+		InstanceId:         instance.ID,
+		DatabaseName:       &database.Name,
+		MigrationHistoryId: &history.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find the migration snapshot: %w", err)
+	}
+	// The migration isn't applied by us or applied before we record the synced schema.
+	if len(snapshotList) == 0 || snapshotList[0].SyncedSchema == "" {
+		return nil
+	}
+	expected := snapshotList[0].SyncedSchema
+	actual := renderSchema(schema)
+
+	status := api.SchemaDriftOpen
+	driftList, err := s.server.SchemaDriftService.FindSchemaDriftList(ctx, &api.SchemaDriftFind{
+		DatabaseId: &database.ID,
+		Status:     &status,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find open schema drifts: %w", err)
+	}
+
+	if expected == actual {
+		return s.resolveSchemaDriftList(ctx, driftList)
+	}
+
+	// We have already reported the same drift.
+	if len(driftList) > 0 && driftList[0].Version == history.Version && driftList[0].Schema == actual {
+		return nil
+	}
+	if err := s.resolveSchemaDriftList(ctx, driftList); err != nil {
+		return err
+	}
+
+	drift, err := s.server.SchemaDriftService.CreateSchemaDrift(ctx, &api.SchemaDriftCreate{
+		CreatorId:  api.SYSTEM_BOT_ID,
+		DatabaseId: database.ID,
+		Version:    history.Version,
+		Schema:     actual,
+		Diff:       computeSchemaDiff(expected, actual),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create schema drift: %w", err)
+	}
+
+	payload, err := json.Marshal(api.ActivityDatabaseSchemaDriftPayload{
+		DriftId:      drift.ID,
+		Version:      drift.Version,
+		DatabaseName: database.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal activity payload: %w", err)
+	}
+	activityCreate := &api.ActivityCreate{
+		CreatorId:   api.SYSTEM_BOT_ID,
+		ContainerId: database.ID,
+		Type:        api.ActivityDatabaseSchemaDrift,
+		Level:       api.ACTIVITY_WARNING,
+		Comment:     fmt.Sprintf("Schema of database %q drifted from the expected schema after migration version %s.", database.Name, drift.Version),
+		Payload:     string(payload),
+	}
+	if _, err := s.server.ActivityManager.CreateActivity(ctx, activityCreate, &ActivityMeta{
+		database: database,
+	}); err != nil {
+		return fmt.Errorf("failed to create activity: %w", err)
+	}
+	return nil
+}
+
+func (s *SchemaDriftChecker) resolveSchemaDriftList(ctx context.Context, driftList []*api.SchemaDrift) error {
+	status := api.SchemaDriftResolved
+	for _, drift := range driftList {
+		if _, err := s.server.SchemaDriftService.PatchSchemaDrift(ctx, &api.SchemaDriftPatch{
+			ID:        drift.ID,
+			UpdaterId: api.SYSTEM_BOT_ID,
+			Status:    &status,
+		}); err != nil {
+			return fmt.Errorf("failed to resolve schema drift %d: %w", drift.ID, err)
+		}
+	}
+	return nil
+}
+
+// syncDatabaseSchema returns the rendered schema of the database synced from the driver.
+func syncDatabaseSchema(ctx context.Context, driver db.Driver, databaseName string) (string, error) {
+	_, schemaList, err := driver.SyncSchema(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, schema := range schemaList {
+		if schema.Name == databaseName {
+			return renderSchema(schema), nil
+		}
+	}
+	return "", fmt.Errorf("database %q not found", databaseName)
+}
+
+// renderSchema renders the schema as stable text for comparing and diffing. Everything is sorted by name,
+// and the statistics changing along with the data (e.g. row count and size) are left out.
+func renderSchema(schema *db.DBSchema) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "DATABASE %s CHARACTER SET %s COLLATE %s\n", schema.Name, schema.CharacterSet, schema.Collation)
+
+	tableList := append([]db.DBTable(nil), schema.TableList...)
+	sort.Slice(tableList, func(i, j int) bool { return tableList[i].Name < tableList[j].Name })
+	for _, table := range tableList {
+		fmt.Fprintf(&buf, "\nTABLE %s TYPE %s ENGINE %s COLLATE %s OPTIONS %q COMMENT %q\n", table.Name, table.Type, table.Engine, table.Collation, table.CreateOptions, table.Comment)
+
+		columnList := append([]db.DBColumn(nil), table.ColumnList...)
+		sort.Slice(columnList, func(i, j int) bool { return columnList[i].Position < columnList[j].Position })
+		for _, column := range columnList {
+			defaultValue := "NULL"
+			if column.Default != nil {
+				defaultValue = fmt.Sprintf("%q", *column.Default)
+			}
+			fmt.Fprintf(&buf, "  COLUMN %s %s NULLABLE %t DEFAULT %s CHARACTER SET %s COLLATE %s COMMENT %q\n", column.Name, column.Type, column.Nullable, defaultValue, column.CharacterSet, column.Collation, column.Comment)
+		}
+
+		// Render each index on a single line, with its expressions in position order.
+		indexMap := make(map[string][]db.DBIndex)
+		var indexNameList []string
+		for _, index := range table.IndexList {
+			if _, ok := indexMap[index.Name]; !ok {
+				indexNameList = append(indexNameList, index.Name)
+			}
+			indexMap[index.Name] = append(indexMap[index.Name], index)
+		}
+		sort.Strings(indexNameList)
+		for _, name := range indexNameList {
+			indexList := indexMap[name]
+			sort.Slice(indexList, func(i, j int) bool { return indexList[i].Position < indexList[j].Position })
+			var expressionList []string
+			for _, index := range indexList {
+				expressionList = append(expressionList, index.Expression)
+			}
+			index := indexList[0]
+			fmt.Fprintf(&buf, "  INDEX %s (%s) TYPE %s UNIQUE %t VISIBLE %t COMMENT %q\n", name, strings.Join(expressionList, ", "), index.Type, index.Unique, index.Visible, index.Comment)
+		}
+
+		constraintList := append([]db.DBConstraint(nil), table.ConstraintList...)
+		sort.Slice(constraintList, func(i, j int) bool {
+			if constraintList[i].Type != constraintList[j].Type {
+				return constraintList[i].Type < constraintList[j].Type
+			}
+			return constraintList[i].Name < constraintList[j].Name
+		})
+		for _, constraint := range constraintList {
+			fmt.Fprintf(&buf, "  CONSTRAINT %s %s (%s)", constraint.Name, constraint.Type, strings.Join(constraint.ColumnList, ", "))
+			if constraint.ReferencedTable != "" {
+				fmt.Fprintf(&buf, " REFERENCES %s (%s) ON UPDATE %s ON DELETE %s", constraint.ReferencedTable, strings.Join(constraint.ReferencedColumnList, ", "), constraint.UpdateRule, constraint.DeleteRule)
+			}
+			if constraint.Expression != "" {
+				fmt.Fprintf(&buf, " CHECK %s", constraint.Expression)
+			}
+			buf.WriteString("\n")
+		}
+	}
+
+	viewList := append([]db.DBView(nil), schema.ViewList...)
+	sort.Slice(viewList, func(i, j int) bool { return viewList[i].Name < viewList[j].Name })
+	for _, view := range viewList {
+		fmt.Fprintf(&buf, "\nVIEW %s COMMENT %q\n%s\n", view.Name, view.Comment, view.Definition)
+	}
+
+	routineList := append([]db.DBRoutine(nil), schema.RoutineList...)
+	sort.Slice(routineList, func(i, j int) bool {
+		if routineList[i].Type != routineList[j].Type {
+			return routineList[i].Type < routineList[j].Type
+		}
+		return routineList[i].Name < routineList[j].Name
+	})
+	for _, routine := range routineList {
+		fmt.Fprintf(&buf, "\n%s %s COMMENT %q\n%s\n", routine.Type, routine.Name, routine.Comment, routine.Definition)
+	}
+
+	triggerList := append([]db.DBTrigger(nil), schema.TriggerList...)
+	sort.Slice(triggerList, func(i, j int) bool {
+		if triggerList[i].TableName != triggerList[j].TableName {
+			return triggerList[i].TableName < triggerList[j].TableName
+		}
+		return triggerList[i].Name < triggerList[j].Name
+	})
+	for _, trigger := range triggerList {
+		fmt.Fprintf(&buf, "\nTRIGGER %s %s %s ON %s\n%s\n", trigger.Name, trigger.Timing, trigger.Event, trigger.TableName, trigger.Definition)
+	}
+
+	eventList := append([]db.DBEvent(nil), schema.EventList...)
+	sort.Slice(eventList, func(i, j int) bool { return eventList[i].Name < eventList[j].Name })
+	for _, event := range eventList {
+		fmt.Fprintf(&buf, "\nEVENT %s %s STATUS %s COMMENT %q\n%s\n", event.Name, event.Schedule, event.Status, event.Comment, event.Definition)
+	}
+
+	return buf.String()
+}
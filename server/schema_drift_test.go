@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bytebase/bytebase/api"
+	"go.uber.org/zap"
+)
+
+func TestSchemaDriftChecker(t *testing.T) {
+	s, _ := newTestServer(t)
+	ctx := context.Background()
+	database := newTestSQLiteDatabase(t, s)
+	checker := NewSchemaDriftChecker(zap.NewNop(), s)
+
+	findDriftList := func() []*api.SchemaDrift {
+		driftList, err := s.SchemaDriftService.FindSchemaDriftList(ctx, &api.SchemaDriftFind{DatabaseId: &database.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return driftList
+	}
+	check := func() {
+		if err := checker.checkInstance(ctx, database.Instance); err != nil {
+			t.Fatalf("failed to check schema drift: %v", err)
+		}
+	}
+
+	task := runSchemaUpdateTask(t, s, database, "CREATE TABLE book (id INTEGER PRIMARY KEY, name TEXT);")
+	version := defaultMigrationVersionFromTaskId(task.ID)
+	check()
+	if driftList := findDriftList(); len(driftList) != 0 {
+		t.Fatalf("got %d schema drifts right after the migration, want 0", len(driftList))
+	}
+
+	// Change the schema behind the migration.
+	driver, err := s.GetDatabaseDriver(database.Instance, database.Name, api.Admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = driver.Execute(ctx, "ALTER TABLE book ADD COLUMN price INTEGER;")
+	driver.Close(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check()
+	// The same drift is only reported once.
+	check()
+	driftList := findDriftList()
+	if len(driftList) != 1 {
+		t.Fatalf("got %d schema drifts after changing the schema, want 1", len(driftList))
+	}
+	drift := driftList[0]
+	if drift.Status != api.SchemaDriftOpen || drift.Version != version {
+		t.Errorf("got schema drift %s at version %s, want %s at version %s", drift.Status, drift.Version, api.SchemaDriftOpen, version)
+	}
+	var changedList []string
+	for _, line := range strings.Split(drift.Diff, "\n") {
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			changedList = append(changedList, line)
+		}
+	}
+	if len(changedList) != 1 || !strings.HasPrefix(changedList[0], "+") || !strings.Contains(changedList[0], "price") {
+		t.Errorf("got schema drift diff %q, want only the price column added", drift.Diff)
+	}
+
+	// The next migration takes the changed schema as expected, which resolves the drift.
+	runSchemaUpdateTask(t, s, database, "CREATE INDEX idx_book_name ON book (name);")
+	check()
+	driftList = findDriftList()
+	if len(driftList) != 1 || driftList[0].Status != api.SchemaDriftResolved {
+		t.Errorf("got schema drifts %+v after the next migration, want the drift resolved", driftList)
+	}
+}
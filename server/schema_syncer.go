@@ -13,6 +13,13 @@ const (
 	SCHEMA_SYNC_INTERVAL = time.Duration(30) * time.Minute
 )
 
+// tableStatRetention is how long the table stats of each granularity are kept, the HOUR stats are downsampled to
+// the DAY stats which last longer.
+var tableStatRetention = map[api.TableStatGranularity]time.Duration{
+	api.TableStatHour: time.Duration(30*24) * time.Hour,
+	api.TableStatDay:  time.Duration(2*365*24) * time.Hour,
+}
+
 func NewSchemaSyncer(logger *zap.Logger, server *Server) *SchemaSyncer {
 	return &SchemaSyncer{
 		l:      logger,
@@ -67,6 +74,8 @@ func (s *SchemaSyncer) Run() error {
 						}
 					}(instance)
 				}
+
+				s.pruneTableStat(context.Background())
 			}()
 
 			time.Sleep(SCHEMA_SYNC_INTERVAL)
@@ -75,3 +84,18 @@ func (s *SchemaSyncer) Run() error {
 
 	return nil
 }
+
+// pruneTableStat deletes the table stats beyond the retention.
+func (s *SchemaSyncer) pruneTableStat(ctx context.Context) {
+	for granularity, retention := range tableStatRetention {
+		tableStatDelete := &api.TableStatDelete{
+			Granularity: granularity,
+			BeforeTs:    time.Now().Add(-retention).Unix(),
+		}
+		if err := s.server.TableStatService.DeleteTableStat(ctx, tableStatDelete); err != nil {
+			s.l.Error("Failed to prune table stat",
+				zap.String("granularity", string(granularity)),
+				zap.Error(err))
+		}
+	}
+}
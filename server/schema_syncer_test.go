@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"go.uber.org/zap"
+)
+
+func TestPruneTableStat(t *testing.T) {
+	s, _ := newTestServer(t)
+	ctx := context.Background()
+	tableId, databaseId := 7101, 7002
+
+	now := time.Now()
+	tests := []struct {
+		granularity api.TableStatGranularity
+		age         time.Duration
+		kept        bool
+	}{
+		{granularity: api.TableStatHour, age: tableStatRetention[api.TableStatHour] + 2*time.Hour, kept: false},
+		{granularity: api.TableStatHour, age: tableStatRetention[api.TableStatHour] - 2*time.Hour, kept: true},
+		{granularity: api.TableStatDay, age: tableStatRetention[api.TableStatDay] + 48*time.Hour, kept: false},
+		// The day stats are kept for longer than the hour stats.
+		{granularity: api.TableStatDay, age: tableStatRetention[api.TableStatHour] + 48*time.Hour, kept: true},
+	}
+	for _, test := range tests {
+		if err := s.TableStatService.UpsertTableStat(ctx, &api.TableStatUpsert{
+			DatabaseId:  databaseId,
+			TableId:     tableId,
+			Granularity: test.granularity,
+			Ts:          test.granularity.BucketTs(now.Add(-test.age).Unix()),
+			RowCount:    1,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	NewSchemaSyncer(zap.NewNop(), s).pruneTableStat(ctx)
+
+	for _, test := range tests {
+		ts := test.granularity.BucketTs(now.Add(-test.age).Unix())
+		pointList, err := s.TableStatService.FindTableStatPointList(ctx, &api.TableStatFind{
+			TableId:     &tableId,
+			Granularity: test.granularity,
+			SinceTs:     &ts,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		kept := len(pointList) > 0 && pointList[0].Ts == ts
+		if kept != test.kept {
+			t.Errorf("got %s stat %v old kept %t, want %t", test.granularity, test.age, kept, test.kept)
+		}
+	}
+}
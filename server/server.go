@@ -19,39 +19,50 @@ import (
 )
 
 type Server struct {
-	TaskScheduler *TaskScheduler
-	SchemaSyncer  *SchemaSyncer
-	BackupRunner  *BackupRunner
+	TaskScheduler      *TaskScheduler
+	SchemaSyncer       *SchemaSyncer
+	SchemaDriftChecker *SchemaDriftChecker
+	BackupRunner       *BackupRunner
+	DataSourceExpirer  *DataSourceExpirer
+	DriverPool         *DriverPool
 
 	ActivityManager *ActivityManager
 
 	CacheService api.CacheService
 
-	SettingService         api.SettingService
-	PrincipalService       api.PrincipalService
-	MemberService          api.MemberService
-	ProjectService         api.ProjectService
-	ProjectMemberService   api.ProjectMemberService
-	ProjectWebhookService  api.ProjectWebhookService
-	EnvironmentService     api.EnvironmentService
-	InstanceService        api.InstanceService
-	InstanceUserService    api.InstanceUserService
-	DatabaseService        api.DatabaseService
-	TableService           api.TableService
-	ColumnService          api.ColumnService
-	IndexService           api.IndexService
-	DataSourceService      api.DataSourceService
-	BackupService          api.BackupService
-	IssueService           api.IssueService
-	IssueSubscriberService api.IssueSubscriberService
-	PipelineService        api.PipelineService
-	StageService           api.StageService
-	TaskService            api.TaskService
-	ActivityService        api.ActivityService
-	InboxService           api.InboxService
-	BookmarkService        api.BookmarkService
-	VCSService             api.VCSService
-	RepositoryService      api.RepositoryService
+	SettingService           api.SettingService
+	PrincipalService         api.PrincipalService
+	MemberService            api.MemberService
+	ProjectService           api.ProjectService
+	ProjectMemberService     api.ProjectMemberService
+	ProjectWebhookService    api.ProjectWebhookService
+	EnvironmentService       api.EnvironmentService
+	InstanceService          api.InstanceService
+	InstanceUserService      api.InstanceUserService
+	DatabaseService          api.DatabaseService
+	TableService             api.TableService
+	TableStatService         api.TableStatService
+	ColumnService            api.ColumnService
+	IndexService             api.IndexService
+	ConstraintService        api.ConstraintService
+	ViewService              api.ViewService
+	RoutineService           api.RoutineService
+	TriggerService           api.TriggerService
+	EventService             api.EventService
+	DataSourceService        api.DataSourceService
+	BackupService            api.BackupService
+	MigrationSnapshotService api.MigrationSnapshotService
+	SchemaDriftService       api.SchemaDriftService
+	IssueService             api.IssueService
+	IssueSubscriberService   api.IssueSubscriberService
+	PipelineService          api.PipelineService
+	StageService             api.StageService
+	TaskService              api.TaskService
+	ActivityService          api.ActivityService
+	InboxService             api.InboxService
+	BookmarkService          api.BookmarkService
+	VCSService               api.VCSService
+	RepositoryService        api.RepositoryService
 
 	e *echo.Echo
 
@@ -68,6 +79,8 @@ type Server struct {
 	demo         bool
 	plan         api.PlanType
 	dataDir      string
+	// How long a migration waits for another one of the same namespace, e.g. from another Bytebase server.
+	migrationLockTimeout time.Duration
 }
 
 //go:embed acl_casbin_model.conf
@@ -82,7 +95,7 @@ var casbinDBAPolicy string
 //go:embed acl_casbin_policy_developer.csv
 var casbinDeveloperPolicy string
 
-func NewServer(logger *zap.Logger, version string, host string, port int, frontendHost string, frontendPort int, mode string, dataDir string, backupRunnerInterval time.Duration, secret string, readonly bool, demo bool, debug bool) *Server {
+func NewServer(logger *zap.Logger, version string, host string, port int, frontendHost string, frontendPort int, mode string, dataDir string, backupRunnerInterval time.Duration, migrationLockTimeout time.Duration, secret string, readonly bool, demo bool, debug bool) *Server {
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
@@ -105,7 +118,10 @@ func NewServer(logger *zap.Logger, version string, host string, port int, fronte
 		demo:         demo,
 		plan:         api.TEAM,
 		dataDir:      dataDir,
+
+		migrationLockTimeout: migrationLockTimeout,
 	}
+	s.DriverPool = NewDriverPool(logger)
 
 	if !readonly {
 		scheduler := NewTaskScheduler(logger, s)
@@ -114,16 +130,23 @@ func NewServer(logger *zap.Logger, version string, host string, port int, fronte
 		sqlExecutor := NewSchemaUpdateTaskExecutor(logger)
 		backupDBExecutor := NewDatabaseBackupTaskExecutor(logger)
 		restoreDBExecutor := NewDatabaseRestoreTaskExecutor(logger)
+		grantDBExecutor := NewDatabaseGrantTaskExecutor(logger)
+		dataSourceRequestExecutor := NewDataSourceRequestTaskExecutor(logger)
 		scheduler.Register(string(api.TaskGeneral), defaultExecutor)
 		scheduler.Register(string(api.TaskDatabaseCreate), createDBExecutor)
 		scheduler.Register(string(api.TaskDatabaseSchemaUpdate), sqlExecutor)
 		scheduler.Register(string(api.TaskDatabaseBackup), backupDBExecutor)
 		scheduler.Register(string(api.TaskDatabaseRestore), restoreDBExecutor)
+		scheduler.Register(string(api.TaskDatabaseGrant), grantDBExecutor)
+		scheduler.Register(string(api.TaskDatabaseRevoke), grantDBExecutor)
+		scheduler.Register(string(api.TaskDataSourceRequest), dataSourceRequestExecutor)
 		s.TaskScheduler = scheduler
 
 		schemaSyncer := NewSchemaSyncer(logger, s)
 		s.SchemaSyncer = schemaSyncer
+		s.SchemaDriftChecker = NewSchemaDriftChecker(logger, s)
 		s.BackupRunner = NewBackupRunner(logger, s, backupRunnerInterval)
+		s.DataSourceExpirer = NewDataSourceExpirer(logger, s)
 	}
 
 	// Middleware
@@ -173,6 +196,7 @@ func NewServer(logger *zap.Logger, version string, host string, port int, fronte
 	s.registerEnvironmentRoutes(apiGroup)
 	s.registerInstanceRoutes(apiGroup)
 	s.registerDatabaseRoutes(apiGroup)
+	s.registerTableStatRoutes(apiGroup)
 	s.registerIssueRoutes(apiGroup)
 	s.registerIssueSubscriberRoutes(apiGroup)
 	s.registerTaskRoutes(apiGroup)
@@ -194,6 +218,10 @@ func NewServer(logger *zap.Logger, version string, host string, port int, fronte
 }
 
 func (server *Server) Run() error {
+	if err := server.DriverPool.Run(); err != nil {
+		return err
+	}
+
 	if !server.readonly {
 		if err := server.TaskScheduler.Run(); err != nil {
 			return err
@@ -203,9 +231,17 @@ func (server *Server) Run() error {
 			return err
 		}
 
+		if err := server.SchemaDriftChecker.Run(); err != nil {
+			return err
+		}
+
 		if err := server.BackupRunner.Run(); err != nil {
 			return err
 		}
+
+		if err := server.DataSourceExpirer.Run(); err != nil {
+			return err
+		}
 	}
 
 	// Sleep for 1 sec to make sure port is released between runs.
@@ -218,4 +254,5 @@ func (server *Server) Shutdown(ctx context.Context) {
 	if err := server.e.Shutdown(ctx); err != nil {
 		server.e.Logger.Fatal(err)
 	}
+	server.DriverPool.Close()
 }
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+	"github.com/bytebase/bytebase/store"
+	"go.uber.org/zap"
+)
+
+// newTestServer returns the server backed by a new store seeded with the test data, see store/seed/test. The routes
+// and the runners are not started, and the drivers are opened by the returned fakeDriverOpener.
+func newTestServer(t *testing.T) (*Server, *fakeDriverOpener) {
+	logger := zap.NewNop()
+	dir := t.TempDir()
+	d := store.NewDB(logger, fmt.Sprintf("file:%s/bytebase_test.db", dir), "seed/test", true /* forceResetSeed */, false)
+	if err := d.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		d.Close()
+	})
+
+	s := &Server{
+		l:            logger,
+		CacheService: NewCacheService(logger),
+		plan:         api.TEAM,
+		dataDir:      dir,
+	}
+	s.SettingService = store.NewSettingService(logger, d)
+	s.PrincipalService = store.NewPrincipalService(logger, d, s.CacheService)
+	s.MemberService = store.NewMemberService(logger, d, s.CacheService)
+	s.ProjectService = store.NewProjectService(logger, d, s.CacheService)
+	s.ProjectMemberService = store.NewProjectMemberService(logger, d)
+	s.ProjectWebhookService = store.NewProjectWebhookService(logger, d)
+	s.EnvironmentService = store.NewEnvironmentService(logger, d, s.CacheService)
+	s.DataSourceService = store.NewDataSourceService(logger, d)
+	s.DatabaseService = store.NewDatabaseService(logger, d, s.CacheService)
+	s.InstanceService = store.NewInstanceService(logger, d, s.CacheService, s.DatabaseService, s.DataSourceService)
+	s.InstanceUserService = store.NewInstanceUserService(logger, d)
+	s.TableService = store.NewTableService(logger, d)
+	s.TableStatService = store.NewTableStatService(logger, d)
+	s.ColumnService = store.NewColumnService(logger, d)
+	s.IndexService = store.NewIndexService(logger, d)
+	s.ConstraintService = store.NewConstraintService(logger, d)
+	s.ViewService = store.NewViewService(logger, d)
+	s.RoutineService = store.NewRoutineService(logger, d)
+	s.TriggerService = store.NewTriggerService(logger, d)
+	s.EventService = store.NewEventService(logger, d)
+	s.BackupService = store.NewBackupService(logger, d)
+	s.MigrationSnapshotService = store.NewMigrationSnapshotService(logger, d)
+	s.SchemaDriftService = store.NewSchemaDriftService(logger, d)
+	s.IssueService = store.NewIssueService(logger, d, s.CacheService)
+	s.IssueSubscriberService = store.NewIssueSubscriberService(logger, d)
+	s.PipelineService = store.NewPipelineService(logger, d, s.CacheService)
+	s.StageService = store.NewStageService(logger, d)
+	s.TaskService = store.NewTaskService(logger, d, store.NewTaskRunService(logger, d))
+	s.ActivityService = store.NewActivityService(logger, d)
+	s.InboxService = store.NewInboxService(logger, d, s.ActivityService)
+	s.BookmarkService = store.NewBookmarkService(logger, d)
+	s.VCSService = store.NewVCSService(logger, d)
+	s.RepositoryService = store.NewRepositoryService(logger, d, s.ProjectService)
+	s.ActivityManager = NewActivityManager(s, s.ActivityService)
+	s.TaskScheduler = NewTaskScheduler(logger, s)
+
+	opener := &fakeDriverOpener{}
+	s.DriverPool = NewDriverPool(logger)
+	s.DriverPool.openDriver = opener.open
+	return s, opener
+}
+
+// newTestSQLiteDatabase creates the SQLite instance in the Dev environment 5001 with the database "app.db" in the
+// project 3001, and sets up the migration schema. The driver pool is switched to open the real drivers.
+func newTestSQLiteDatabase(t *testing.T, s *Server) *api.Database {
+	ctx := context.Background()
+	s.DriverPool.openDriver = openDataSourceDriver
+	t.Cleanup(s.DriverPool.Close)
+
+	instance, err := s.InstanceService.CreateInstance(ctx, &api.InstanceCreate{
+		CreatorId:     api.SYSTEM_BOT_ID,
+		EnvironmentId: 5001,
+		Name:          "SQLite",
+		Engine:        db.SQLite,
+		Host:          t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create sqlite instance: %v", err)
+	}
+	database, err := s.DatabaseService.CreateDatabase(ctx, &api.DatabaseCreate{
+		CreatorId:  api.SYSTEM_BOT_ID,
+		ProjectId:  3001,
+		InstanceId: instance.ID,
+		Name:       "app.db",
+	})
+	if err != nil {
+		t.Fatalf("failed to create sqlite database: %v", err)
+	}
+	if database, err = s.ComposeDatabaseByFind(ctx, &api.DatabaseFind{ID: &database.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	driver, err := s.GetDatabaseDriver(database.Instance, database.Name, api.Admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Close(ctx)
+	if err := driver.SetupMigrationIfNeeded(ctx); err != nil {
+		t.Fatalf("failed to set up migration schema: %v", err)
+	}
+	return database
+}
@@ -11,6 +11,16 @@ import (
 	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	// Number of rows returned by the query if the request doesn't specify the limit.
+	SQL_QUERY_DEFAULT_LIMIT = 1000
+	// Max number of rows returned by a single query.
+	SQL_QUERY_MAX_LIMIT = 10000
+	// Max time a query can run, the statement timeout of the environment applies if shorter.
+	SQL_QUERY_MAX_TIMEOUT = time.Duration(60) * time.Second
 )
 
 func (s *Server) registerSqlRoutes(g *echo.Group) {
@@ -92,17 +102,102 @@ func (s *Server) registerSqlRoutes(g *echo.Group) {
 		}
 		return nil
 	})
+
+	g.POST("/sql/query", func(c echo.Context) error {
+		sqlQuery := &api.SqlQuery{}
+		if err := jsonapi.UnmarshalPayload(c.Request().Body, sqlQuery); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Malformatted sql query request").SetInternal(err)
+		}
+		limit := sqlQuery.Limit
+		if limit == 0 {
+			limit = SQL_QUERY_DEFAULT_LIMIT
+		}
+		if limit < 0 || limit > SQL_QUERY_MAX_LIMIT {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid query limit %d, must be between 1 and %d", sqlQuery.Limit, SQL_QUERY_MAX_LIMIT))
+		}
+
+		databaseFind := &api.DatabaseFind{
+			ID: &sqlQuery.DatabaseId,
+		}
+		database, err := s.ComposeDatabaseByFind(context.Background(), databaseFind)
+		if err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", sqlQuery.DatabaseId))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", sqlQuery.DatabaseId)).SetInternal(err)
+		}
+		// The statement is lexed per engine, e.g. the backslash doesn't escape the quote on Postgres.
+		if err := db.ValidateQueryStatement(database.Instance.Engine, sqlQuery.Statement); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid query statement: %v", err))
+		}
+
+		// Same as the database list, Developer can only query the databases belonging to the project where the caller is a member of.
+		principalId := c.Get(GetPrincipalIdContextKey()).(int)
+		if c.Get(GetRoleContextKey()).(api.Role) == api.Developer {
+			isMember := false
+			for _, projectMember := range database.Project.ProjectMemberList {
+				if projectMember.PrincipalId == principalId {
+					isMember = true
+					break
+				}
+			}
+			if !isMember {
+				return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Only the members of project %q can query database %q", database.Project.Name, database.Name))
+			}
+		}
+
+		timeout := SQL_QUERY_MAX_TIMEOUT
+		if environmentTimeout := time.Duration(database.Instance.Environment.StatementTimeout) * time.Second; environmentTimeout > 0 && environmentTimeout < timeout {
+			timeout = environmentTimeout
+		}
+		ctx := db.WithStatementTimeout(context.Background(), timeout)
+
+		resultSet := &api.SqlQueryResult{}
+		// The query only connects with the RO data source, and still runs in a read-only transaction.
+		driver, err := s.GetStrictDatabaseDriver(database.Instance, database.Name, api.RO)
+		if err != nil {
+			resultSet.Error = err.Error()
+		} else {
+			defer driver.Close(context.Background())
+			result, err := driver.Query(ctx, sqlQuery.Statement, limit)
+			if err != nil {
+				resultSet.Error = err.Error()
+			} else {
+				resultSet.ColumnNameList = result.ColumnNameList
+				resultSet.ColumnTypeList = result.ColumnTypeList
+				resultSet.RowList = result.RowList
+				resultSet.Truncated = result.Truncated
+			}
+		}
+		s.l.Info("Queried database",
+			zap.Int("principal", principalId),
+			zap.String("instance", database.Instance.Name),
+			zap.String("database", database.Name),
+			zap.String("statement", sqlQuery.Statement),
+			zap.String("error", resultSet.Error),
+		)
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, resultSet); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal sql query result response").SetInternal(err)
+		}
+		return nil
+	})
 }
 
 func (s *Server) SyncSchema(instance *api.Instance) (rs *api.SqlResultSet) {
 	resultSet := &api.SqlResultSet{}
 	err := func() error {
-		driver, err := GetDatabaseDriver(instance, "", s.l)
+		driver, err := s.GetDatabaseDriver(instance, "", api.RO)
 		if err != nil {
 			return err
 		}
 		defer driver.Close(context.Background())
 
+		if err := s.syncInstanceServerInfo(instance, driver); err != nil {
+			return err
+		}
+
 		userList, schemaList, err := driver.SyncSchema(context.Background())
 		if err != nil {
 			resultSet.Error = err.Error()
@@ -140,6 +235,25 @@ func (s *Server) SyncSchema(instance *api.Instance) (rs *api.SqlResultSet) {
 				return nil
 			}
 
+			// Samples the table size into the buckets of each granularity for tracking the growth over time.
+			var recordTableStat = func(database *api.Database, table *api.Table, ts int64) error {
+				for _, granularity := range []api.TableStatGranularity{api.TableStatHour, api.TableStatDay} {
+					tableStatUpsert := &api.TableStatUpsert{
+						DatabaseId:  database.ID,
+						TableId:     table.ID,
+						Granularity: granularity,
+						Ts:          granularity.BucketTs(ts),
+						RowCount:    table.RowCount,
+						DataSize:    table.DataSize,
+						IndexSize:   table.IndexSize,
+					}
+					if err := s.TableStatService.UpsertTableStat(context.Background(), tableStatUpsert); err != nil {
+						return fmt.Errorf("failed to sync table stat for instance: %s, database: %s, table: %s. Error %w", instance.Name, database.Name, table.Name, err)
+					}
+				}
+				return nil
+			}
+
 			instanceUserFind := &api.InstanceUserFind{
 				InstanceId: instance.ID,
 			}
@@ -261,6 +375,10 @@ func (s *Server) SyncSchema(instance *api.Instance) (rs *api.SqlResultSet) {
 								UpdaterId:            api.SYSTEM_BOT_ID,
 								SyncStatus:           &syncStatus,
 								LastSuccessfulSyncTs: &ts,
+								RowCount:             &table.RowCount,
+								DataSize:             &table.DataSize,
+								IndexSize:            &table.IndexSize,
+								DataFree:             &table.DataFree,
 							}
 							upsertedTable, err = s.TableService.PatchTable(context.Background(), tablePatch)
 							if err != nil {
@@ -270,6 +388,9 @@ func (s *Server) SyncSchema(instance *api.Instance) (rs *api.SqlResultSet) {
 								return fmt.Errorf("failed to sync table for instance: %s, database: %s. Failed to update table: %s. Error %w", instance.Name, database.Name, storedTable.Name, err)
 							}
 						}
+						if err := recordTableStat(database, upsertedTable, ts); err != nil {
+							return err
+						}
 
 						// Column
 						for _, column := range table.ColumnList {
@@ -362,6 +483,14 @@ func (s *Server) SyncSchema(instance *api.Instance) (rs *api.SqlResultSet) {
 								}
 							}
 						}
+
+						if err := s.syncTableConstraintList(instance, database, upsertedTable, table.ConstraintList); err != nil {
+							return err
+						}
+					}
+
+					if err := s.syncDatabaseObjectList(instance, database, schema); err != nil {
+						return err
 					}
 				} else {
 					// Case 2
@@ -404,6 +533,9 @@ func (s *Server) SyncSchema(instance *api.Instance) (rs *api.SqlResultSet) {
 						if err != nil {
 							return err
 						}
+						if err := recordTableStat(database, upsertedTable, time.Now().Unix()); err != nil {
+							return err
+						}
 
 						// Column
 						for _, column := range table.ColumnList {
@@ -468,6 +600,14 @@ func (s *Server) SyncSchema(instance *api.Instance) (rs *api.SqlResultSet) {
 								}
 							}
 						}
+
+						if err := s.syncTableConstraintList(instance, database, upsertedTable, table.ConstraintList); err != nil {
+							return err
+						}
+					}
+
+					if err := s.syncDatabaseObjectList(instance, database, schema); err != nil {
+						return err
 					}
 				}
 			}
@@ -509,3 +649,193 @@ func (s *Server) SyncSchema(instance *api.Instance) (rs *api.SqlResultSet) {
 
 	return resultSet
 }
+
+// syncTableConstraintList syncs the constraints of the table. Same as the views, we upsert the constraints
+// found in the synced table and delete the rest.
+func (s *Server) syncTableConstraintList(instance *api.Instance, database *api.Database, table *api.Table, constraintList []db.DBConstraint) error {
+	constraintFind := &api.ConstraintFind{
+		DatabaseId: &database.ID,
+		TableId:    &table.ID,
+	}
+	storedConstraintList, err := s.ConstraintService.FindConstraintList(context.Background(), constraintFind)
+	if err != nil {
+		return fmt.Errorf("failed to sync constraint for instance: %s, database: %s, table: %s. Failed to find constraint list. Error %w", instance.Name, database.Name, table.Name, err)
+	}
+
+	// type/name -> found
+	constraintSet := make(map[string]bool)
+	for _, constraint := range constraintList {
+		constraintUpsert := &api.ConstraintUpsert{
+			CreatorId:            api.SYSTEM_BOT_ID,
+			DatabaseId:           database.ID,
+			TableId:              table.ID,
+			Name:                 constraint.Name,
+			Type:                 constraint.Type,
+			ColumnList:           constraint.ColumnList,
+			ReferencedTable:      constraint.ReferencedTable,
+			ReferencedColumnList: constraint.ReferencedColumnList,
+			UpdateRule:           constraint.UpdateRule,
+			DeleteRule:           constraint.DeleteRule,
+			Expression:           constraint.Expression,
+		}
+		if _, err := s.ConstraintService.UpsertConstraint(context.Background(), constraintUpsert); err != nil {
+			return fmt.Errorf("failed to sync constraint for instance: %s, database: %s, table: %s. Failed to upsert %s: %s. Error %w", instance.Name, database.Name, table.Name, constraint.Type, constraint.Name, err)
+		}
+		constraintSet[fmt.Sprintf("%s/%s", constraint.Type, constraint.Name)] = true
+	}
+	for _, constraint := range storedConstraintList {
+		if !constraintSet[fmt.Sprintf("%s/%s", constraint.Type, constraint.Name)] {
+			if err := s.ConstraintService.DeleteConstraint(context.Background(), &api.ConstraintDelete{ID: constraint.ID}); err != nil {
+				return fmt.Errorf("failed to sync constraint for instance: %s, database: %s, table: %s. Failed to delete %s: %s. Error %w", instance.Name, database.Name, table.Name, constraint.Type, constraint.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// syncDatabaseObjectList syncs the views, routines, triggers and events of the database.
+// Unlike tables, these objects aren't associated with other entities, so we simply upsert the
+// ones found in the synced schema, which also updates their definitions, and delete the rest.
+func (s *Server) syncDatabaseObjectList(instance *api.Instance, database *api.Database, schema *db.DBSchema) error {
+	// View
+	viewList, err := s.ViewService.FindViewList(context.Background(), &api.ViewFind{DatabaseId: database.ID})
+	if err != nil {
+		return fmt.Errorf("failed to sync view for instance: %s, database: %s. Failed to find view list. Error %w", instance.Name, database.Name, err)
+	}
+	viewSet := make(map[string]bool)
+	for _, view := range schema.ViewList {
+		viewUpsert := &api.ViewUpsert{
+			CreatorId:  api.SYSTEM_BOT_ID,
+			DatabaseId: database.ID,
+			Name:       view.Name,
+			Definition: view.Definition,
+			Comment:    view.Comment,
+		}
+		if _, err := s.ViewService.UpsertView(context.Background(), viewUpsert); err != nil {
+			return fmt.Errorf("failed to sync view for instance: %s, database: %s. Failed to upsert view: %s. Error %w", instance.Name, database.Name, view.Name, err)
+		}
+		viewSet[view.Name] = true
+	}
+	for _, view := range viewList {
+		if !viewSet[view.Name] {
+			if err := s.ViewService.DeleteView(context.Background(), &api.ViewDelete{ID: view.ID}); err != nil {
+				return fmt.Errorf("failed to sync view for instance: %s, database: %s. Failed to delete view: %s. Error %w", instance.Name, database.Name, view.Name, err)
+			}
+		}
+	}
+
+	// Routine
+	routineList, err := s.RoutineService.FindRoutineList(context.Background(), &api.RoutineFind{DatabaseId: database.ID})
+	if err != nil {
+		return fmt.Errorf("failed to sync routine for instance: %s, database: %s. Failed to find routine list. Error %w", instance.Name, database.Name, err)
+	}
+	// type/name -> found
+	routineSet := make(map[string]bool)
+	for _, routine := range schema.RoutineList {
+		routineUpsert := &api.RoutineUpsert{
+			CreatorId:  api.SYSTEM_BOT_ID,
+			DatabaseId: database.ID,
+			Name:       routine.Name,
+			Type:       routine.Type,
+			Definition: routine.Definition,
+			Comment:    routine.Comment,
+		}
+		if _, err := s.RoutineService.UpsertRoutine(context.Background(), routineUpsert); err != nil {
+			return fmt.Errorf("failed to sync routine for instance: %s, database: %s. Failed to upsert %s: %s. Error %w", instance.Name, database.Name, routine.Type, routine.Name, err)
+		}
+		routineSet[fmt.Sprintf("%s/%s", routine.Type, routine.Name)] = true
+	}
+	for _, routine := range routineList {
+		if !routineSet[fmt.Sprintf("%s/%s", routine.Type, routine.Name)] {
+			if err := s.RoutineService.DeleteRoutine(context.Background(), &api.RoutineDelete{ID: routine.ID}); err != nil {
+				return fmt.Errorf("failed to sync routine for instance: %s, database: %s. Failed to delete %s: %s. Error %w", instance.Name, database.Name, routine.Type, routine.Name, err)
+			}
+		}
+	}
+
+	// Trigger
+	triggerList, err := s.TriggerService.FindTriggerList(context.Background(), &api.TriggerFind{DatabaseId: database.ID})
+	if err != nil {
+		return fmt.Errorf("failed to sync trigger for instance: %s, database: %s. Failed to find trigger list. Error %w", instance.Name, database.Name, err)
+	}
+	// tableName/name -> found
+	triggerSet := make(map[string]bool)
+	for _, trigger := range schema.TriggerList {
+		triggerUpsert := &api.TriggerUpsert{
+			CreatorId:  api.SYSTEM_BOT_ID,
+			DatabaseId: database.ID,
+			Name:       trigger.Name,
+			TableName:  trigger.TableName,
+			Timing:     trigger.Timing,
+			Event:      trigger.Event,
+			Definition: trigger.Definition,
+		}
+		if _, err := s.TriggerService.UpsertTrigger(context.Background(), triggerUpsert); err != nil {
+			return fmt.Errorf("failed to sync trigger for instance: %s, database: %s, table: %s. Failed to upsert trigger: %s. Error %w", instance.Name, database.Name, trigger.TableName, trigger.Name, err)
+		}
+		triggerSet[fmt.Sprintf("%s/%s", trigger.TableName, trigger.Name)] = true
+	}
+	for _, trigger := range triggerList {
+		if !triggerSet[fmt.Sprintf("%s/%s", trigger.TableName, trigger.Name)] {
+			if err := s.TriggerService.DeleteTrigger(context.Background(), &api.TriggerDelete{ID: trigger.ID}); err != nil {
+				return fmt.Errorf("failed to sync trigger for instance: %s, database: %s, table: %s. Failed to delete trigger: %s. Error %w", instance.Name, database.Name, trigger.TableName, trigger.Name, err)
+			}
+		}
+	}
+
+	// Event
+	eventList, err := s.EventService.FindEventList(context.Background(), &api.EventFind{DatabaseId: database.ID})
+	if err != nil {
+		return fmt.Errorf("failed to sync event for instance: %s, database: %s. Failed to find event list. Error %w", instance.Name, database.Name, err)
+	}
+	eventSet := make(map[string]bool)
+	for _, event := range schema.EventList {
+		eventUpsert := &api.EventUpsert{
+			CreatorId:  api.SYSTEM_BOT_ID,
+			DatabaseId: database.ID,
+			Name:       event.Name,
+			Definition: event.Definition,
+			Schedule:   event.Schedule,
+			Status:     event.Status,
+			Comment:    event.Comment,
+		}
+		if _, err := s.EventService.UpsertEvent(context.Background(), eventUpsert); err != nil {
+			return fmt.Errorf("failed to sync event for instance: %s, database: %s. Failed to upsert event: %s. Error %w", instance.Name, database.Name, event.Name, err)
+		}
+		eventSet[event.Name] = true
+	}
+	for _, event := range eventList {
+		if !eventSet[event.Name] {
+			if err := s.EventService.DeleteEvent(context.Background(), &api.EventDelete{ID: event.ID}); err != nil {
+				return fmt.Errorf("failed to sync event for instance: %s, database: %s. Failed to delete event: %s. Error %w", instance.Name, database.Name, event.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// syncInstanceServerInfo records the detected server flavor and version of the instance if changed.
+func (s *Server) syncInstanceServerInfo(instance *api.Instance, driver db.Driver) error {
+	serverInfo, err := driver.GetServerInfo(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get server info for instance: %s. Error %w", instance.Name, err)
+	}
+	if serverInfo.Flavor == instance.Flavor && serverInfo.Version == instance.EngineVersion {
+		return nil
+	}
+
+	instancePatch := &api.InstancePatch{
+		ID:            instance.ID,
+		UpdaterId:     api.SYSTEM_BOT_ID,
+		Flavor:        &serverInfo.Flavor,
+		EngineVersion: &serverInfo.Version,
+	}
+	if _, err := s.InstanceService.PatchInstance(context.Background(), instancePatch); err != nil {
+		return fmt.Errorf("failed to update server info for instance: %s. Error %w", instance.Name, err)
+	}
+	instance.Flavor = serverInfo.Flavor
+	instance.EngineVersion = serverInfo.Version
+	return nil
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/google/jsonapi"
+	"github.com/labstack/echo/v4"
+)
+
+func (s *Server) registerTableStatRoutes(g *echo.Group) {
+	g.GET("/database/:id/table/:tableName/stat", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		tableName := c.Param("tableName")
+		tableFind := &api.TableFind{
+			DatabaseId: &id,
+			Name:       &tableName,
+		}
+		table, err := s.TableService.FindTable(context.Background(), tableFind)
+		if err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Table not found for database id: %d, table name: %s", id, tableName))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch table for database id: %d, table name: %s", id, tableName)).SetInternal(err)
+		}
+
+		return s.writeTableGrowth(c, &api.TableStatFind{
+			TableId: &table.ID,
+		})
+	})
+
+	g.GET("/database/:id/stat", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("id"))).SetInternal(err)
+		}
+
+		databaseFind := &api.DatabaseFind{
+			ID: &id,
+		}
+		if _, err := s.DatabaseService.FindDatabase(context.Background(), databaseFind); err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Database ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch database ID: %v", id)).SetInternal(err)
+		}
+
+		return s.writeTableGrowth(c, &api.TableStatFind{
+			DatabaseId: &id,
+		})
+	})
+
+	g.GET("/project/:projectId/stat", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("projectId"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("ID is not a number: %s", c.Param("projectId"))).SetInternal(err)
+		}
+
+		projectFind := &api.ProjectFind{
+			ID: &id,
+		}
+		if _, err := s.ProjectService.FindProject(context.Background(), projectFind); err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Project ID not found: %d", id))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch project ID: %v", id)).SetInternal(err)
+		}
+
+		return s.writeTableGrowth(c, &api.TableStatFind{
+			ProjectId: &id,
+		})
+	})
+}
+
+// writeTableGrowth finds the table stats by the "granularity" (HOUR or DAY, defaults to DAY) and "since" (unix
+// timestamp, defaults to the retention) query params, and writes the growth as the response.
+func (s *Server) writeTableGrowth(c echo.Context, find *api.TableStatFind) error {
+	find.Granularity = api.TableStatDay
+	if granularityStr := c.QueryParam("granularity"); granularityStr != "" {
+		find.Granularity = api.TableStatGranularity(granularityStr)
+	}
+	retention, ok := tableStatRetention[find.Granularity]
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid granularity %q, must be either %s or %s", find.Granularity, api.TableStatHour, api.TableStatDay))
+	}
+	since := time.Now().Add(-retention).Unix()
+	if sinceStr := c.QueryParam("since"); sinceStr != "" {
+		v, err := strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Since is not a number: %s", sinceStr)).SetInternal(err)
+		}
+		since = v
+	}
+	find.SinceTs = &since
+
+	pointList, err := s.TableStatService.FindTableStatPointList(context.Background(), find)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch table stat: %v", find)).SetInternal(err)
+	}
+
+	growth := &api.TableGrowth{
+		Granularity: find.Granularity,
+		PointList:   pointList,
+	}
+	if len(pointList) > 0 {
+		first, last := pointList[0], pointList[len(pointList)-1]
+		growth.RowCountGrowth = last.RowCount - first.RowCount
+		growth.DataSizeGrowth = last.DataSize - first.DataSize
+		growth.IndexSizeGrowth = last.IndexSize - first.IndexSize
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	if err := jsonapi.MarshalPayload(c.Response().Writer, growth); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to marshal table growth response").SetInternal(err)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/bytebase/bytebase/api"
 	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
@@ -52,6 +53,19 @@ func (s *Server) registerTaskRoutes(g *echo.Group) {
 			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update task status").SetInternal(err)
 		}
 
+		// Retrying the canceled task resumes as well, since the canceled migration may have applied some statements.
+		if task.Type == api.TaskDatabaseSchemaUpdate && (task.Status == api.TaskFailed || task.Status == api.TaskCanceled) && taskStatusPatch.Status == api.TaskRunning {
+			taskStatusPatch.Payload, err = s.composeSchemaUpdateRetryPayload(context.Background(), task, taskStatusPatch.ResumeFromStatement)
+			if err != nil {
+				if common.ErrorCode(err) == common.EINVALID {
+					return echo.NewHTTPError(http.StatusBadRequest, common.ErrorMessage(err))
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to compose retry payload for task \"%v\"", task.Name)).SetInternal(err)
+			}
+		} else if taskStatusPatch.ResumeFromStatement != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Resuming from a statement is only supported when retrying the failed or canceled schema update task")
+		}
+
 		updatedTask, err := s.ChangeTaskStatusWithPatch(context.Background(), task, taskStatusPatch)
 		if err != nil {
 			if common.ErrorCode(err) == common.EINVALID {
@@ -70,6 +84,58 @@ func (s *Server) registerTaskRoutes(g *echo.Group) {
 		}
 		return nil
 	})
+
+	g.POST("/pipeline/:pipelineId/task/:taskId/dryrun", func(c echo.Context) error {
+		taskId, err := strconv.Atoi(c.Param("taskId"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task ID is not a number: %s", c.Param("taskId"))).SetInternal(err)
+		}
+
+		task, err := s.ComposeTaskById(context.Background(), taskId)
+		if err != nil {
+			if common.ErrorCode(err) == common.ENOTFOUND {
+				return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Task ID not found: %d", taskId))
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to fetch task ID: %v", taskId)).SetInternal(err)
+		}
+		if task.Type != api.TaskDatabaseSchemaUpdate {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Dry run is only supported for task type %s, got %s", api.TaskDatabaseSchemaUpdate, task.Type))
+		}
+		if task.Database == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Task %q is not associated with any database", task.Name))
+		}
+
+		payload := &api.TaskDatabaseSchemaUpdatePayload{}
+		if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Invalid database schema update payload for task %q", task.Name)).SetInternal(err)
+		}
+
+		mi, err := composeSchemaUpdateMigrationInfo(context.Background(), s, task, payload)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to compose migration info for task %q", task.Name)).SetInternal(err)
+		}
+
+		resultSet := &api.SqlDryRunResult{}
+		driver, err := s.GetDatabaseDriver(task.Instance, task.Database.Name, api.RW)
+		if err != nil {
+			resultSet.Error = err.Error()
+		} else {
+			defer driver.Close(context.Background())
+			errorList, err := s.dryRunMigration(context.Background(), driver, task.Database, mi, payload.Statement)
+			if err != nil {
+				resultSet.Error = err.Error()
+			}
+			for _, e := range errorList {
+				resultSet.ProblemList = append(resultSet.ProblemList, e.Error())
+			}
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		if err := jsonapi.MarshalPayload(c.Response().Writer, resultSet); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to marshal dry run result for task %q", task.Name)).SetInternal(err)
+		}
+		return nil
+	})
 }
 
 func (s *Server) ComposeTaskListByPipelineAndStageId(ctx context.Context, pipelineId int, stageId int) ([]*api.Task, error) {
@@ -150,6 +216,49 @@ func (s *Server) ComposeTaskRelationship(ctx context.Context, task *api.Task) er
 	return nil
 }
 
+// composeSchemaUpdateRetryPayload returns the payload for retrying the failed schema update task. The retry resumes
+// from the statement if resumeFromStatement is not nil, otherwise it starts over. Returns nil if the payload is unchanged.
+func (s *Server) composeSchemaUpdateRetryPayload(ctx context.Context, task *api.Task, resumeFromStatement *int) (*string, error) {
+	payload := &api.TaskDatabaseSchemaUpdatePayload{}
+	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
+		return nil, fmt.Errorf("invalid database schema update payload: %w", err)
+	}
+
+	index := 0
+	if resumeFromStatement != nil {
+		index = *resumeFromStatement
+		statementList := db.SplitStatement(payload.Statement)
+		if index < 0 || index >= len(statementList) {
+			return nil, &common.Error{Code: common.EINVALID, Message: fmt.Sprintf("Invalid statement index %d to resume from, the migration has %d statements", index, len(statementList))}
+		}
+	}
+	if index > 0 {
+		// With transactional DDL, the failed migration is rolled back entirely, so there is nothing to resume.
+		instance, err := s.InstanceService.FindInstance(ctx, &api.InstanceFind{ID: &task.InstanceId})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find instance for task %q: %w", task.Name, err)
+		}
+		capability, err := db.GetCapability(instance.Engine)
+		if err != nil {
+			return nil, err
+		}
+		if capability.Supports(db.OperationTransactionalDDL) {
+			return nil, &common.Error{Code: common.EINVALID, Message: fmt.Sprintf("Resuming from a statement is not supported for engine %s, the failed migration has been rolled back entirely", instance.Engine)}
+		}
+	}
+	if payload.ResumeFromStatement == index {
+		return nil, nil
+	}
+
+	payload.ResumeFromStatement = index
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal database schema update payload: %w", err)
+	}
+	str := string(bytes)
+	return &str, nil
+}
+
 func (s *Server) ChangeTaskStatus(ctx context.Context, task *api.Task, newStatus api.TaskStatus, updaterId int) (*api.Task, error) {
 	taskStatusPatch := &api.TaskStatusPatch{
 		ID:        task.ID,
@@ -189,6 +298,11 @@ func (s *Server) ChangeTaskStatusWithPatch(ctx context.Context, task *api.Task,
 		return nil, fmt.Errorf("failed to change task %v(%v) status: %w", task.ID, task.Name, err)
 	}
 
+	// Stop the executor of the canceled task, including the statement running on the database.
+	if task.Status == api.TaskRunning && updatedTask.Status == api.TaskCanceled && s.TaskScheduler != nil {
+		s.TaskScheduler.CancelTask(task.ID)
+	}
+
 	// Most tasks belong to a pipeline which in turns belongs to an issue. The followup code
 	// behaves differently depending on whether the task is wrapped in an issue.
 	// TODO(tianzhou): Refactor the followup code into chained onTaskStatusChange hook.
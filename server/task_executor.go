@@ -2,11 +2,14 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"github.com/bytebase/bytebase/plugin/db"
 )
 
 type TaskExecutor interface {
@@ -20,6 +23,35 @@ type TaskExecutor interface {
 	RunOnce(ctx context.Context, server *Server, task *api.Task) (terminated bool, detail string, err error)
 }
 
+// taskTypeOperationList lists the driver operations required by the task type.
+// The task is rejected up front if the instance engine doesn't support any of them.
+var taskTypeOperationList = map[api.TaskType][]db.Operation{
+	api.TaskDatabaseCreate:    {db.OperationCreateDatabase},
+	api.TaskDatabaseBackup:    {db.OperationBackupRestore},
+	api.TaskDatabaseRestore:   {db.OperationBackupRestore},
+	api.TaskDatabaseGrant:     {db.OperationUserAndGrant},
+	api.TaskDatabaseRevoke:    {db.OperationUserAndGrant},
+	api.TaskDataSourceRequest: {db.OperationUserAndGrant},
+}
+
+// checkTaskCapability returns ENOTIMPLEMENTED error if the instance engine doesn't support the task type.
+func checkTaskCapability(instance *api.Instance, taskType api.TaskType) error {
+	operationList, ok := taskTypeOperationList[taskType]
+	if !ok {
+		return nil
+	}
+	capability, err := db.GetCapability(instance.Engine)
+	if err != nil {
+		return err
+	}
+	for _, op := range operationList {
+		if !capability.Supports(op) {
+			return &common.Error{Code: common.ENOTIMPLEMENTED, Message: fmt.Sprintf("instance %q with engine %s doesn't support %s required by task type %s", instance.Name, instance.Engine, op, taskType)}
+		}
+	}
+	return nil
+}
+
 // defaultMigrationVersion returns the default migration version string
 // Use the concatenation of current time and the task id to guarantee uniqueness in a monotonic increasing way.
 func defaultMigrationVersionFromTaskId(taskId int) string {
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+	"go.uber.org/zap"
+)
+
+const (
+	// DATA_SOURCE_REQUEST_DEFAULT_TTL is the lifetime of the temporary data source if the request doesn't specify one.
+	DATA_SOURCE_REQUEST_DEFAULT_TTL = time.Duration(1) * time.Hour
+	// DATA_SOURCE_REQUEST_MAX_TTL is the longest lifetime of the temporary data source.
+	DATA_SOURCE_REQUEST_MAX_TTL = time.Duration(7*24) * time.Hour
+
+	dataSourceRequestPasswordLength = 24
+)
+
+// dataSourceRequestPrivilegeList lists the privileges granted to the temporary user for each data source type.
+var dataSourceRequestPrivilegeList = map[api.DataSourceType][]string{
+	api.RO: {"SELECT"},
+	api.RW: {"SELECT", "INSERT", "UPDATE", "DELETE"},
+}
+
+func NewDataSourceRequestTaskExecutor(logger *zap.Logger) TaskExecutor {
+	return &DataSourceRequestTaskExecutor{
+		l: logger,
+	}
+}
+
+// DataSourceRequestTaskExecutor provisions a temporary database user for the requester and attaches it as a data source,
+// which is dropped by the DataSourceExpirer after the TTL.
+type DataSourceRequestTaskExecutor struct {
+	l *zap.Logger
+}
+
+func (exec *DataSourceRequestTaskExecutor) RunOnce(ctx context.Context, server *Server, task *api.Task) (terminated bool, detail string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr, ok := r.(error)
+			if !ok {
+				panicErr = fmt.Errorf("%v", r)
+			}
+			exec.l.Error("DataSourceRequestTaskExecutor PANIC RECOVER", zap.Error(panicErr))
+			terminated = true
+			err = fmt.Errorf("encounter internal error when granting the data source")
+		}
+	}()
+
+	payload := &api.TaskDataSourceRequestPayload{}
+	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
+		return true, "", fmt.Errorf("invalid data source request payload: %w", err)
+	}
+	privilegeList, ok := dataSourceRequestPrivilegeList[payload.DataSourceType]
+	if !ok {
+		return true, "", fmt.Errorf("invalid data source type %q, must be either %s or %s", payload.DataSourceType, api.RO, api.RW)
+	}
+
+	if err := server.ComposeTaskRelationship(ctx, task); err != nil {
+		return true, "", err
+	}
+	if task.Database == nil {
+		return true, "", fmt.Errorf("missing database for task %q", task.Name)
+	}
+
+	driver, err := server.GetDatabaseDriver(task.Instance, task.Database.Name, api.Admin)
+	if err != nil {
+		return true, "", err
+	}
+	defer driver.Close(context.Background())
+
+	password, err := randomPassword(dataSourceRequestPasswordLength)
+	if err != nil {
+		return true, "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	grant := &db.DatabaseGrant{
+		// The task ID keeps the user name unique on the instance.
+		Username:      fmt.Sprintf("bb_jit_%d", task.ID),
+		Password:      password,
+		Database:      task.Database.Name,
+		PrivilegeList: privilegeList,
+	}
+	exec.l.Debug("Start granting temporary data source...",
+		zap.String("instance", task.Instance.Name),
+		zap.String("database", task.Database.Name),
+		zap.String("type", string(payload.DataSourceType)),
+		zap.String("user", grant.Username),
+		zap.Int("requester", payload.RequesterId),
+	)
+
+	user, err := driver.GrantDatabase(ctx, grant)
+	if err != nil {
+		return true, "", err
+	}
+	if err := server.syncInstanceUser(ctx, task.Instance, user, false); err != nil {
+		return true, "", err
+	}
+
+	dataSourceCreate := &api.DataSourceCreate{
+		CreatorId:  payload.RequesterId,
+		InstanceId: task.Instance.ID,
+		DatabaseId: task.Database.ID,
+		Name:       fmt.Sprintf("Temporary %s data source (task %d)", payload.DataSourceType, task.ID),
+		Type:       payload.DataSourceType,
+		Username:   grant.Username,
+		Password:   password,
+		ExpireTs:   time.Now().Unix() + payload.TTL,
+	}
+	dataSource, err := server.DataSourceService.CreateDataSource(ctx, dataSourceCreate)
+	if err != nil {
+		// Drop the user right away, otherwise it would never expire without the data source.
+		if _, revokeErr := driver.RevokeDatabase(ctx, &db.DatabaseGrant{
+			Username: grant.Username,
+			Database: grant.Database,
+			DropUser: true,
+		}); revokeErr != nil {
+			exec.l.Error("Failed to drop the temporary user",
+				zap.String("instance", task.Instance.Name),
+				zap.String("user", grant.Username),
+				zap.Error(revokeErr))
+		} else if syncErr := server.syncInstanceUser(ctx, task.Instance, user, true); syncErr != nil {
+			exec.l.Error("Failed to sync the dropped temporary user", zap.Error(syncErr))
+		}
+		return true, "", fmt.Errorf("failed to create data source: %w", err)
+	}
+
+	if err := server.createDataSourceActivity(ctx, api.ActivityDatabaseDataSourceGrant, task.Database, dataSource,
+		fmt.Sprintf("Granted temporary %s data source %q on database %q, expiring at %s.",
+			dataSource.Type, dataSource.Name, task.Database.Name, time.Unix(dataSource.ExpireTs, 0).UTC().Format(time.RFC3339))); err != nil {
+		exec.l.Error("Failed to create data source grant activity", zap.Error(err))
+	}
+
+	return true, fmt.Sprintf("Granted temporary %s data source %q as user %s, expiring in %v",
+		dataSource.Type, dataSource.Name, user.Name, time.Duration(payload.TTL)*time.Second), nil
+}
+
+// createDataSourceActivity records granting or expiring the temporary data source on the database.
+func (s *Server) createDataSourceActivity(ctx context.Context, activityType api.ActivityType, database *api.Database, dataSource *api.DataSource, comment string) error {
+	payload, err := json.Marshal(api.ActivityDatabaseDataSourcePayload{
+		DataSourceId:   dataSource.ID,
+		DataSourceName: dataSource.Name,
+		DataSourceType: dataSource.Type,
+		Username:       dataSource.Username,
+		RequesterId:    dataSource.CreatorId,
+		ExpireTs:       dataSource.ExpireTs,
+		DatabaseName:   database.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal activity payload: %w", err)
+	}
+	activityCreate := &api.ActivityCreate{
+		CreatorId:   api.SYSTEM_BOT_ID,
+		ContainerId: database.ID,
+		Type:        activityType,
+		Level:       api.ACTIVITY_INFO,
+		Comment:     comment,
+		Payload:     string(payload),
+	}
+	if _, err := s.ActivityManager.CreateActivity(ctx, activityCreate, &ActivityMeta{
+		database: database,
+	}); err != nil {
+		return fmt.Errorf("failed to create activity: %w", err)
+	}
+	return nil
+}
+
+var passwordLetters = []byte("0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+// randomPassword returns a cryptographically random alphanumeric password, unlike common.RandomString.
+func randomPassword(n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(passwordLetters)))
+	for i := range b {
+		v, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = passwordLetters[v.Int64()]
+	}
+	return string(b), nil
+}
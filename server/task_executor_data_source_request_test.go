@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"go.uber.org/zap"
+)
+
+// createDataSourceRequestTask creates the data source request issue on the database 7002 testdb_dev of the MySQL
+// instance 6001 by the developer 103, and returns the task.
+func createDataSourceRequestTask(t *testing.T, s *Server, dataSourceType api.DataSourceType, ttl int64) *api.Task {
+	ctx := context.Background()
+	databaseId := 7002
+	issue, err := s.CreateIssue(ctx, &api.IssueCreate{
+		ProjectId:  3001,
+		Name:       "Request data source",
+		Type:       api.IssueGeneral,
+		AssigneeId: 102,
+		Pipeline: api.PipelineCreate{
+			Name: "Request data source",
+			StageList: []api.StageCreate{
+				{
+					Name:          "Dev",
+					EnvironmentId: 5001,
+					TaskList: []api.TaskCreate{
+						{
+							Name:           "Request data source",
+							Type:           api.TaskDataSourceRequest,
+							Status:         api.TaskPendingApproval,
+							InstanceId:     6001,
+							DatabaseId:     &databaseId,
+							DataSourceType: dataSourceType,
+							TTL:            ttl,
+						},
+					},
+				},
+			},
+		},
+	}, 103)
+	if err != nil {
+		t.Fatalf("failed to create data source request issue: %v", err)
+	}
+	return issue.Pipeline.StageList[0].TaskList[0]
+}
+
+func TestDataSourceRequestTaskExecutor(t *testing.T) {
+	tests := []struct {
+		dataSourceType api.DataSourceType
+		ttl            int64
+		wantPrivilege  []string
+		wantTTL        int64
+	}{
+		{
+			dataSourceType: api.RO,
+			ttl:            600,
+			wantPrivilege:  []string{"SELECT"},
+			wantTTL:        600,
+		},
+		{
+			dataSourceType: api.RW,
+			wantPrivilege:  []string{"SELECT", "INSERT", "UPDATE", "DELETE"},
+			wantTTL:        int64(DATA_SOURCE_REQUEST_DEFAULT_TTL / time.Second),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.dataSourceType), func(t *testing.T) {
+			s, opener := newTestServer(t)
+			ctx := context.Background()
+			task := createDataSourceRequestTask(t, s, test.dataSourceType, test.ttl)
+
+			before := time.Now().Unix()
+			terminated, _, err := NewDataSourceRequestTaskExecutor(zap.NewNop()).RunOnce(ctx, s, task)
+			if err != nil {
+				t.Fatalf("failed to run the data source request task: %v", err)
+			}
+			if !terminated {
+				t.Fatalf("got the data source request task not terminated")
+			}
+
+			// The user is granted with the ADMIN data source 8001 of the instance.
+			driverList := opener.list()
+			if len(driverList) != 1 || driverList[0].dataSourceId != 8001 {
+				t.Fatalf("got %d drivers opened, want 1 with the ADMIN data source 8001", len(driverList))
+			}
+			grantList := driverList[0].grantList
+			if len(grantList) != 1 {
+				t.Fatalf("got %d grants, want 1", len(grantList))
+			}
+			grant := grantList[0]
+			wantUsername := fmt.Sprintf("bb_jit_%d", task.ID)
+			if grant.Username != wantUsername || grant.Database != "testdb_dev" {
+				t.Errorf("got grant to user %s on database %s, want user %s on database testdb_dev", grant.Username, grant.Database, wantUsername)
+			}
+			if fmt.Sprint(grant.PrivilegeList) != fmt.Sprint(test.wantPrivilege) {
+				t.Errorf("got privileges %v, want %v", grant.PrivilegeList, test.wantPrivilege)
+			}
+			if len(grant.Password) != dataSourceRequestPasswordLength {
+				t.Errorf("got password of length %d, want %d", len(grant.Password), dataSourceRequestPasswordLength)
+			}
+			if entry, ok := s.DriverPool.entries[driverKey{instanceId: 6001, databaseName: "testdb_dev", dataSourceId: 8001}]; ok && entry.refCount != 0 {
+				t.Errorf("got the driver not released back to the pool")
+			}
+
+			databaseId := 7002
+			dataSourceList, err := s.DataSourceService.FindDataSourceList(ctx, &api.DataSourceFind{DatabaseId: &databaseId})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(dataSourceList) != 1 {
+				t.Fatalf("got %d data sources on the database, want 1", len(dataSourceList))
+			}
+			dataSource := dataSourceList[0]
+			if dataSource.Type != test.dataSourceType || dataSource.Username != wantUsername || dataSource.Password != grant.Password {
+				t.Errorf("got data source type %s user %s, want type %s user %s with the granted password", dataSource.Type, dataSource.Username, test.dataSourceType, wantUsername)
+			}
+			// The data source belongs to the requester.
+			if dataSource.CreatorId != 103 {
+				t.Errorf("got data source created by %d, want the requester 103", dataSource.CreatorId)
+			}
+			if dataSource.ExpireTs < before+test.wantTTL || dataSource.ExpireTs > time.Now().Unix()+test.wantTTL {
+				t.Errorf("got data source expiring in %d seconds, want %d", dataSource.ExpireTs-before, test.wantTTL)
+			}
+
+			instanceUserList, err := s.InstanceUserService.FindInstanceUserList(ctx, &api.InstanceUserFind{InstanceId: 6001})
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			for _, instanceUser := range instanceUserList {
+				if instanceUser.Name == wantUsername {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("got user %s not synced to the instance", wantUsername)
+			}
+		})
+	}
+}
@@ -125,8 +125,8 @@ func getAndCreateBackupPath(dataDir string, database *api.Database, name string)
 	return filepath.Join(dir, fmt.Sprintf("%s.sql", name)), nil
 }
 
-func getMigrationVersion(database *api.Database, logger *zap.Logger) (string, error) {
-	driver, err := GetDatabaseDriver(database.Instance, database.Name, logger)
+func getMigrationVersion(server *Server, database *api.Database) (string, error) {
+	driver, err := server.GetDatabaseDriver(database.Instance, database.Name, api.Admin)
 	if err != nil {
 		return "", err
 	}
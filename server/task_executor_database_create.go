@@ -42,7 +42,7 @@ func (exec *DatabaseCreateTaskExecutor) RunOnce(ctx context.Context, server *Ser
 	}
 
 	instance := task.Instance
-	driver, err := GetDatabaseDriver(task.Instance, "", exec.l)
+	driver, err := server.GetDatabaseDriver(task.Instance, "", api.Admin)
 	if err != nil {
 		return true, "", err
 	}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
+	"go.uber.org/zap"
+)
+
+func NewDatabaseGrantTaskExecutor(logger *zap.Logger) TaskExecutor {
+	return &DatabaseGrantTaskExecutor{
+		l: logger,
+	}
+}
+
+// DatabaseGrantTaskExecutor runs both the grant and the revoke tasks.
+type DatabaseGrantTaskExecutor struct {
+	l *zap.Logger
+}
+
+func (exec *DatabaseGrantTaskExecutor) RunOnce(ctx context.Context, server *Server, task *api.Task) (terminated bool, detail string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr, ok := r.(error)
+			if !ok {
+				panicErr = fmt.Errorf("%v", r)
+			}
+			exec.l.Error("DatabaseGrantTaskExecutor PANIC RECOVER", zap.Error(panicErr))
+			terminated = true
+			err = fmt.Errorf("encounter internal error when granting database privileges")
+		}
+	}()
+
+	payload := &api.TaskDatabaseGrantPayload{}
+	if err := json.Unmarshal([]byte(task.Payload), payload); err != nil {
+		return true, "", fmt.Errorf("invalid database grant payload: %w", err)
+	}
+
+	if err := server.ComposeTaskRelationship(ctx, task); err != nil {
+		return true, "", err
+	}
+	if task.Database == nil {
+		return true, "", fmt.Errorf("missing database for task %q", task.Name)
+	}
+
+	driver, err := server.GetDatabaseDriver(task.Instance, task.Database.Name, api.Admin)
+	if err != nil {
+		return true, "", err
+	}
+	defer driver.Close(context.Background())
+
+	grant := &db.DatabaseGrant{
+		Username:      payload.Username,
+		Host:          payload.Host,
+		Password:      task.Secret,
+		Database:      task.Database.Name,
+		PrivilegeList: payload.PrivilegeList,
+		DropUser:      payload.DropUser,
+	}
+	exec.l.Debug("Start granting database privileges...",
+		zap.String("instance", task.Instance.Name),
+		zap.String("database", task.Database.Name),
+		zap.String("type", string(task.Type)),
+		zap.String("user", payload.Username),
+		zap.Strings("privileges", payload.PrivilegeList),
+	)
+
+	revoke := task.Type == api.TaskDatabaseRevoke
+	var user *db.DBUser
+	if revoke {
+		user, err = driver.RevokeDatabase(ctx, grant)
+	} else {
+		user, err = driver.GrantDatabase(ctx, grant)
+	}
+	if err != nil {
+		return true, "", err
+	}
+
+	if err := server.syncInstanceUser(ctx, task.Instance, user, revoke && payload.DropUser); err != nil {
+		return true, "", err
+	}
+
+	privileges := strings.Join(grant.PrivilegeList, ", ")
+	if revoke && payload.DropUser {
+		return true, fmt.Sprintf("Revoked all privileges and dropped user %s", user.Name), nil
+	} else if revoke {
+		return true, fmt.Sprintf("Revoked %s on database %q from user %s", privileges, task.Database.Name, user.Name), nil
+	}
+	return true, fmt.Sprintf("Granted %s on database %q to user %s", privileges, task.Database.Name, user.Name), nil
+}
+
+// syncInstanceUser reflects the user changed on the instance in the instance user list, deleting it if dropped.
+func (s *Server) syncInstanceUser(ctx context.Context, instance *api.Instance, user *db.DBUser, dropped bool) error {
+	if !dropped {
+		userUpsert := &api.InstanceUserUpsert{
+			CreatorId:  api.SYSTEM_BOT_ID,
+			InstanceId: instance.ID,
+			Name:       user.Name,
+			Grant:      user.Grant,
+		}
+		if _, err := s.InstanceUserService.UpsertInstanceUser(ctx, userUpsert); err != nil {
+			return fmt.Errorf("failed to sync user %s for instance %q: %w", user.Name, instance.Name, err)
+		}
+		return nil
+	}
+
+	instanceUserFind := &api.InstanceUserFind{
+		InstanceId: instance.ID,
+	}
+	instanceUserList, err := s.InstanceUserService.FindInstanceUserList(ctx, instanceUserFind)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user list for instance %q: %w", instance.Name, err)
+	}
+	for _, instanceUser := range instanceUserList {
+		if instanceUser.Name == user.Name {
+			userDelete := &api.InstanceUserDelete{
+				ID: instanceUser.ID,
+			}
+			if err := s.InstanceUserService.DeleteInstanceUser(ctx, userDelete); err != nil {
+				return fmt.Errorf("failed to delete user %s for instance %q: %w", user.Name, instance.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -92,7 +92,7 @@ func (exec *DatabaseRestoreTaskExecutor) RunOnce(ctx context.Context, server *Se
 
 	// TODO(tianzhou): This should be done in the same transaction as restoreDatabase to guarantee consistency.
 	// For now, we do this after restoreDatabase, since this one is unlikely to fail.
-	if err := createBranchMigrationHistory(ctx, server, sourceDatabase, targetDatabase, backup, task, exec.l); err != nil {
+	if err := createBranchMigrationHistory(ctx, server, sourceDatabase, targetDatabase, backup, task); err != nil {
 		return true, "", err
 	}
 
@@ -141,8 +141,8 @@ func restoreDatabase(database *api.Database, backup *api.Backup, dataDir string)
 // createBranchMigrationHistory creates a migration history with "BRANCH" type. We choose NOT to copy over
 // all migrationhistory from source database because that might be expensive (e.g. we may use restore to
 // create many ephemeral databases from backup for testing purpose)
-func createBranchMigrationHistory(ctx context.Context, server *Server, sourceDatabase, targetDatabase *api.Database, backup *api.Backup, task *api.Task, logger *zap.Logger) error {
-	targetDriver, err := GetDatabaseDriver(targetDatabase.Instance, targetDatabase.Name, logger)
+func createBranchMigrationHistory(ctx context.Context, server *Server, sourceDatabase, targetDatabase *api.Database, backup *api.Backup, task *api.Task) error {
+	targetDriver, err := server.GetDatabaseDriver(targetDatabase.Instance, targetDatabase.Name, api.Admin)
 	if err != nil {
 		return err
 	}
@@ -179,8 +179,9 @@ func createBranchMigrationHistory(ctx context.Context, server *Server, sourceDat
 		Creator:     task.Creator.Name,
 		IssueId:     issueId,
 		Payload:     "",
+		LockTimeout: server.migrationLockTimeout,
 	}
-	if err := targetDriver.ExecuteMigration(ctx, m, ""); err != nil {
+	if err := targetDriver.ExecuteMigration(ctx, m, "", nil); err != nil {
 		return fmt.Errorf("failed to create migration history: %w", err)
 	}
 	return nil
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
 	"github.com/bytebase/bytebase/plugin/db"
 	"go.uber.org/zap"
 )
@@ -45,6 +47,170 @@ func (exec *SchemaUpdateTaskExecutor) RunOnce(ctx context.Context, server *Serve
 		return true, "", fmt.Errorf("invalid database schema update payload: %w", err)
 	}
 
+	mi, err := composeSchemaUpdateMigrationInfo(ctx, server, task, payload)
+	if err != nil {
+		return true, "", err
+	}
+
+	sql := strings.TrimSpace(payload.Statement)
+	// Only baseline can have empty sql statement, which indicates empty database.
+	if mi.Type != db.Baseline && sql == "" {
+		return true, "", fmt.Errorf("empty sql statement")
+	}
+
+	if err := server.ComposeTaskRelationship(ctx, task); err != nil {
+		return true, "", err
+	}
+
+	driver, err := server.GetDatabaseDriver(task.Instance, databaseName, api.RW)
+	if err != nil {
+		return true, "", err
+	}
+	defer driver.Close(context.Background())
+
+	exec.l.Debug("Start sql migration...",
+		zap.String("instance", task.Instance.Name),
+		zap.String("database", databaseName),
+		zap.String("engine", mi.Engine.String()),
+		zap.String("type", mi.Type.String()),
+		zap.String("sql", sql),
+	)
+
+	setup, err := driver.NeedsSetupMigration(ctx)
+	if err != nil {
+		return true, "", fmt.Errorf("failed to check migration setup for instance %q: %w", task.Instance.Name, err)
+	}
+	if setup {
+		return true, "", fmt.Errorf("missing or outdated migration schema for instance %q", task.Instance.Name)
+	}
+
+	if payload.OnlineDDL {
+		capability, err := db.GetCapability(task.Instance.Engine)
+		if err != nil {
+			return true, "", err
+		}
+		if !capability.Supports(db.OperationOnlineDDL) {
+			return true, "", fmt.Errorf("online DDL is not supported by %s instance %q", task.Instance.Engine, task.Instance.Name)
+		}
+	}
+
+	// Execute the statements one by one, so that we know how far the migration goes if it fails halfway.
+	statementList := db.SplitStatement(sql)
+	if payload.ResumeFromStatement < 0 || (payload.ResumeFromStatement > 0 && payload.ResumeFromStatement >= len(statementList)) {
+		return true, "", fmt.Errorf("invalid statement index %d to resume from, the migration has %d statements", payload.ResumeFromStatement, len(statementList))
+	}
+
+	if payload.DryRun {
+		// The statements applied by the previous task run are not checked again.
+		dryRunStatement := sql
+		if payload.ResumeFromStatement > 0 {
+			dryRunStatement = strings.Join(statementList[payload.ResumeFromStatement:], ";\n")
+		}
+		errorList, err := server.dryRunMigration(ctx, driver, task.Database, mi, dryRunStatement)
+		if err != nil {
+			return true, "", fmt.Errorf("failed to dry run migration: %w", err)
+		}
+		if len(errorList) > 0 {
+			return true, "", fmt.Errorf("dry run found %d problem(s), migration is not applied: %s", len(errorList), formatDryRunErrorList(errorList))
+		}
+	}
+
+	var backupTableList []string
+	if payload.BackupAffectedRows {
+		if mi.Type != db.Data {
+			return true, "", fmt.Errorf("only data migration can back up the affected rows")
+		}
+		backupTableList, err = backupAffectedRows(ctx, driver, task.ID, statementList[payload.ResumeFromStatement:])
+		if err != nil {
+			return true, "", fmt.Errorf("failed to back up the affected rows, migration is not applied: %w", err)
+		}
+	}
+
+	// The snapshot is only for troubleshooting and drift detection, failing to take it shouldn't block the migration.
+	schemaPrev, snapshotErr := dumpDatabaseSchema(task.Instance, databaseName)
+	if snapshotErr != nil && common.ErrorCode(snapshotErr) != common.ENOTIMPLEMENTED {
+		exec.l.Warn("Failed to dump schema before migration",
+			zap.String("instance", task.Instance.Name),
+			zap.String("database", databaseName),
+			zap.Error(snapshotErr),
+		)
+	}
+
+	progress := newSchemaUpdateProgress(exec.l, server, task, statementList, payload.ResumeFromStatement)
+	execution := &db.MigrationExecution{
+		StatementList: statementList,
+		StartIndex:    payload.ResumeFromStatement,
+		Progress:      progress.update,
+	}
+	if payload.OnlineDDL {
+		execution.OnlineDDL = &db.OnlineDDLConfig{
+			Progress: progress.copy,
+		}
+	}
+	if err := driver.ExecuteMigration(ctx, mi, sql, execution); err != nil {
+		progress.fail(task.Instance)
+		return true, "", err
+	}
+
+	if err := server.recordMigrationSnapshot(ctx, driver, task, mi, schemaPrev, snapshotErr == nil); err != nil {
+		exec.l.Warn("Failed to record migration snapshot",
+			zap.String("instance", task.Instance.Name),
+			zap.String("database", databaseName),
+			zap.String("version", mi.Version),
+			zap.Error(err),
+		)
+	}
+
+	detail = fmt.Sprintf("Applied migration version %s to database %q", mi.Version, databaseName)
+	if mi.Type == db.Baseline {
+		detail = fmt.Sprintf("Established baseline version %s for database %q", mi.Version, databaseName)
+	} else if mi.Type == db.Rollback {
+		detail = fmt.Sprintf("Applied rollback migration version %s to database %q", mi.Version, databaseName)
+	} else if mi.Type == db.Data {
+		detail = fmt.Sprintf("Applied data migration version %s to database %q", mi.Version, databaseName)
+	}
+	if len(backupTableList) > 0 {
+		detail = fmt.Sprintf("%s, affected rows are backed up to %s", detail, strings.Join(backupTableList, ", "))
+	}
+	if payload.OnlineDDL {
+		detail = fmt.Sprintf("%s with online DDL", detail)
+	}
+	if payload.ResumeFromStatement > 0 {
+		detail = fmt.Sprintf("%s, resumed from statement #%d", detail, payload.ResumeFromStatement+1)
+	}
+
+	return true, detail, nil
+}
+
+// backupAffectedRows copies the rows affected by the UPDATE and DELETE statements into the backup tables in the same
+// database before the data migration changes them, and returns the backup table names.
+func backupAffectedRows(ctx context.Context, driver db.Driver, taskID int, statementList []string) ([]string, error) {
+	// Compose all the queries first, so that we don't leave any backup table behind if a statement is not supported.
+	var queryList []string
+	for _, statement := range statementList {
+		query, err := db.AffectedRowsQuery(statement)
+		if err != nil {
+			return nil, fmt.Errorf("statement %q: %w", statement, err)
+		}
+		if query != "" {
+			queryList = append(queryList, query)
+		}
+	}
+
+	var tableList []string
+	ts := time.Now().Unix()
+	for i, query := range queryList {
+		table := fmt.Sprintf("_bb_backup_%d_%d_%d", taskID, ts, i+1)
+		if err := driver.Execute(ctx, fmt.Sprintf("CREATE TABLE %s AS %s", table, query)); err != nil {
+			return tableList, fmt.Errorf("failed to create backup table %s: %w", table, err)
+		}
+		tableList = append(tableList, table)
+	}
+	return tableList, nil
+}
+
+// composeSchemaUpdateMigrationInfo composes the migration info of the schema update task, the task database must be set.
+func composeSchemaUpdateMigrationInfo(ctx context.Context, server *Server, task *api.Task, payload *api.TaskDatabaseSchemaUpdatePayload) (*db.MigrationInfo, error) {
 	mi := &db.MigrationInfo{
 		Type: db.Sql,
 	}
@@ -54,7 +220,7 @@ func (exec *SchemaUpdateTaskExecutor) RunOnce(ctx context.Context, server *Serve
 		if err != nil {
 			// If somehow we unable to find the principal, we just emit the error since it's not
 			// critical enough to fail the entire operation.
-			exec.l.Error("Failed to fetch creator for composing the migration info",
+			server.l.Error("Failed to fetch creator for composing the migration info",
 				zap.Int("task_id", task.ID),
 				zap.Error(err),
 			)
@@ -62,27 +228,39 @@ func (exec *SchemaUpdateTaskExecutor) RunOnce(ctx context.Context, server *Serve
 			mi.Creator = creator.Name
 		}
 		mi.Version = defaultMigrationVersionFromTaskId(task.ID)
-		mi.Database = databaseName
-		mi.Namespace = databaseName
+		mi.Database = task.Database.Name
+		mi.Namespace = task.Database.Name
 		mi.Description = task.Name
 	} else {
-		mi, err = db.ParseMigrationInfo(payload.VCSPushEvent.FileCommit.Added, payload.VCSPushEvent.BaseDirectory)
+		var err error
+		mi, err = db.ParseMigrationInfo(payload.VCSPushEvent.FileCommit.Added, payload.VCSPushEvent.BaseDirectory, payload.VersionScheme)
 		// This should not happen normally as we already check this when creating the issue. Just in case.
 		if err != nil {
-			return true, "", fmt.Errorf("failed to start schema migration, error: %w", err)
+			return nil, fmt.Errorf("failed to start schema migration, error: %w", err)
 		}
 		mi.Creator = payload.VCSPushEvent.FileCommit.AuthorName
+		// The tenant migration is tracked by each tenant database on its own.
+		if mi.Tenant {
+			mi.Database = task.Database.Name
+			mi.Namespace = task.Database.Name
+		}
 
 		miPayload := &db.MigrationInfoPayload{
 			VCSPushEvent: payload.VCSPushEvent,
 		}
 		bytes, err := json.Marshal(miPayload)
 		if err != nil {
-			return true, "", fmt.Errorf("failed to start schema migration, unable to marshal vcs push event payload %w", err)
+			return nil, fmt.Errorf("failed to start schema migration, unable to marshal vcs push event payload %w", err)
 		}
 		mi.Payload = string(bytes)
 	}
 
+	if payload.MigrationType != "" {
+		mi.Type = payload.MigrationType
+	}
+	mi.RollbackStatement = payload.RollbackStatement
+	mi.LockTimeout = server.migrationLockTimeout
+
 	issueFind := &api.IssueFind{
 		PipelineId: &task.PipelineId,
 	}
@@ -90,7 +268,7 @@ func (exec *SchemaUpdateTaskExecutor) RunOnce(ctx context.Context, server *Serve
 	if err != nil {
 		// If somehow we unable to find the issue, we just emit the error since it's not
 		// critical enough to fail the entire operation.
-		exec.l.Error("Failed to fetch containing issue for composing the migration info",
+		server.l.Error("Failed to fetch containing issue for composing the migration info",
 			zap.Int("task_id", task.ID),
 			zap.Error(err),
 		)
@@ -98,46 +276,145 @@ func (exec *SchemaUpdateTaskExecutor) RunOnce(ctx context.Context, server *Serve
 		mi.IssueId = strconv.Itoa(issue.ID)
 	}
 
-	sql := strings.TrimSpace(payload.Statement)
-	// Only baseline can have empty sql statement, which indicates empty database.
-	if mi.Type != db.Baseline && sql == "" {
-		return true, "", fmt.Errorf("empty sql statement")
-	}
+	return mi, nil
+}
 
-	if err := server.ComposeTaskRelationship(ctx, task); err != nil {
-		return true, "", err
+// dryRunMigration dry runs the migration against the database, using its synced schema to check the referenced tables and columns.
+func (s *Server) dryRunMigration(ctx context.Context, driver db.Driver, database *api.Database, mi *db.MigrationInfo, statement string) ([]*db.DryRunError, error) {
+	var schema *db.DBSchema
+	// Without a successful sync, we can't tell the missing tables from the unknown ones.
+	if database.LastSuccessfulSyncTs > 0 {
+		tableList, err := s.TableService.FindTableList(ctx, &api.TableFind{DatabaseId: &database.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch table list for database %q: %w", database.Name, err)
+		}
+		columnList, err := s.ColumnService.FindColumnList(ctx, &api.ColumnFind{DatabaseId: &database.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch column list for database %q: %w", database.Name, err)
+		}
+
+		columnMap := make(map[int][]db.DBColumn)
+		for _, column := range columnList {
+			columnMap[column.TableId] = append(columnMap[column.TableId], db.DBColumn{
+				Name:     column.Name,
+				Position: column.Position,
+				Type:     column.Type,
+			})
+		}
+		schema = &db.DBSchema{Name: database.Name}
+		for _, table := range tableList {
+			schema.TableList = append(schema.TableList, db.DBTable{
+				Name:       table.Name,
+				ColumnList: columnMap[table.ID],
+			})
+		}
 	}
 
-	driver, err := GetDatabaseDriver(task.Instance, databaseName, exec.l)
-	if err != nil {
-		return true, "", err
+	return driver.DryRunMigration(ctx, mi, statement, schema)
+}
+
+func formatDryRunErrorList(errorList []*db.DryRunError) string {
+	var list []string
+	for _, e := range errorList {
+		list = append(list, e.Error())
 	}
-	defer driver.Close(context.Background())
+	return strings.Join(list, "; ")
+}
 
-	exec.l.Debug("Start sql migration...",
-		zap.String("instance", task.Instance.Name),
-		zap.String("database", databaseName),
-		zap.String("engine", mi.Engine.String()),
-		zap.String("type", mi.Type.String()),
-		zap.String("sql", sql),
-	)
+// The online DDL table copy progress is saved at most once per interval, since it's reported after every chunk.
+const onlineDDLProgressSaveInterval = 5 * time.Second
 
-	setup, err := driver.NeedsSetupMigration(ctx)
-	if err != nil {
-		return true, "", fmt.Errorf("failed to check migration setup for instance %q: %w", task.Instance.Name, err)
+// schemaUpdateProgress records the result of each statement to the running task run of the schema update task.
+type schemaUpdateProgress struct {
+	l       *zap.Logger
+	server  *Server
+	taskRun *api.TaskRun
+	result  *api.TaskRunSchemaUpdateResult
+	// Last time the online DDL table copy progress is saved.
+	copySavedTs time.Time
+}
+
+func newSchemaUpdateProgress(logger *zap.Logger, server *Server, task *api.Task, statementList []string, startIndex int) *schemaUpdateProgress {
+	progress := &schemaUpdateProgress{
+		l:      logger,
+		server: server,
+		result: &api.TaskRunSchemaUpdateResult{StatementList: []*api.TaskRunStatementResult{}},
 	}
-	if setup {
-		return true, "", fmt.Errorf("missing migration schema for instance %q", task.Instance.Name)
+	for _, taskRun := range task.TaskRunList {
+		if taskRun.Status == api.TaskRunRunning {
+			progress.taskRun = taskRun
+			break
+		}
 	}
+	for i, statement := range statementList {
+		status := api.TaskRunStatementPending
+		if i < startIndex {
+			status = api.TaskRunStatementSkipped
+		}
+		progress.result.StatementList = append(progress.result.StatementList, &api.TaskRunStatementResult{
+			Statement: statement,
+			Status:    status,
+		})
+	}
+	progress.save()
+	return progress
+}
 
-	if err := driver.ExecuteMigration(ctx, mi, sql); err != nil {
-		return true, "", err
+// update is called after executing each statement.
+func (p *schemaUpdateProgress) update(index int, duration time.Duration, err error) {
+	statement := p.result.StatementList[index]
+	statement.Duration = duration.Milliseconds()
+	if err != nil {
+		statement.Status = api.TaskRunStatementFailed
+		statement.Error = err.Error()
+	} else {
+		statement.Status = api.TaskRunStatementDone
 	}
+	p.save()
+}
 
-	detail = fmt.Sprintf("Applied migration version %s to database %q", mi.Version, databaseName)
-	if mi.Type == db.Baseline {
-		detail = fmt.Sprintf("Established baseline version %s for database %q", mi.Version, databaseName)
+// copy is called after copying each chunk of the ALTER TABLE statement applied by online DDL.
+func (p *schemaUpdateProgress) copy(index int, copiedRows int64, estimatedRows int64) {
+	p.result.StatementList[index].OnlineDDL = &api.TaskRunOnlineDDLProgress{
+		CopiedRows:    copiedRows,
+		EstimatedRows: estimatedRows,
+	}
+	if time.Since(p.copySavedTs) < onlineDDLProgressSaveInterval {
+		return
 	}
+	p.copySavedTs = time.Now()
+	p.save()
+}
 
-	return true, detail, nil
+// fail is called after the migration fails. If the database supports transactional DDL, the applied statements
+// are rolled back together with the failed one.
+func (p *schemaUpdateProgress) fail(instance *api.Instance) {
+	capability, err := db.GetCapability(instance.Engine)
+	if err != nil || !capability.Supports(db.OperationTransactionalDDL) {
+		return
+	}
+	for _, statement := range p.result.StatementList {
+		if statement.Status == api.TaskRunStatementDone {
+			statement.Status = api.TaskRunStatementRolledBack
+		}
+	}
+	p.save()
+}
+
+// save stores the result to the task run. It's only for reporting, so failing to save doesn't fail the migration.
+func (p *schemaUpdateProgress) save() {
+	if p.taskRun == nil {
+		return
+	}
+	bytes, err := json.Marshal(p.result)
+	if err != nil {
+		p.l.Warn("Failed to marshal schema update result", zap.Int("task_run_id", p.taskRun.ID), zap.Error(err))
+		return
+	}
+	if _, err := p.server.TaskService.PatchTaskRunResult(context.Background(), &api.TaskRunResultPatch{
+		ID:     p.taskRun.ID,
+		Result: string(bytes),
+	}); err != nil {
+		p.l.Warn("Failed to save schema update result", zap.Int("task_run_id", p.taskRun.ID), zap.Error(err))
+	}
 }
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bytebase/bytebase/api"
+	"go.uber.org/zap"
+)
+
+// runSchemaUpdateTask creates the schema update issue on the database by the DBA 102, and runs the task to the end.
+func runSchemaUpdateTask(t *testing.T, s *Server, database *api.Database, statement string) *api.Task {
+	ctx := context.Background()
+	issue, err := s.CreateIssue(ctx, &api.IssueCreate{
+		ProjectId:  database.ProjectId,
+		Name:       "Update schema",
+		Type:       api.IssueDatabaseSchemaUpdate,
+		AssigneeId: 102,
+		Pipeline: api.PipelineCreate{
+			Name: "Update schema",
+			StageList: []api.StageCreate{
+				{
+					Name:          "Dev",
+					EnvironmentId: database.Instance.EnvironmentId,
+					TaskList: []api.TaskCreate{
+						{
+							Name:       "Update schema",
+							Type:       api.TaskDatabaseSchemaUpdate,
+							Status:     api.TaskPending,
+							InstanceId: database.InstanceId,
+							DatabaseId: &database.ID,
+							Statement:  statement,
+						},
+					},
+				},
+			},
+		},
+	}, 102)
+	if err != nil {
+		t.Fatalf("failed to create schema update issue: %v", err)
+	}
+	task, err := s.ComposeTaskById(ctx, issue.Pipeline.StageList[0].TaskList[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != api.TaskRunning {
+		t.Fatalf("got task status %s after creating the issue, want %s", task.Status, api.TaskRunning)
+	}
+
+	terminated, _, err := NewSchemaUpdateTaskExecutor(zap.NewNop()).RunOnce(ctx, s, task)
+	if err != nil {
+		t.Fatalf("failed to run the schema update task: %v", err)
+	}
+	if !terminated {
+		t.Fatalf("got the schema update task not terminated")
+	}
+	return task
+}
+
+func TestSchemaUpdateTaskExecutorSnapshot(t *testing.T) {
+	s, _ := newTestServer(t)
+	ctx := context.Background()
+	database := newTestSQLiteDatabase(t, s)
+
+	runSchemaUpdateTask(t, s, database, "CREATE TABLE book (id INTEGER PRIMARY KEY, name TEXT);")
+
+	snapshotList, err := s.MigrationSnapshotService.FindMigrationSnapshotList(ctx, &api.MigrationSnapshotFind{
+		InstanceId:   database.InstanceId,
+		DatabaseName: &database.Name,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshotList) != 1 {
+		t.Fatalf("got %d migration snapshots, want 1", len(snapshotList))
+	}
+	snapshot := snapshotList[0]
+	// SQLite can't be dumped, which leaves out the dumps without failing the migration.
+	if snapshot.SchemaPrev != "" || snapshot.Schema != "" {
+		t.Errorf("got schema dump %q before and %q after the migration, want empty", snapshot.SchemaPrev, snapshot.Schema)
+	}
+	// The synced schema is still recorded as the expected schema.
+	if !strings.Contains(snapshot.SyncedSchema, "TABLE book ") {
+		t.Errorf("got synced schema %q, want table book", snapshot.SyncedSchema)
+	}
+}
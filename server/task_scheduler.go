@@ -3,9 +3,11 @@ package server
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/plugin/db"
 	"go.uber.org/zap"
 )
 
@@ -15,9 +17,10 @@ const (
 
 func NewTaskScheduler(logger *zap.Logger, server *Server) *TaskScheduler {
 	return &TaskScheduler{
-		l:         logger,
-		executors: make(map[string]TaskExecutor),
-		server:    server,
+		l:            logger,
+		executors:    make(map[string]TaskExecutor),
+		server:       server,
+		runningTasks: make(map[int]context.CancelFunc),
 	}
 }
 
@@ -26,6 +29,10 @@ type TaskScheduler struct {
 	executors map[string]TaskExecutor
 
 	server *Server
+
+	runningTasksMu sync.Mutex
+	// Task ID -> the function canceling the context of the running executor, see CancelTask.
+	runningTasks map[int]context.CancelFunc
 }
 
 func (s *TaskScheduler) Run() error {
@@ -121,7 +128,27 @@ func (s *TaskScheduler) Run() error {
 						continue
 					}
 
-					done, detail, err := executor.RunOnce(context.Background(), s.server, task)
+					var done bool
+					var detail string
+					if err = checkTaskCapability(task.Instance, task.Type); err != nil {
+						// Fail the task instead of letting the executor run halfway.
+						done = true
+					} else {
+						ctx := s.startTask(task.ID)
+						// The statements are limited by the timeout of the environment.
+						ctx = db.WithStatementTimeout(ctx, time.Duration(task.Instance.Environment.StatementTimeout)*time.Second)
+						done, detail, err = executor.RunOnce(ctx, s.server, task)
+						if canceled := s.finishTask(task.ID, ctx); canceled {
+							// The task status has been changed to CANCELED.
+							s.l.Info("Stopped running canceled task",
+								zap.Int("id", task.ID),
+								zap.String("name", task.Name),
+								zap.String("type", string(task.Type)),
+								zap.Error(err),
+							)
+							continue
+						}
+					}
 					if done {
 						if err != nil {
 							s.l.Debug("Failed to run task",
@@ -172,6 +199,37 @@ func (s *TaskScheduler) Run() error {
 	return nil
 }
 
+// startTask returns the context of running the task, which is canceled once the task is canceled.
+func (s *TaskScheduler) startTask(taskId int) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.runningTasksMu.Lock()
+	s.runningTasks[taskId] = cancel
+	s.runningTasksMu.Unlock()
+	return ctx
+}
+
+// finishTask releases the context of running the task, and returns true if the task has been canceled meanwhile.
+func (s *TaskScheduler) finishTask(taskId int, ctx context.Context) bool {
+	s.runningTasksMu.Lock()
+	cancel := s.runningTasks[taskId]
+	delete(s.runningTasks, taskId)
+	s.runningTasksMu.Unlock()
+
+	canceled := ctx.Err() != nil
+	cancel()
+	return canceled
+}
+
+// CancelTask cancels the context of the running task, which stops the executor and cancels the statement running
+// on the database. It's a no-op if the task is not running.
+func (s *TaskScheduler) CancelTask(taskId int) {
+	s.runningTasksMu.Lock()
+	defer s.runningTasksMu.Unlock()
+	if cancel, ok := s.runningTasks[taskId]; ok {
+		cancel()
+	}
+}
+
 func (s *TaskScheduler) Register(taskType string, executor TaskExecutor) {
 	if executor == nil {
 		panic("scheduler: Register executor is nil for task type: " + taskType)
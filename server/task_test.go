@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRetrySchemaUpdateTask(t *testing.T) {
+	for _, status := range []api.TaskStatus{api.TaskFailed, api.TaskCanceled} {
+		t.Run(string(status), func(t *testing.T) {
+			s, _ := newTestServer(t)
+			ctx := context.Background()
+			databaseId := 7002
+			issue, err := s.CreateIssue(ctx, &api.IssueCreate{
+				ProjectId:  3001,
+				Name:       "Create tables",
+				Type:       api.IssueDatabaseSchemaUpdate,
+				AssigneeId: 102,
+				Pipeline: api.PipelineCreate{
+					Name: "Create tables",
+					StageList: []api.StageCreate{
+						{
+							Name:          "Dev",
+							EnvironmentId: 5001,
+							TaskList: []api.TaskCreate{
+								{
+									Name:       "Create tables",
+									Type:       api.TaskDatabaseSchemaUpdate,
+									Status:     api.TaskPendingApproval,
+									InstanceId: 6001,
+									DatabaseId: &databaseId,
+									Statement:  "CREATE TABLE t1 (id INT);\nCREATE TABLE t2 (id INT);\nCREATE TABLE t3 (id INT);",
+								},
+							},
+						},
+					},
+				},
+			}, 102)
+			if err != nil {
+				t.Fatalf("failed to create schema update issue: %v", err)
+			}
+			task := issue.Pipeline.StageList[0].TaskList[0]
+			// Approving the task schedules it to run, then stop it halfway.
+			for _, newStatus := range []api.TaskStatus{api.TaskPending, status} {
+				if _, err := s.ChangeTaskStatus(ctx, task, newStatus, 102); err != nil {
+					t.Fatalf("failed to change task status to %s: %v", newStatus, err)
+				}
+				if task, err = s.TaskService.FindTask(ctx, &api.TaskFind{ID: &task.ID}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if task.Status != status {
+				t.Fatalf("got task status %s, want %s", task.Status, status)
+			}
+
+			e := echo.New()
+			g := e.Group("/api")
+			g.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					c.Set(GetPrincipalIdContextKey(), 102)
+					return next(c)
+				}
+			})
+			s.registerTaskRoutes(g)
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/pipeline/%d/task/%d/status", issue.PipelineId, task.ID),
+				strings.NewReader(`{"data":{"type":"taskStatusPatch","attributes":{"status":"RUNNING","resumeFromStatement":1}}}`))
+			e.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("got status %d retrying the %s task, want %d: %s", rec.Code, status, http.StatusOK, rec.Body.String())
+			}
+
+			updatedTask, err := s.TaskService.FindTask(ctx, &api.TaskFind{ID: &task.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updatedTask.Status != api.TaskRunning {
+				t.Errorf("got task status %s after retrying, want %s", updatedTask.Status, api.TaskRunning)
+			}
+			payload := &api.TaskDatabaseSchemaUpdatePayload{}
+			if err := json.Unmarshal([]byte(updatedTask.Payload), payload); err != nil {
+				t.Fatal(err)
+			}
+			if payload.ResumeFromStatement != 1 {
+				t.Errorf("got the %s task resumed from statement %d, want 1", status, payload.ResumeFromStatement)
+			}
+		})
+	}
+}
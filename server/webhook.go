@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -70,7 +71,7 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 		for _, commit := range pushEvent.CommitList {
 			for _, added := range commit.AddedList {
 				if strings.HasPrefix(added, repository.BaseDirectory) && filepath.Ext(added) == ".sql" {
-					mi, err := db.ParseMigrationInfo(added, repository.BaseDirectory)
+					mi, err := db.ParseMigrationInfo(added, repository.BaseDirectory, repository.VersionScheme)
 					if err != nil {
 						s.l.Warn("Invalid migration filename. Skip", zap.String("file", added), zap.Error(err))
 						continue
@@ -97,16 +98,29 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 					// Find matching database list
 					databaseFind := &api.DatabaseFind{
 						ProjectId: &repository.ProjectId,
-						Name:      &mi.Database,
+					}
+					if !mi.Tenant {
+						databaseFind.Name = &mi.Database
+					} else if repository.Project.TenantDatabasePattern == "" {
+						s.l.Warn("Project has no tenant database pattern for the tenant migration. Skip",
+							zap.Int("project_id", repository.ProjectId),
+							zap.String("file", added),
+						)
+						continue
 					}
 					databaseList, err := s.ComposeDatabaseListByFind(context.Background(), databaseFind)
 					if err != nil {
 						s.l.Warn("Failed to find database matching added repository file. Skip", zap.String("file", added), zap.Error(err))
 						continue
-					} else if len(databaseList) == 0 {
+					}
+					if mi.Tenant {
+						databaseList = filterTenantDatabaseList(databaseList, repository.Project.TenantDatabasePattern)
+					}
+					if len(databaseList) == 0 {
 						s.l.Warn("Project does not own this database. Skip",
 							zap.Int("project_id", repository.ProjectId),
 							zap.String("database_name", mi.Database),
+							zap.String("tenant_database_pattern", repository.Project.TenantDatabasePattern),
 							zap.String("file", added),
 						)
 						continue
@@ -122,6 +136,9 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 					//
 					// Pattern 3:  	The database name is different among different environments. In such case, the database name alone is enough
 					//             	to identify ambiguity.
+					//
+					// Besides, the tenant migration file like "v1__@tenant" applies to every tenant database of the project in the environment,
+					// each of which gets its own task and migration history.
 
 					// Further filter by environment name if applicable.
 					filterdDatabaseList := []*api.Database{}
@@ -144,7 +161,7 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 						filterdDatabaseList = databaseList
 					}
 
-					{
+					if !mi.Tenant {
 						// It could happen that for a particular environment a project contain 2 database with the same name.
 						// We will emit warning in this case.
 						var databaseListByEnv = map[int][]*api.Database{}
@@ -200,21 +217,31 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 					}
 
 					stageList := []api.StageCreate{}
+					// Environment ID -> index of its stage, the tenant databases in the same environment share the stage.
+					stageIndexMap := make(map[int]int)
 					for _, database := range filterdDatabaseList {
 						databaseID := database.ID
-						taskStatus := api.TaskPendingApproval
-						if database.Instance.Environment.ApprovalPolicy == api.ManualApprovalNever {
-							taskStatus = api.TaskPending
-						}
 						task := &api.TaskCreate{
-							InstanceId:   database.InstanceId,
-							DatabaseId:   &databaseID,
-							Name:         mi.Description,
-							Status:       taskStatus,
-							Type:         api.TaskDatabaseSchemaUpdate,
-							Statement:    string(b),
-							VCSPushEvent: &vcsPushEvent,
+							InstanceId:    database.InstanceId,
+							DatabaseId:    &databaseID,
+							Name:          mi.Description,
+							Status:        migrationTaskStatus(database.Instance.Environment, mi.Type),
+							Type:          api.TaskDatabaseSchemaUpdate,
+							Statement:     string(b),
+							VCSPushEvent:  &vcsPushEvent,
+							VersionScheme: repository.VersionScheme,
+						}
+						if mi.Type == db.Data {
+							task.MigrationType = db.Data
+						}
+						if mi.Tenant {
+							task.Name = fmt.Sprintf("%s for %s", mi.Description, database.Name)
+						}
+						if i, ok := stageIndexMap[database.Instance.EnvironmentId]; ok {
+							stageList[i].TaskList = append(stageList[i].TaskList, *task)
+							continue
 						}
+						stageIndexMap[database.Instance.EnvironmentId] = len(stageList)
 						stageList = append(stageList, api.StageCreate{
 							EnvironmentId: database.Instance.EnvironmentId,
 							TaskList:      []api.TaskCreate{*task},
@@ -248,3 +275,15 @@ func (s *Server) registerWebhookRoutes(g *echo.Group) {
 		return c.String(http.StatusOK, strings.Join(createdMessageList, "\n"))
 	})
 }
+
+// filterTenantDatabaseList returns the databases matching the tenant database pattern of the project.
+func filterTenantDatabaseList(databaseList []*api.Database, pattern string) []*api.Database {
+	var list []*api.Database
+	for _, database := range databaseList {
+		// The pattern is validated when patching the project.
+		if matched, _ := path.Match(pattern, database.Name); matched {
+			list = append(list, database)
+		}
+	}
+	return list
+}
@@ -108,6 +108,26 @@ func (s *DataSourceService) PatchDataSource(ctx context.Context, patch *api.Data
 	return dataSource, nil
 }
 
+// DeleteDataSource deletes an existing dataSource by ID.
+// Returns ENOTFOUND if dataSource does not exist.
+func (s *DataSourceService) DeleteDataSource(ctx context.Context, delete *api.DataSourceDelete) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteDataSource(ctx, tx, delete); err != nil {
+		return FormatError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return FormatError(err)
+	}
+
+	return nil
+}
+
 // createDataSource creates a new dataSource.
 func (s *DataSourceService) createDataSource(ctx context.Context, tx *sql.Tx, create *api.DataSourceCreate) (*api.DataSource, error) {
 	// Insert row into dataSource.
@@ -120,10 +140,11 @@ func (s *DataSourceService) createDataSource(ctx context.Context, tx *sql.Tx, cr
 			name,
 			type,
 			username,
-			password
+			password,
+			expire_ts
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, instance_id, database_id, name, type, username, password
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, instance_id, database_id, name, type, username, password, expire_ts
 	`,
 		create.CreatorId,
 		create.CreatorId,
@@ -133,6 +154,7 @@ func (s *DataSourceService) createDataSource(ctx context.Context, tx *sql.Tx, cr
 		create.Type,
 		create.Username,
 		create.Password,
+		create.ExpireTs,
 	)
 
 	if err != nil {
@@ -154,6 +176,7 @@ func (s *DataSourceService) createDataSource(ctx context.Context, tx *sql.Tx, cr
 		&dataSource.Type,
 		&dataSource.Username,
 		&dataSource.Password,
+		&dataSource.ExpireTs,
 	); err != nil {
 		return nil, FormatError(err)
 	}
@@ -164,6 +187,9 @@ func (s *DataSourceService) createDataSource(ctx context.Context, tx *sql.Tx, cr
 func (s *DataSourceService) findDataSourceList(ctx context.Context, tx *Tx, find *api.DataSourceFind) (_ []*api.DataSource, err error) {
 	// Build WHERE clause.
 	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := find.CreatorId; v != nil {
+		where, args = append(where, "creator_id = ?"), append(args, *v)
+	}
 	if v := find.InstanceId; v != nil {
 		where, args = append(where, "instance_id = ?"), append(args, *v)
 	}
@@ -173,6 +199,9 @@ func (s *DataSourceService) findDataSourceList(ctx context.Context, tx *Tx, find
 	if v := find.Type; v != nil {
 		where, args = append(where, "`type` = ?"), append(args, api.DataSourceType(*v))
 	}
+	if v := find.ExpireTsBefore; v != nil {
+		where, args = append(where, "expire_ts > 0 AND expire_ts <= ?"), append(args, *v)
+	}
 
 	rows, err := tx.QueryContext(ctx, `
 		SELECT 
@@ -186,7 +215,8 @@ func (s *DataSourceService) findDataSourceList(ctx context.Context, tx *Tx, find
 		    name,
 		    type,
 			username,
-			password
+			password,
+			expire_ts
 		FROM data_source
 		WHERE `+strings.Join(where, " AND "),
 		args...,
@@ -212,6 +242,7 @@ func (s *DataSourceService) findDataSourceList(ctx context.Context, tx *Tx, find
 			&dataSource.Type,
 			&dataSource.Username,
 			&dataSource.Password,
+			&dataSource.ExpireTs,
 		); err != nil {
 			return nil, FormatError(err)
 		}
@@ -243,7 +274,7 @@ func (s *DataSourceService) patchDataSource(ctx context.Context, tx *Tx, patch *
 		UPDATE data_source
 		SET `+strings.Join(set, ", ")+`
 		WHERE id = ?
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, instance_id, database_id, name, type, username, password
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, instance_id, database_id, name, type, username, password, expire_ts
 	`,
 		args...,
 	)
@@ -266,6 +297,7 @@ func (s *DataSourceService) patchDataSource(ctx context.Context, tx *Tx, patch *
 			&dataSource.Type,
 			&dataSource.Username,
 			&dataSource.Password,
+			&dataSource.ExpireTs,
 		); err != nil {
 			return nil, FormatError(err)
 		}
@@ -274,3 +306,19 @@ func (s *DataSourceService) patchDataSource(ctx context.Context, tx *Tx, patch *
 
 	return nil, &common.Error{Code: common.ENOTFOUND, Message: fmt.Sprintf("dataSource ID not found: %d", patch.ID)}
 }
+
+// deleteDataSource permanently deletes a dataSource by ID.
+func deleteDataSource(ctx context.Context, tx *Tx, delete *api.DataSourceDelete) error {
+	// Remove row from database.
+	result, err := tx.ExecContext(ctx, `DELETE FROM data_source WHERE id = ?`, delete.ID)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return &common.Error{Code: common.ENOTFOUND, Message: fmt.Sprintf("dataSource ID not found: %d", delete.ID)}
+	}
+
+	return nil
+}
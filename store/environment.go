@@ -160,6 +160,11 @@ func (s *EnvironmentService) createEnvironment(ctx context.Context, tx *Tx, crea
 		return nil, FormatError(err1)
 	}
 
+	dataApprovalPolicy := create.DataApprovalPolicy
+	if dataApprovalPolicy == "" {
+		dataApprovalPolicy = api.ManualApprovalAlways
+	}
+
 	// Insert row into database.
 	row2, err2 := tx.QueryContext(ctx, `
 		INSERT INTO environment (
@@ -167,16 +172,20 @@ func (s *EnvironmentService) createEnvironment(ctx context.Context, tx *Tx, crea
 			updater_id,
 			name,
 			`+"`order`"+`,
-			approval_policy
+			approval_policy,
+			data_approval_policy,
+			statement_timeout
 		)
-		VALUES (?, ?, ?, ?, ?)
-		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, `+"`order`, approval_policy"+`
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, `+"`order`, approval_policy, data_approval_policy, statement_timeout"+`
 	`,
 		create.CreatorId,
 		create.CreatorId,
 		create.Name,
 		order+1,
 		create.ApprovalPolicy,
+		dataApprovalPolicy,
+		create.StatementTimeout,
 	)
 
 	if err2 != nil {
@@ -196,6 +205,8 @@ func (s *EnvironmentService) createEnvironment(ctx context.Context, tx *Tx, crea
 		&environment.Name,
 		&environment.Order,
 		&environment.ApprovalPolicy,
+		&environment.DataApprovalPolicy,
+		&environment.StatementTimeout,
 	); err != nil {
 		return nil, FormatError(err)
 	}
@@ -223,7 +234,9 @@ func (s *EnvironmentService) findEnvironmentList(ctx context.Context, tx *Tx, fi
 		    updated_ts,
 		    name,
 		    `+"`order`"+`,
-			approval_policy
+			approval_policy,
+			data_approval_policy,
+			statement_timeout
 		FROM environment
 		WHERE `+strings.Join(where, " AND "),
 		args...,
@@ -247,6 +260,8 @@ func (s *EnvironmentService) findEnvironmentList(ctx context.Context, tx *Tx, fi
 			&environment.Name,
 			&environment.Order,
 			&environment.ApprovalPolicy,
+			&environment.DataApprovalPolicy,
+			&environment.StatementTimeout,
 		); err != nil {
 			return nil, FormatError(err)
 		}
@@ -276,6 +291,12 @@ func (s *EnvironmentService) patchEnvironment(ctx context.Context, tx *Tx, patch
 	if v := patch.ApprovalPolicy; v != nil {
 		set, args = append(set, "approval_policy = ?"), append(args, *v)
 	}
+	if v := patch.DataApprovalPolicy; v != nil {
+		set, args = append(set, "data_approval_policy = ?"), append(args, *v)
+	}
+	if v := patch.StatementTimeout; v != nil {
+		set, args = append(set, "statement_timeout = ?"), append(args, *v)
+	}
 
 	args = append(args, patch.ID)
 
@@ -284,7 +305,7 @@ func (s *EnvironmentService) patchEnvironment(ctx context.Context, tx *Tx, patch
 		UPDATE environment
 		SET `+strings.Join(set, ", ")+`
 		WHERE id = ?
-		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, `+"`order`, approval_policy"+`
+		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, `+"`order`, approval_policy, data_approval_policy, statement_timeout"+`
 	`,
 		args...,
 	)
@@ -305,6 +326,8 @@ func (s *EnvironmentService) patchEnvironment(ctx context.Context, tx *Tx, patch
 			&environment.Name,
 			&environment.Order,
 			&environment.ApprovalPolicy,
+			&environment.DataApprovalPolicy,
+			&environment.StatementTimeout,
 		); err != nil {
 			return nil, FormatError(err)
 		}
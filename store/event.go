@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"go.uber.org/zap"
+)
+
+var (
+	_ api.EventService = (*EventService)(nil)
+)
+
+// EventService represents a service for managing event.
+type EventService struct {
+	l  *zap.Logger
+	db *DB
+}
+
+// NewEventService returns a new instance of EventService.
+func NewEventService(logger *zap.Logger, db *DB) *EventService {
+	return &EventService{l: logger, db: db}
+}
+
+// UpsertEvent would update the existing event if name matches.
+func (s *EventService) UpsertEvent(ctx context.Context, upsert *api.EventUpsert) (*api.Event, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	event, err := upsertEvent(ctx, tx, upsert)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return event, nil
+}
+
+// FindEventList retrieves a list of events based on find.
+func (s *EventService) FindEventList(ctx context.Context, find *api.EventFind) ([]*api.Event, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	list, err := findEventList(ctx, tx, find)
+	if err != nil {
+		return []*api.Event{}, err
+	}
+
+	return list, nil
+}
+
+// DeleteEvent deletes an existing event by ID.
+// Returns ENOTFOUND if event does not exist.
+func (s *EventService) DeleteEvent(ctx context.Context, delete *api.EventDelete) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer tx.Rollback()
+
+	err = deleteEvent(ctx, tx, delete)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return FormatError(err)
+	}
+
+	return nil
+}
+
+// upsertEvent upserts a new event.
+func upsertEvent(ctx context.Context, tx *Tx, upsert *api.EventUpsert) (*api.Event, error) {
+	// Upsert row into database.
+	row, err := tx.QueryContext(ctx, `
+		INSERT INTO event (
+			creator_id,
+			updater_id,
+			database_id,
+			name,
+			definition,
+			schedule,
+			status,
+			comment
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (database_id, name) DO UPDATE SET
+			updater_id = excluded.updater_id,
+			definition = excluded.definition,
+			schedule = excluded.schedule,
+			status = excluded.status,
+			comment = excluded.comment
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, database_id, name, definition, schedule, status, comment
+	`,
+		upsert.CreatorId,
+		upsert.CreatorId,
+		upsert.DatabaseId,
+		upsert.Name,
+		upsert.Definition,
+		upsert.Schedule,
+		upsert.Status,
+		upsert.Comment,
+	)
+
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer row.Close()
+
+	row.Next()
+	var event api.Event
+	if err := row.Scan(
+		&event.ID,
+		&event.CreatorId,
+		&event.CreatedTs,
+		&event.UpdaterId,
+		&event.UpdatedTs,
+		&event.DatabaseId,
+		&event.Name,
+		&event.Definition,
+		&event.Schedule,
+		&event.Status,
+		&event.Comment,
+	); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return &event, nil
+}
+
+func findEventList(ctx context.Context, tx *Tx, find *api.EventFind) (_ []*api.Event, err error) {
+	// Build WHERE clause.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	where, args = append(where, "database_id = ?"), append(args, find.DatabaseId)
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			creator_id,
+			created_ts,
+			updater_id,
+			updated_ts,
+			database_id,
+			name,
+			definition,
+			schedule,
+			status,
+			comment
+		FROM event
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY name ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over result set and deserialize rows into list.
+	list := make([]*api.Event, 0)
+	for rows.Next() {
+		var event api.Event
+		if err := rows.Scan(
+			&event.ID,
+			&event.CreatorId,
+			&event.CreatedTs,
+			&event.UpdaterId,
+			&event.UpdatedTs,
+			&event.DatabaseId,
+			&event.Name,
+			&event.Definition,
+			&event.Schedule,
+			&event.Status,
+			&event.Comment,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+
+		list = append(list, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return list, nil
+}
+
+// deleteEvent permanently deletes a event by ID.
+func deleteEvent(ctx context.Context, tx *Tx, delete *api.EventDelete) error {
+	// Remove row from database.
+	result, err := tx.ExecContext(ctx, `DELETE FROM event WHERE id = ?`, delete.ID)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return &common.Error{Code: common.ENOTFOUND, Message: fmt.Sprintf("event ID not found: %d", delete.ID)}
+	}
+
+	return nil
+}
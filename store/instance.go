@@ -186,7 +186,7 @@ func createInstance(ctx context.Context, tx *Tx, create *api.InstanceCreate) (*a
 			port
 		)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, environment_id, name, engine, external_link, host, port
+		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, environment_id, name, engine, flavor, engine_version, external_link, host, port
 	`,
 		create.CreatorId,
 		create.CreatorId,
@@ -215,6 +215,8 @@ func createInstance(ctx context.Context, tx *Tx, create *api.InstanceCreate) (*a
 		&instance.EnvironmentId,
 		&instance.Name,
 		&instance.Engine,
+		&instance.Flavor,
+		&instance.EngineVersion,
 		&instance.ExternalLink,
 		&instance.Host,
 		&instance.Port,
@@ -246,6 +248,8 @@ func findInstanceList(ctx context.Context, tx *Tx, find *api.InstanceFind) (_ []
 			environment_id,
 			name,
 			engine,
+			flavor,
+			engine_version,
 			external_link,
 			host,
 			port
@@ -272,6 +276,8 @@ func findInstanceList(ctx context.Context, tx *Tx, find *api.InstanceFind) (_ []
 			&instance.EnvironmentId,
 			&instance.Name,
 			&instance.Engine,
+			&instance.Flavor,
+			&instance.EngineVersion,
 			&instance.ExternalLink,
 			&instance.Host,
 			&instance.Port,
@@ -307,6 +313,12 @@ func patchInstance(ctx context.Context, tx *Tx, patch *api.InstancePatch) (*api.
 	if v := patch.Port; v != nil {
 		set, args = append(set, "port = ?"), append(args, *v)
 	}
+	if v := patch.Flavor; v != nil {
+		set, args = append(set, "flavor = ?"), append(args, *v)
+	}
+	if v := patch.EngineVersion; v != nil {
+		set, args = append(set, "engine_version = ?"), append(args, *v)
+	}
 
 	args = append(args, patch.ID)
 
@@ -315,7 +327,7 @@ func patchInstance(ctx context.Context, tx *Tx, patch *api.InstancePatch) (*api.
 		UPDATE instance
 		SET `+strings.Join(set, ", ")+`
 		WHERE id = ?
-		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, environment_id, name, engine, external_link, host, port
+		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, environment_id, name, engine, flavor, engine_version, external_link, host, port
 	`,
 		args...,
 	)
@@ -336,6 +348,8 @@ func patchInstance(ctx context.Context, tx *Tx, patch *api.InstancePatch) (*api.
 			&instance.EnvironmentId,
 			&instance.Name,
 			&instance.Engine,
+			&instance.Flavor,
+			&instance.EngineVersion,
 			&instance.ExternalLink,
 			&instance.Host,
 			&instance.Port,
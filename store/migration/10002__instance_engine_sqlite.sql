@@ -0,0 +1,84 @@
+PRAGMA user_version = 10002;
+
+-- Add SQLITE to the allowed instance engines.
+-- SQLite doesn't support altering CHECK constraint, so we recreate the table following
+-- https://www.sqlite.org/lang_altertable.html#otheralter
+-- Foreign key enforcement is disabled by the migration runner while applying this file.
+CREATE TABLE instance_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    row_status TEXT NOT NULL CHECK (
+        row_status IN ('NORMAL', 'ARCHIVED')
+    ) DEFAULT 'NORMAL',
+    creator_id INTEGER NOT NULL REFERENCES principal (id),
+    created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    updater_id INTEGER NOT NULL REFERENCES principal (id),
+    updated_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    environment_id INTEGER NOT NULL REFERENCES environment (id),
+    name TEXT NOT NULL,
+    `engine` TEXT NOT NULL CHECK (`engine` IN ('MYSQL', 'POSTGRES', 'SQLITE')),
+    host TEXT NOT NULL,
+    port TEXT NOT NULL,
+    external_link TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO
+    instance_new (
+        id,
+        row_status,
+        creator_id,
+        created_ts,
+        updater_id,
+        updated_ts,
+        environment_id,
+        name,
+        `engine`,
+        host,
+        port,
+        external_link
+    )
+SELECT
+    id,
+    row_status,
+    creator_id,
+    created_ts,
+    updater_id,
+    updated_ts,
+    environment_id,
+    name,
+    `engine`,
+    host,
+    port,
+    external_link
+FROM
+    instance;
+
+-- Carry over the AUTOINCREMENT counter, otherwise it's reset to the max copied id.
+DELETE FROM
+    sqlite_sequence
+WHERE
+    name = 'instance_new';
+
+UPDATE
+    sqlite_sequence
+SET
+    name = 'instance_new'
+WHERE
+    name = 'instance';
+
+DROP TABLE instance;
+
+ALTER TABLE
+    instance_new RENAME TO instance;
+
+CREATE TRIGGER IF NOT EXISTS `trigger_update_instance_modification_time`
+AFTER
+UPDATE
+    ON `instance` FOR EACH ROW BEGIN
+UPDATE
+    `instance`
+SET
+    updated_ts = (strftime('%s', 'now'))
+WHERE
+    rowid = old.rowid;
+
+END;
@@ -0,0 +1,13 @@
+PRAGMA user_version = 10003;
+
+-- Record the detected server flavor (e.g. TiDB for a MYSQL engine instance) and version.
+-- Both are populated by the schema syncer.
+ALTER TABLE
+    instance
+ADD
+    COLUMN flavor TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE
+    instance
+ADD
+    COLUMN engine_version TEXT NOT NULL DEFAULT '';
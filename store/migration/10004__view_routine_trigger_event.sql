@@ -0,0 +1,146 @@
+PRAGMA user_version = 10004;
+
+-- vw stores the views of a particular database, data is synced periodically from the instance
+CREATE TABLE vw (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    row_status TEXT NOT NULL CHECK (
+        row_status IN ('NORMAL', 'ARCHIVED')
+    ) DEFAULT 'NORMAL',
+    creator_id INTEGER NOT NULL REFERENCES principal (id),
+    created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    updater_id INTEGER NOT NULL REFERENCES principal (id),
+    updated_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    database_id INTEGER NOT NULL REFERENCES db (id),
+    name TEXT NOT NULL,
+    definition TEXT NOT NULL,
+    comment TEXT NOT NULL,
+    UNIQUE(database_id, name)
+);
+
+INSERT INTO
+    sqlite_sequence (name, seq)
+VALUES
+    ('vw', 100);
+
+CREATE TRIGGER IF NOT EXISTS `trigger_update_vw_modification_time`
+AFTER
+UPDATE
+    ON `vw` FOR EACH ROW BEGIN
+UPDATE
+    `vw`
+SET
+    updated_ts = (strftime('%s', 'now'))
+WHERE
+    rowid = old.rowid;
+
+END;
+
+-- routine stores the stored procedures and functions of a particular database, data is synced periodically from the instance
+CREATE TABLE routine (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    row_status TEXT NOT NULL CHECK (
+        row_status IN ('NORMAL', 'ARCHIVED')
+    ) DEFAULT 'NORMAL',
+    creator_id INTEGER NOT NULL REFERENCES principal (id),
+    created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    updater_id INTEGER NOT NULL REFERENCES principal (id),
+    updated_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    database_id INTEGER NOT NULL REFERENCES db (id),
+    name TEXT NOT NULL,
+    `type` TEXT NOT NULL CHECK (`type` IN ('PROCEDURE', 'FUNCTION')),
+    definition TEXT NOT NULL,
+    comment TEXT NOT NULL,
+    UNIQUE(database_id, `type`, name)
+);
+
+INSERT INTO
+    sqlite_sequence (name, seq)
+VALUES
+    ('routine', 100);
+
+CREATE TRIGGER IF NOT EXISTS `trigger_update_routine_modification_time`
+AFTER
+UPDATE
+    ON `routine` FOR EACH ROW BEGIN
+UPDATE
+    `routine`
+SET
+    updated_ts = (strftime('%s', 'now'))
+WHERE
+    rowid = old.rowid;
+
+END;
+
+-- trig stores the triggers of a particular database, data is synced periodically from the instance
+CREATE TABLE trig (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    row_status TEXT NOT NULL CHECK (
+        row_status IN ('NORMAL', 'ARCHIVED')
+    ) DEFAULT 'NORMAL',
+    creator_id INTEGER NOT NULL REFERENCES principal (id),
+    created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    updater_id INTEGER NOT NULL REFERENCES principal (id),
+    updated_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    database_id INTEGER NOT NULL REFERENCES db (id),
+    name TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+    timing TEXT NOT NULL,
+    event TEXT NOT NULL,
+    definition TEXT NOT NULL,
+    UNIQUE(database_id, table_name, name)
+);
+
+INSERT INTO
+    sqlite_sequence (name, seq)
+VALUES
+    ('trig', 100);
+
+CREATE TRIGGER IF NOT EXISTS `trigger_update_trig_modification_time`
+AFTER
+UPDATE
+    ON `trig` FOR EACH ROW BEGIN
+UPDATE
+    `trig`
+SET
+    updated_ts = (strftime('%s', 'now'))
+WHERE
+    rowid = old.rowid;
+
+END;
+
+-- event stores the scheduled events of a particular database, data is synced periodically from the instance
+CREATE TABLE event (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    row_status TEXT NOT NULL CHECK (
+        row_status IN ('NORMAL', 'ARCHIVED')
+    ) DEFAULT 'NORMAL',
+    creator_id INTEGER NOT NULL REFERENCES principal (id),
+    created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    updater_id INTEGER NOT NULL REFERENCES principal (id),
+    updated_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    database_id INTEGER NOT NULL REFERENCES db (id),
+    name TEXT NOT NULL,
+    definition TEXT NOT NULL,
+    schedule TEXT NOT NULL,
+    status TEXT NOT NULL,
+    comment TEXT NOT NULL,
+    UNIQUE(database_id, name)
+);
+
+INSERT INTO
+    sqlite_sequence (name, seq)
+VALUES
+    ('event', 100);
+
+CREATE TRIGGER IF NOT EXISTS `trigger_update_event_modification_time`
+AFTER
+UPDATE
+    ON `event` FOR EACH ROW BEGIN
+UPDATE
+    `event`
+SET
+    updated_ts = (strftime('%s', 'now'))
+WHERE
+    rowid = old.rowid;
+
+END;
@@ -0,0 +1,49 @@
+PRAGMA user_version = 10005;
+
+-- tbl_constraint stores the primary keys, foreign keys and check constraints of a particular table,
+-- data is synced periodically from the instance
+CREATE TABLE tbl_constraint (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    row_status TEXT NOT NULL CHECK (
+        row_status IN ('NORMAL', 'ARCHIVED')
+    ) DEFAULT 'NORMAL',
+    creator_id INTEGER NOT NULL REFERENCES principal (id),
+    created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    updater_id INTEGER NOT NULL REFERENCES principal (id),
+    updated_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    database_id INTEGER NOT NULL REFERENCES db (id),
+    table_id INTEGER NOT NULL REFERENCES tbl (id),
+    name TEXT NOT NULL,
+    `type` TEXT NOT NULL CHECK (
+        `type` IN ('PRIMARY KEY', 'FOREIGN KEY', 'CHECK')
+    ),
+    -- JSON array of the constrained column names
+    column_list TEXT NOT NULL,
+    referenced_table TEXT NOT NULL,
+    -- JSON array of the referenced column names
+    referenced_column_list TEXT NOT NULL,
+    update_rule TEXT NOT NULL,
+    delete_rule TEXT NOT NULL,
+    expression TEXT NOT NULL,
+    UNIQUE(database_id, table_id, `type`, name)
+);
+
+CREATE INDEX idx_tbl_constraint_database_id_table_id ON tbl_constraint(database_id, table_id);
+
+INSERT INTO
+    sqlite_sequence (name, seq)
+VALUES
+    ('tbl_constraint', 100);
+
+CREATE TRIGGER IF NOT EXISTS `trigger_update_tbl_constraint_modification_time`
+AFTER
+UPDATE
+    ON `tbl_constraint` FOR EACH ROW BEGIN
+UPDATE
+    `tbl_constraint`
+SET
+    updated_ts = (strftime('%s', 'now'))
+WHERE
+    rowid = old.rowid;
+
+END;
@@ -0,0 +1,41 @@
+PRAGMA user_version = 10006;
+
+-- migration_snapshot stores the schema-only dump of a database right before and after a migration.
+-- The migration history itself lives in the instance, so the snapshot references it by the instance
+-- and the migration history id there.
+CREATE TABLE migration_snapshot (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    row_status TEXT NOT NULL CHECK (
+        row_status IN ('NORMAL', 'ARCHIVED')
+    ) DEFAULT 'NORMAL',
+    creator_id INTEGER NOT NULL REFERENCES principal (id),
+    created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    updater_id INTEGER NOT NULL REFERENCES principal (id),
+    updated_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    instance_id INTEGER NOT NULL REFERENCES instance (id),
+    database_name TEXT NOT NULL,
+    migration_history_id INTEGER NOT NULL,
+    schema_prev TEXT NOT NULL,
+    `schema` TEXT NOT NULL,
+    UNIQUE(instance_id, migration_history_id)
+);
+
+CREATE INDEX idx_migration_snapshot_instance_id_database_name ON migration_snapshot(instance_id, database_name);
+
+INSERT INTO
+    sqlite_sequence (name, seq)
+VALUES
+    ('migration_snapshot', 100);
+
+CREATE TRIGGER IF NOT EXISTS `trigger_update_migration_snapshot_modification_time`
+AFTER
+UPDATE
+    ON `migration_snapshot` FOR EACH ROW BEGIN
+UPDATE
+    `migration_snapshot`
+SET
+    updated_ts = (strftime('%s', 'now'))
+WHERE
+    rowid = old.rowid;
+
+END;
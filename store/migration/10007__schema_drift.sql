@@ -0,0 +1,46 @@
+PRAGMA user_version = 10007;
+
+-- The synced schema right after the migration, it's the expected schema for detecting drift
+-- until the next migration.
+ALTER TABLE migration_snapshot ADD COLUMN synced_schema TEXT NOT NULL DEFAULT '';
+
+-- schema_drift records the live schema diverging from the expected schema after the latest migration,
+-- e.g. someone changes the schema by hand outside of bytebase.
+CREATE TABLE schema_drift (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    row_status TEXT NOT NULL CHECK (
+        row_status IN ('NORMAL', 'ARCHIVED')
+    ) DEFAULT 'NORMAL',
+    creator_id INTEGER NOT NULL REFERENCES principal (id),
+    created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    updater_id INTEGER NOT NULL REFERENCES principal (id),
+    updated_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    database_id INTEGER NOT NULL REFERENCES db (id),
+    `status` TEXT NOT NULL CHECK (`status` IN ('OPEN', 'RESOLVED')),
+    -- The latest migration version when the drift is detected
+    version TEXT NOT NULL,
+    -- The live schema when the drift is detected
+    `schema` TEXT NOT NULL,
+    -- The unified diff from the expected schema to the live schema
+    diff TEXT NOT NULL
+);
+
+CREATE INDEX idx_schema_drift_database_id_status ON schema_drift(database_id, `status`);
+
+INSERT INTO
+    sqlite_sequence (name, seq)
+VALUES
+    ('schema_drift', 100);
+
+CREATE TRIGGER IF NOT EXISTS `trigger_update_schema_drift_modification_time`
+AFTER
+UPDATE
+    ON `schema_drift` FOR EACH ROW BEGIN
+UPDATE
+    `schema_drift`
+SET
+    updated_ts = (strftime('%s', 'now'))
+WHERE
+    rowid = old.rowid;
+
+END;
@@ -0,0 +1,10 @@
+PRAGMA user_version = 10008;
+
+-- The version scheme orders the migration versions of the repository. Existing repositories keep comparing the
+-- versions as plain strings, which is the behavior before introducing the version scheme.
+ALTER TABLE
+    repo
+ADD
+    COLUMN version_scheme TEXT NOT NULL CHECK (
+        version_scheme IN ('LEXICOGRAPHIC', 'NUMERIC', 'SEMVER', 'TIMESTAMP')
+    ) DEFAULT 'LEXICOGRAPHIC';
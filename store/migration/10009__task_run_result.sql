@@ -0,0 +1,8 @@
+PRAGMA user_version = 10009;
+
+-- The result of the task run, e.g. the status and duration of each statement of the schema update task.
+-- Updated while the task run is in progress, so it shows how far the task run goes if it fails halfway.
+ALTER TABLE
+    task_run
+ADD
+    COLUMN result TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+PRAGMA user_version = 10010;
+
+-- The glob pattern selecting the tenant databases of the project, to which the "@tenant" migration files apply.
+-- Empty means the project has no tenant databases.
+ALTER TABLE
+    project
+ADD
+    COLUMN tenant_database_pattern TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,18 @@
+PRAGMA user_version = 10011;
+
+-- Data migrations have their own approval policy. Existing environments keep the approval policy of the schema
+-- migrations, which data migrations followed before.
+ALTER TABLE
+    environment
+ADD
+    COLUMN data_approval_policy TEXT NOT NULL CHECK (
+        data_approval_policy IN (
+            'MANUAL_APPROVAL_NEVER',
+            'MANUAL_APPROVAL_ALWAYS'
+        )
+    ) DEFAULT 'MANUAL_APPROVAL_ALWAYS';
+
+UPDATE
+    environment
+SET
+    data_approval_policy = approval_policy;
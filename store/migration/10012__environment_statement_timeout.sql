@@ -0,0 +1,7 @@
+PRAGMA user_version = 10012;
+
+-- The timeout in seconds of a single statement executed by the tasks in the environment, 0 means no timeout.
+ALTER TABLE
+    environment
+ADD
+    COLUMN statement_timeout INTEGER NOT NULL CHECK (statement_timeout >= 0) DEFAULT 0;
@@ -0,0 +1,8 @@
+PRAGMA user_version = 10013;
+
+-- The time the temporary data source granted by a data source request expires, after which the database user is
+-- dropped and the data source is deleted. 0 means the data source never expires.
+ALTER TABLE
+    data_source
+ADD
+    COLUMN expire_ts BIGINT NOT NULL DEFAULT 0;
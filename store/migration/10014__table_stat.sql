@@ -0,0 +1,38 @@
+PRAGMA user_version = 10014;
+
+-- table_stat records the size of the tables over time. Each schema sync samples the tables into the HOUR and DAY
+-- buckets, the latest sample in a bucket wins. The HOUR samples are pruned sooner than the DAY samples.
+CREATE TABLE table_stat (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    updated_ts BIGINT NOT NULL DEFAULT (strftime('%s', 'now')),
+    database_id INTEGER NOT NULL REFERENCES db (id),
+    table_id INTEGER NOT NULL REFERENCES tbl (id),
+    granularity TEXT NOT NULL CHECK (granularity IN ('HOUR', 'DAY')),
+    -- The start of the bucket
+    ts BIGINT NOT NULL,
+    row_count BIGINT NOT NULL,
+    data_size BIGINT NOT NULL,
+    index_size BIGINT NOT NULL,
+    UNIQUE(table_id, granularity, ts)
+);
+
+CREATE INDEX idx_table_stat_database_id_granularity_ts ON table_stat(database_id, granularity, ts);
+
+INSERT INTO
+    sqlite_sequence (name, seq)
+VALUES
+    ('table_stat', 100);
+
+CREATE TRIGGER IF NOT EXISTS `trigger_update_table_stat_modification_time`
+AFTER
+UPDATE
+    ON `table_stat` FOR EACH ROW BEGIN
+UPDATE
+    `table_stat`
+SET
+    updated_ts = (strftime('%s', 'now'))
+WHERE
+    rowid = old.rowid;
+
+END;
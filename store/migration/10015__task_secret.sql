@@ -0,0 +1,8 @@
+PRAGMA user_version = 10015;
+
+-- The secret needed to run the task, e.g. the password of the user created by the database grant task. Unlike the
+-- payload, it's never returned by the API nor copied to the task run, and it's cleared once the task is done or canceled.
+ALTER TABLE
+    task
+ADD
+    COLUMN secret TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"go.uber.org/zap"
+)
+
+var (
+	_ api.MigrationSnapshotService = (*MigrationSnapshotService)(nil)
+)
+
+// MigrationSnapshotService represents a service for managing migration snapshot.
+type MigrationSnapshotService struct {
+	l  *zap.Logger
+	db *DB
+}
+
+// NewMigrationSnapshotService returns a new instance of MigrationSnapshotService.
+func NewMigrationSnapshotService(logger *zap.Logger, db *DB) *MigrationSnapshotService {
+	return &MigrationSnapshotService{l: logger, db: db}
+}
+
+// UpsertMigrationSnapshot would update the existing snapshot if the instance and migration history id match.
+func (s *MigrationSnapshotService) UpsertMigrationSnapshot(ctx context.Context, upsert *api.MigrationSnapshotUpsert) (*api.MigrationSnapshot, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	snapshot, err := upsertMigrationSnapshot(ctx, tx, upsert)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return snapshot, nil
+}
+
+// FindMigrationSnapshotList retrieves a list of migration snapshots based on find.
+func (s *MigrationSnapshotService) FindMigrationSnapshotList(ctx context.Context, find *api.MigrationSnapshotFind) ([]*api.MigrationSnapshot, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	list, err := findMigrationSnapshotList(ctx, tx, find)
+	if err != nil {
+		return []*api.MigrationSnapshot{}, err
+	}
+
+	return list, nil
+}
+
+// upsertMigrationSnapshot upserts a new migration snapshot.
+func upsertMigrationSnapshot(ctx context.Context, tx *Tx, upsert *api.MigrationSnapshotUpsert) (*api.MigrationSnapshot, error) {
+	// Upsert row into database.
+	row, err := tx.QueryContext(ctx, `
+		INSERT INTO migration_snapshot (
+			creator_id,
+			updater_id,
+			instance_id,
+			database_name,
+			migration_history_id,
+			schema_prev,
+			`+"`schema`,"+`
+			synced_schema
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (instance_id, migration_history_id) DO UPDATE SET
+			updater_id = excluded.updater_id,
+			database_name = excluded.database_name,
+			schema_prev = excluded.schema_prev,
+			`+"`schema`"+` = excluded.`+"`schema`,"+`
+			synced_schema = excluded.synced_schema
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, instance_id, database_name, migration_history_id, schema_prev, `+"`schema`"+`, synced_schema
+	`,
+		upsert.CreatorId,
+		upsert.CreatorId,
+		upsert.InstanceId,
+		upsert.DatabaseName,
+		upsert.MigrationHistoryId,
+		upsert.SchemaPrev,
+		upsert.Schema,
+		upsert.SyncedSchema,
+	)
+
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer row.Close()
+
+	row.Next()
+	var snapshot api.MigrationSnapshot
+	if err := row.Scan(
+		&snapshot.ID,
+		&snapshot.CreatorId,
+		&snapshot.CreatedTs,
+		&snapshot.UpdaterId,
+		&snapshot.UpdatedTs,
+		&snapshot.InstanceId,
+		&snapshot.DatabaseName,
+		&snapshot.MigrationHistoryId,
+		&snapshot.SchemaPrev,
+		&snapshot.Schema,
+		&snapshot.SyncedSchema,
+	); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return &snapshot, nil
+}
+
+func findMigrationSnapshotList(ctx context.Context, tx *Tx, find *api.MigrationSnapshotFind) (_ []*api.MigrationSnapshot, err error) {
+	// Build WHERE clause.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	where, args = append(where, "instance_id = ?"), append(args, find.InstanceId)
+	if v := find.DatabaseName; v != nil {
+		where, args = append(where, "database_name = ?"), append(args, *v)
+	}
+	if v := find.MigrationHistoryId; v != nil {
+		where, args = append(where, "migration_history_id = ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			creator_id,
+			created_ts,
+			updater_id,
+			updated_ts,
+			instance_id,
+			database_name,
+			migration_history_id,
+			schema_prev,
+			`+"`schema`,"+`
+			synced_schema
+		FROM migration_snapshot
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY migration_history_id DESC`,
+		args...,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over result set and deserialize rows into list.
+	list := make([]*api.MigrationSnapshot, 0)
+	for rows.Next() {
+		var snapshot api.MigrationSnapshot
+		if err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.CreatorId,
+			&snapshot.CreatedTs,
+			&snapshot.UpdaterId,
+			&snapshot.UpdatedTs,
+			&snapshot.InstanceId,
+			&snapshot.DatabaseName,
+			&snapshot.MigrationHistoryId,
+			&snapshot.SchemaPrev,
+			&snapshot.Schema,
+			&snapshot.SyncedSchema,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+
+		list = append(list, &snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return list, nil
+}
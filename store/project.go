@@ -167,7 +167,7 @@ func createProject(ctx context.Context, tx *Tx, create *api.ProjectCreate) (*api
 			visibility
 		)
 		VALUES (?, ?, ?, ?, 'UI', 'PUBLIC')
-		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, `+"`key`, workflow_type, visibility"+`
+		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, `+"`key`, workflow_type, visibility, tenant_database_pattern"+`
 	`,
 		create.CreatorId,
 		create.CreatorId,
@@ -193,6 +193,7 @@ func createProject(ctx context.Context, tx *Tx, create *api.ProjectCreate) (*api
 		&project.Key,
 		&project.WorkflowType,
 		&project.Visibility,
+		&project.TenantDatabasePattern,
 	); err != nil {
 		return nil, FormatError(err)
 	}
@@ -224,7 +225,8 @@ func findProjectList(ctx context.Context, tx *Tx, find *api.ProjectFind) (_ []*a
 			name,
 			key,
 			workflow_type,
-			visibility
+			visibility,
+			tenant_database_pattern
 		FROM project
 		WHERE `+strings.Join(where, " AND "),
 		args...,
@@ -249,6 +251,7 @@ func findProjectList(ctx context.Context, tx *Tx, find *api.ProjectFind) (_ []*a
 			&project.Key,
 			&project.WorkflowType,
 			&project.Visibility,
+			&project.TenantDatabasePattern,
 		); err != nil {
 			return nil, FormatError(err)
 		}
@@ -278,6 +281,9 @@ func patchProject(ctx context.Context, tx *sql.Tx, patch *api.ProjectPatch) (*ap
 	if v := patch.WorkflowType; v != nil {
 		set, args = append(set, "`workflow_type` = ?"), append(args, *v)
 	}
+	if v := patch.TenantDatabasePattern; v != nil {
+		set, args = append(set, "tenant_database_pattern = ?"), append(args, *v)
+	}
 
 	args = append(args, patch.ID)
 
@@ -286,7 +292,7 @@ func patchProject(ctx context.Context, tx *sql.Tx, patch *api.ProjectPatch) (*ap
 		UPDATE project
 		SET `+strings.Join(set, ", ")+`
 		WHERE id = ?
-		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, `+"`key`, workflow_type, visibility"+`
+		RETURNING id, row_status, creator_id, created_ts, updater_id, updated_ts, name, `+"`key`, workflow_type, visibility, tenant_database_pattern"+`
 	`,
 		args...,
 	)
@@ -308,6 +314,7 @@ func patchProject(ctx context.Context, tx *sql.Tx, patch *api.ProjectPatch) (*ap
 			&project.Key,
 			&project.WorkflowType,
 			&project.Visibility,
+			&project.TenantDatabasePattern,
 		); err != nil {
 			return nil, FormatError(err)
 		}
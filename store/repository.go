@@ -151,6 +151,7 @@ func (s *RepositoryService) createRepository(ctx context.Context, tx *Tx, create
 			web_url,
 			base_directory,
 			branch_filter,
+			version_scheme,
 			external_id,
 			external_webhook_id,
 			webhook_url_host,
@@ -160,8 +161,8 @@ func (s *RepositoryService) createRepository(ctx context.Context, tx *Tx, create
 			expires_ts,
 			refresh_token
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, base_directory, branch_filter, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, base_directory, branch_filter, version_scheme, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
 	`,
 		create.CreatorId,
 		create.CreatorId,
@@ -172,6 +173,7 @@ func (s *RepositoryService) createRepository(ctx context.Context, tx *Tx, create
 		create.WebURL,
 		create.BaseDirectory,
 		create.BranchFilter,
+		create.VersionScheme,
 		create.ExternalId,
 		create.ExternalWebhookId,
 		create.WebhookURLHost,
@@ -202,6 +204,7 @@ func (s *RepositoryService) createRepository(ctx context.Context, tx *Tx, create
 		&repository.WebURL,
 		&repository.BaseDirectory,
 		&repository.BranchFilter,
+		&repository.VersionScheme,
 		&repository.ExternalId,
 		&repository.ExternalWebhookId,
 		&repository.WebhookURLHost,
@@ -247,6 +250,7 @@ func findRepositoryList(ctx context.Context, tx *Tx, find *api.RepositoryFind) (
 			web_url,
 			base_directory,
 			branch_filter,
+			version_scheme,
 			external_id,
 			external_webhook_id,
 			webhook_url_host,
@@ -281,6 +285,7 @@ func findRepositoryList(ctx context.Context, tx *Tx, find *api.RepositoryFind) (
 			&repository.WebURL,
 			&repository.BaseDirectory,
 			&repository.BranchFilter,
+			&repository.VersionScheme,
 			&repository.ExternalId,
 			&repository.ExternalWebhookId,
 			&repository.WebhookURLHost,
@@ -312,6 +317,9 @@ func patchRepository(ctx context.Context, tx *Tx, patch *api.RepositoryPatch) (*
 	if v := patch.BranchFilter; v != nil {
 		set, args = append(set, "branch_filter = ?"), append(args, *v)
 	}
+	if v := patch.VersionScheme; v != nil {
+		set, args = append(set, "version_scheme = ?"), append(args, *v)
+	}
 
 	args = append(args, patch.ID)
 
@@ -320,7 +328,7 @@ func patchRepository(ctx context.Context, tx *Tx, patch *api.RepositoryPatch) (*
 		UPDATE repo
 		SET `+strings.Join(set, ", ")+`
 		WHERE id = ?
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, base_directory, branch_filter, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, vcs_id, project_id, name, full_path, web_url, base_directory, branch_filter, version_scheme, external_id, external_webhook_id, webhook_url_host, webhook_endpoint_id, webhook_secret_token, access_token, expires_ts, refresh_token
 	`,
 		args...,
 	)
@@ -344,6 +352,7 @@ func patchRepository(ctx context.Context, tx *Tx, patch *api.RepositoryPatch) (*
 			&repository.WebURL,
 			&repository.BaseDirectory,
 			&repository.BranchFilter,
+			&repository.VersionScheme,
 			&repository.ExternalId,
 			&repository.ExternalWebhookId,
 			&repository.WebhookURLHost,
@@ -0,0 +1,208 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"go.uber.org/zap"
+)
+
+var (
+	_ api.RoutineService = (*RoutineService)(nil)
+)
+
+// RoutineService represents a service for managing routine.
+type RoutineService struct {
+	l  *zap.Logger
+	db *DB
+}
+
+// NewRoutineService returns a new instance of RoutineService.
+func NewRoutineService(logger *zap.Logger, db *DB) *RoutineService {
+	return &RoutineService{l: logger, db: db}
+}
+
+// UpsertRoutine would update the existing routine if both name and type match.
+func (s *RoutineService) UpsertRoutine(ctx context.Context, upsert *api.RoutineUpsert) (*api.Routine, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	routine, err := upsertRoutine(ctx, tx, upsert)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return routine, nil
+}
+
+// FindRoutineList retrieves a list of routines based on find.
+func (s *RoutineService) FindRoutineList(ctx context.Context, find *api.RoutineFind) ([]*api.Routine, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	list, err := findRoutineList(ctx, tx, find)
+	if err != nil {
+		return []*api.Routine{}, err
+	}
+
+	return list, nil
+}
+
+// DeleteRoutine deletes an existing routine by ID.
+// Returns ENOTFOUND if routine does not exist.
+func (s *RoutineService) DeleteRoutine(ctx context.Context, delete *api.RoutineDelete) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer tx.Rollback()
+
+	err = deleteRoutine(ctx, tx, delete)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return FormatError(err)
+	}
+
+	return nil
+}
+
+// upsertRoutine upserts a new routine.
+func upsertRoutine(ctx context.Context, tx *Tx, upsert *api.RoutineUpsert) (*api.Routine, error) {
+	// Upsert row into database.
+	row, err := tx.QueryContext(ctx, `
+		INSERT INTO routine (
+			creator_id,
+			updater_id,
+			database_id,
+			name,
+			`+"`type`"+`,
+			definition,
+			comment
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (database_id, `+"`type`"+`, name) DO UPDATE SET
+			updater_id = excluded.updater_id,
+			definition = excluded.definition,
+			comment = excluded.comment
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, database_id, name, `+"`type`"+`, definition, comment
+	`,
+		upsert.CreatorId,
+		upsert.CreatorId,
+		upsert.DatabaseId,
+		upsert.Name,
+		upsert.Type,
+		upsert.Definition,
+		upsert.Comment,
+	)
+
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer row.Close()
+
+	row.Next()
+	var routine api.Routine
+	if err := row.Scan(
+		&routine.ID,
+		&routine.CreatorId,
+		&routine.CreatedTs,
+		&routine.UpdaterId,
+		&routine.UpdatedTs,
+		&routine.DatabaseId,
+		&routine.Name,
+		&routine.Type,
+		&routine.Definition,
+		&routine.Comment,
+	); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return &routine, nil
+}
+
+func findRoutineList(ctx context.Context, tx *Tx, find *api.RoutineFind) (_ []*api.Routine, err error) {
+	// Build WHERE clause.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	where, args = append(where, "database_id = ?"), append(args, find.DatabaseId)
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			creator_id,
+			created_ts,
+			updater_id,
+			updated_ts,
+			database_id,
+			name,
+			`+"`type`"+`,
+			definition,
+			comment
+		FROM routine
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+"`type`"+` ASC, name ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over result set and deserialize rows into list.
+	list := make([]*api.Routine, 0)
+	for rows.Next() {
+		var routine api.Routine
+		if err := rows.Scan(
+			&routine.ID,
+			&routine.CreatorId,
+			&routine.CreatedTs,
+			&routine.UpdaterId,
+			&routine.UpdatedTs,
+			&routine.DatabaseId,
+			&routine.Name,
+			&routine.Type,
+			&routine.Definition,
+			&routine.Comment,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+
+		list = append(list, &routine)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return list, nil
+}
+
+// deleteRoutine permanently deletes a routine by ID.
+func deleteRoutine(ctx context.Context, tx *Tx, delete *api.RoutineDelete) error {
+	// Remove row from database.
+	result, err := tx.ExecContext(ctx, `DELETE FROM routine WHERE id = ?`, delete.ID)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return &common.Error{Code: common.ENOTFOUND, Message: fmt.Sprintf("routine ID not found: %d", delete.ID)}
+	}
+
+	return nil
+}
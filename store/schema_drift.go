@@ -0,0 +1,242 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"go.uber.org/zap"
+)
+
+var (
+	_ api.SchemaDriftService = (*SchemaDriftService)(nil)
+)
+
+// SchemaDriftService represents a service for managing schema drift.
+type SchemaDriftService struct {
+	l  *zap.Logger
+	db *DB
+}
+
+// NewSchemaDriftService returns a new instance of SchemaDriftService.
+func NewSchemaDriftService(logger *zap.Logger, db *DB) *SchemaDriftService {
+	return &SchemaDriftService{l: logger, db: db}
+}
+
+// CreateSchemaDrift creates a new open schema drift.
+func (s *SchemaDriftService) CreateSchemaDrift(ctx context.Context, create *api.SchemaDriftCreate) (*api.SchemaDrift, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	drift, err := createSchemaDrift(ctx, tx, create)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return drift, nil
+}
+
+// FindSchemaDriftList retrieves a list of schema drifts based on find.
+func (s *SchemaDriftService) FindSchemaDriftList(ctx context.Context, find *api.SchemaDriftFind) ([]*api.SchemaDrift, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	list, err := findSchemaDriftList(ctx, tx, find)
+	if err != nil {
+		return []*api.SchemaDrift{}, err
+	}
+
+	return list, nil
+}
+
+// PatchSchemaDrift updates an existing schema drift by ID.
+// Returns ENOTFOUND if schema drift does not exist.
+func (s *SchemaDriftService) PatchSchemaDrift(ctx context.Context, patch *api.SchemaDriftPatch) (*api.SchemaDrift, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	drift, err := patchSchemaDrift(ctx, tx, patch)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return drift, nil
+}
+
+// createSchemaDrift creates a new open schema drift.
+func createSchemaDrift(ctx context.Context, tx *Tx, create *api.SchemaDriftCreate) (*api.SchemaDrift, error) {
+	// Insert row into database.
+	row, err := tx.QueryContext(ctx, `
+		INSERT INTO schema_drift (
+			creator_id,
+			updater_id,
+			database_id,
+			`+"`status`,"+`
+			version,
+			`+"`schema`,"+`
+			diff
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, database_id, `+"`status`"+`, version, `+"`schema`"+`, diff
+	`,
+		create.CreatorId,
+		create.CreatorId,
+		create.DatabaseId,
+		api.SchemaDriftOpen,
+		create.Version,
+		create.Schema,
+		create.Diff,
+	)
+
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer row.Close()
+
+	row.Next()
+	var drift api.SchemaDrift
+	if err := row.Scan(
+		&drift.ID,
+		&drift.CreatorId,
+		&drift.CreatedTs,
+		&drift.UpdaterId,
+		&drift.UpdatedTs,
+		&drift.DatabaseId,
+		&drift.Status,
+		&drift.Version,
+		&drift.Schema,
+		&drift.Diff,
+	); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return &drift, nil
+}
+
+func findSchemaDriftList(ctx context.Context, tx *Tx, find *api.SchemaDriftFind) (_ []*api.SchemaDrift, err error) {
+	// Build WHERE clause.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := find.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := find.DatabaseId; v != nil {
+		where, args = append(where, "database_id = ?"), append(args, *v)
+	}
+	if v := find.Status; v != nil {
+		where, args = append(where, "`status` = ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			creator_id,
+			created_ts,
+			updater_id,
+			updated_ts,
+			database_id,
+			`+"`status`,"+`
+			version,
+			`+"`schema`,"+`
+			diff
+		FROM schema_drift
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id DESC`,
+		args...,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over result set and deserialize rows into list.
+	list := make([]*api.SchemaDrift, 0)
+	for rows.Next() {
+		var drift api.SchemaDrift
+		if err := rows.Scan(
+			&drift.ID,
+			&drift.CreatorId,
+			&drift.CreatedTs,
+			&drift.UpdaterId,
+			&drift.UpdatedTs,
+			&drift.DatabaseId,
+			&drift.Status,
+			&drift.Version,
+			&drift.Schema,
+			&drift.Diff,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+
+		list = append(list, &drift)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return list, nil
+}
+
+// patchSchemaDrift updates a schema drift by ID. Returns the new state of the schema drift after update.
+func patchSchemaDrift(ctx context.Context, tx *Tx, patch *api.SchemaDriftPatch) (*api.SchemaDrift, error) {
+	// Build UPDATE clause.
+	set, args := []string{"updater_id = ?"}, []interface{}{patch.UpdaterId}
+	if v := patch.Status; v != nil {
+		set, args = append(set, "`status` = ?"), append(args, *v)
+	}
+
+	args = append(args, patch.ID)
+
+	// Execute update query with RETURNING.
+	row, err := tx.QueryContext(ctx, `
+		UPDATE schema_drift
+		SET `+strings.Join(set, ", ")+`
+		WHERE id = ?
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, database_id, `+"`status`"+`, version, `+"`schema`"+`, diff
+	`,
+		args...,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer row.Close()
+
+	if row.Next() {
+		var drift api.SchemaDrift
+		if err := row.Scan(
+			&drift.ID,
+			&drift.CreatorId,
+			&drift.CreatedTs,
+			&drift.UpdaterId,
+			&drift.UpdatedTs,
+			&drift.DatabaseId,
+			&drift.Status,
+			&drift.Version,
+			&drift.Schema,
+			&drift.Diff,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+		return &drift, nil
+	}
+
+	return nil, &common.Error{Code: common.ENOTFOUND, Message: fmt.Sprintf("schema drift ID not found: %d", patch.ID)}
+}
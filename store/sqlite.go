@@ -265,6 +265,12 @@ func (db *DB) migrate() error {
 }
 
 // migrateFile runs a migration file within a transaction.
+//
+// Foreign key enforcement is turned off while running the migration so that we can recreate
+// a table referenced by others (e.g. to change its CHECK constraint), which is the procedure
+// recommended by https://www.sqlite.org/lang_altertable.html#otheralter. Since the pragma is
+// a no-op inside a transaction and only applies to the current connection, we pin a dedicated
+// connection, and verify the foreign key integrity before committing.
 func (db *DB) migrateFile(name string, up bool) error {
 	if up {
 		db.l.Info(fmt.Sprintf("Migrating %s...", name))
@@ -272,7 +278,19 @@ func (db *DB) migrateFile(name string, up bool) error {
 		db.l.Info(fmt.Sprintf("Migrating %s...", name))
 	}
 
-	tx, err := db.Db.Begin()
+	ctx := context.Background()
+	conn, err := db.Db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -285,9 +303,34 @@ func (db *DB) migrateFile(name string, up bool) error {
 		return err
 	}
 
+	if err := checkForeignKey(tx); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// checkForeignKey returns error if there is any foreign key violation.
+func checkForeignKey(tx *sql.Tx) error {
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table, parent string
+		var rowid sql.NullInt64
+		var fkid int
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return err
+		}
+		return fmt.Errorf("foreign key violation: table %q rowid %d references missing row in table %q", table, rowid.Int64, parent)
+	}
+
+	return rows.Err()
+}
+
 // Close closes the database connection.
 func (db *DB) Close() error {
 	// Close database.
@@ -263,6 +263,18 @@ func (s *TableService) patchTable(ctx context.Context, tx *Tx, patch *api.TableP
 	if v := patch.LastSuccessfulSyncTs; v != nil {
 		set, args = append(set, "last_successful_sync_ts = ?"), append(args, *v)
 	}
+	if v := patch.RowCount; v != nil {
+		set, args = append(set, "row_count = ?"), append(args, *v)
+	}
+	if v := patch.DataSize; v != nil {
+		set, args = append(set, "data_size = ?"), append(args, *v)
+	}
+	if v := patch.IndexSize; v != nil {
+		set, args = append(set, "index_size = ?"), append(args, *v)
+	}
+	if v := patch.DataFree; v != nil {
+		set, args = append(set, "data_free = ?"), append(args, *v)
+	}
 
 	args = append(args, patch.ID)
 
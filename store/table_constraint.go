@@ -0,0 +1,274 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"go.uber.org/zap"
+)
+
+var (
+	_ api.ConstraintService = (*ConstraintService)(nil)
+)
+
+// ConstraintService represents a service for managing constraint.
+type ConstraintService struct {
+	l  *zap.Logger
+	db *DB
+}
+
+// NewConstraintService returns a new instance of ConstraintService.
+func NewConstraintService(logger *zap.Logger, db *DB) *ConstraintService {
+	return &ConstraintService{l: logger, db: db}
+}
+
+// UpsertConstraint would update the existing constraint if both type and name match in the same table.
+func (s *ConstraintService) UpsertConstraint(ctx context.Context, upsert *api.ConstraintUpsert) (*api.Constraint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	constraint, err := upsertConstraint(ctx, tx, upsert)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return constraint, nil
+}
+
+// FindConstraintList retrieves a list of constraints based on find.
+func (s *ConstraintService) FindConstraintList(ctx context.Context, find *api.ConstraintFind) ([]*api.Constraint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	list, err := findConstraintList(ctx, tx, find)
+	if err != nil {
+		return []*api.Constraint{}, err
+	}
+
+	return list, nil
+}
+
+// DeleteConstraint deletes an existing constraint by ID.
+// Returns ENOTFOUND if constraint does not exist.
+func (s *ConstraintService) DeleteConstraint(ctx context.Context, delete *api.ConstraintDelete) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer tx.Rollback()
+
+	err = deleteConstraint(ctx, tx, delete)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return FormatError(err)
+	}
+
+	return nil
+}
+
+// upsertConstraint upserts a new constraint.
+func upsertConstraint(ctx context.Context, tx *Tx, upsert *api.ConstraintUpsert) (*api.Constraint, error) {
+	columnList, err := json.Marshal(nonNilStringList(upsert.ColumnList))
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	referencedColumnList, err := json.Marshal(nonNilStringList(upsert.ReferencedColumnList))
+	if err != nil {
+		return nil, FormatError(err)
+	}
+
+	// Upsert row into database.
+	row, err := tx.QueryContext(ctx, `
+		INSERT INTO tbl_constraint (
+			creator_id,
+			updater_id,
+			database_id,
+			table_id,
+			name,
+			`+"`type`,"+`
+			column_list,
+			referenced_table,
+			referenced_column_list,
+			update_rule,
+			delete_rule,
+			expression
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (database_id, table_id, `+"`type`"+`, name) DO UPDATE SET
+			updater_id = excluded.updater_id,
+			column_list = excluded.column_list,
+			referenced_table = excluded.referenced_table,
+			referenced_column_list = excluded.referenced_column_list,
+			update_rule = excluded.update_rule,
+			delete_rule = excluded.delete_rule,
+			expression = excluded.expression
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, database_id, table_id, name, `+"`type`"+`, column_list, referenced_table, referenced_column_list, update_rule, delete_rule, expression
+	`,
+		upsert.CreatorId,
+		upsert.CreatorId,
+		upsert.DatabaseId,
+		upsert.TableId,
+		upsert.Name,
+		upsert.Type,
+		string(columnList),
+		upsert.ReferencedTable,
+		string(referencedColumnList),
+		upsert.UpdateRule,
+		upsert.DeleteRule,
+		upsert.Expression,
+	)
+
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer row.Close()
+
+	row.Next()
+	var constraint api.Constraint
+	var columnListStr, referencedColumnListStr string
+	if err := row.Scan(
+		&constraint.ID,
+		&constraint.CreatorId,
+		&constraint.CreatedTs,
+		&constraint.UpdaterId,
+		&constraint.UpdatedTs,
+		&constraint.DatabaseId,
+		&constraint.TableId,
+		&constraint.Name,
+		&constraint.Type,
+		&columnListStr,
+		&constraint.ReferencedTable,
+		&referencedColumnListStr,
+		&constraint.UpdateRule,
+		&constraint.DeleteRule,
+		&constraint.Expression,
+	); err != nil {
+		return nil, FormatError(err)
+	}
+	if err := json.Unmarshal([]byte(columnListStr), &constraint.ColumnList); err != nil {
+		return nil, FormatError(err)
+	}
+	if err := json.Unmarshal([]byte(referencedColumnListStr), &constraint.ReferencedColumnList); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return &constraint, nil
+}
+
+func findConstraintList(ctx context.Context, tx *Tx, find *api.ConstraintFind) (_ []*api.Constraint, err error) {
+	// Build WHERE clause.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := find.DatabaseId; v != nil {
+		where, args = append(where, "database_id = ?"), append(args, *v)
+	}
+	if v := find.TableId; v != nil {
+		where, args = append(where, "table_id = ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			creator_id,
+			created_ts,
+			updater_id,
+			updated_ts,
+			database_id,
+			table_id,
+			name,
+			`+"`type`,"+`
+			column_list,
+			referenced_table,
+			referenced_column_list,
+			update_rule,
+			delete_rule,
+			expression
+		FROM tbl_constraint
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY database_id, table_id, CASE `+"`type`"+` WHEN 'PRIMARY KEY' THEN 1 WHEN 'FOREIGN KEY' THEN 2 ELSE 3 END, name ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over result set and deserialize rows into list.
+	list := make([]*api.Constraint, 0)
+	for rows.Next() {
+		var constraint api.Constraint
+		var columnListStr, referencedColumnListStr string
+		if err := rows.Scan(
+			&constraint.ID,
+			&constraint.CreatorId,
+			&constraint.CreatedTs,
+			&constraint.UpdaterId,
+			&constraint.UpdatedTs,
+			&constraint.DatabaseId,
+			&constraint.TableId,
+			&constraint.Name,
+			&constraint.Type,
+			&columnListStr,
+			&constraint.ReferencedTable,
+			&referencedColumnListStr,
+			&constraint.UpdateRule,
+			&constraint.DeleteRule,
+			&constraint.Expression,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+		if err := json.Unmarshal([]byte(columnListStr), &constraint.ColumnList); err != nil {
+			return nil, FormatError(err)
+		}
+		if err := json.Unmarshal([]byte(referencedColumnListStr), &constraint.ReferencedColumnList); err != nil {
+			return nil, FormatError(err)
+		}
+
+		list = append(list, &constraint)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return list, nil
+}
+
+// deleteConstraint permanently deletes a constraint by ID.
+func deleteConstraint(ctx context.Context, tx *Tx, delete *api.ConstraintDelete) error {
+	// Remove row from database.
+	result, err := tx.ExecContext(ctx, `DELETE FROM tbl_constraint WHERE id = ?`, delete.ID)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return &common.Error{Code: common.ENOTFOUND, Message: fmt.Sprintf("constraint ID not found: %d", delete.ID)}
+	}
+
+	return nil
+}
+
+// nonNilStringList returns an empty list for nil, so it's stored as "[]" instead of "null".
+func nonNilStringList(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
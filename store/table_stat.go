@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"go.uber.org/zap"
+)
+
+var (
+	_ api.TableStatService = (*TableStatService)(nil)
+)
+
+// TableStatService represents a service for managing table stat.
+type TableStatService struct {
+	l  *zap.Logger
+	db *DB
+}
+
+// NewTableStatService returns a new instance of TableStatService.
+func NewTableStatService(logger *zap.Logger, db *DB) *TableStatService {
+	return &TableStatService{l: logger, db: db}
+}
+
+// UpsertTableStat would update the sample in the same bucket of the table.
+func (s *TableStatService) UpsertTableStat(ctx context.Context, upsert *api.TableStatUpsert) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer tx.Rollback()
+
+	if err := upsertTableStat(ctx, tx, upsert); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return FormatError(err)
+	}
+
+	return nil
+}
+
+// FindTableStatPointList returns the stats summed up per bucket based on find, ordered by the bucket ts.
+func (s *TableStatService) FindTableStatPointList(ctx context.Context, find *api.TableStatFind) ([]*api.TableStatPoint, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	list, err := findTableStatPointList(ctx, tx, find)
+	if err != nil {
+		return []*api.TableStatPoint{}, err
+	}
+
+	return list, nil
+}
+
+// DeleteTableStat prunes the stats of the granularity before the ts.
+func (s *TableStatService) DeleteTableStat(ctx context.Context, delete *api.TableStatDelete) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteTableStat(ctx, tx, delete); err != nil {
+		return FormatError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return FormatError(err)
+	}
+
+	return nil
+}
+
+// upsertTableStat upserts the sample into the bucket of the table.
+func upsertTableStat(ctx context.Context, tx *Tx, upsert *api.TableStatUpsert) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO table_stat (
+			database_id,
+			table_id,
+			granularity,
+			ts,
+			row_count,
+			data_size,
+			index_size
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (table_id, granularity, ts) DO UPDATE SET
+			row_count = excluded.row_count,
+			data_size = excluded.data_size,
+			index_size = excluded.index_size
+	`,
+		upsert.DatabaseId,
+		upsert.TableId,
+		upsert.Granularity,
+		upsert.Ts,
+		upsert.RowCount,
+		upsert.DataSize,
+		upsert.IndexSize,
+	); err != nil {
+		return FormatError(err)
+	}
+
+	return nil
+}
+
+func findTableStatPointList(ctx context.Context, tx *Tx, find *api.TableStatFind) (_ []*api.TableStatPoint, err error) {
+	// Build WHERE clause.
+	where, args := []string{"table_stat.granularity = ?"}, []interface{}{find.Granularity}
+	if v := find.TableId; v != nil {
+		where, args = append(where, "table_stat.table_id = ?"), append(args, *v)
+	}
+	if v := find.DatabaseId; v != nil {
+		where, args = append(where, "table_stat.database_id = ?"), append(args, *v)
+	}
+	if v := find.ProjectId; v != nil {
+		where, args = append(where, "table_stat.database_id IN (SELECT id FROM db WHERE project_id = ?)"), append(args, *v)
+	}
+	if v := find.SinceTs; v != nil {
+		where, args = append(where, "table_stat.ts >= ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			ts,
+			SUM(row_count),
+			SUM(data_size),
+			SUM(index_size)
+		FROM table_stat
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY ts
+		ORDER BY ts ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over result set and deserialize rows into list.
+	list := make([]*api.TableStatPoint, 0)
+	for rows.Next() {
+		var point api.TableStatPoint
+		if err := rows.Scan(
+			&point.Ts,
+			&point.RowCount,
+			&point.DataSize,
+			&point.IndexSize,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+
+		list = append(list, &point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return list, nil
+}
+
+// deleteTableStat permanently deletes the stats of the granularity before the ts.
+func deleteTableStat(ctx context.Context, tx *Tx, delete *api.TableStatDelete) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM table_stat WHERE granularity = ? AND ts < ?`, delete.Granularity, delete.BeforeTs); err != nil {
+		return FormatError(err)
+	}
+
+	return nil
+}
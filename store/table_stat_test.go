@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bytebase/bytebase/api"
+	"go.uber.org/zap"
+)
+
+// newTestDB returns a new database seeded with the test data, see seed/test.
+func newTestDB(t *testing.T) *DB {
+	db := NewDB(zap.NewNop(), fmt.Sprintf("file:%s/bytebase_test.db", t.TempDir()), "seed/test", true /* forceResetSeed */, false)
+	if err := db.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return db
+}
+
+func TestTableStat(t *testing.T) {
+	ctx := context.Background()
+	s := NewTableStatService(zap.NewNop(), newTestDB(t))
+
+	// The table 7101 tbl1 of the database 7002 testdb_dev.
+	tableId, databaseId := 7101, 7002
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC).Unix()
+	sampleList := []struct {
+		ts       int64
+		rowCount int64
+	}{
+		{ts: start + 60, rowCount: 1},
+		// The latest sample in the same hour wins.
+		{ts: start + 30*60, rowCount: 2},
+		{ts: start + 2*3600 + 10, rowCount: 3},
+		// The next day.
+		{ts: start + 24*3600 + 5, rowCount: 4},
+		{ts: start + 25*3600, rowCount: 5},
+	}
+	for _, sample := range sampleList {
+		for _, granularity := range []api.TableStatGranularity{api.TableStatHour, api.TableStatDay} {
+			if err := s.UpsertTableStat(ctx, &api.TableStatUpsert{
+				DatabaseId:  databaseId,
+				TableId:     tableId,
+				Granularity: granularity,
+				Ts:          granularity.BucketTs(sample.ts),
+				RowCount:    sample.rowCount,
+				DataSize:    sample.rowCount * 100,
+				IndexSize:   sample.rowCount * 10,
+			}); err != nil {
+				t.Fatalf("failed to upsert table stat: %v", err)
+			}
+		}
+	}
+
+	find := func(granularity api.TableStatGranularity) []api.TableStatPoint {
+		pointList, err := s.FindTableStatPointList(ctx, &api.TableStatFind{
+			DatabaseId:  &databaseId,
+			Granularity: granularity,
+		})
+		if err != nil {
+			t.Fatalf("failed to find table stat: %v", err)
+		}
+		var list []api.TableStatPoint
+		for _, point := range pointList {
+			list = append(list, *point)
+		}
+		return list
+	}
+
+	hourList := []api.TableStatPoint{
+		{Ts: start, RowCount: 2, DataSize: 200, IndexSize: 20},
+		{Ts: start + 2*3600, RowCount: 3, DataSize: 300, IndexSize: 30},
+		{Ts: start + 24*3600, RowCount: 4, DataSize: 400, IndexSize: 40},
+		{Ts: start + 25*3600, RowCount: 5, DataSize: 500, IndexSize: 50},
+	}
+	if got := find(api.TableStatHour); !reflect.DeepEqual(got, hourList) {
+		t.Errorf("got hour stats %+v, want %+v", got, hourList)
+	}
+	dayList := []api.TableStatPoint{
+		{Ts: start, RowCount: 3, DataSize: 300, IndexSize: 30},
+		{Ts: start + 24*3600, RowCount: 5, DataSize: 500, IndexSize: 50},
+	}
+	if got := find(api.TableStatDay); !reflect.DeepEqual(got, dayList) {
+		t.Errorf("got day stats %+v, want %+v", got, dayList)
+	}
+
+	// Pruning the hour stats before the next day keeps the day stats.
+	if err := s.DeleteTableStat(ctx, &api.TableStatDelete{
+		Granularity: api.TableStatHour,
+		BeforeTs:    start + 24*3600,
+	}); err != nil {
+		t.Fatalf("failed to delete table stat: %v", err)
+	}
+	if got := find(api.TableStatHour); !reflect.DeepEqual(got, hourList[2:]) {
+		t.Errorf("got hour stats %+v after pruning, want %+v", got, hourList[2:])
+	}
+	if got := find(api.TableStatDay); !reflect.DeepEqual(got, dayList) {
+		t.Errorf("got day stats %+v after pruning the hour stats, want %+v", got, dayList)
+	}
+}
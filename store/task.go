@@ -99,6 +99,27 @@ func (s *TaskService) PatchTaskStatus(ctx context.Context, patch *api.TaskStatus
 	return task, nil
 }
 
+// PatchTaskRunResult updates the result of a task run, e.g. the progress of the running task run.
+// Returns ENOTFOUND if task run does not exist.
+func (s *TaskService) PatchTaskRunResult(ctx context.Context, patch *api.TaskRunResultPatch) (*api.TaskRun, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	taskRun, err := s.TaskRunService.PatchTaskRunResult(ctx, tx.Tx, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return taskRun, nil
+}
+
 // createTask creates a new task.
 func (s *TaskService) createTask(ctx context.Context, tx *Tx, create *api.TaskCreate) (*api.Task, error) {
 	var row *sql.Rows
@@ -115,10 +136,11 @@ func (s *TaskService) createTask(ctx context.Context, tx *Tx, create *api.TaskCr
 			name,
 			`+"`status`,"+`	
 			`+"`type`,"+`
-			payload	
+			payload,
+			secret
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, pipeline_id, stage_id, instance_id, database_id, name, `+"`status`, `type`, payload"+`
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, pipeline_id, stage_id, instance_id, database_id, name, `+"`status`, `type`, payload, secret"+`
 	`,
 			create.CreatorId,
 			create.CreatorId,
@@ -129,6 +151,7 @@ func (s *TaskService) createTask(ctx context.Context, tx *Tx, create *api.TaskCr
 			create.Status,
 			create.Type,
 			create.Payload,
+			create.Secret,
 		)
 	} else {
 		row, err = tx.QueryContext(ctx, `
@@ -142,10 +165,11 @@ func (s *TaskService) createTask(ctx context.Context, tx *Tx, create *api.TaskCr
 			name,
 			`+"`status`,"+`	
 			`+"`type`,"+`
-			payload	
+			payload,
+			secret
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, pipeline_id, stage_id, instance_id, database_id, name, `+"`status`, `type`, payload"+`
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, pipeline_id, stage_id, instance_id, database_id, name, `+"`status`, `type`, payload, secret"+`
 	`,
 			create.CreatorId,
 			create.CreatorId,
@@ -157,6 +181,7 @@ func (s *TaskService) createTask(ctx context.Context, tx *Tx, create *api.TaskCr
 			create.Status,
 			create.Type,
 			create.Payload,
+			create.Secret,
 		)
 	}
 
@@ -183,6 +208,7 @@ func (s *TaskService) createTask(ctx context.Context, tx *Tx, create *api.TaskCr
 		&task.Status,
 		&task.Type,
 		&task.Payload,
+		&task.Secret,
 	); err != nil {
 		return nil, FormatError(err)
 	}
@@ -237,7 +263,8 @@ func (s *TaskService) findTaskList(ctx context.Context, tx *Tx, find *api.TaskFi
 		    name,
 		    `+"`status`,"+`
 			`+"`type`,"+`
-			payload
+			payload,
+			secret
 		FROM task
 		WHERE `+strings.Join(where, " AND "),
 		args...,
@@ -265,6 +292,7 @@ func (s *TaskService) findTaskList(ctx context.Context, tx *Tx, find *api.TaskFi
 			&task.Status,
 			&task.Type,
 			&task.Payload,
+			&task.Secret,
 		); err != nil {
 			return nil, FormatError(err)
 		}
@@ -326,6 +354,9 @@ func (s *TaskService) patchTaskStatus(ctx context.Context, tx *Tx, patch *api.Ta
 				Type:      task.Type,
 				Payload:   task.Payload,
 			}
+			if patch.Payload != nil {
+				taskRunCreate.Payload = *patch.Payload
+			}
 			if _, err := s.TaskRunService.CreateTaskRun(ctx, tx.Tx, taskRunCreate); err != nil {
 				return nil, err
 			}
@@ -355,6 +386,13 @@ func (s *TaskService) patchTaskStatus(ctx context.Context, tx *Tx, patch *api.Ta
 	// Build UPDATE clause.
 	set, args := []string{"updater_id = ?"}, []interface{}{patch.UpdaterId}
 	set, args = append(set, "`status` = ?"), append(args, patch.Status)
+	if v := patch.Payload; v != nil {
+		set, args = append(set, "payload = ?"), append(args, *v)
+	}
+	// The task won't run again once done or canceled, so its secret is no longer needed.
+	if patch.Status == api.TaskDone || patch.Status == api.TaskCanceled {
+		set = append(set, "secret = ''")
+	}
 	args = append(args, patch.ID)
 
 	// Execute update query with RETURNING.
@@ -362,7 +400,7 @@ func (s *TaskService) patchTaskStatus(ctx context.Context, tx *Tx, patch *api.Ta
 		UPDATE task
 		SET `+strings.Join(set, ", ")+`
 		WHERE id = ?
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, pipeline_id, stage_id, instance_id, database_id, name, `+"`status`, `type`, payload"+`
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, pipeline_id, stage_id, instance_id, database_id, name, `+"`status`, `type`, payload, secret"+`
 	`,
 		args...,
 	)
@@ -387,6 +425,7 @@ func (s *TaskService) patchTaskStatus(ctx context.Context, tx *Tx, patch *api.Ta
 			&task.Status,
 			&task.Type,
 			&task.Payload,
+			&task.Secret,
 		); err != nil {
 			return nil, FormatError(err)
 		}
@@ -39,7 +39,7 @@ func (s *TaskRunService) CreateTaskRun(ctx context.Context, tx *sql.Tx, create *
 			payload
 		)
 		VALUES (?, ?, ?, ?, 'RUNNING', ?, ?)
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, task_id, name, `+"`status`, `type`, comment, payload"+`
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, task_id, name, `+"`status`, `type`, comment, payload, result"+`
 	`,
 		create.CreatorId,
 		create.CreatorId,
@@ -68,6 +68,7 @@ func (s *TaskRunService) CreateTaskRun(ctx context.Context, tx *sql.Tx, create *
 		&taskRun.Type,
 		&taskRun.Comment,
 		&taskRun.Payload,
+		&taskRun.Result,
 	); err != nil {
 		return nil, FormatError(err)
 	}
@@ -120,7 +121,7 @@ func (s *TaskRunService) PatchTaskRunStatus(ctx context.Context, tx *sql.Tx, pat
 		UPDATE task_run
 		SET `+strings.Join(set, ", ")+`
 		WHERE `+strings.Join(where, " AND ")+`
-		RETURNING id, creator_id, created_ts, updater_id, updated_ts, task_id, name, `+"`status`, `type`, comment, payload"+`
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, task_id, name, `+"`status`, `type`, comment, payload, result"+`
 	`,
 		args...,
 	)
@@ -144,6 +145,7 @@ func (s *TaskRunService) PatchTaskRunStatus(ctx context.Context, tx *sql.Tx, pat
 		&taskRun.Type,
 		&taskRun.Comment,
 		&taskRun.Payload,
+		&taskRun.Result,
 	); err != nil {
 		return nil, FormatError(err)
 	}
@@ -151,6 +153,48 @@ func (s *TaskRunService) PatchTaskRunStatus(ctx context.Context, tx *sql.Tx, pat
 	return &taskRun, nil
 }
 
+// PatchTaskRunResult updates a taskRun result. Returns the new state of the taskRun after update.
+func (s *TaskRunService) PatchTaskRunResult(ctx context.Context, tx *sql.Tx, patch *api.TaskRunResultPatch) (*api.TaskRun, error) {
+	row, err := tx.QueryContext(ctx, `
+		UPDATE task_run
+		SET result = ?
+		WHERE id = ?
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, task_id, name, `+"`status`, `type`, comment, payload, result"+`
+	`,
+		patch.Result,
+		patch.ID,
+	)
+
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer row.Close()
+
+	if row.Next() {
+		var taskRun api.TaskRun
+		if err := row.Scan(
+			&taskRun.ID,
+			&taskRun.CreatorId,
+			&taskRun.CreatedTs,
+			&taskRun.UpdaterId,
+			&taskRun.UpdatedTs,
+			&taskRun.TaskId,
+			&taskRun.Name,
+			&taskRun.Status,
+			&taskRun.Type,
+			&taskRun.Comment,
+			&taskRun.Payload,
+			&taskRun.Result,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+
+		return &taskRun, nil
+	}
+
+	return nil, &common.Error{Code: common.ENOTFOUND, Message: fmt.Sprintf("task run ID not found: %d", patch.ID)}
+}
+
 func (s *TaskRunService) findTaskRunList(ctx context.Context, tx *sql.Tx, find *api.TaskRunFind) (_ []*api.TaskRun, err error) {
 	// Build WHERE clause.
 	where, args := []string{"1 = 1"}, []interface{}{}
@@ -181,7 +225,8 @@ func (s *TaskRunService) findTaskRunList(ctx context.Context, tx *sql.Tx, find *
 			`+"`status`,"+`
 			`+"`type`,"+`
 			comment,
-			payload
+			payload,
+			result
 		FROM task_run
 		WHERE `+strings.Join(where, " AND "),
 		args...,
@@ -207,6 +252,7 @@ func (s *TaskRunService) findTaskRunList(ctx context.Context, tx *sql.Tx, find *
 			&taskRun.Type,
 			&taskRun.Comment,
 			&taskRun.Payload,
+			&taskRun.Result,
 		); err != nil {
 			return nil, FormatError(err)
 		}
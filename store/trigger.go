@@ -0,0 +1,214 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"go.uber.org/zap"
+)
+
+var (
+	_ api.TriggerService = (*TriggerService)(nil)
+)
+
+// TriggerService represents a service for managing trigger.
+type TriggerService struct {
+	l  *zap.Logger
+	db *DB
+}
+
+// NewTriggerService returns a new instance of TriggerService.
+func NewTriggerService(logger *zap.Logger, db *DB) *TriggerService {
+	return &TriggerService{l: logger, db: db}
+}
+
+// UpsertTrigger would update the existing trigger if both table name and name match.
+func (s *TriggerService) UpsertTrigger(ctx context.Context, upsert *api.TriggerUpsert) (*api.Trigger, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	trigger, err := upsertTrigger(ctx, tx, upsert)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return trigger, nil
+}
+
+// FindTriggerList retrieves a list of triggers based on find.
+func (s *TriggerService) FindTriggerList(ctx context.Context, find *api.TriggerFind) ([]*api.Trigger, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	list, err := findTriggerList(ctx, tx, find)
+	if err != nil {
+		return []*api.Trigger{}, err
+	}
+
+	return list, nil
+}
+
+// DeleteTrigger deletes an existing trigger by ID.
+// Returns ENOTFOUND if trigger does not exist.
+func (s *TriggerService) DeleteTrigger(ctx context.Context, delete *api.TriggerDelete) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer tx.Rollback()
+
+	err = deleteTrigger(ctx, tx, delete)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return FormatError(err)
+	}
+
+	return nil
+}
+
+// upsertTrigger upserts a new trigger.
+func upsertTrigger(ctx context.Context, tx *Tx, upsert *api.TriggerUpsert) (*api.Trigger, error) {
+	// Upsert row into database.
+	row, err := tx.QueryContext(ctx, `
+		INSERT INTO trig (
+			creator_id,
+			updater_id,
+			database_id,
+			name,
+			table_name,
+			timing,
+			event,
+			definition
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (database_id, table_name, name) DO UPDATE SET
+			updater_id = excluded.updater_id,
+			timing = excluded.timing,
+			event = excluded.event,
+			definition = excluded.definition
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, database_id, name, table_name, timing, event, definition
+	`,
+		upsert.CreatorId,
+		upsert.CreatorId,
+		upsert.DatabaseId,
+		upsert.Name,
+		upsert.TableName,
+		upsert.Timing,
+		upsert.Event,
+		upsert.Definition,
+	)
+
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer row.Close()
+
+	row.Next()
+	var trigger api.Trigger
+	if err := row.Scan(
+		&trigger.ID,
+		&trigger.CreatorId,
+		&trigger.CreatedTs,
+		&trigger.UpdaterId,
+		&trigger.UpdatedTs,
+		&trigger.DatabaseId,
+		&trigger.Name,
+		&trigger.TableName,
+		&trigger.Timing,
+		&trigger.Event,
+		&trigger.Definition,
+	); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return &trigger, nil
+}
+
+func findTriggerList(ctx context.Context, tx *Tx, find *api.TriggerFind) (_ []*api.Trigger, err error) {
+	// Build WHERE clause.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	where, args = append(where, "database_id = ?"), append(args, find.DatabaseId)
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			creator_id,
+			created_ts,
+			updater_id,
+			updated_ts,
+			database_id,
+			name,
+			table_name,
+			timing,
+			event,
+			definition
+		FROM trig
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY table_name ASC, name ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over result set and deserialize rows into list.
+	list := make([]*api.Trigger, 0)
+	for rows.Next() {
+		var trigger api.Trigger
+		if err := rows.Scan(
+			&trigger.ID,
+			&trigger.CreatorId,
+			&trigger.CreatedTs,
+			&trigger.UpdaterId,
+			&trigger.UpdatedTs,
+			&trigger.DatabaseId,
+			&trigger.Name,
+			&trigger.TableName,
+			&trigger.Timing,
+			&trigger.Event,
+			&trigger.Definition,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+
+		list = append(list, &trigger)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return list, nil
+}
+
+// deleteTrigger permanently deletes a trigger by ID.
+func deleteTrigger(ctx context.Context, tx *Tx, delete *api.TriggerDelete) error {
+	// Remove row from database.
+	result, err := tx.ExecContext(ctx, `DELETE FROM trig WHERE id = ?`, delete.ID)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return &common.Error{Code: common.ENOTFOUND, Message: fmt.Sprintf("trigger ID not found: %d", delete.ID)}
+	}
+
+	return nil
+}
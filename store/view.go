@@ -0,0 +1,203 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bytebase/bytebase/api"
+	"github.com/bytebase/bytebase/common"
+	"go.uber.org/zap"
+)
+
+var (
+	_ api.ViewService = (*ViewService)(nil)
+)
+
+// ViewService represents a service for managing view.
+type ViewService struct {
+	l  *zap.Logger
+	db *DB
+}
+
+// NewViewService returns a new instance of ViewService.
+func NewViewService(logger *zap.Logger, db *DB) *ViewService {
+	return &ViewService{l: logger, db: db}
+}
+
+// UpsertView would update the existing view if name matches.
+func (s *ViewService) UpsertView(ctx context.Context, upsert *api.ViewUpsert) (*api.View, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	view, err := upsertView(ctx, tx, upsert)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return view, nil
+}
+
+// FindViewList retrieves a list of views based on find.
+func (s *ViewService) FindViewList(ctx context.Context, find *api.ViewFind) ([]*api.View, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer tx.Rollback()
+
+	list, err := findViewList(ctx, tx, find)
+	if err != nil {
+		return []*api.View{}, err
+	}
+
+	return list, nil
+}
+
+// DeleteView deletes an existing view by ID.
+// Returns ENOTFOUND if view does not exist.
+func (s *ViewService) DeleteView(ctx context.Context, delete *api.ViewDelete) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer tx.Rollback()
+
+	err = deleteView(ctx, tx, delete)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return FormatError(err)
+	}
+
+	return nil
+}
+
+// upsertView upserts a new view.
+func upsertView(ctx context.Context, tx *Tx, upsert *api.ViewUpsert) (*api.View, error) {
+	// Upsert row into database.
+	row, err := tx.QueryContext(ctx, `
+		INSERT INTO vw (
+			creator_id,
+			updater_id,
+			database_id,
+			name,
+			definition,
+			comment
+		)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (database_id, name) DO UPDATE SET
+			updater_id = excluded.updater_id,
+			definition = excluded.definition,
+			comment = excluded.comment
+		RETURNING id, creator_id, created_ts, updater_id, updated_ts, database_id, name, definition, comment
+	`,
+		upsert.CreatorId,
+		upsert.CreatorId,
+		upsert.DatabaseId,
+		upsert.Name,
+		upsert.Definition,
+		upsert.Comment,
+	)
+
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer row.Close()
+
+	row.Next()
+	var view api.View
+	if err := row.Scan(
+		&view.ID,
+		&view.CreatorId,
+		&view.CreatedTs,
+		&view.UpdaterId,
+		&view.UpdatedTs,
+		&view.DatabaseId,
+		&view.Name,
+		&view.Definition,
+		&view.Comment,
+	); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return &view, nil
+}
+
+func findViewList(ctx context.Context, tx *Tx, find *api.ViewFind) (_ []*api.View, err error) {
+	// Build WHERE clause.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	where, args = append(where, "database_id = ?"), append(args, find.DatabaseId)
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			creator_id,
+			created_ts,
+			updater_id,
+			updated_ts,
+			database_id,
+			name,
+			definition,
+			comment
+		FROM vw
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY name ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over result set and deserialize rows into list.
+	list := make([]*api.View, 0)
+	for rows.Next() {
+		var view api.View
+		if err := rows.Scan(
+			&view.ID,
+			&view.CreatorId,
+			&view.CreatedTs,
+			&view.UpdaterId,
+			&view.UpdatedTs,
+			&view.DatabaseId,
+			&view.Name,
+			&view.Definition,
+			&view.Comment,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+
+		list = append(list, &view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+
+	return list, nil
+}
+
+// deleteView permanently deletes a view by ID.
+func deleteView(ctx context.Context, tx *Tx, delete *api.ViewDelete) error {
+	// Remove row from database.
+	result, err := tx.ExecContext(ctx, `DELETE FROM vw WHERE id = ?`, delete.ID)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return &common.Error{Code: common.ENOTFOUND, Message: fmt.Sprintf("view ID not found: %d", delete.ID)}
+	}
+
+	return nil
+}